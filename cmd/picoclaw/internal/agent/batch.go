@@ -0,0 +1,266 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/agent"
+)
+
+// Output formats for non-interactive runs.
+const (
+	outputText  = "text"
+	outputJSON  = "json"
+	outputJSONL = "jsonl"
+)
+
+const maxPromptLine = 4 * 1024 * 1024
+
+// batchRunner is the part of the agent loop batch mode drives.
+type batchRunner interface {
+	turnRunner
+	SetEventHandler(handler agent.EventHandler)
+}
+
+// batchPrompt is one line of batch input. Lines that are not JSON objects
+// are taken as the prompt text.
+type batchPrompt struct {
+	ID      string `json:"id,omitempty"`
+	Prompt  string `json:"prompt"`
+	Session string `json:"session,omitempty"`
+}
+
+type batchResult struct {
+	ID         string          `json:"id,omitempty"`
+	Prompt     string          `json:"prompt"`
+	SessionKey string          `json:"session_key"`
+	Content    string          `json:"content"`
+	Model      string          `json:"model,omitempty"`
+	Provider   string          `json:"provider,omitempty"`
+	ToolCalls  []batchToolCall `json:"tool_calls"`
+	Usage      batchUsage      `json:"usage"`
+	Iterations int             `json:"iterations"`
+	DurationMS int64           `json:"duration_ms"`
+	Error      string          `json:"error,omitempty"`
+}
+
+type batchToolCall struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	Arguments  map[string]any `json:"arguments"`
+	Result     string         `json:"result"`
+	IsError    bool           `json:"is_error,omitempty"`
+	DurationMS int64          `json:"duration_ms"`
+}
+
+type batchUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// batchFailedError reports how many prompts of a batch failed, so the
+// command exits non-zero after all results have been written.
+type batchFailedError struct {
+	failed, total int
+}
+
+func (e *batchFailedError) Error() string {
+	return fmt.Sprintf("%d of %d prompt(s) failed", e.failed, e.total)
+}
+
+func validOutput(format string) error {
+	switch format {
+	case outputText, outputJSON, outputJSONL:
+		return nil
+	}
+	return fmt.Errorf("invalid --output %q (want text, json or jsonl)", format)
+}
+
+// parsePromptLine parses a line of batch input; ok is false for blank lines.
+func parsePromptLine(line string) (p batchPrompt, ok bool, err error) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return p, false, nil
+	}
+	if !strings.HasPrefix(line, "{") {
+		return batchPrompt{Prompt: line}, true, nil
+	}
+	if err := json.Unmarshal([]byte(line), &p); err != nil {
+		return p, true, fmt.Errorf("invalid prompt: %w", err)
+	}
+	if strings.TrimSpace(p.Prompt) == "" {
+		return p, true, fmt.Errorf("prompt is empty")
+	}
+	return p, true, nil
+}
+
+// runBatch runs each prompt read from r in turn and writes the results to
+// w in the given format; in text format errors go to errW instead.
+// Prompts without a session use sessionKey.
+// Results are written even when prompts fail; a *batchFailedError is
+// returned if any did.
+func runBatch(
+	ctx context.Context,
+	runner batchRunner,
+	agentID, sessionKey string,
+	r io.Reader,
+	w, errW io.Writer,
+	format string,
+) error {
+	var (
+		results []*batchResult
+		failed  int
+		total   int
+		lineNo  int
+	)
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxPromptLine)
+	for scanner.Scan() {
+		lineNo++
+		p, ok, err := parsePromptLine(scanner.Text())
+		if !ok {
+			continue
+		}
+		total++
+
+		var res *batchResult
+		if err != nil {
+			res = &batchResult{ID: p.ID, Prompt: p.Prompt, Error: fmt.Sprintf("line %d: %v", lineNo, err)}
+		} else {
+			session := p.Session
+			if session == "" {
+				session = sessionKey
+			}
+			res = runPrompt(ctx, runner, p, sessionKeyFor(agentID, session))
+		}
+		if res.Error != "" {
+			failed++
+		}
+
+		switch format {
+		case outputJSONL:
+			if err := enc.Encode(res); err != nil {
+				return err
+			}
+		case outputJSON:
+			results = append(results, res)
+		default:
+			writeTextResult(w, errW, res)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading prompts: %w", err)
+	}
+
+	if format == outputJSON {
+		if results == nil {
+			results = []*batchResult{}
+		}
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return &batchFailedError{failed: failed, total: total}
+	}
+	return nil
+}
+
+// runPrompt runs one turn and records the tool calls, usage and model
+// reported by the agent's turn events.
+func runPrompt(ctx context.Context, runner batchRunner, p batchPrompt, sessionKey string) *batchResult {
+	res := &batchResult{
+		ID:         p.ID,
+		Prompt:     p.Prompt,
+		SessionKey: sessionKey,
+		ToolCalls:  []batchToolCall{},
+	}
+
+	rec := &turnRecorder{res: res, tools: make(map[string]int)}
+	runner.SetEventHandler(rec.record)
+	defer runner.SetEventHandler(nil)
+
+	start := time.Now()
+	content, err := runner.ProcessDirect(ctx, p.Prompt, sessionKey)
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	res.DurationMS = time.Since(start).Milliseconds()
+	res.Content = content
+	if err != nil {
+		res.Error = err.Error()
+	}
+	return res
+}
+
+// turnRecorder fills in a result from the turn's events.
+type turnRecorder struct {
+	mu       sync.Mutex
+	res      *batchResult
+	tools    map[string]int // tool call ID -> index in ToolCalls
+	fellBack bool           // the current iteration was answered by a fallback
+}
+
+func (rec *turnRecorder) record(ev agent.Event) {
+	res := rec.res
+	if !inSession(ev.SessionKey, res.SessionKey) {
+		return // e.g. a subagent's turn
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	switch ev.Kind {
+	case agent.EventIteration:
+		res.Iterations = ev.Iteration
+		rec.fellBack = false
+	case agent.EventFallback:
+		res.Provider = ev.Provider
+		res.Model = ev.Model
+		rec.fellBack = true
+	case agent.EventLLMResponse:
+		if !rec.fellBack {
+			res.Provider = ""
+			res.Model = ev.Model
+		}
+		if ev.Usage != nil {
+			res.Usage.PromptTokens += ev.Usage.PromptTokens
+			res.Usage.CompletionTokens += ev.Usage.CompletionTokens
+			res.Usage.TotalTokens += ev.Usage.TotalTokens
+		}
+	case agent.EventToolCall:
+		rec.tools[ev.ToolCallID] = len(res.ToolCalls)
+		res.ToolCalls = append(res.ToolCalls, batchToolCall{
+			ID:        ev.ToolCallID,
+			Name:      ev.Tool,
+			Arguments: ev.Arguments,
+		})
+	case agent.EventToolResult:
+		if i, ok := rec.tools[ev.ToolCallID]; ok {
+			call := &res.ToolCalls[i]
+			call.Result = ev.Result
+			call.IsError = ev.IsError
+			call.DurationMS = ev.Duration.Milliseconds()
+		}
+	}
+}
+
+func writeTextResult(w, errW io.Writer, res *batchResult) {
+	if res.Error != "" {
+		fmt.Fprintf(errW, "Error: %s\n", res.Error)
+		return
+	}
+	fmt.Fprintf(w, "%s\n\n", res.Content)
+}
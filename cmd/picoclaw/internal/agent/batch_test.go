@@ -0,0 +1,161 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// scriptedRunner answers every prompt after one tool call, emitting the
+// events the agent loop would. Prompts containing "fail" return an error.
+type scriptedRunner struct {
+	handler  agent.EventHandler
+	sessions []string
+}
+
+func (r *scriptedRunner) SetEventHandler(h agent.EventHandler) { r.handler = h }
+
+func (r *scriptedRunner) ProcessDirect(_ context.Context, content, sessionKey string) (string, error) {
+	r.sessions = append(r.sessions, sessionKey)
+	if strings.Contains(content, "fail") {
+		return "", errors.New("provider unavailable")
+	}
+
+	emit := func(ev agent.Event) {
+		ev.SessionKey = sessionKey
+		r.handler(ev)
+	}
+	emit(agent.Event{Kind: agent.EventIteration, Iteration: 1, Model: "primary"})
+	emit(agent.Event{Kind: agent.EventFallback, Iteration: 1, Provider: "backup", Model: "backup-model"})
+	emit(agent.Event{
+		Kind: agent.EventLLMResponse, Iteration: 1, Model: "primary",
+		Usage: &providers.UsageInfo{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+	})
+	emit(agent.Event{
+		Kind: agent.EventToolCall, Iteration: 1, ToolCallID: "c1",
+		Tool: "read_file", Arguments: map[string]any{"path": "a.txt"},
+	})
+	emit(agent.Event{
+		Kind: agent.EventToolResult, Iteration: 1, ToolCallID: "c1",
+		Tool: "read_file", Result: "hello", Duration: 3 * time.Millisecond,
+	})
+	emit(agent.Event{Kind: agent.EventIteration, Iteration: 2, Model: "primary"})
+	emit(agent.Event{
+		Kind: agent.EventLLMResponse, Iteration: 2, Model: "primary",
+		Usage: &providers.UsageInfo{PromptTokens: 20, CompletionTokens: 7, TotalTokens: 27},
+	})
+	// A subagent's events are not part of this turn.
+	r.handler(agent.Event{
+		Kind: agent.EventLLMResponse, SessionKey: "agent:main:subagent",
+		Usage: &providers.UsageInfo{PromptTokens: 1000},
+	})
+	return "answer to " + content, nil
+}
+
+func TestParsePromptLine(t *testing.T) {
+	p, ok, err := parsePromptLine(`  {"id": "q1", "prompt": "hi", "session": "ci"}  `)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, batchPrompt{ID: "q1", Prompt: "hi", Session: "ci"}, p)
+
+	p, ok, err = parsePromptLine("what is 2+2?")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "what is 2+2?", p.Prompt)
+
+	_, ok, _ = parsePromptLine("   ")
+	assert.False(t, ok)
+
+	_, ok, err = parsePromptLine(`{"id": "q2"}`)
+	assert.True(t, ok)
+	assert.Error(t, err)
+
+	_, _, err = parsePromptLine(`{"prompt": `)
+	assert.Error(t, err)
+}
+
+func TestRunBatch_JSONL(t *testing.T) {
+	runner := &scriptedRunner{}
+	input := strings.Join([]string{
+		`{"id": "q1", "prompt": "first"}`,
+		``,
+		`second`,
+		`{"id": "q3", "prompt": "third", "session": "ci"}`,
+	}, "\n")
+
+	var out, errOut bytes.Buffer
+	err := runBatch(context.Background(), runner, "main", "cli:default",
+		strings.NewReader(input), &out, &errOut, outputJSONL)
+	require.NoError(t, err)
+	assert.Nil(t, runner.handler, "event handler should be removed after each prompt")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+
+	var first batchResult
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	assert.Equal(t, "q1", first.ID)
+	assert.Equal(t, "answer to first", first.Content)
+	assert.Equal(t, "agent:main:main", first.SessionKey)
+	assert.Equal(t, 2, first.Iterations)
+	assert.Equal(t, batchUsage{PromptTokens: 30, CompletionTokens: 12, TotalTokens: 42}, first.Usage)
+	// The last iteration was answered by the primary model.
+	assert.Equal(t, "primary", first.Model)
+	assert.Empty(t, first.Provider)
+	require.Len(t, first.ToolCalls, 1)
+	assert.Equal(t, batchToolCall{
+		ID: "c1", Name: "read_file", Arguments: map[string]any{"path": "a.txt"},
+		Result: "hello", DurationMS: 3,
+	}, first.ToolCalls[0])
+
+	assert.Equal(t, []string{"agent:main:main", "agent:main:main", "agent:main:cli:ci"}, runner.sessions)
+}
+
+func TestRunBatch_JSONReportsFailures(t *testing.T) {
+	runner := &scriptedRunner{}
+	input := "ok\nplease fail\n{\"id\": \"bad\"}\n"
+
+	var out, errOut bytes.Buffer
+	err := runBatch(context.Background(), runner, "main", "cli:default",
+		strings.NewReader(input), &out, &errOut, outputJSON)
+
+	var failed *batchFailedError
+	require.ErrorAs(t, err, &failed)
+	assert.Equal(t, "2 of 3 prompt(s) failed", err.Error())
+
+	var results []batchResult
+	require.NoError(t, json.Unmarshal(out.Bytes(), &results))
+	require.Len(t, results, 3)
+	assert.Empty(t, results[0].Error)
+	assert.Equal(t, "provider unavailable", results[1].Error)
+	assert.Equal(t, "bad", results[2].ID)
+	assert.Contains(t, results[2].Error, "line 3")
+	assert.Len(t, runner.sessions, 2, "invalid lines are not sent to the agent")
+}
+
+func TestRunBatch_Text(t *testing.T) {
+	var out, errOut bytes.Buffer
+	err := runBatch(context.Background(), &scriptedRunner{}, "main", "cli:default",
+		strings.NewReader("one\nfail two\n"), &out, &errOut, outputText)
+	require.Error(t, err)
+
+	assert.Equal(t, "answer to one\n\n", out.String())
+	assert.Equal(t, "Error: provider unavailable\n", errOut.String())
+}
+
+func TestValidOutput(t *testing.T) {
+	for _, f := range []string{outputText, outputJSON, outputJSONL} {
+		assert.NoError(t, validOutput(f))
+	}
+	assert.Error(t, validOutput("yaml"))
+}
@@ -5,37 +5,52 @@ import (
 )
 
 func NewAgentCommand() *cobra.Command {
-	var (
-		message    string
-		sessionKey string
-		model      string
-		debug      bool
-	)
+	var opts agentOptions
 
 	cmd := &cobra.Command{
 		Use:   "agent",
 		Short: "Interact with the agent directly",
-		Long: `Start an interactive chat session with the AI agent or send a single
-message in one-shot mode.
+		Long: `Start an interactive chat session with the AI agent, send a single
+message in one-shot mode, or run a batch of prompts.
 
 Use this command to converse with the configured agent. Run without flags
-to start an interactive REPL-like session. Use -m/--message to send a
-single message non-interactively and exit. You can override the model
+to start an interactive chat UI that shows tool calls, token usage and
+the active session as the agent works. Use --plain for a line-based
+prompt instead; it is also used when output is not a terminal. Use
+-m/--message to send a single message non-interactively and exit
+("-m -" reads the message from stdin). You can override the model
 with --model and control logging with --debug. The --session flag lets
 you choose a session namespace so conversations are kept separate.
+
+For scripting, --input-file (or piped stdin) runs one prompt per line.
+A line is either plain text or a JSON object such as
+{"id": "q1", "prompt": "...", "session": "ci"}. --output json or jsonl
+writes the reply, tool calls, token usage and model for each prompt.
+The command exits non-zero if any prompt fails, after writing all
+results. --tools limits the agent to the named tools and --no-tools
+disables tools entirely.
 `,
 		Example: `  picoclaw agent
-	  picoclaw agent -m "What time is it?" --model openai/gpt-4`,
+	  picoclaw agent --plain
+	  picoclaw agent -m "What time is it?" --model openai/gpt-4
+	  picoclaw agent -m "Summarize README.md" --tools read_file --output json
+	  picoclaw agent --input-file prompts.jsonl --output jsonl --no-tools
+	  git diff | picoclaw agent -m - --no-tools`,
 		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			return agentCmd(message, sessionKey, model, debug)
+			return agentCmd(opts)
 		},
 	}
 
-	cmd.Flags().BoolVarP(&debug, "debug", "d", false, "Enable debug logging")
-	cmd.Flags().StringVarP(&message, "message", "m", "", "Send a single message (non-interactive mode)")
-	cmd.Flags().StringVarP(&sessionKey, "session", "s", "cli:default", "Session key")
-	cmd.Flags().StringVarP(&model, "model", "", "", "Model to use")
+	cmd.Flags().BoolVarP(&opts.debug, "debug", "d", false, "Enable debug logging")
+	cmd.Flags().StringVarP(&opts.message, "message", "m", "", "Send a single message (non-interactive mode); - reads it from stdin")
+	cmd.Flags().StringVarP(&opts.sessionKey, "session", "s", "cli:default", "Session key")
+	cmd.Flags().StringVarP(&opts.model, "model", "", "", "Model to use")
+	cmd.Flags().BoolVar(&opts.plain, "plain", false, "Use a plain line-based prompt instead of the chat UI")
+	cmd.Flags().StringVarP(&opts.inputFile, "input-file", "f", "", "Run the prompts in a file, one per line (plain text or JSON); - reads stdin")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", outputText, "Output format: text, json or jsonl")
+	cmd.Flags().StringSliceVar(&opts.tools, "tools", nil, "Only allow these tools (comma-separated)")
+	cmd.Flags().BoolVar(&opts.noTools, "no-tools", false, "Disable all tools")
 
 	return cmd
 }
@@ -30,4 +30,9 @@ func TestNewAgentCommand(t *testing.T) {
 	assert.NotNil(t, cmd.Flags().Lookup("message"))
 	assert.NotNil(t, cmd.Flags().Lookup("session"))
 	assert.NotNil(t, cmd.Flags().Lookup("model"))
+	assert.NotNil(t, cmd.Flags().Lookup("plain"))
+	assert.NotNil(t, cmd.Flags().Lookup("input-file"))
+	assert.NotNil(t, cmd.Flags().Lookup("output"))
+	assert.NotNil(t, cmd.Flags().Lookup("tools"))
+	assert.NotNil(t, cmd.Flags().Lookup("no-tools"))
 }
@@ -3,6 +3,7 @@ package agent
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -18,14 +19,74 @@ import (
 	"github.com/sipeed/picoclaw/pkg/providers"
 )
 
-func agentCmd(message, sessionKey, model string, debug bool) error {
-	if sessionKey == "" {
-		sessionKey = "cli:default"
+type agentOptions struct {
+	message    string
+	sessionKey string
+	model      string
+	debug      bool
+	plain      bool
+	inputFile  string
+	output     string
+	tools      []string
+	noTools    bool
+}
+
+func agentCmd(opts agentOptions) error {
+	if opts.sessionKey == "" {
+		opts.sessionKey = "cli:default"
+	}
+	if opts.output == "" {
+		opts.output = outputText
+	}
+	if err := validOutput(opts.output); err != nil {
+		return err
+	}
+	if opts.noTools && len(opts.tools) > 0 {
+		return fmt.Errorf("--no-tools and --tools cannot be used together")
+	}
+	if opts.message != "" && opts.inputFile != "" {
+		return fmt.Errorf("--message and --input-file cannot be used together")
+	}
+
+	// Anything but the reply goes to stderr when scripting.
+	info := io.Writer(os.Stdout)
+	if opts.output != outputText {
+		info = os.Stderr
 	}
 
-	if debug {
+	if opts.debug {
 		logger.SetLevel(logger.DEBUG)
-		fmt.Println("🔍 Debug mode enabled")
+		fmt.Fprintln(info, "🔍 Debug mode enabled")
+	}
+
+	message := opts.message
+	if message == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("error reading message from stdin: %w", err)
+		}
+		message = strings.TrimSpace(string(data))
+		if message == "" {
+			return fmt.Errorf("no message on stdin")
+		}
+	}
+
+	var prompts io.Reader
+	switch {
+	case message != "":
+	case opts.inputFile == "-":
+		prompts = os.Stdin
+	case opts.inputFile != "":
+		f, err := os.Open(opts.inputFile)
+		if err != nil {
+			return fmt.Errorf("error opening input file: %w", err)
+		}
+		defer f.Close()
+		prompts = f
+	case !isTerminal(os.Stdin):
+		prompts = os.Stdin
+	case opts.output != outputText:
+		return fmt.Errorf("--output %s needs --message, --input-file or piped input", opts.output)
 	}
 
 	cfg, err := internal.LoadConfig()
@@ -33,8 +94,8 @@ func agentCmd(message, sessionKey, model string, debug bool) error {
 		return fmt.Errorf("error loading config: %w", err)
 	}
 
-	if model != "" {
-		cfg.Agents.Defaults.ModelName = model
+	if opts.model != "" {
+		cfg.Agents.Defaults.ModelName = opts.model
 	}
 
 	provider, modelID, err := providers.CreateProvider(cfg)
@@ -50,6 +111,12 @@ func agentCmd(message, sessionKey, model string, debug bool) error {
 	msgBus := bus.NewMessageBus()
 	agentLoop := agent.NewAgentLoop(cfg, msgBus, provider)
 
+	if opts.noTools || len(opts.tools) > 0 {
+		if err := agentLoop.RestrictTools(opts.tools); err != nil {
+			return err
+		}
+	}
+
 	// Print agent startup info (only for interactive mode)
 	startupInfo := agentLoop.GetStartupInfo()
 	logger.InfoCF("agent", "Agent initialized",
@@ -59,8 +126,26 @@ func agentCmd(message, sessionKey, model string, debug bool) error {
 			"skills_available": startupInfo["skills"].(map[string]any)["available"],
 		})
 
+	ctx := context.Background()
+	sessionKey := opts.sessionKey
+
 	if message != "" {
-		ctx := context.Background()
+		if opts.output != outputText {
+			res := runPrompt(ctx, agentLoop, batchPrompt{Prompt: message},
+				sessionKeyFor(agentLoop.DefaultAgentID(), sessionKey))
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetEscapeHTML(false)
+			if opts.output == outputJSON {
+				enc.SetIndent("", "  ")
+			}
+			if err := enc.Encode(res); err != nil {
+				return err
+			}
+			if res.Error != "" {
+				return fmt.Errorf("error processing message: %s", res.Error)
+			}
+			return nil
+		}
 		response, err := agentLoop.ProcessDirect(ctx, message, sessionKey)
 		if err != nil {
 			return fmt.Errorf("error processing message: %w", err)
@@ -69,8 +154,20 @@ func agentCmd(message, sessionKey, model string, debug bool) error {
 		return nil
 	}
 
-	fmt.Printf("%s Interactive mode (Ctrl+C to exit)\n\n", internal.Logo)
-	interactiveMode(agentLoop, sessionKey)
+	if prompts != nil {
+		return runBatch(ctx, agentLoop, agentLoop.DefaultAgentID(), sessionKey,
+			prompts, os.Stdout, os.Stderr, opts.output)
+	}
+
+	if opts.plain || opts.debug || !isTerminal(os.Stdout) {
+		// Debug logs write to the terminal and would garble the TUI.
+		fmt.Printf("%s Interactive mode (Ctrl+C to exit)\n\n", internal.Logo)
+		interactiveMode(agentLoop, sessionKey)
+		return nil
+	}
+	if err := runTUI(agentLoop, sessionKey, cfg.Agents.Defaults.ModelName); err != nil {
+		return fmt.Errorf("error running chat UI: %w", err)
+	}
 
 	return nil
 }
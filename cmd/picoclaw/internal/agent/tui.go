@@ -0,0 +1,428 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/routing"
+	"github.com/sipeed/picoclaw/pkg/session"
+)
+
+// turnRunner is the part of the agent loop the chat TUI drives.
+type turnRunner interface {
+	ProcessDirect(ctx context.Context, content, sessionKey string) (string, error)
+}
+
+type entryKind int
+
+const (
+	entryUser entryKind = iota
+	entryAssistant
+	entryTool
+	entryInfo
+	entryError
+)
+
+type chatEntry struct {
+	kind entryKind
+	text string
+	tool *toolPanel
+}
+
+// toolPanel is a tool call shown live in the scrollback.
+type toolPanel struct {
+	name     string
+	args     string
+	result   string
+	isError  bool
+	done     bool
+	duration time.Duration
+}
+
+// slashCommands are offered for completion. The TUI handles the first
+// group itself; the rest are agent commands passed through as messages.
+var slashCommands = []string{
+	"/help",
+	"/clear",
+	"/session ",
+	"/exit",
+	"/undo",
+	"/retry",
+	"/new",
+	"/fork ",
+	"/sessions",
+	"/show model",
+	"/show agents",
+	"/list agents",
+	"/switch model to ",
+}
+
+type (
+	agentEventMsg agent.Event
+	replyMsg      struct {
+		response string
+		err      error
+	}
+)
+
+type chatModel struct {
+	runner     turnRunner
+	agentID    string
+	sessionKey string
+	modelName  string
+
+	viewport viewport.Model
+	input    textarea.Model
+	spinner  spinner.Model
+
+	entries   []chatEntry
+	toolIndex map[string]int // tool call ID -> entry index
+	busy      bool
+	cancel    context.CancelFunc
+	iteration int
+	usage     providers.UsageInfo // totals for this TUI session
+	context   int                 // prompt tokens of the latest call
+	notice    string
+
+	suggestions []string
+	width       int
+	height      int
+	ready       bool
+}
+
+func newChatModel(runner turnRunner, agentID, sessionKey, modelName string) chatModel {
+	input := textarea.New()
+	input.Placeholder = "Message (Enter to send, Alt+Enter for a new line, Tab completes /commands)"
+	input.ShowLineNumbers = false
+	input.Prompt = "┃ "
+	input.SetHeight(3)
+	input.CharLimit = 0
+	input.KeyMap.InsertNewline = key.NewBinding(key.WithKeys("alt+enter", "ctrl+j"))
+	input.Focus()
+
+	sp := spinner.New()
+	sp.Spinner = spinner.Dot
+	sp.Style = chatStyles.Spinner
+
+	return chatModel{
+		runner:     runner,
+		agentID:    agentID,
+		sessionKey: sessionKey,
+		modelName:  modelName,
+		input:      input,
+		spinner:    sp,
+		toolIndex:  make(map[string]int),
+		entries: []chatEntry{{
+			kind: entryInfo,
+			text: "Type a message, or /help for commands. Ctrl+C cancels a running turn, then quits.",
+		}},
+	}
+}
+
+// runTUI runs the chat TUI until the user quits.
+func runTUI(agentLoop *agent.AgentLoop, sessionKey, modelName string) error {
+	agentID := agentLoop.DefaultAgentID()
+	m := newChatModel(agentLoop, agentID, sessionKeyFor(agentID, sessionKey), modelName)
+
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	agentLoop.SetEventHandler(func(ev agent.Event) {
+		p.Send(agentEventMsg(ev))
+	})
+	defer agentLoop.SetEventHandler(nil)
+
+	// Log lines written to the terminal would tear through the UI.
+	logOutput := log.Writer()
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(logOutput)
+
+	_, err := p.Run()
+	return err
+}
+
+// sessionKeyFor maps a session name to an agent-scoped session key, so
+// sessions picked in the TUI are kept apart. "default" (and the CLI's
+// default "cli:default") is the agent's main session.
+func sessionKeyFor(agentID, name string) string {
+	name = strings.TrimSpace(name)
+	if strings.HasPrefix(name, "agent:") {
+		return name
+	}
+	name = strings.TrimPrefix(name, "cli:")
+	if name == "" || name == "default" || name == routing.DefaultMainKey {
+		return routing.BuildAgentMainSessionKey(agentID)
+	}
+	return fmt.Sprintf("agent:%s:cli:%s", routing.NormalizeAgentID(agentID), name)
+}
+
+// sessionName is the inverse of sessionKeyFor, for display.
+func sessionName(agentID, key string) string {
+	if key == routing.BuildAgentMainSessionKey(agentID) {
+		return "default"
+	}
+	prefix := fmt.Sprintf("agent:%s:cli:", routing.NormalizeAgentID(agentID))
+	if strings.HasPrefix(key, prefix) {
+		return strings.TrimPrefix(key, prefix)
+	}
+	return key
+}
+
+// inSession reports whether an event for eventKey belongs to the turn run
+// on sessionKey, which the agent may have redirected to a forked branch.
+func inSession(eventKey, sessionKey string) bool {
+	return eventKey == sessionKey || session.BaseKey(eventKey) == sessionKey
+}
+
+// completeCommand returns the slash commands that extend input.
+func completeCommand(input string) []string {
+	if !strings.HasPrefix(input, "/") || strings.Contains(input, "\n") {
+		return nil
+	}
+	var out []string
+	for _, c := range slashCommands {
+		if strings.HasPrefix(c, input) && c != input {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (m chatModel) Init() tea.Cmd {
+	return tea.Batch(textarea.Blink, m.spinner.Tick)
+}
+
+func (m chatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.input.SetWidth(msg.Width)
+		vpHeight := max(msg.Height-m.input.Height()-3, 3)
+		if !m.ready {
+			m.viewport = viewport.New(msg.Width, vpHeight)
+			m.ready = true
+		} else {
+			m.viewport.Width, m.viewport.Height = msg.Width, vpHeight
+		}
+		m.refresh(true)
+		return m, nil
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+
+	case tea.MouseMsg:
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+
+	case agentEventMsg:
+		m.handleEvent(agent.Event(msg))
+		return m, nil
+
+	case replyMsg:
+		m.busy = false
+		m.cancel = nil
+		m.notice = ""
+		switch {
+		case errors.Is(msg.err, context.Canceled):
+			m.add(chatEntry{kind: entryInfo, text: "Turn canceled."})
+		case msg.err != nil:
+			m.add(chatEntry{kind: entryError, text: msg.err.Error()})
+		default:
+			m.add(chatEntry{kind: entryAssistant, text: msg.response})
+		}
+		return m, nil
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		if m.busy {
+			m.refresh(false)
+		}
+		return m, cmd
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m chatModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		if m.busy && m.cancel != nil {
+			m.cancel()
+			return m, nil
+		}
+		return m, tea.Quit
+	case "esc":
+		if m.busy && m.cancel != nil {
+			m.cancel()
+		}
+		return m, nil
+	case "pgup", "pgdown", "ctrl+up", "ctrl+down":
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+	case "tab":
+		if options := completeCommand(m.input.Value()); len(options) > 0 {
+			m.input.SetValue(commonPrefix(options))
+			m.input.CursorEnd()
+			m.suggestions = completeCommand(m.input.Value())
+		}
+		return m, nil
+	case "enter":
+		return m.submit()
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	m.suggestions = completeCommand(m.input.Value())
+	return m, cmd
+}
+
+func (m chatModel) submit() (tea.Model, tea.Cmd) {
+	text := strings.TrimSpace(m.input.Value())
+	if text == "" || m.busy {
+		return m, nil
+	}
+	m.input.Reset()
+	m.suggestions = nil
+
+	fields := strings.Fields(text)
+	switch fields[0] {
+	case "/exit", "/quit", "exit", "quit":
+		return m, tea.Quit
+	case "/clear":
+		m.entries = nil
+		m.toolIndex = make(map[string]int)
+		m.refresh(true)
+		return m, nil
+	case "/help":
+		m.add(chatEntry{kind: entryInfo, text: strings.Join([]string{
+			"/session [name]  show or switch the session (history is kept per session)",
+			"/clear           clear the screen (the session history is kept)",
+			"/exit            quit",
+			"/undo, /retry    take back or regenerate the last exchange",
+			"/new, /fork <n>  archive and start over, or branch the conversation (/sessions lists them)",
+			"Other /commands (/show, /list, /switch) are handled by the agent.",
+			"Keys: Enter send · Alt+Enter newline · Tab complete · PgUp/PgDn scroll · Esc cancel turn",
+		}, "\n")})
+		return m, nil
+	case "/session":
+		if len(fields) == 1 {
+			m.add(chatEntry{kind: entryInfo, text: fmt.Sprintf("Session: %s (%s)",
+				sessionName(m.agentID, m.sessionKey), m.sessionKey)})
+			return m, nil
+		}
+		m.sessionKey = sessionKeyFor(m.agentID, fields[1])
+		m.usage = providers.UsageInfo{}
+		m.context = 0
+		m.add(chatEntry{kind: entryInfo, text: "Switched to session " + sessionName(m.agentID, m.sessionKey)})
+		return m, nil
+	}
+
+	m.add(chatEntry{kind: entryUser, text: text})
+	m.busy = true
+	m.iteration = 0
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+
+	runner, sessionKey := m.runner, m.sessionKey
+	return m, func() tea.Msg {
+		defer cancel()
+		response, err := runner.ProcessDirect(ctx, text, sessionKey)
+		if err != nil && ctx.Err() != nil {
+			err = context.Canceled
+		}
+		return replyMsg{response: response, err: err}
+	}
+}
+
+func (m *chatModel) handleEvent(ev agent.Event) {
+	if !inSession(ev.SessionKey, m.sessionKey) {
+		return // e.g. a subagent's turn
+	}
+	switch ev.Kind {
+	case agent.EventIteration:
+		m.iteration = ev.Iteration
+		if ev.Model != "" {
+			m.modelName = ev.Model
+		}
+	case agent.EventLLMResponse:
+		if ev.Usage != nil {
+			m.usage.PromptTokens += ev.Usage.PromptTokens
+			m.usage.CompletionTokens += ev.Usage.CompletionTokens
+			m.usage.TotalTokens += ev.Usage.TotalTokens
+			m.context = ev.Usage.PromptTokens
+		}
+	case agent.EventFallback:
+		var failed []string
+		for _, a := range ev.Attempts {
+			failed = append(failed, a.Provider+"/"+a.Model)
+		}
+		m.notice = fmt.Sprintf("fell back to %s/%s", ev.Provider, ev.Model)
+		m.add(chatEntry{kind: entryInfo, text: fmt.Sprintf("Fallback: %s failed, answered by %s/%s",
+			strings.Join(failed, ", "), ev.Provider, ev.Model)})
+	case agent.EventToolCall:
+		args, _ := json.Marshal(ev.Arguments)
+		m.toolIndex[ev.ToolCallID] = len(m.entries)
+		m.add(chatEntry{kind: entryTool, tool: &toolPanel{name: ev.Tool, args: string(args)}})
+	case agent.EventToolResult:
+		if i, ok := m.toolIndex[ev.ToolCallID]; ok && i < len(m.entries) {
+			panel := m.entries[i].tool
+			panel.result = ev.Result
+			panel.isError = ev.IsError
+			panel.duration = ev.Duration
+			panel.done = true
+			m.refresh(false)
+		}
+	}
+}
+
+func (m *chatModel) add(e chatEntry) {
+	m.entries = append(m.entries, e)
+	m.refresh(true)
+}
+
+// refresh re-renders the scrollback, following the bottom when the view
+// was already there (or follow is set).
+func (m *chatModel) refresh(follow bool) {
+	if !m.ready {
+		return
+	}
+	atBottom := m.viewport.AtBottom()
+	m.viewport.SetContent(m.renderEntries())
+	if follow || atBottom {
+		m.viewport.GotoBottom()
+	}
+}
+
+func commonPrefix(options []string) string {
+	prefix := options[0]
+	for _, o := range options[1:] {
+		for !strings.HasPrefix(o, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// isTerminal reports whether f is attached to a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,168 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+type echoRunner struct {
+	sessionKey string
+}
+
+func (r *echoRunner) ProcessDirect(_ context.Context, content, sessionKey string) (string, error) {
+	r.sessionKey = sessionKey
+	return "echo: " + content, nil
+}
+
+func sizedModel(t *testing.T, runner turnRunner) chatModel {
+	t.Helper()
+	m := newChatModel(runner, "main", sessionKeyFor("main", "cli:default"), "test-model")
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	return next.(chatModel)
+}
+
+func typeText(m chatModel, text string) chatModel {
+	m.input.SetValue(text)
+	return m
+}
+
+func TestSessionKeyFor(t *testing.T) {
+	assert.Equal(t, "agent:main:main", sessionKeyFor("main", "cli:default"))
+	assert.Equal(t, "agent:main:main", sessionKeyFor("main", "default"))
+	assert.Equal(t, "agent:main:cli:work", sessionKeyFor("main", "work"))
+	assert.Equal(t, "agent:ops:cli:work", sessionKeyFor("ops", "cli:work"))
+	assert.Equal(t, "agent:other:main", sessionKeyFor("main", "agent:other:main"))
+
+	assert.Equal(t, "default", sessionName("main", "agent:main:main"))
+	assert.Equal(t, "work", sessionName("main", "agent:main:cli:work"))
+	assert.Equal(t, "agent:other:main", sessionName("main", "agent:other:main"))
+
+	assert.True(t, inSession("agent:main:main", "agent:main:main"))
+	assert.True(t, inSession("agent:main:main#idea", "agent:main:main"))
+	assert.False(t, inSession("agent:main:subagent:1", "agent:main:main"))
+}
+
+func TestCompleteCommand(t *testing.T) {
+	assert.Equal(t, []string{"/show model", "/show agents"}, completeCommand("/sh"))
+	assert.Equal(t, []string{"/session ", "/sessions"}, completeCommand("/se"))
+	assert.Nil(t, completeCommand("hello"))
+	assert.Nil(t, completeCommand("/help"))
+	assert.Nil(t, completeCommand("/sh\nmore"))
+
+	assert.Equal(t, "/show ", commonPrefix([]string{"/show model", "/show agents"}))
+}
+
+func TestChatModel_TabCompletes(t *testing.T) {
+	m := typeText(sizedModel(t, &echoRunner{}), "/sw")
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = next.(chatModel)
+	assert.Equal(t, "/switch model to ", m.input.Value())
+}
+
+func TestChatModel_SubmitRunsTurn(t *testing.T) {
+	runner := &echoRunner{}
+	m := typeText(sizedModel(t, runner), "hello")
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(chatModel)
+	require.NotNil(t, cmd)
+	assert.True(t, m.busy)
+	assert.Empty(t, m.input.Value())
+
+	next, _ = m.Update(cmd())
+	m = next.(chatModel)
+	assert.False(t, m.busy)
+	assert.Equal(t, "agent:main:main", runner.sessionKey)
+
+	last := m.entries[len(m.entries)-1]
+	assert.Equal(t, entryAssistant, last.kind)
+	assert.Equal(t, "echo: hello", last.text)
+}
+
+func TestChatModel_SessionCommand(t *testing.T) {
+	runner := &echoRunner{}
+	m := typeText(sizedModel(t, runner), "/session work")
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(chatModel)
+	assert.Nil(t, cmd)
+	assert.Equal(t, "agent:main:cli:work", m.sessionKey)
+	assert.Contains(t, m.statusLine(), "session work")
+
+	m = typeText(m, "hi")
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	require.NotNil(t, cmd)
+	cmd()
+	assert.Equal(t, "agent:main:cli:work", runner.sessionKey)
+}
+
+func TestChatModel_ToolEvents(t *testing.T) {
+	m := sizedModel(t, &echoRunner{})
+	m.busy = true
+	key := m.sessionKey
+
+	for _, ev := range []agent.Event{
+		{Kind: agent.EventIteration, SessionKey: key, Iteration: 1, Model: "gpt-test"},
+		{Kind: agent.EventLLMResponse, SessionKey: key, Usage: &providers.UsageInfo{PromptTokens: 120, CompletionTokens: 30}},
+		{
+			Kind: agent.EventToolCall, SessionKey: key, ToolCallID: "call_1",
+			Tool: "read_file", Arguments: map[string]any{"path": "notes.txt"},
+		},
+		{
+			Kind: agent.EventToolResult, SessionKey: key, ToolCallID: "call_1",
+			Tool: "read_file", Result: "file contents", Duration: 12 * time.Millisecond,
+		},
+		// Events for other sessions, e.g. subagents, are ignored.
+		{Kind: agent.EventLLMResponse, SessionKey: "agent:main:other", Usage: &providers.UsageInfo{PromptTokens: 999}},
+	} {
+		next, _ := m.Update(agentEventMsg(ev))
+		m = next.(chatModel)
+	}
+
+	require.Contains(t, m.toolIndex, "call_1")
+	panel := m.entries[m.toolIndex["call_1"]].tool
+	assert.True(t, panel.done)
+	assert.Equal(t, `{"path":"notes.txt"}`, panel.args)
+	assert.Equal(t, "file contents", panel.result)
+
+	assert.Equal(t, 120, m.usage.PromptTokens)
+	assert.Equal(t, 30, m.usage.CompletionTokens)
+
+	status := m.statusLine()
+	assert.Contains(t, status, "gpt-test")
+	assert.Contains(t, status, "tokens 120 in / 30 out")
+	assert.Contains(t, status, "iteration 1")
+
+	view := m.renderEntries()
+	assert.Contains(t, view, "read_file")
+	assert.Contains(t, view, "file contents")
+	assert.Contains(t, view, "✓")
+}
+
+func TestChatModel_CtrlCCancelsThenQuits(t *testing.T) {
+	m := sizedModel(t, &echoRunner{})
+	canceled := false
+	m.busy = true
+	m.cancel = func() { canceled = true }
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	m = next.(chatModel)
+	assert.True(t, canceled)
+	assert.Nil(t, cmd)
+
+	next, _ = m.Update(replyMsg{err: context.Canceled})
+	m = next.(chatModel)
+	assert.False(t, m.busy)
+	assert.Equal(t, "Turn canceled.", m.entries[len(m.entries)-1].text)
+
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	require.NotNil(t, cmd)
+	assert.IsType(t, tea.QuitMsg{}, cmd())
+}
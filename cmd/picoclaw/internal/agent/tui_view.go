@@ -0,0 +1,168 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	maxToolArgs   = 300
+	maxToolResult = 600
+)
+
+// chatStyles holds the chat TUI styles.
+var chatStyles = struct {
+	User      lipgloss.Style
+	Assistant lipgloss.Style
+	Info      lipgloss.Style
+	Error     lipgloss.Style
+	ToolBox   lipgloss.Style
+	ToolError lipgloss.Style
+	ToolName  lipgloss.Style
+	ToolMeta  lipgloss.Style
+	Status    lipgloss.Style
+	Suggest   lipgloss.Style
+	Spinner   lipgloss.Style
+}{
+	User: lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#7D56F4")),
+
+	Assistant: lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#04B575")),
+
+	Info: lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#888888")).
+		Italic(true),
+
+	Error: lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#FF6B6B")).
+		Bold(true),
+
+	ToolBox: lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#555555")).
+		Padding(0, 1),
+
+	ToolError: lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#FF6B6B")).
+		Padding(0, 1),
+
+	ToolName: lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#FFB86C")),
+
+	ToolMeta: lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#888888")),
+
+	Status: lipgloss.NewStyle().
+		Background(lipgloss.Color("#333333")).
+		Foreground(lipgloss.Color("#CCCCCC")).
+		Padding(0, 1),
+
+	Suggest: lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#888888")),
+
+	Spinner: lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#7D56F4")),
+}
+
+func (m chatModel) View() string {
+	if !m.ready {
+		return "Loading..."
+	}
+
+	var b strings.Builder
+	b.WriteString(m.viewport.View())
+	b.WriteString("\n")
+	b.WriteString(m.statusLine())
+	b.WriteString("\n")
+	b.WriteString(m.input.View())
+	if len(m.suggestions) > 0 {
+		b.WriteString("\n")
+		b.WriteString(chatStyles.Suggest.Render(strings.Join(m.suggestions, "  ")))
+	}
+	return b.String()
+}
+
+func (m chatModel) renderEntries() string {
+	width := max(m.viewport.Width-2, 20)
+	wrap := lipgloss.NewStyle().Width(width)
+
+	var b strings.Builder
+	for _, e := range m.entries {
+		switch e.kind {
+		case entryUser:
+			b.WriteString(chatStyles.User.Render("You"))
+			b.WriteString("\n")
+			b.WriteString(wrap.Render(e.text))
+		case entryAssistant:
+			b.WriteString(chatStyles.Assistant.Render("Agent"))
+			b.WriteString("\n")
+			b.WriteString(wrap.Render(e.text))
+		case entryTool:
+			b.WriteString(m.renderTool(e.tool, width))
+		case entryInfo:
+			b.WriteString(chatStyles.Info.Width(width).Render(e.text))
+		case entryError:
+			b.WriteString(chatStyles.Error.Width(width).Render("Error: " + e.text))
+		}
+		b.WriteString("\n\n")
+	}
+	if m.busy {
+		b.WriteString(m.spinner.View() + chatStyles.Info.Render(" thinking..."))
+	}
+	return b.String()
+}
+
+func (m chatModel) renderTool(t *toolPanel, width int) string {
+	style := chatStyles.ToolBox
+	status := m.spinner.View() + " running"
+	if t.done {
+		status = "✓ " + t.duration.Round(time.Millisecond).String()
+		if t.isError {
+			style = chatStyles.ToolError
+			status = "✗ " + t.duration.Round(time.Millisecond).String()
+		}
+	}
+
+	inner := max(width-4, 10)
+	lines := []string{
+		chatStyles.ToolName.Render("⚙ "+t.name) + " " + chatStyles.ToolMeta.Render(status),
+		chatStyles.ToolMeta.Width(inner).Render(utils.Truncate(t.args, maxToolArgs)),
+	}
+	if t.done && t.result != "" {
+		lines = append(lines, lipgloss.NewStyle().Width(inner).Render(utils.Truncate(t.result, maxToolResult)))
+	}
+	return style.Width(width).Render(strings.Join(lines, "\n"))
+}
+
+func (m chatModel) statusLine() string {
+	state := "ready"
+	if m.busy {
+		state = "working"
+		if m.iteration > 0 {
+			state = fmt.Sprintf("working (iteration %d)", m.iteration)
+		}
+	}
+	if m.notice != "" {
+		state += " · " + m.notice
+	}
+	parts := []string{
+		m.modelName,
+		"session " + sessionName(m.agentID, m.sessionKey),
+		fmt.Sprintf("tokens %d in / %d out", m.usage.PromptTokens, m.usage.CompletionTokens),
+	}
+	if m.context > 0 {
+		parts = append(parts, fmt.Sprintf("context %d", m.context))
+	}
+	parts = append(parts, state)
+	return chatStyles.Status.Width(m.width).Render(strings.Join(parts, " │ "))
+}
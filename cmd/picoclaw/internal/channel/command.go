@@ -21,7 +21,7 @@ credentials and settings. The configuration is tested before saving.
 Supported channels:
   - Telegram, Discord, Slack, QQ
   - DingTalk, WeCom (Bot & App), Feishu
-  - LINE, OneBot, MaixCam, WhatsApp`,
+  - LINE, OneBot, MaixCam, Serial, WhatsApp`,
 		Example: `  picoclaw channel`,
 		Args:    cobra.NoArgs,
 		RunE: func(_ *cobra.Command, _ []string) error {
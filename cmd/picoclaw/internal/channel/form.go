@@ -35,6 +35,8 @@ func (m *Model) InitFormForChannel(channelName string) {
 		m.InitOneBotForm()
 	case "maixcam":
 		m.InitMaixCamForm()
+	case "serial":
+		m.InitSerialForm()
 	case "whatsapp":
 		m.InitWhatsAppForm()
 	}
@@ -200,6 +202,17 @@ func (m *Model) InitMaixCamForm() {
 	}
 }
 
+// InitSerialForm initializes the serial port configuration form
+func (m *Model) InitSerialForm() {
+	cfg := m.Config.Channels.Serial
+	m.FormFields = []FormField{
+		{Name: "enabled", Label: "Enabled", Type: FieldTypeBool, BoolValue: cfg.Enabled},
+		{Name: "device", Label: "Device", Type: FieldTypeText, Value: cfg.Device, Placeholder: "/dev/ttyUSB0", Required: true},
+		{Name: "baud", Label: "Baud Rate", Type: FieldTypeNumber, Value: strconv.Itoa(cfg.Baud), Placeholder: "115200"},
+		{Name: "line_ending", Label: "Line Ending", Type: FieldTypeText, Value: cfg.LineEnding, Placeholder: "lf or crlf"},
+	}
+}
+
 // InitWhatsAppForm initializes the WhatsApp configuration form
 func (m *Model) InitWhatsAppForm() {
 	cfg := m.Config.Channels.WhatsApp
@@ -246,6 +259,9 @@ func (m *Model) SaveFormToConfig() {
 	case "maixcam":
 		m.SaveMaixCamConfig()
 		m.Config.Channels.MaixCam.Enabled = true
+	case "serial":
+		m.SaveSerialConfig()
+		m.Config.Channels.Serial.Enabled = true
 	case "whatsapp":
 		m.SaveWhatsAppConfig()
 		m.Config.Channels.WhatsApp.Enabled = true
@@ -370,6 +386,15 @@ func (m *Model) SaveMaixCamConfig() {
 	cfg.AllowFrom = m.GetFieldValueArray("allow_from")
 }
 
+// SaveSerialConfig saves serial port config from form
+func (m *Model) SaveSerialConfig() {
+	cfg := &m.Config.Channels.Serial
+	cfg.Enabled = m.GetFieldValueBool("enabled")
+	cfg.Device = m.GetFieldValue("device")
+	cfg.Baud = m.GetFieldValueInt("baud")
+	cfg.LineEnding = m.GetFieldValue("line_ending")
+}
+
 // SaveWhatsAppConfig saves WhatsApp config from form
 func (m *Model) SaveWhatsAppConfig() {
 	cfg := &m.Config.Channels.WhatsApp
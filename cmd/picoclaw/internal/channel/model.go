@@ -189,6 +189,13 @@ func NewModel() (*Model, error) {
 			Configured:  cfg.Channels.MaixCam.Host != "",
 			Enabled:     cfg.Channels.MaixCam.Enabled,
 		},
+		{
+			Name:        "serial",
+			DisplayName: "Serial",
+			Description: "Serial Port (UART)",
+			Configured:  cfg.Channels.Serial.Device != "",
+			Enabled:     cfg.Channels.Serial.Enabled,
+		},
 		{
 			Name:        "whatsapp",
 			DisplayName: "WhatsApp",
@@ -262,6 +269,9 @@ func (m *Model) RefreshChannelStatus() {
 		case "maixcam":
 			m.Channels[i].Configured = m.Config.Channels.MaixCam.Host != ""
 			m.Channels[i].Enabled = m.Config.Channels.MaixCam.Enabled
+		case "serial":
+			m.Channels[i].Configured = m.Config.Channels.Serial.Device != ""
+			m.Channels[i].Enabled = m.Config.Channels.Serial.Enabled
 		case "whatsapp":
 			m.Channels[i].Configured = m.Config.Channels.WhatsApp.BridgeURL != ""
 			m.Channels[i].Enabled = m.Config.Channels.WhatsApp.Enabled
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
 	"time"
 )
@@ -42,6 +43,8 @@ func (m *Model) PerformTest() TestResult {
 		return m.TestOneBot()
 	case "maixcam":
 		return m.TestMaixCam()
+	case "serial":
+		return m.TestSerial()
 	case "whatsapp":
 		return m.TestWhatsApp()
 	default:
@@ -479,6 +482,39 @@ func (m *Model) TestMaixCam() TestResult {
 	}
 }
 
+// TestSerial tests serial port configuration
+func (m *Model) TestSerial() TestResult {
+	cfg := m.Config.Channels.Serial
+
+	if cfg.Device == "" {
+		return TestResult{
+			Success: false,
+			Message: "Serial device is required",
+			Error:   errors.New("device cannot be empty"),
+		}
+	}
+
+	info, err := os.Stat(cfg.Device)
+	if err != nil {
+		return TestResult{
+			Success: true,
+			Message: fmt.Sprintf("Serial configuration appears valid (%s is not connected)", cfg.Device),
+		}
+	}
+	if info.Mode()&os.ModeCharDevice == 0 {
+		return TestResult{
+			Success: false,
+			Message: fmt.Sprintf("%s is not a serial device", cfg.Device),
+			Error:   errors.New("device is not a character device"),
+		}
+	}
+
+	return TestResult{
+		Success: true,
+		Message: fmt.Sprintf("Serial device %s is present", cfg.Device),
+	}
+}
+
 // TestWhatsApp tests WhatsApp Bridge configuration
 func (m *Model) TestWhatsApp() TestResult {
 	cfg := m.Config.Channels.WhatsApp
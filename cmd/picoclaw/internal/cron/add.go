@@ -14,9 +14,19 @@ func newAddCommand(storePath func() string) *cobra.Command {
 		message string
 		every   int64
 		cronExp string
+		tz      string
+		misfire string
+		maxRuns int
+		grace   int64
 		deliver bool
 		channel string
 		to      string
+		retries int
+		backoff int64
+		notifyC string
+		notifyT string
+		concur  string
+		maxRun  int64
 	)
 
 	cmd := &cobra.Command{
@@ -29,8 +39,23 @@ You must specify either --every (interval in seconds) for a repeating
 interval or --cron for a cron expression. --name and --message are
 required. Use --deliver, --channel and --to to have the response
 delivered via an integrated channel.
+
+Cron expressions are evaluated in --tz (an IANA zone name), or the
+host's local time zone when it is not set. --misfire decides what
+happens to runs missed while the gateway was down: skip (default),
+run_once, or run_all (capped by --misfire-max). A missed run no older
+than --misfire-grace seconds always runs once.
+
+--retries re-runs a failed job, waiting --retry-backoff seconds before
+the first retry and doubling the wait each time. When a run still
+fails, --notify-channel and --notify-to receive a failure report.
+
+--concurrency decides what happens when the job comes due while its
+previous run is still going: forbid (default) skips the new run, allow
+runs both and replace cancels the old one. --max-runtime cancels a run
+that takes longer than N seconds.
 `,
-		Example: `  picoclaw cron add --name morning --cron "0 9 * * *" --message "Good morning"
+		Example: `  picoclaw cron add --name morning --cron "0 9 * * *" --tz Asia/Shanghai --message "Good morning"
 	  picoclaw cron add --name heartbeat --every 3600 --message "Status check" --deliver --channel telegram --to 12345`,
 		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
@@ -45,6 +70,14 @@ delivered via an integrated channel.
 			} else {
 				schedule = cron.CronSchedule{Kind: "cron", Expr: cronExp}
 			}
+			schedule.TZ = tz
+			if misfire != "" || maxRuns != 0 || grace != 0 {
+				schedule.Misfire = &cron.CronMisfire{Policy: misfire, MaxRuns: maxRuns, GraceMS: grace * 1000}
+			}
+
+			if (notifyC == "") != (notifyT == "") {
+				return fmt.Errorf("--notify-channel and --notify-to must be used together")
+			}
 
 			cs := cron.NewCronService(storePath(), nil)
 			job, err := cs.AddJob(name, schedule, message, deliver, channel, to)
@@ -52,6 +85,21 @@ delivered via an integrated channel.
 				return fmt.Errorf("error adding job: %w", err)
 			}
 
+			if retries > 0 || notifyC != "" || concur != "" || maxRun > 0 {
+				job.Concurrency = concur
+				job.MaxRuntimeMS = maxRun * 1000
+				if retries > 0 {
+					job.Retry = &cron.CronRetry{MaxRetries: retries, BackoffMS: backoff * 1000}
+				}
+				if notifyC != "" {
+					job.Notify = &cron.CronNotify{Channel: notifyC, To: notifyT}
+				}
+				if err := cs.UpdateJob(job); err != nil {
+					cs.RemoveJob(job.ID)
+					return fmt.Errorf("error adding job: %w", err)
+				}
+			}
+
 			fmt.Printf("✓ Added job '%s' (%s)\n", job.Name, job.ID)
 
 			return nil
@@ -62,10 +110,22 @@ delivered via an integrated channel.
 	cmd.Flags().StringVarP(&message, "message", "m", "", "Message for agent")
 	cmd.Flags().Int64VarP(&every, "every", "e", 0, "Run every N seconds")
 	cmd.Flags().StringVarP(&cronExp, "cron", "c", "", "Cron expression (e.g. '0 9 * * *')")
+	cmd.Flags().StringVar(&tz, "tz", "", "IANA time zone for --cron (e.g. 'Asia/Shanghai')")
+	cmd.Flags().StringVar(&misfire, "misfire", "", "Missed-run policy: skip, run_once or run_all")
+	cmd.Flags().IntVar(&maxRuns, "misfire-max", 0, "Maximum missed runs replayed by run_all (default 10)")
+	cmd.Flags().Int64Var(&grace, "misfire-grace", 0, "Run a missed run once if it is at most N seconds old")
 	cmd.Flags().BoolVarP(&deliver, "deliver", "d", false, "Deliver response to channel")
 	cmd.Flags().StringVar(&to, "to", "", "Recipient for delivery")
 	cmd.Flags().StringVar(&channel, "channel", "", "Channel for delivery")
 
+	cmd.Flags().IntVar(&retries, "retries", 0, "Retry a failed run up to N times")
+	cmd.Flags().Int64Var(&backoff, "retry-backoff", 0, "Seconds before the first retry, doubled per retry (default 60)")
+	cmd.Flags().StringVar(&notifyC, "notify-channel", "", "Channel to report failures to")
+	cmd.Flags().StringVar(&notifyT, "notify-to", "", "Recipient of failure reports")
+
+	cmd.Flags().StringVar(&concur, "concurrency", "", "Overlap policy: forbid, allow or replace")
+	cmd.Flags().Int64Var(&maxRun, "max-runtime", 0, "Cancel a run after N seconds")
+
 	_ = cmd.MarkFlagRequired("name")
 	_ = cmd.MarkFlagRequired("message")
 	cmd.MarkFlagsMutuallyExclusive("every", "cron")
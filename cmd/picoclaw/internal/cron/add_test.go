@@ -24,6 +24,16 @@ func TestNewAddSubcommand(t *testing.T) {
 	assert.NotNil(t, cmd.Flags().Lookup("deliver"))
 	assert.NotNil(t, cmd.Flags().Lookup("to"))
 	assert.NotNil(t, cmd.Flags().Lookup("channel"))
+	assert.NotNil(t, cmd.Flags().Lookup("tz"))
+	assert.NotNil(t, cmd.Flags().Lookup("misfire"))
+	assert.NotNil(t, cmd.Flags().Lookup("misfire-max"))
+	assert.NotNil(t, cmd.Flags().Lookup("misfire-grace"))
+	assert.NotNil(t, cmd.Flags().Lookup("retries"))
+	assert.NotNil(t, cmd.Flags().Lookup("retry-backoff"))
+	assert.NotNil(t, cmd.Flags().Lookup("notify-channel"))
+	assert.NotNil(t, cmd.Flags().Lookup("notify-to"))
+	assert.NotNil(t, cmd.Flags().Lookup("concurrency"))
+	assert.NotNil(t, cmd.Flags().Lookup("max-runtime"))
 
 	nameFlag := cmd.Flags().Lookup("name")
 	require.NotNil(t, nameFlag)
@@ -22,8 +22,9 @@ The cron command group allows listing, adding, removing, enabling and
 disabling scheduled jobs. Jobs are persisted in the workspace under the
 cron store (usually ~/.picoclaw/workspace/cron/jobs.json). Use 'cron
 add' to schedule one-time or recurring jobs (supports both --every and
-cron expressions), 'cron list' to view jobs, and 'cron remove' to
-delete jobs by id.
+cron expressions), 'cron list' to view jobs, 'cron remove' to delete
+jobs by id, 'cron history' to inspect past runs and 'cron run' to
+trigger a job by hand.
 `,
 		Example: `  picoclaw cron list
 	  picoclaw cron add --name reminder --every 3600 --message "Stand up"
@@ -50,6 +51,8 @@ delete jobs by id.
 		newRemoveCommand(func() string { return storePath }),
 		newEnableCommand(func() string { return storePath }),
 		newDisableCommand(func() string { return storePath }),
+		newHistoryCommand(func() string { return storePath }),
+		newRunCommand(func() string { return storePath }),
 	)
 
 	return cmd
@@ -1,10 +1,16 @@
 package cron
 
 import (
+	"context"
 	"fmt"
 	"time"
 
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/cron"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/tools"
 )
 
 func cronListCmd(storePath string) {
@@ -24,6 +30,9 @@ func cronListCmd(storePath string) {
 			schedule = fmt.Sprintf("every %ds", *job.Schedule.EveryMS/1000)
 		} else if job.Schedule.Kind == "cron" {
 			schedule = job.Schedule.Expr
+			if job.Schedule.TZ != "" {
+				schedule += " (" + job.Schedule.TZ + ")"
+			}
 		} else {
 			schedule = "one-time"
 		}
@@ -43,7 +52,96 @@ func cronListCmd(storePath string) {
 		fmt.Printf("    Schedule: %s\n", schedule)
 		fmt.Printf("    Status: %s\n", status)
 		fmt.Printf("    Next run: %s\n", nextRun)
+		if job.State.LastStatus != "" {
+			fmt.Printf("    Last run: %s\n", job.State.LastStatus)
+		}
+	}
+}
+
+func cronHistoryCmd(storePath, jobID string) error {
+	cs := cron.NewCronService(storePath, nil)
+	runs, found := cs.History(jobID)
+	if !found {
+		return fmt.Errorf("job %s not found", jobID)
+	}
+	if len(runs) == 0 {
+		fmt.Printf("Job %s has not run yet.\n", jobID)
+		return nil
+	}
+
+	fmt.Printf("\nRuns of %s:\n", jobID)
+	fmt.Println("----------------")
+	for _, run := range runs {
+		trigger := ""
+		if run.Manual {
+			trigger = ", manual"
+		}
+		fmt.Printf("  %s  %s  %s (attempt %d%s)\n",
+			time.UnixMilli(run.StartedAtMS).Format("2006-01-02 15:04:05"),
+			run.Status,
+			time.Duration(run.DurationMS)*time.Millisecond,
+			run.Attempt,
+			trigger)
+		if run.SessionKey != "" {
+			fmt.Printf("    Session: %s\n", run.SessionKey)
+		}
+		if run.Error != "" {
+			fmt.Printf("    Error: %s\n", run.Error)
+		} else if run.Output != "" {
+			fmt.Printf("    Output: %s\n", run.Output)
+		}
+	}
+	return nil
+}
+
+func cronRunCmd(storePath, jobID string) error {
+	cfg, err := internal.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+
+	provider, modelID, err := providers.CreateProvider(cfg)
+	if err != nil {
+		return fmt.Errorf("error creating provider: %w", err)
+	}
+	if modelID != "" {
+		cfg.Agents.Defaults.ModelName = modelID
+	}
+
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+	agentLoop := agent.NewAgentLoop(cfg, msgBus, provider)
+
+	cs := cron.NewCronService(storePath, nil)
+	cs.SetAgentID(agentLoop.DefaultAgentID())
+	execTimeout := time.Duration(cfg.Tools.Cron.ExecTimeoutMinutes) * time.Minute
+	cronTool := tools.NewCronTool(
+		cs, agentLoop, msgBus, cfg.WorkspacePath(), cfg.Agents.Defaults.RestrictToWorkspace, execTimeout, cfg,
+	)
+	cronTool.SetAccess(agentLoop.AccessController())
+
+	// Keep the full output; the history only stores an excerpt.
+	var output string
+	cs.SetOnJob(func(ctx context.Context, job *cron.CronJob) (string, error) {
+		var err error
+		output, err = cronTool.ExecuteJob(ctx, job)
+		return output, err
+	})
+
+	run, err := cs.RunJob(jobID)
+	if err != nil {
+		return err
+	}
+
+	if output != "" {
+		fmt.Printf("\n%s %s\n", internal.Logo, output)
+	}
+	if run.Status != "ok" {
+		return fmt.Errorf("job %s failed after %s: %s",
+			jobID, time.Duration(run.DurationMS)*time.Millisecond, run.Error)
 	}
+	fmt.Printf("✓ Job %s finished in %s\n", jobID, time.Duration(run.DurationMS)*time.Millisecond)
+	return nil
 }
 
 func cronRemoveCmd(storePath, jobID string) {
@@ -0,0 +1,23 @@
+package cron
+
+import "github.com/spf13/cobra"
+
+func newHistoryCommand(storePath func() string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show recent runs of a job",
+		Long: `Display the recorded runs of a scheduled job, oldest first.
+
+Each run shows when it started, its status, duration, attempt number,
+the agent session it used and an excerpt of its output or error. Only
+the most recent runs are kept.
+`,
+		Example: `  picoclaw cron history <job-id>`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return cronHistoryCmd(storePath(), args[0])
+		},
+	}
+
+	return cmd
+}
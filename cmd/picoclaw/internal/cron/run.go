@@ -0,0 +1,24 @@
+package cron
+
+import "github.com/spf13/cobra"
+
+func newRunCommand(storePath func() string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Run a job now",
+		Long: `Run a scheduled job immediately, whatever its schedule or enabled
+state, and record the run in its history.
+
+The job runs in this process rather than in the gateway, so its output
+is printed here instead of being delivered to a channel. The job's
+schedule, retries and failure notifications are left untouched.
+`,
+		Example: `  picoclaw cron run <job-id>`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return cronRunCmd(storePath(), args[0])
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,35 @@
+package eval
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewEvalCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "eval",
+		Short: "Run agent regression suites",
+		Long: `Run suites of agent test cases and compare results between runs.
+
+A suite is a YAML file of cases. Each case has a prompt, optional
+fixture files for the agent's workspace, the tool calls it expects and
+assertions on the reply (contains, regex, json_path or an LLM judge).
+Cases run against a scripted mock provider when they include a mock
+script, or against the configured model. Use 'eval run' to run suites
+and save a report, and 'eval diff' to compare two saved reports.
+`,
+		Example: `  picoclaw eval run evals/
+	  picoclaw eval run evals/notes.yaml --mode live --report new.json --baseline old.json
+	  picoclaw eval diff old.json new.json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(
+		newRunCommand(),
+		newDiffCommand(),
+	)
+
+	return cmd
+}
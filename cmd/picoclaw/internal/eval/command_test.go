@@ -0,0 +1,33 @@
+package eval
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEvalCommand(t *testing.T) {
+	cmd := NewEvalCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "eval", cmd.Use)
+	assert.Equal(t, "Run agent regression suites", cmd.Short)
+	assert.True(t, cmd.HasExample())
+	assert.True(t, cmd.HasSubCommands())
+
+	allowedCommands := []string{
+		"run",
+		"diff",
+	}
+
+	subcommands := cmd.Commands()
+	assert.Len(t, subcommands, len(allowedCommands))
+
+	for _, subcmd := range subcommands {
+		found := slices.Contains(allowedCommands, subcmd.Name())
+		assert.True(t, found, "unexpected subcommand %q", subcmd.Name())
+	}
+}
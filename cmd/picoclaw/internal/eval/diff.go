@@ -0,0 +1,21 @@
+package eval
+
+import "github.com/spf13/cobra"
+
+func newDiffCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff <baseline> <report>",
+		Short: "Compare two eval reports",
+		Long: `Compare two reports saved with 'eval run --report' and show cases that
+regressed or were fixed, added or removed cases, changed tool calls and
+line diffs of changed replies. The command fails if any case regressed.
+`,
+		Example: `  picoclaw eval diff old.json new.json`,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return evalDiffCmd(args[0], args[1])
+		},
+	}
+
+	return cmd
+}
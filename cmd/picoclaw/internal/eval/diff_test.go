@@ -0,0 +1,18 @@
+package eval
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDiffSubcommand(t *testing.T) {
+	cmd := newDiffCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "diff <baseline> <report>", cmd.Use)
+	assert.Equal(t, "Compare two eval reports", cmd.Short)
+	assert.True(t, cmd.HasExample())
+}
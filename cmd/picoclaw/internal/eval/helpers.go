@@ -0,0 +1,147 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/eval"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func evalRunCmd(paths []string, opts runOptions) error {
+	switch opts.mode {
+	case eval.ModeAuto, eval.ModeMock, eval.ModeLive:
+	default:
+		return fmt.Errorf("invalid --mode %q (want auto, mock or live)", opts.mode)
+	}
+
+	suites, err := eval.LoadSuites(paths)
+	if err != nil {
+		return fmt.Errorf("error loading suites: %w", err)
+	}
+
+	cfg, err := internal.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	if opts.model != "" {
+		cfg.Agents.Defaults.ModelName = opts.model
+	}
+
+	runner := &eval.Runner{
+		Config: cfg,
+		Mode:   opts.mode,
+		Filter: opts.filter,
+	}
+
+	if opts.mode != eval.ModeMock && needsLive(suites, opts.mode) {
+		provider, modelID, err := providers.CreateProvider(cfg)
+		if err != nil {
+			if opts.mode == eval.ModeLive {
+				return fmt.Errorf("error creating provider: %w", err)
+			}
+			fmt.Fprintf(os.Stderr, "Warning: %v; cases without a mock script are skipped\n", err)
+		} else {
+			if modelID != "" {
+				cfg.Agents.Defaults.ModelName = modelID
+			}
+			runner.Live = provider
+			runner.Model = cfg.Agents.Defaults.GetModelName()
+		}
+	}
+
+	if needsJudge(suites) {
+		judge, err := newJudge(cfg, opts.judgeModel)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: no judge model (%v); judge assertions will fail\n", err)
+		} else {
+			runner.Judge = judge
+		}
+	}
+
+	report := runner.Run(context.Background(), suites)
+	report.Print(os.Stdout)
+
+	if opts.report != "" {
+		if err := report.Save(opts.report); err != nil {
+			return fmt.Errorf("error saving report: %w", err)
+		}
+		fmt.Printf("Report saved to %s\n", opts.report)
+	}
+
+	regressions := 0
+	if opts.baseline != "" {
+		base, err := eval.LoadReport(opts.baseline)
+		if err != nil {
+			return fmt.Errorf("error loading baseline: %w", err)
+		}
+		changes := eval.Diff(base, report)
+		fmt.Printf("\nChanges since %s:\n", opts.baseline)
+		eval.PrintDiff(os.Stdout, changes)
+		regressions = eval.Regressions(changes)
+	}
+
+	if !report.OK() || regressions > 0 {
+		return fmt.Errorf("eval failed: %d failed, %d errors, %d regressions",
+			report.Failed, report.Errors, regressions)
+	}
+	return nil
+}
+
+func evalDiffCmd(basePath, reportPath string) error {
+	base, err := eval.LoadReport(basePath)
+	if err != nil {
+		return fmt.Errorf("error loading baseline: %w", err)
+	}
+	report, err := eval.LoadReport(reportPath)
+	if err != nil {
+		return fmt.Errorf("error loading report: %w", err)
+	}
+
+	changes := eval.Diff(base, report)
+	eval.PrintDiff(os.Stdout, changes)
+	if n := eval.Regressions(changes); n > 0 {
+		return fmt.Errorf("%d case(s) regressed", n)
+	}
+	return nil
+}
+
+// needsLive reports whether any case would run against the live model.
+func needsLive(suites []*eval.Suite, mode string) bool {
+	for _, s := range suites {
+		for _, c := range s.Cases {
+			if mode == eval.ModeLive || len(c.Mock) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func needsJudge(suites []*eval.Suite) bool {
+	for _, s := range suites {
+		for _, c := range s.Cases {
+			for _, a := range c.Assert {
+				if a.Judge != "" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func newJudge(cfg *config.Config, model string) (*eval.Judge, error) {
+	judgeCfg := *cfg
+	if model != "" {
+		judgeCfg.Agents.Defaults.ModelName = model
+	}
+	provider, modelID, err := providers.CreateProvider(&judgeCfg)
+	if err != nil {
+		return nil, err
+	}
+	return &eval.Judge{Provider: provider, Model: modelID}, nil
+}
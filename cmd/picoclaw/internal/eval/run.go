@@ -0,0 +1,53 @@
+package eval
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/eval"
+)
+
+type runOptions struct {
+	mode       string
+	model      string
+	judgeModel string
+	report     string
+	baseline   string
+	filter     string
+}
+
+func newRunCommand() *cobra.Command {
+	var opts runOptions
+
+	cmd := &cobra.Command{
+		Use:   "run <suite>...",
+		Short: "Run eval suites",
+		Long: `Run the cases in the given suite files, or in every .yaml/.yml file of
+the given directories, and print a pass/fail report.
+
+Each case runs in a fresh temporary workspace seeded with the configured
+workspace's AGENTS.md, SOUL.md, USER.md, IDENTITY.md, TOOLS.md and
+skills, plus the case's fixture files. With --mode auto (the default) a
+case uses its mock script when it has one and the configured model
+otherwise; --mode mock runs only scripted cases and --mode live ignores
+the scripts. --report saves the results as JSON and --baseline compares
+them with an earlier report. The command fails if any case fails or
+regresses.
+`,
+		Example: `  picoclaw eval run evals/
+	  picoclaw eval run evals/ --mode live --model gpt-4o --judge-model claude-sonnet
+	  picoclaw eval run evals/ --report new.json --baseline old.json`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return evalRunCmd(args, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.mode, "mode", eval.ModeAuto, "Provider mode: auto, mock or live")
+	cmd.Flags().StringVar(&opts.model, "model", "", "Model to evaluate (default: the configured model)")
+	cmd.Flags().StringVar(&opts.judgeModel, "judge-model", "", "Model that grades judge assertions (default: --model)")
+	cmd.Flags().StringVarP(&opts.report, "report", "r", "", "Save the results as JSON to this file")
+	cmd.Flags().StringVarP(&opts.baseline, "baseline", "b", "", "Compare the results with an earlier report")
+	cmd.Flags().StringVar(&opts.filter, "filter", "", "Only run cases whose suite/case name contains this")
+
+	return cmd
+}
@@ -0,0 +1,25 @@
+package eval
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRunSubcommand(t *testing.T) {
+	cmd := newRunCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "run <suite>...", cmd.Use)
+	assert.Equal(t, "Run eval suites", cmd.Short)
+	assert.True(t, cmd.HasExample())
+
+	assert.NotNil(t, cmd.Flags().Lookup("mode"))
+	assert.NotNil(t, cmd.Flags().Lookup("model"))
+	assert.NotNil(t, cmd.Flags().Lookup("judge-model"))
+	assert.NotNil(t, cmd.Flags().Lookup("report"))
+	assert.NotNil(t, cmd.Flags().Lookup("baseline"))
+	assert.NotNil(t, cmd.Flags().Lookup("filter"))
+}
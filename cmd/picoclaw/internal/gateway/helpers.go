@@ -12,12 +12,15 @@ import (
 	"time"
 
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/access"
 	"github.com/sipeed/picoclaw/pkg/agent"
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/cron"
 	"github.com/sipeed/picoclaw/pkg/devices"
+	"github.com/sipeed/picoclaw/pkg/devices/events"
+	"github.com/sipeed/picoclaw/pkg/devices/sources"
 	"github.com/sipeed/picoclaw/pkg/health"
 	"github.com/sipeed/picoclaw/pkg/heartbeat"
 	"github.com/sipeed/picoclaw/pkg/logger"
@@ -88,6 +91,13 @@ func gatewayCmd(debug bool) error {
 		cfg.Heartbeat.Interval,
 		cfg.Heartbeat.Enabled,
 	)
+	// Share the agent loop's state so heartbeat task saves and last channel
+	// updates don't overwrite each other.
+	stateManager := agentLoop.StateManager()
+	if stateManager == nil {
+		stateManager = state.NewManager(cfg.WorkspacePath())
+	}
+	heartbeatService.SetStateManager(stateManager)
 	heartbeatService.SetBus(msgBus)
 	heartbeatService.SetHandler(func(prompt, channel, chatID string) *tools.ToolResult {
 		// Use cli:direct as fallback if no valid channel
@@ -181,12 +191,24 @@ func gatewayCmd(debug bool) error {
 	}
 	fmt.Println("✓ Heartbeat service started")
 
-	stateManager := state.NewManager(cfg.WorkspacePath())
 	deviceService := devices.NewService(devices.Config{
-		Enabled:    cfg.Devices.Enabled,
-		MonitorUSB: cfg.Devices.MonitorUSB,
+		Enabled:           cfg.Devices.Enabled,
+		MonitorUSB:        cfg.Devices.MonitorUSB,
+		USBBackend:        cfg.Devices.USBBackend,
+		MonitorBluetooth:  cfg.Devices.MonitorBluetooth,
+		MonitorSubsystems: cfg.Devices.MonitorSubsystems,
+		MonitorNetwork:    cfg.Devices.MonitorNetwork,
+		MonitorPower:      cfg.Devices.MonitorPower,
+		PowerPollInterval: time.Duration(cfg.Devices.PowerPollSeconds) * time.Second,
+		GPIO:              gpioLines(cfg.Devices.GPIO),
+		Rules:             deviceRules(cfg.Devices.Rules),
+		AgentID:           agentLoop.DefaultAgentID(),
 	}, stateManager)
 	deviceService.SetBus(msgBus)
+	// Rules come from the config, so they are not subject to access roles.
+	deviceService.SetRuleHandler(func(ctx context.Context, prompt, sessionKey, channel, chatID string) (string, error) {
+		return agentLoop.ProcessDirectWithChannel(access.Trusted(ctx), prompt, sessionKey, channel, chatID)
+	})
 	if err := deviceService.Start(ctx); err != nil {
 		fmt.Printf("Error starting device service: %v\n", err)
 	} else if cfg.Devices.Enabled {
@@ -252,16 +274,53 @@ func setupCronTool(
 
 	// Create cron service
 	cronService := cron.NewCronService(cronStorePath, nil)
+	cronService.SetAgentID(agentLoop.DefaultAgentID())
 
 	// Create and register CronTool
 	cronTool := tools.NewCronTool(cronService, agentLoop, msgBus, workspace, restrict, execTimeout, cfg)
+	cronTool.SetAccess(agentLoop.AccessController())
 	agentLoop.RegisterTool(cronTool)
 
 	// Set the onJob handler
-	cronService.SetOnJob(func(job *cron.CronJob) (string, error) {
-		result := cronTool.ExecuteJob(context.Background(), job)
-		return result, nil
-	})
+	cronService.SetOnJob(cronTool.ExecuteJob)
+	cronService.SetMaxConcurrent(cfg.Tools.Cron.MaxConcurrentJobs)
+	cronService.SetOnFailure(cronTool.NotifyFailure)
 
 	return cronService
 }
+
+func deviceRules(cfgRules []config.DeviceRuleConfig) []devices.Rule {
+	rules := make([]devices.Rule, 0, len(cfgRules))
+	for _, r := range cfgRules {
+		rules = append(rules, devices.Rule{
+			Name:       r.Name,
+			Kind:       events.Kind(r.Kind),
+			Action:     events.Action(r.Action),
+			VendorID:   r.VendorID,
+			ProductID:  r.ProductID,
+			Serial:     r.Serial,
+			Capability: r.Capability,
+			Prompt:     r.Prompt,
+			Skill:      r.Skill,
+			Channel:    r.Channel,
+			ChatID:     r.ChatID,
+			Debounce:   time.Duration(r.Debounce) * time.Second,
+		})
+	}
+	return rules
+}
+
+func gpioLines(cfgLines []config.GPIOEventConfig) []sources.GPIOLine {
+	lines := make([]sources.GPIOLine, 0, len(cfgLines))
+	for _, l := range cfgLines {
+		lines = append(lines, sources.GPIOLine{
+			Name:      l.Name,
+			Chip:      l.Chip,
+			Line:      l.Line,
+			Edge:      l.Edge,
+			ActiveLow: l.ActiveLow,
+			Debounce:  time.Duration(l.DebounceMs) * time.Millisecond,
+		})
+	}
+	return lines
+}
@@ -7,7 +7,7 @@ import (
 func NewModelsCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "models",
-		Short: "Manage model_list configuration (list/add/delete/edit)",
+		Short: "Manage model_list configuration (list/add/delete/edit) and local Ollama models",
 	}
 
 	cmd.AddCommand(
@@ -15,6 +15,8 @@ func NewModelsCommand() *cobra.Command {
 		NewAddCommand(),
 		NewDeleteCommand(),
 		NewEditCommand(),
+		NewPullCommand(),
+		NewListLocalCommand(),
 	)
 
 	return cmd
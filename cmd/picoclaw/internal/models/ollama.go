@@ -0,0 +1,138 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers/ollama"
+)
+
+func NewPullCommand() *cobra.Command {
+	var apiBase string
+
+	cmd := &cobra.Command{
+		Use:     "pull <model>",
+		Short:   "Pull a model onto the Ollama server",
+		Example: `  picoclaw models pull qwen3:4b --api-base http://192.168.1.20:11434`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			p := newOllamaClient(apiBase)
+			fmt.Printf("Pulling %s from %s\n", args[0], p.BaseURL())
+
+			lastStatus := ""
+			err := p.Pull(ctx, args[0], func(update ollama.PullProgress) {
+				if update.Total > 0 {
+					pct := float64(update.Completed) / float64(update.Total) * 100
+					fmt.Printf("\r%s %5.1f%%", update.Status, pct)
+					lastStatus = update.Status
+					return
+				}
+				if update.Status != lastStatus {
+					if lastStatus != "" {
+						fmt.Println()
+					}
+					fmt.Print(update.Status)
+					lastStatus = update.Status
+				}
+			})
+			fmt.Println()
+			if err != nil {
+				return fmt.Errorf("pull failed: %w", err)
+			}
+			fmt.Printf("✓ %s is ready\n", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&apiBase, "api-base", "", "Ollama server URL (defaults to the first ollama/ entry in model_list)")
+
+	return cmd
+}
+
+func NewListLocalCommand() *cobra.Command {
+	var apiBase string
+
+	cmd := &cobra.Command{
+		Use:   "list-local",
+		Short: "List models available on the Ollama server",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			p := newOllamaClient(apiBase)
+			models, err := p.ListLocal(ctx)
+			if err != nil {
+				return fmt.Errorf("listing models from %s: %w", p.BaseURL(), err)
+			}
+			if len(models) == 0 {
+				fmt.Printf("No models on %s. Use: picoclaw models pull <model>\n", p.BaseURL())
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tPARAMS\tQUANT\tSIZE\tCONTEXT")
+			for _, m := range models {
+				ctxLen := "-"
+				if info, err := p.Show(ctx, m.Name); err == nil && info.ContextLength > 0 {
+					ctxLen = fmt.Sprintf("%d", info.ContextLength)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+					m.Name, m.ParameterSize, m.Quantization, formatBytes(m.Size), ctxLen)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&apiBase, "api-base", "", "Ollama server URL (defaults to the first ollama/ entry in model_list)")
+
+	return cmd
+}
+
+// newOllamaClient builds an Ollama client from the flag, or from the first
+// ollama/ entry in model_list, falling back to the local default.
+func newOllamaClient(apiBase string) *ollama.Provider {
+	apiKey, proxy := "", ""
+	if apiBase == "" {
+		if cfg, err := internal.LoadConfig(); err == nil {
+			if mc := findOllamaModel(cfg); mc != nil {
+				apiBase, apiKey, proxy = mc.APIBase, mc.APIKey, mc.Proxy
+			}
+		}
+	}
+	return ollama.NewProvider(apiKey, apiBase, proxy, "")
+}
+
+func findOllamaModel(cfg *config.Config) *config.ModelConfig {
+	for i := range cfg.ModelList {
+		if strings.HasPrefix(strings.ToLower(cfg.ModelList[i].Model), "ollama/") {
+			return &cfg.ModelList[i]
+		}
+	}
+	return nil
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -14,6 +14,8 @@ type deps struct {
 	workspace    string
 	installer    *skills.SkillInstaller
 	skillsLoader *skills.SkillsLoader
+	updater      *skills.Updater
+	registries   *skills.RegistryManager
 }
 
 func NewSkillsCommand() *cobra.Command {
@@ -39,14 +41,24 @@ about a specific skill.
 				return fmt.Errorf("error loading config: %w", err)
 			}
 
+			registries, err := newRegistryManager(cfg)
+			if err != nil {
+				return err
+			}
+			trust := registries.TrustStore()
+
 			d.workspace = cfg.WorkspacePath()
 			d.installer = skills.NewSkillInstaller(d.workspace)
+			d.installer.SetTrustStore(trust)
+			d.updater = skills.NewUpdater(d.workspace, registries)
+			d.registries = registries
 
 			// get global config directory and builtin skills directory
 			globalDir := filepath.Dir(internal.GetConfigPath())
 			globalSkillsDir := filepath.Join(globalDir, "skills")
 			builtinSkillsDir := filepath.Join(globalDir, "picoclaw", "skills")
 			d.skillsLoader = skills.NewSkillsLoader(d.workspace, globalSkillsDir, builtinSkillsDir)
+			d.skillsLoader.SetTrustStore(trust)
 
 			return nil
 		},
@@ -69,6 +81,20 @@ about a specific skill.
 		return d.skillsLoader, nil
 	}
 
+	updaterFn := func() (*skills.Updater, error) {
+		if d.updater == nil {
+			return nil, fmt.Errorf("skills updater is not initialized")
+		}
+		return d.updater, nil
+	}
+
+	registriesFn := func() (*skills.RegistryManager, error) {
+		if d.registries == nil {
+			return nil, fmt.Errorf("skill registries are not initialized")
+		}
+		return d.registries, nil
+	}
+
 	workspaceFn := func() (string, error) {
 		if d.workspace == "" {
 			return "", fmt.Errorf("workspace is not initialized")
@@ -82,8 +108,13 @@ about a specific skill.
 		newInstallBuiltinCommand(workspaceFn),
 		newListBuiltinCommand(),
 		newRemoveCommand(installerFn),
-		newSearchCommand(installerFn),
+		newSearchCommand(installerFn, registriesFn),
 		newShowCommand(loaderFn),
+		newUpdateCommand(updaterFn),
+		newOutdatedCommand(updaterFn),
+		newSyncCommand(updaterFn),
+		newKeygenCommand(),
+		newSignCommand(),
 	)
 
 	return cmd
@@ -1,6 +1,7 @@
 package skills
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"io"
@@ -26,7 +27,11 @@ func skillsListCmd(loader *skills.SkillsLoader) {
 	fmt.Println("\nInstalled Skills:")
 	fmt.Println("------------------")
 	for _, skill := range allSkills {
-		fmt.Printf("  ✓ %s (%s)\n", skill.Name, skill.Source)
+		if skill.Integrity == skills.IntegrityModified {
+			fmt.Printf("  ✗ %s (%s, modified since install)\n", skill.Name, skill.Source)
+		} else {
+			fmt.Printf("  ✓ %s (%s)\n", skill.Name, skill.Source)
+		}
 		if skill.Description != "" {
 			fmt.Printf("    %s\n", skill.Description)
 		}
@@ -62,12 +67,11 @@ func skillsInstallFromRegistry(cfg *config.Config, registryName, slug string) er
 
 	fmt.Printf("Installing skill '%s' from %s registry...\n", slug, registryName)
 
-	registryMgr := skills.NewRegistryManagerFromConfig(skills.RegistryConfig{
-		MaxConcurrentSearches: cfg.Tools.Skills.MaxConcurrentSearches,
-		ClawHub:               skills.ClawHubConfig(cfg.Tools.Skills.Registries.ClawHub),
-	})
-
-	registry := registryMgr.GetRegistry(registryName)
+	registries, err := newRegistryManager(cfg)
+	if err != nil {
+		return fmt.Errorf("\u2717 %w", err)
+	}
+	registry := registries.GetRegistry(registryName)
 	if registry == nil {
 		return fmt.Errorf("✗  registry '%s' not found or not enabled. check your config.json.", registryName)
 	}
@@ -108,6 +112,19 @@ func skillsInstallFromRegistry(cfg *config.Config, registryName, slug string) er
 		fmt.Printf("\u26a0\ufe0f  Warning: skill '%s' is flagged as suspicious.\n", slug)
 	}
 
+	if result.Trust.Status != "" && result.Trust.Status != skills.TrustVerified {
+		fmt.Printf("\u26a0\ufe0f  Warning: skill '%s' signature: %s\n", slug, result.Trust)
+	}
+
+	if err = skills.RecordInstall(workspace, slug, skills.LockEntry{
+		Registry:    registryName,
+		Slug:        slug,
+		Version:     result.Version,
+		ResolvedURL: result.ResolvedURL,
+	}); err != nil {
+		fmt.Printf("\u26a0\ufe0f  Warning: failed to update %s: %v\n", skills.LockFileName, err)
+	}
+
 	fmt.Printf("\u2713 Skill '%s' v%s installed successfully!\n", slug, result.Version)
 	if result.Summary != "" {
 		fmt.Printf("  %s\n", result.Summary)
@@ -116,6 +133,35 @@ func skillsInstallFromRegistry(cfg *config.Config, registryName, slug string) er
 	return nil
 }
 
+// newRegistryManager builds the registry manager for the registries enabled in config.
+func newRegistryManager(cfg *config.Config) (*skills.RegistryManager, error) {
+	trust, err := newTrustStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	rc := skills.RegistryConfig{
+		MaxConcurrentSearches: cfg.Tools.Skills.MaxConcurrentSearches,
+		ClawHub:               skills.ClawHubConfig(cfg.Tools.Skills.Registries.ClawHub),
+		Trust:                 trust,
+	}
+	for _, g := range cfg.Tools.Skills.Registries.Git {
+		rc.Git = append(rc.Git, skills.GitRegistryConfig(g))
+	}
+	for _, i := range cfg.Tools.Skills.Registries.Index {
+		rc.Index = append(rc.Index, skills.IndexRegistryConfig(i))
+	}
+	return skills.NewRegistryManagerFromConfig(rc), nil
+}
+
+// newTrustStore builds the skill trust store from config.
+func newTrustStore(cfg *config.Config) (*skills.TrustStore, error) {
+	trust, err := skills.NewTrustStore(skills.TrustConfig(cfg.Tools.Skills.Trust))
+	if err != nil {
+		return nil, fmt.Errorf("invalid skill trust config: %w", err)
+	}
+	return trust, nil
+}
+
 func skillsRemoveCmd(installer *skills.SkillInstaller, skillName string) {
 	fmt.Printf("Removing skill '%s'...\n", skillName)
 
@@ -215,6 +261,37 @@ func skillsListBuiltinCmd() {
 	}
 }
 
+func skillsSearchRegistriesCmd(registries *skills.RegistryManager, query string) error {
+	fmt.Printf("Searching registries for %q...\n", query)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	results, err := registries.SearchAll(ctx, query, 20)
+	if err != nil {
+		return fmt.Errorf("\u2717 search failed: %w", err)
+	}
+	if len(results) == 0 {
+		fmt.Println("No matching skills found.")
+		return nil
+	}
+
+	fmt.Printf("\nMatching Skills (%d):\n", len(results))
+	fmt.Println("--------------------")
+	for _, r := range results {
+		fmt.Printf("  📦 %s (%s)\n", r.DisplayName, r.RegistryName)
+		if r.Summary != "" {
+			fmt.Printf("     %s\n", r.Summary)
+		}
+		if r.Version != "" {
+			fmt.Printf("     Version: %s\n", r.Version)
+		}
+		fmt.Printf("     Install: picoclaw skills install --registry %s %s\n", r.RegistryName, r.Slug)
+		fmt.Println()
+	}
+	return nil
+}
+
 func skillsSearchCmd(installer *skills.SkillInstaller) {
 	fmt.Println("Searching for available skills...")
 
@@ -256,10 +333,205 @@ func skillsShowCmd(loader *skills.SkillsLoader, skillName string) {
 	}
 
 	fmt.Printf("\n📦 Skill: %s\n", skillName)
+	if result, policy, ok := loader.TrustStatus(skillName); ok {
+		fmt.Printf("🔏 Trust: %s (policy: %s)\n", result, policy)
+	}
 	fmt.Println("----------------------")
 	fmt.Println(content)
 }
 
+// maxPreviewLines caps the per-file diff shown by skills update.
+const maxPreviewLines = 40
+
+func skillsUpdateCmd(updater *skills.Updater, names []string, version string, yes, dryRun bool, in io.Reader) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	if len(names) == 0 {
+		infos, err := updater.Outdated(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("\u2717 failed to check for updates: %w", err)
+		}
+		for _, info := range infos {
+			if info.Outdated {
+				names = append(names, info.Name)
+			}
+		}
+		if len(names) == 0 {
+			fmt.Println("\u2713 All skills are up to date.")
+			return nil
+		}
+	}
+
+	reader := bufio.NewReader(in)
+	for _, name := range names {
+		staged, err := updater.Stage(ctx, name, version)
+		if err != nil {
+			return fmt.Errorf("\u2717 failed to download update for '%s': %w", name, err)
+		}
+		if !staged.Changed() {
+			staged.Discard()
+			fmt.Printf("\u2713 Skill '%s' is up to date (%s)\n", name, staged.Current.Version)
+			continue
+		}
+
+		printUpdatePreview(staged)
+
+		if dryRun {
+			staged.Discard()
+			continue
+		}
+		if !yes {
+			fmt.Printf("Apply update to '%s'? (y/n): ", name)
+			answer, _ := reader.ReadString('\n')
+			if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+				staged.Discard()
+				fmt.Println("Skipped.")
+				continue
+			}
+		}
+
+		if err := staged.Apply(); err != nil {
+			return fmt.Errorf("\u2717 failed to update '%s': %w", name, err)
+		}
+		fmt.Printf("\u2713 Skill '%s' updated to %s\n", name, staged.Next.Version)
+	}
+
+	return nil
+}
+
+func printUpdatePreview(staged *skills.StagedUpdate) {
+	fmt.Printf("\n\U0001f4e6 %s: %s -> %s (%s)\n", staged.Name, staged.Current.Version, staged.Next.Version,
+		staged.Next.Registry)
+	for _, c := range staged.Changes {
+		switch c.Kind {
+		case "added":
+			fmt.Printf("  + %s\n", c.Path)
+		case "removed":
+			fmt.Printf("  - %s\n", c.Path)
+		default:
+			fmt.Printf("  ~ %s\n", c.Path)
+			lines := strings.Split(strings.TrimSuffix(c.Diff, "\n"), "\n")
+			for i, line := range lines {
+				if i == maxPreviewLines {
+					fmt.Printf("      ... %d more lines\n", len(lines)-maxPreviewLines)
+					break
+				}
+				fmt.Printf("      %s\n", line)
+			}
+		}
+	}
+	fmt.Println()
+}
+
+func skillsOutdatedCmd(updater *skills.Updater, names []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	infos, err := updater.Outdated(ctx, names)
+	if err != nil {
+		return fmt.Errorf("\u2717 failed to check for updates: %w", err)
+	}
+
+	var outdated []skills.OutdatedInfo
+	for _, info := range infos {
+		if info.Outdated {
+			outdated = append(outdated, info)
+		}
+	}
+	if len(outdated) == 0 {
+		fmt.Println("\u2713 All skills are up to date.")
+		return nil
+	}
+
+	fmt.Printf("\nOutdated Skills (%d):\n", len(outdated))
+	fmt.Println("--------------------")
+	fmt.Printf("  %-24s %-12s %-12s %s\n", "NAME", "CURRENT", "LATEST", "REGISTRY")
+	for _, info := range outdated {
+		latest := info.Latest
+		if latest == info.Current.Version {
+			latest = "changed"
+		}
+		fmt.Printf("  %-24s %-12s %-12s %s\n", info.Name, info.Current.Version, latest, info.Current.Registry)
+	}
+	fmt.Println("\nRun 'picoclaw skills update <name>' or 'picoclaw skills update --all' to update.")
+
+	return nil
+}
+
+func skillsSyncCmd(updater *skills.Updater, prune bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	results, err := updater.Sync(ctx, prune)
+	if err != nil {
+		return fmt.Errorf("\u2717 failed to sync skills: %w", err)
+	}
+	if len(results) == 0 {
+		fmt.Printf("No skills recorded in %s.\n", skills.LockFileName)
+		return nil
+	}
+
+	failed := 0
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			failed++
+			fmt.Printf("  \u2717 %s: %v\n", r.Name, r.Err)
+		case r.Action == "ok":
+			fmt.Printf("  \u2713 %s\n", r.Name)
+		default:
+			fmt.Printf("  \u2713 %s (%s)\n", r.Name, r.Action)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("\u2717 %d skill(s) failed to sync", failed)
+	}
+	fmt.Println("\n\u2713 Skills match", skills.LockFileName)
+	return nil
+}
+
+func skillsKeygenCmd(keyFile string) error {
+	if _, err := os.Stat(keyFile); err == nil {
+		return fmt.Errorf("\u2717 %s already exists", keyFile)
+	}
+
+	publicKey, privateKey, err := skills.GenerateSigningKey()
+	if err != nil {
+		return fmt.Errorf("\u2717 failed to generate key: %w", err)
+	}
+	if err := os.WriteFile(keyFile, []byte(privateKey+"\n"), 0o600); err != nil {
+		return fmt.Errorf("\u2717 failed to write private key: %w", err)
+	}
+
+	fmt.Printf("\u2713 Private key written to %s\n", keyFile)
+	fmt.Printf("  Public key: %s\n", publicKey)
+	fmt.Println("  Add it to tools.skills.trust.keys in config.json under your key ID.")
+	return nil
+}
+
+func skillsSignCmd(dir, keyFile, keyID string) error {
+	if _, err := os.Stat(filepath.Join(dir, "SKILL.md")); err != nil {
+		return fmt.Errorf("\u2717 %s is not a skill directory: %w", dir, err)
+	}
+
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return fmt.Errorf("\u2717 failed to read private key: %w", err)
+	}
+	key, err := skills.ParsePrivateKey(string(data))
+	if err != nil {
+		return fmt.Errorf("\u2717 %w", err)
+	}
+
+	if err := skills.SignSkill(dir, keyID, key); err != nil {
+		return fmt.Errorf("\u2717 failed to sign skill: %w", err)
+	}
+	fmt.Printf("\u2713 Signed %s as %s (%s)\n", dir, keyID, skills.SignatureFileName)
+	return nil
+}
+
 func copyDirectory(src, dst string) error {
 	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -0,0 +1,26 @@
+package skills
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newKeygenCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "keygen <private-key-file>",
+		Short:   "Generate a skill signing key",
+		Example: `picoclaw skills keygen ~/.picoclaw/signing.key`,
+		Long: `Generate an ed25519 key pair for signing skills.
+
+The private key is written to the given file, readable only by you.
+The public key is printed so it can be published and added to the
+tools.skills.trust.keys section of config.json on devices that should
+trust your skills.
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return skillsKeygenCmd(args[0])
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,27 @@
+package skills
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewKeygenSubcommand(t *testing.T) {
+	cmd := newKeygenCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "keygen <private-key-file>", cmd.Use)
+	assert.Equal(t, "Generate a skill signing key", cmd.Short)
+
+	assert.Nil(t, cmd.Run)
+	assert.NotNil(t, cmd.RunE)
+
+	assert.True(t, cmd.HasExample())
+	assert.False(t, cmd.HasSubCommands())
+
+	assert.False(t, cmd.HasFlags())
+
+	assert.Len(t, cmd.Aliases, 0)
+}
@@ -0,0 +1,29 @@
+package skills
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+func newOutdatedCommand(updaterFn func() (*skills.Updater, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "outdated [name...]",
+		Short:   "List skills with available updates",
+		Example: `picoclaw skills outdated`,
+		Long: `Check skills recorded in skills.lock.json against their registry
+and list the ones with a newer version available. Skills installed from
+GitHub have no version, so their current content is downloaded and
+compared instead.
+`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			updater, err := updaterFn()
+			if err != nil {
+				return err
+			}
+			return skillsOutdatedCmd(updater, args)
+		},
+	}
+
+	return cmd
+}
@@ -6,18 +6,32 @@ import (
 	"github.com/sipeed/picoclaw/pkg/skills"
 )
 
-func newSearchCommand(installerFn func() (*skills.SkillInstaller, error)) *cobra.Command {
+func newSearchCommand(
+	installerFn func() (*skills.SkillInstaller, error),
+	registriesFn func() (*skills.RegistryManager, error),
+) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "search",
+		Use:   "search [query]",
 		Short: "Search available skills",
+		Example: `picoclaw skills search
+picoclaw skills search weather`,
 		Long: `Search remote registries or indexes for available skills.
 
 This command assists discovering third-party skills you can install.
-Depending on configuration it may query public registries or a
-centralized index. After finding a skill, use 'picoclaw skills
+Without a query it lists the central picoclaw-skills index. With a
+query it searches every configured registry (ClawHub, Git and static
+index registries). After finding a skill, use 'picoclaw skills
 install' to add it to your workspace.
 `,
-		RunE: func(_ *cobra.Command, _ []string) error {
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				registries, err := registriesFn()
+				if err != nil {
+					return err
+				}
+				return skillsSearchRegistriesCmd(registries, args[0])
+			}
 			installer, err := installerFn()
 			if err != nil {
 				return err
@@ -0,0 +1,36 @@
+package skills
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newSignCommand() *cobra.Command {
+	var (
+		keyFile string
+		keyID   string
+	)
+
+	cmd := &cobra.Command{
+		Use:     "sign <skill-dir>",
+		Short:   "Sign a skill",
+		Example: `picoclaw skills sign ./weather --key ~/.picoclaw/signing.key --key-id sipeed`,
+		Long: `Sign a skill directory with an ed25519 private key.
+
+Writes SKILL.sig next to SKILL.md. The signature covers the content of
+every file in the directory, so sign after the last change and publish
+SKILL.sig with the skill. The key ID names the publisher key under which
+devices list the public key in tools.skills.trust.keys.
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return skillsSignCmd(args[0], keyFile, keyID)
+		},
+	}
+
+	cmd.Flags().StringVar(&keyFile, "key", "", "Private key file created by 'picoclaw skills keygen'")
+	cmd.Flags().StringVar(&keyID, "key-id", "", "Publisher key ID recorded in the signature")
+	_ = cmd.MarkFlagRequired("key")
+	_ = cmd.MarkFlagRequired("key-id")
+
+	return cmd
+}
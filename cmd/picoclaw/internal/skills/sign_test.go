@@ -0,0 +1,29 @@
+package skills
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSignSubcommand(t *testing.T) {
+	cmd := newSignCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "sign <skill-dir>", cmd.Use)
+	assert.Equal(t, "Sign a skill", cmd.Short)
+
+	assert.Nil(t, cmd.Run)
+	assert.NotNil(t, cmd.RunE)
+
+	assert.True(t, cmd.HasExample())
+	assert.False(t, cmd.HasSubCommands())
+
+	assert.True(t, cmd.HasFlags())
+	assert.NotNil(t, cmd.Flags().Lookup("key"))
+	assert.NotNil(t, cmd.Flags().Lookup("key-id"))
+
+	assert.Len(t, cmd.Aliases, 0)
+}
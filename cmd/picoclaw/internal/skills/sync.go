@@ -0,0 +1,37 @@
+package skills
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+func newSyncCommand(updaterFn func() (*skills.Updater, error)) *cobra.Command {
+	var prune bool
+
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Install skills exactly as locked",
+		Example: `picoclaw skills sync
+picoclaw skills sync --prune`,
+		Long: `Install exactly the skills recorded in skills.lock.json.
+
+Missing or modified skills are downloaded at their locked version and
+must match the locked content hash. Use this to reproduce a workspace
+on another device. With --prune, workspace skills that are not in the
+lockfile are removed.
+`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			updater, err := updaterFn()
+			if err != nil {
+				return err
+			}
+			return skillsSyncCmd(updater, prune)
+		},
+	}
+
+	cmd.Flags().BoolVar(&prune, "prune", false, "Remove workspace skills that are not in the lockfile")
+
+	return cmd
+}
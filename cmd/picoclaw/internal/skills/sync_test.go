@@ -0,0 +1,28 @@
+package skills
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSyncSubcommand(t *testing.T) {
+	cmd := newSyncCommand(nil)
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "sync", cmd.Use)
+	assert.Equal(t, "Install skills exactly as locked", cmd.Short)
+
+	assert.Nil(t, cmd.Run)
+	assert.NotNil(t, cmd.RunE)
+
+	assert.True(t, cmd.HasExample())
+	assert.False(t, cmd.HasSubCommands())
+
+	assert.True(t, cmd.HasFlags())
+	assert.NotNil(t, cmd.Flags().Lookup("prune"))
+
+	assert.Len(t, cmd.Aliases, 0)
+}
@@ -0,0 +1,59 @@
+package skills
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+func newUpdateCommand(updaterFn func() (*skills.Updater, error)) *cobra.Command {
+	var (
+		all     bool
+		yes     bool
+		dryRun  bool
+		version string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "update [name]",
+		Short: "Update installed skills",
+		Example: `picoclaw skills update weather
+picoclaw skills update weather --version 1.2.0
+picoclaw skills update --all --dry-run`,
+		Long: `Update skills recorded in skills.lock.json to their latest version.
+
+Each update is downloaded first and a preview of the changed files is
+shown before it replaces the installed copy. Pass --yes to apply
+without asking, or --dry-run to only show the preview.
+`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if all {
+				if len(args) != 0 || version != "" {
+					return fmt.Errorf("--all cannot be combined with a skill name or --version")
+				}
+				return nil
+			}
+			if len(args) != 1 {
+				return fmt.Errorf("exactly 1 argument is required: <name> (or use --all)")
+			}
+			return nil
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			updater, err := updaterFn()
+			if err != nil {
+				return err
+			}
+			return skillsUpdateCmd(updater, args, version, yes, dryRun, os.Stdin)
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Update all locked skills")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Apply updates without confirmation")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the update preview without applying it")
+	cmd.Flags().StringVar(&version, "version", "", "Install a specific version instead of the latest")
+
+	return cmd
+}
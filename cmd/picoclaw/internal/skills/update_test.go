@@ -0,0 +1,38 @@
+package skills
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUpdateSubcommand(t *testing.T) {
+	cmd := newUpdateCommand(nil)
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "update [name]", cmd.Use)
+	assert.Equal(t, "Update installed skills", cmd.Short)
+
+	assert.Nil(t, cmd.Run)
+	assert.NotNil(t, cmd.RunE)
+
+	assert.True(t, cmd.HasExample())
+	assert.False(t, cmd.HasSubCommands())
+
+	assert.True(t, cmd.HasFlags())
+	assert.NotNil(t, cmd.Flags().Lookup("all"))
+	assert.NotNil(t, cmd.Flags().Lookup("yes"))
+	assert.NotNil(t, cmd.Flags().Lookup("dry-run"))
+	assert.NotNil(t, cmd.Flags().Lookup("version"))
+
+	assert.Error(t, cmd.Args(cmd, nil))
+	assert.NoError(t, cmd.Args(cmd, []string{"weather"}))
+
+	require.NoError(t, cmd.Flags().Set("all", "true"))
+	assert.NoError(t, cmd.Args(cmd, nil))
+	assert.Error(t, cmd.Args(cmd, []string{"weather"}))
+
+	assert.Len(t, cmd.Aliases, 0)
+}
@@ -37,6 +37,7 @@ func TestNewPicoclawCommand(t *testing.T) {
 		"auth",
 		"channel",
 		"cron",
+		"eval",
 		"gateway",
 		"migrate",
 		"models",
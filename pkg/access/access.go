@@ -0,0 +1,254 @@
+// Package access assigns roles to senders across channels and enforces
+// what each role may do: agents, tools, slash commands, models and hourly
+// quotas.
+package access
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/routing"
+)
+
+const quotaWindow = time.Hour
+
+// noAccess applies to senders whose role is not defined: they may chat
+// with the routed agent but use no tools, commands or other models.
+var noAccess = config.RolePolicy{
+	Tools:    []string{},
+	Commands: []string{},
+	Models:   []string{},
+}
+
+// Controller resolves senders to roles and tracks their quotas. A nil
+// Controller (no access config) resolves every sender to a nil Principal,
+// which has full access.
+type Controller struct {
+	cfg   config.AccessConfig
+	links map[string][]string
+	now   func() time.Time
+
+	mu    sync.Mutex
+	usage map[string]*window // keyed by identity and quota kind
+}
+
+type window struct {
+	start time.Time
+	count int
+}
+
+// NewController returns the access controller for cfg, or nil when access
+// control is not configured.
+func NewController(cfg *config.Config) *Controller {
+	if cfg == nil || cfg.Access == nil {
+		return nil
+	}
+	members := make(map[string]string, len(cfg.Access.Members))
+	for id, role := range cfg.Access.Members {
+		members[strings.ToLower(strings.TrimSpace(id))] = role
+	}
+	c := &Controller{
+		cfg:   *cfg.Access,
+		links: cfg.Session.IdentityLinks,
+		now:   time.Now,
+		usage: make(map[string]*window),
+	}
+	c.cfg.Members = members
+	return c
+}
+
+// Identities returns the names a sender can be listed under, most specific
+// first: its identity_links name, "channel:id", the ID, and the raw sender ID
+// (compound IDs such as Telegram's "123|username" are matched by their ID).
+func Identities(links map[string][]string, channel, senderID string) []string {
+	sender := strings.ToLower(strings.TrimSpace(senderID))
+	if sender == "" {
+		return nil
+	}
+	id, _, _ := strings.Cut(sender, "|")
+	channel = strings.ToLower(strings.TrimSpace(channel))
+
+	var ids []string
+	if linked := routing.LinkedIdentity(links, channel, id); linked != "" {
+		ids = append(ids, strings.ToLower(linked))
+	}
+	ids = append(ids, channel+":"+id, id)
+	if sender != id {
+		ids = append(ids, sender)
+	}
+	return ids
+}
+
+// Principal is a sender and the role they act under. A nil Principal has
+// full access, except that it is not an admin.
+type Principal struct {
+	Channel  string
+	SenderID string
+	Identity string // quota key: the linked identity, or "channel:id"
+	Role     string
+
+	policy config.RolePolicy
+	c      *Controller
+}
+
+// Resolve returns the principal for a sender on a channel.
+func (c *Controller) Resolve(channel, senderID string) *Principal {
+	if c == nil {
+		return nil
+	}
+
+	ids := Identities(c.links, channel, senderID)
+	p := &Principal{Channel: channel, SenderID: senderID, Identity: channel + ":" + senderID, c: c}
+	if len(ids) > 0 {
+		// Quotas follow the linked identity across channels.
+		p.Identity = ids[0]
+	}
+	for _, id := range ids {
+		if role, ok := c.cfg.Members[id]; ok {
+			p.Role = role
+			break
+		}
+	}
+	if p.Role == "" {
+		p.Role = c.cfg.DefaultRole
+	}
+
+	policy, ok := c.cfg.Roles[p.Role]
+	if !ok {
+		policy = noAccess
+	}
+	p.policy = policy
+	return p
+}
+
+// IsAdmin reports whether the principal's role may change global settings.
+func (p *Principal) IsAdmin() bool {
+	return p != nil && p.policy.Admin
+}
+
+// AllowAgent reports whether the principal may talk to an agent.
+func (p *Principal) AllowAgent(agentID string) bool {
+	return p == nil || matches(p.policy.Agents, routing.NormalizeAgentID(agentID))
+}
+
+// AllowCommand reports whether the principal may run a slash command.
+func (p *Principal) AllowCommand(cmd string) bool {
+	if p == nil || p.policy.Commands == nil {
+		return true
+	}
+	name := strings.TrimPrefix(cmd, "/")
+	for _, allowed := range p.policy.Commands {
+		if allowed == "*" || strings.EqualFold(strings.TrimPrefix(allowed, "/"), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowModel reports whether the principal may pick a model_list entry.
+func (p *Principal) AllowModel(modelName string) bool {
+	return p == nil || matches(p.policy.Models, modelName)
+}
+
+// Permits reports whether the principal may use a tool at all.
+func (p *Principal) Permits(tool string) bool {
+	return p == nil || matches(p.policy.Tools, tool)
+}
+
+// AllowTool checks a tool call against the role's tools and its hourly
+// tool call quota, counting the call if it is allowed.
+func (p *Principal) AllowTool(tool string) error {
+	if p == nil {
+		return nil
+	}
+	if !p.Permits(tool) {
+		return fmt.Errorf("the %s role may not use %s", p.roleName(), tool)
+	}
+	return p.c.take(p.Identity+"|tools", p.policy.ToolCallsPerHour, "tool call")
+}
+
+// AllowMessage checks the role's hourly message quota, counting the
+// message if it is allowed.
+func (p *Principal) AllowMessage() error {
+	if p == nil {
+		return nil
+	}
+	return p.c.take(p.Identity+"|messages", p.policy.MessagesPerHour, "message")
+}
+
+func (p *Principal) roleName() string {
+	if p.Role == "" {
+		return "default"
+	}
+	return p.Role
+}
+
+// take counts one use against an hourly quota; limit <= 0 is unlimited.
+func (c *Controller) take(key string, limit int, what string) error {
+	if limit <= 0 {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	w := c.usage[key]
+	if w == nil || now.Sub(w.start) >= quotaWindow {
+		w = &window{start: now}
+		c.usage[key] = w
+	}
+	if w.count >= limit {
+		wait := max(w.start.Add(quotaWindow).Sub(now).Round(time.Minute), time.Minute)
+		return fmt.Errorf("hourly %s quota of %d reached, try again in %s", what, limit, wait)
+	}
+	w.count++
+	return nil
+}
+
+// matches reports whether name is in list; a nil list matches everything.
+func matches(list []string, name string) bool {
+	if list == nil {
+		return true
+	}
+	for _, allowed := range list {
+		if allowed == "*" || strings.EqualFold(allowed, name) {
+			return true
+		}
+	}
+	return false
+}
+
+type (
+	senderKey  struct{}
+	trustedKey struct{}
+)
+
+type sender struct{ channel, id string }
+
+// WithSender attributes work done on someone's behalf, such as a scheduled
+// job they created, to them, so it runs under their current role.
+func WithSender(ctx context.Context, channel, senderID string) context.Context {
+	return context.WithValue(ctx, senderKey{}, sender{channel, senderID})
+}
+
+// SenderFrom returns the sender set with WithSender.
+func SenderFrom(ctx context.Context) (channel, senderID string, ok bool) {
+	s, ok := ctx.Value(senderKey{}).(sender)
+	return s.channel, s.id, ok
+}
+
+// Trusted marks work started by the device's own configuration, such as
+// device rules or the local web UI, which is not subject to roles.
+func Trusted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, trustedKey{}, true)
+}
+
+// IsTrusted reports whether ctx was marked with Trusted.
+func IsTrusted(ctx context.Context) bool {
+	trusted, _ := ctx.Value(trustedKey{}).(bool)
+	return trusted
+}
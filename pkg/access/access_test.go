@@ -0,0 +1,153 @@
+package access
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func testController(t *testing.T) *Controller {
+	t.Helper()
+	cfg := &config.Config{
+		Session: config.SessionConfig{
+			IdentityLinks: map[string][]string{"mum": {"telegram:100", "discord:200"}},
+		},
+		Access: &config.AccessConfig{
+			DefaultRole: "guest",
+			Members: map[string]string{
+				"Mum":         "family",
+				"telegram:42": "admin",
+				"slack:u1":    "missing",
+			},
+			Roles: map[string]config.RolePolicy{
+				"admin":  {Admin: true},
+				"family": {Tools: []string{"web_search", "read_file"}, Agents: []string{"main"}},
+				"guest":  {Tools: []string{}, Commands: []string{"/show"}, Models: []string{"fast"}},
+			},
+		},
+	}
+	return NewController(cfg)
+}
+
+func TestResolve(t *testing.T) {
+	c := testController(t)
+	cases := []struct {
+		channel, sender string
+		role            string
+	}{
+		{"telegram", "42", "admin"},
+		{"telegram", "42|someone", "admin"},
+		{"telegram", "100", "family"},
+		{"discord", "200", "family"},
+		{"telegram", "7", "guest"},
+		{"discord", "42", "guest"},
+		{"slack", "U1", "missing"},
+	}
+	for _, tc := range cases {
+		if got := c.Resolve(tc.channel, tc.sender).Role; got != tc.role {
+			t.Errorf("Resolve(%s, %s) role = %q, want %q", tc.channel, tc.sender, got, tc.role)
+		}
+	}
+
+	// Linked identities share one identity for quotas.
+	if a, b := c.Resolve("telegram", "100"), c.Resolve("discord", "200"); a.Identity != b.Identity {
+		t.Errorf("linked identities differ: %q, %q", a.Identity, b.Identity)
+	}
+}
+
+func TestPolicies(t *testing.T) {
+	c := testController(t)
+
+	admin := c.Resolve("telegram", "42")
+	if !admin.IsAdmin() || !admin.Permits("exec") || !admin.AllowCommand("/global") || !admin.AllowModel("any") {
+		t.Error("admin role with unset lists should allow everything")
+	}
+
+	family := c.Resolve("telegram", "100")
+	if family.IsAdmin() || family.Permits("exec") || !family.Permits("web_search") {
+		t.Error("family tools not applied")
+	}
+	if !family.AllowAgent("Main") || family.AllowAgent("coder") {
+		t.Error("family agents not applied")
+	}
+	if err := family.AllowTool("exec"); err == nil || !strings.Contains(err.Error(), "family role") {
+		t.Errorf("AllowTool(exec) = %v", err)
+	}
+
+	guest := c.Resolve("telegram", "7")
+	if guest.Permits("read_file") {
+		t.Error("an empty tool list should allow no tools")
+	}
+	if !guest.AllowCommand("show") || guest.AllowCommand("/switch") {
+		t.Error("guest commands not applied")
+	}
+	if !guest.AllowModel("fast") || guest.AllowModel("smart") {
+		t.Error("guest models not applied")
+	}
+
+	undefined := c.Resolve("slack", "u1")
+	if !undefined.AllowAgent("main") || undefined.Permits("read_file") || undefined.AllowCommand("/show") {
+		t.Error("an undefined role should only be able to chat")
+	}
+
+	var none *Principal
+	if none.IsAdmin() || !none.Permits("exec") || none.AllowTool("exec") != nil || none.AllowMessage() != nil {
+		t.Error("a nil principal should have full access but not be an admin")
+	}
+	if (*Controller)(nil).Resolve("telegram", "1") != nil {
+		t.Error("a nil controller should resolve to a nil principal")
+	}
+}
+
+func TestQuotas(t *testing.T) {
+	cfg := &config.Config{Access: &config.AccessConfig{
+		DefaultRole: "user",
+		Roles: map[string]config.RolePolicy{
+			"user": {MessagesPerHour: 2, ToolCallsPerHour: 1},
+		},
+	}}
+	c := NewController(cfg)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	p := c.Resolve("telegram", "1")
+	for i := 0; i < 2; i++ {
+		if err := p.AllowMessage(); err != nil {
+			t.Fatalf("message %d: %v", i+1, err)
+		}
+	}
+	if err := p.AllowMessage(); err == nil || !strings.Contains(err.Error(), "try again in 1h0m0s") {
+		t.Errorf("third message = %v, want the quota error", err)
+	}
+	if err := c.Resolve("telegram", "2").AllowMessage(); err != nil {
+		t.Errorf("quotas should be per sender: %v", err)
+	}
+
+	if err := p.AllowTool("exec"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AllowTool("read_file"); err == nil {
+		t.Error("second tool call should hit the tool quota")
+	}
+
+	now = now.Add(time.Hour)
+	if err := p.AllowMessage(); err != nil {
+		t.Errorf("quota should reset after an hour: %v", err)
+	}
+}
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	if _, _, ok := SenderFrom(ctx); ok || IsTrusted(ctx) {
+		t.Error("a plain context should have no sender and not be trusted")
+	}
+	if channel, sender, ok := SenderFrom(WithSender(ctx, "telegram", "7")); !ok || channel != "telegram" || sender != "7" {
+		t.Errorf("SenderFrom = %s, %s, %v", channel, sender, ok)
+	}
+	if !IsTrusted(Trusted(ctx)) {
+		t.Error("Trusted context should be trusted")
+	}
+}
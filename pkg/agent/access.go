@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sipeed/picoclaw/pkg/access"
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/constants"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// AccessController returns the roles and quotas senders are held to, or nil
+// when access control is off.
+func (al *AgentLoop) AccessController() *access.Controller {
+	return al.access
+}
+
+// principal returns the sender's role for access control, or nil for full
+// access. Work attributed to a sender with access.WithSender, such as their
+// cron jobs, runs under that sender's role. The local CLI and work marked
+// with access.Trusted (device rules, the web UI) are not restricted.
+func (al *AgentLoop) principal(ctx context.Context, msg bus.InboundMessage) *access.Principal {
+	if channel, senderID, ok := access.SenderFrom(ctx); ok {
+		return al.access.Resolve(channel, senderID)
+	}
+	if access.IsTrusted(ctx) || constants.IsInternalChannel(msg.Channel) {
+		return nil
+	}
+	return al.access.Resolve(msg.Channel, msg.SenderID)
+}
+
+// checkCommand returns the refusal for a slash command the sender's role
+// may not run.
+func checkCommand(chat *chatContext, cmd string) (string, bool) {
+	if chat.principal.AllowCommand(cmd) {
+		return "", false
+	}
+	return fmt.Sprintf("You don't have permission to use %s.", cmd), true
+}
+
+// admitTurn counts an LLM turn against the sender's message quota and
+// attaches their tool policy to ctx, where ToolRegistry enforces it.
+func admitTurn(ctx context.Context, chat *chatContext) (context.Context, error) {
+	if chat.principal == nil {
+		return ctx, nil
+	}
+	if err := chat.principal.AllowMessage(); err != nil {
+		return ctx, err
+	}
+	return tools.WithGuard(ctx, chat.principal), nil
+}
@@ -0,0 +1,204 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/access"
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// execProvider asks for an exec call on each turn and records the tools it
+// was offered and the tool result it got back. Background summarization
+// calls it too, so the records are locked.
+type execProvider struct {
+	mu      sync.Mutex
+	offered [][]string
+	results []string
+}
+
+// calls returns what was recorded so far and clears it.
+func (p *execProvider) calls() (offered [][]string, results []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	offered, results = p.offered, p.results
+	p.offered, p.results = nil, nil
+	return offered, results
+}
+
+func (p *execProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	last := messages[len(messages)-1]
+	if last.Role == "tool" {
+		p.results = append(p.results, last.Content)
+		return &providers.LLMResponse{Content: "done"}, nil
+	}
+	var names []string
+	for _, td := range tools {
+		names = append(names, td.Function.Name)
+	}
+	p.offered = append(p.offered, names)
+	return &providers.LLMResponse{
+		ToolCalls: []providers.ToolCall{{
+			ID:        "call_1",
+			Name:      "exec",
+			Arguments: map[string]any{"command": "echo hi"},
+		}},
+	}, nil
+}
+
+func (p *execProvider) GetDefaultModel() string { return "exec-model" }
+
+func TestAgentLoop_AccessControl(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:           t.TempDir(),
+				Model:               "test-model",
+				MaxTokens:           4096,
+				MaxToolIterations:   10,
+				RestrictToWorkspace: true,
+			},
+		},
+		Access: &config.AccessConfig{
+			DefaultRole: "guest",
+			Members:     map[string]string{"telegram:42": "admin"},
+			Roles: map[string]config.RolePolicy{
+				"admin": {Admin: true},
+				"guest": {
+					Tools:           []string{"read_file"},
+					Commands:        []string{"show", "undo"},
+					MessagesPerHour: 2,
+				},
+			},
+		},
+	}
+	provider := &execProvider{}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	ctx := context.Background()
+
+	send := func(sender, content string) string {
+		t.Helper()
+		resp, err := al.processMessage(ctx, bus.InboundMessage{
+			Channel: "telegram", SenderID: sender, ChatID: sender, Content: content,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	if got := send("7", "hello"); got != "done" {
+		t.Fatalf("guest reply = %q", got)
+	}
+	offered, results := provider.calls()
+	for _, name := range offered[0] {
+		if name != "read_file" {
+			t.Errorf("guest was offered %s", name)
+		}
+	}
+	if len(results) != 1 || !strings.Contains(results[0], "permission denied") {
+		t.Errorf("guest exec result = %v, want permission denied", results)
+	}
+
+	if got := send("7", "/switch model to fast"); !strings.Contains(got, "don't have permission to use /switch") {
+		t.Errorf("guest /switch = %q", got)
+	}
+	if got := send("7", "/global model to fast"); !strings.Contains(got, "don't have permission") {
+		t.Errorf("guest /global = %q", got)
+	}
+	if got := send("7", "/show"); !strings.Contains(got, "Agent: main") {
+		t.Errorf("guest /show = %q", got)
+	}
+
+	send("7", "again")
+	if got := send("7", "and again"); !strings.Contains(got, "quota") {
+		t.Errorf("third guest message = %q, want the hourly quota message", got)
+	}
+
+	if got := send("42", "/global"); !strings.Contains(got, "Global settings") {
+		t.Errorf("admin /global = %q", got)
+	}
+
+	// Direct calls are held to the sender's role unless marked trusted.
+	direct := func(ctx context.Context) string {
+		t.Helper()
+		provider.calls()
+		if _, err := al.ProcessDirectWithChannel(ctx, "hello", "cron-job", "telegram", "7"); err != nil {
+			t.Fatal(err)
+		}
+		offered, _ := provider.calls()
+		if len(offered) == 0 {
+			return ""
+		}
+		return strings.Join(offered[0], ",")
+	}
+	if offered := direct(access.Trusted(ctx)); !strings.Contains(offered, "exec") {
+		t.Errorf("trusted direct call was offered %s, want every tool", offered)
+	}
+	if offered := direct(access.WithSender(ctx, "telegram", "42")); !strings.Contains(offered, "exec") {
+		t.Errorf("admin's direct call was offered %s, want every tool", offered)
+	}
+	if offered := direct(access.WithSender(ctx, "telegram", "8")); offered != "read_file" {
+		t.Errorf("guest's direct call was offered %q, want read_file", offered)
+	}
+	if offered := direct(ctx); offered != "read_file" {
+		t.Errorf("unattributed direct call was offered %q, want read_file", offered)
+	}
+}
+
+func TestAgentLoop_RetryOverQuotaKeepsHistory(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+		Access: &config.AccessConfig{
+			DefaultRole: "user",
+			Roles:       map[string]config.RolePolicy{"user": {MessagesPerHour: 1}},
+		},
+	}
+	provider := &countingProvider{}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	msg := bus.InboundMessage{Channel: "telegram", SenderID: "7", ChatID: "7", Content: "hello"}
+
+	if _, err := al.processMessage(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+	sessions := al.registry.GetDefaultAgent().Sessions
+	const sessionKey = "agent:main:main" // direct messages share the main session by default
+	before := sessions.GetHistory(sessionKey)
+	if len(before) != 2 {
+		t.Fatalf("history after the first turn = %d messages, want 2", len(before))
+	}
+
+	msg.Content = "/retry"
+	resp, err := al.processMessage(context.Background(), msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(resp, "quota") {
+		t.Errorf("/retry over quota = %q, want the quota message", resp)
+	}
+	if after := sessions.GetHistory(sessionKey); len(after) != len(before) {
+		t.Errorf("refused /retry changed the history from %d to %d messages", len(before), len(after))
+	}
+	if provider.calls != 1 {
+		t.Errorf("provider called %d times, want 1", provider.calls)
+	}
+}
@@ -11,9 +11,11 @@ import (
 	"sync"
 	"time"
 
+	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/skills"
+	"github.com/sipeed/picoclaw/pkg/utils"
 )
 
 type ContextBuilder struct {
@@ -33,6 +35,16 @@ type ContextBuilder struct {
 	// created (didn't exist at cache time, now exist) or deleted (existed at
 	// cache time, now gone) — both of which should trigger a cache rebuild.
 	existedAtCache map[string]bool
+
+	// promptGeneration counts rebuilds and invalidations of the cached prompt,
+	// so users of the same sources (e.g. skill script tools) can follow it.
+	promptGeneration uint64
+
+	// Relevance-based skill activation (nil = list every skill in the prompt).
+	// The skill index is rebuilt together with the cached system prompt.
+	activation   *skillActivation
+	skillIndexMu sync.Mutex
+	skillIndex   *skills.SkillIndex
 }
 
 func getGlobalConfigDir() string {
@@ -94,9 +106,18 @@ func (cb *ContextBuilder) BuildSystemPrompt() string {
 		parts = append(parts, bootstrapContent)
 	}
 
-	// Skills - show summary, AI can read full content with read_file tool
-	skillsSummary := cb.skillsLoader.BuildSkillsSummary()
-	if skillsSummary != "" {
+	// Skills - show summary, AI can read full content with read_file tool.
+	// With relevance-based activation, matching skills are loaded per turn
+	// (see activeSkillsBlock) and only a compact list goes here.
+	if cb.activation != nil {
+		if catalog := cb.skillsLoader.BuildSkillsCatalog(); catalog != "" {
+			parts = append(parts, fmt.Sprintf(`# Skills
+
+These skills are installed. Skills relevant to the current request are loaded in full under "Active Skills". To use another one, read its SKILL.md file using the read_file tool.
+
+%s`, catalog))
+		}
+	} else if skillsSummary := cb.skillsLoader.BuildSkillsSummary(); skillsSummary != "" {
 		parts = append(parts, fmt.Sprintf(`# Skills
 
 The following skills extend your capabilities. To use a skill, read its SKILL.md file using the read_file tool.
@@ -147,6 +168,8 @@ func (cb *ContextBuilder) BuildSystemPromptWithCache() string {
 	cb.cachedSystemPrompt = prompt
 	cb.cachedAt = baseline.maxMtime
 	cb.existedAtCache = baseline.existed
+	cb.promptGeneration++
+	cb.resetSkillIndex()
 
 	logger.DebugCF("agent", "System prompt cached",
 		map[string]any{
@@ -166,12 +189,23 @@ func (cb *ContextBuilder) InvalidateCache() {
 	cb.cachedSystemPrompt = ""
 	cb.cachedAt = time.Time{}
 	cb.existedAtCache = nil
+	cb.promptGeneration++
+	cb.resetSkillIndex()
 
 	logger.DebugCF("agent", "System prompt cache invalidated", nil)
 }
 
+// PromptGeneration changes whenever the cached system prompt is rebuilt or
+// invalidated, that is when workspace sources such as skills may have changed.
+func (cb *ContextBuilder) PromptGeneration() uint64 {
+	cb.systemPromptMutex.RLock()
+	defer cb.systemPromptMutex.RUnlock()
+	return cb.promptGeneration
+}
+
 // sourcePaths returns the workspace source file paths tracked for cache
-// invalidation (bootstrap files + memory). The skills directory is handled
+// invalidation (bootstrap files, memory and the skills lockfile, which
+// decides how skills are verified). The skills directory is handled
 // separately in sourceFilesChangedLocked because it requires both directory-
 // level and recursive file-level mtime checks.
 func (cb *ContextBuilder) sourcePaths() []string {
@@ -181,6 +215,7 @@ func (cb *ContextBuilder) sourcePaths() []string {
 		filepath.Join(cb.workspace, "USER.md"),
 		filepath.Join(cb.workspace, "IDENTITY.md"),
 		filepath.Join(cb.workspace, "memory", "MEMORY.md"),
+		skills.LockFilePath(cb.workspace),
 	}
 }
 
@@ -380,7 +415,7 @@ func (cb *ContextBuilder) BuildMessages(
 	summary string,
 	currentMessage string,
 	media []string,
-	channel, chatID string,
+	channel, chatID, sessionKey string,
 ) []providers.Message {
 	messages := []providers.Message{}
 
@@ -411,9 +446,19 @@ func (cb *ContextBuilder) BuildMessages(
 
 	contentBlocks := []providers.ContentBlock{
 		{Type: "text", Text: staticPrompt, CacheControl: &providers.CacheControl{Type: "ephemeral"}},
-		{Type: "text", Text: dynamicCtx},
 	}
 
+	// Active skills sit between the static prompt and the dynamic context.
+	// Selections are sticky, so this block is usually identical to the last
+	// turn's and gets its own cache breakpoint.
+	if skillsBlock := cb.activeSkillsBlock(sessionKey, currentMessage); skillsBlock != "" {
+		stringParts = []string{staticPrompt, skillsBlock, dynamicCtx}
+		contentBlocks = append(contentBlocks, providers.ContentBlock{
+			Type: "text", Text: skillsBlock, CacheControl: &providers.CacheControl{Type: "ephemeral"},
+		})
+	}
+	contentBlocks = append(contentBlocks, providers.ContentBlock{Type: "text", Text: dynamicCtx})
+
 	if summary != "" {
 		summaryText := fmt.Sprintf(
 			"CONTEXT_SUMMARY: The following is an approximate summary of prior conversation "+
@@ -451,7 +496,7 @@ func (cb *ContextBuilder) BuildMessages(
 			"preview": preview,
 		})
 
-	history = sanitizeHistoryForProvider(history)
+	history = dropStaleReasoning(sanitizeHistoryForProvider(history))
 
 	// Single system message containing all context — compatible with all providers.
 	// SystemParts enables cache-aware adapters to set per-block cache_control;
@@ -465,11 +510,12 @@ func (cb *ContextBuilder) BuildMessages(
 	// Add conversation history
 	messages = append(messages, history...)
 
-	// Add current user message
-	if strings.TrimSpace(currentMessage) != "" {
+	// Add current user message (media paths are attached for vision-capable adapters)
+	if strings.TrimSpace(currentMessage) != "" || len(media) > 0 {
 		messages = append(messages, providers.Message{
 			Role:    "user",
 			Content: currentMessage,
+			Media:   media,
 		})
 	}
 
@@ -541,6 +587,27 @@ func sanitizeHistoryForProvider(history []providers.Message) []providers.Message
 	return sanitized
 }
 
+// dropStaleReasoning clears thinking from assistant messages of completed
+// turns. Providers only need reasoning replayed within the tool-call turn that
+// produced it; DeepSeek rejects it otherwise and it wastes context elsewhere.
+// An in-progress turn (after the last user message) keeps its reasoning.
+func dropStaleReasoning(history []providers.Message) []providers.Message {
+	lastUser := -1
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == "user" && history[i].ToolCallID == "" {
+			lastUser = i
+			break
+		}
+	}
+	for i := 0; i < lastUser; i++ {
+		if history[i].Role == "assistant" {
+			history[i].ReasoningContent = ""
+			history[i].ThinkingBlocks = nil
+		}
+	}
+	return history
+}
+
 func (cb *ContextBuilder) AddToolResult(
 	messages []providers.Message,
 	toolCallID, toolName, result string,
@@ -567,6 +634,81 @@ func (cb *ContextBuilder) AddAssistantMessage(
 	return messages
 }
 
+// SetSkillActivation configures how skills are put in context; nil or mode
+// "summary" lists every skill in the system prompt.
+func (cb *ContextBuilder) SetSkillActivation(cfg *config.SkillActivationConfig) {
+	cb.activation = newSkillActivation(cfg)
+	cb.InvalidateCache()
+}
+
+// ResetActiveSkills forgets the skills selected for a session, e.g. when
+// its history is cleared.
+func (cb *ContextBuilder) ResetActiveSkills(sessionKey string) {
+	if cb.activation != nil {
+		cb.activation.reset(sessionKey)
+	}
+}
+
+// activeSkillsBlock selects the skills relevant to message for the session
+// and returns their content, within the configured character budget.
+func (cb *ContextBuilder) activeSkillsBlock(sessionKey, message string) string {
+	if cb.activation == nil {
+		return ""
+	}
+
+	var ranked []skills.SkillMatch
+	if strings.TrimSpace(message) != "" {
+		ranked = cb.getSkillIndex().Rank(message)
+	}
+	names := cb.activation.selectSkills(sessionKey, strings.TrimSpace(message), ranked)
+	if len(names) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, name := range names {
+		content, ok := cb.skillsLoader.LoadSkill(name)
+		if !ok {
+			continue
+		}
+		part := fmt.Sprintf("### Skill: %s\n\n%s\n\n", name, strings.TrimSpace(content))
+		if sb.Len()+len(part) > cb.activation.maxChars {
+			if sb.Len() > 0 {
+				continue
+			}
+			part = utils.Truncate(part, cb.activation.maxChars) + "\n\n"
+		}
+		sb.WriteString(part)
+	}
+	if sb.Len() == 0 {
+		return ""
+	}
+
+	logger.DebugCF("agent", "Active skills selected", map[string]any{"skills": names, "chars": sb.Len()})
+	return "# Active Skills\n\nThese skills match the current conversation. Follow their instructions when relevant.\n\n" +
+		strings.TrimSpace(sb.String())
+}
+
+func (cb *ContextBuilder) getSkillIndex() *skills.SkillIndex {
+	cb.skillIndexMu.Lock()
+	defer cb.skillIndexMu.Unlock()
+	if cb.skillIndex == nil {
+		cb.skillIndex = cb.skillsLoader.BuildIndex()
+	}
+	return cb.skillIndex
+}
+
+func (cb *ContextBuilder) resetSkillIndex() {
+	cb.skillIndexMu.Lock()
+	cb.skillIndex = nil
+	cb.skillIndexMu.Unlock()
+}
+
+// SkillsLoader returns the loader used for the agent's skills.
+func (cb *ContextBuilder) SkillsLoader() *skills.SkillsLoader {
+	return cb.skillsLoader
+}
+
 // GetSkillsInfo returns information about loaded skills.
 func (cb *ContextBuilder) GetSkillsInfo() map[string]any {
 	allSkills := cb.skillsLoader.ListSkills()
@@ -207,3 +207,30 @@ func assertRoles(t *testing.T, msgs []providers.Message, expected ...string) {
 		}
 	}
 }
+
+func TestDropStaleReasoning(t *testing.T) {
+	oldTurn := assistantWithTools("A")
+	oldTurn.ReasoningContent = "old thinking"
+	oldTurn.ThinkingBlocks = []providers.ThinkingBlock{{Thinking: "old thinking", Signature: "old-sig"}}
+	current := assistantWithTools("B")
+	current.ReasoningContent = "current thinking"
+	current.ThinkingBlocks = []providers.ThinkingBlock{{Thinking: "current thinking", Signature: "current-sig"}}
+
+	history := []providers.Message{
+		msg("user", "first"),
+		oldTurn,
+		toolResult("A"),
+		msg("assistant", "done"),
+		msg("user", "second"),
+		current,
+		toolResult("B"),
+	}
+
+	result := dropStaleReasoning(history)
+	if result[1].ReasoningContent != "" || result[1].ThinkingBlocks != nil {
+		t.Errorf("expected reasoning cleared from completed turn, got %+v", result[1])
+	}
+	if result[5].ReasoningContent != "current thinking" || len(result[5].ThinkingBlocks) != 1 {
+		t.Errorf("expected in-progress turn to keep reasoning, got %+v", result[5])
+	}
+}
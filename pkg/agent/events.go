@@ -0,0 +1,64 @@
+package agent
+
+import (
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// EventKind identifies a step of an agent turn.
+type EventKind string
+
+const (
+	EventIteration   EventKind = "iteration"    // an LLM call is about to be made
+	EventLLMResponse EventKind = "llm_response" // the LLM answered
+	EventFallback    EventKind = "fallback"     // earlier candidates failed before one answered
+	EventToolCall    EventKind = "tool_call"    // a tool is about to run
+	EventToolResult  EventKind = "tool_result"  // a tool finished
+)
+
+// Event describes a step of an agent turn, for front ends that want to
+// show progress (tool calls, token usage, fallbacks) rather than only the
+// final reply. Only the fields relevant to Kind are set.
+type Event struct {
+	Kind       EventKind
+	AgentID    string
+	SessionKey string
+	Iteration  int
+	Model      string
+
+	// EventLLMResponse
+	Usage         *providers.UsageInfo
+	ToolCallCount int
+
+	// EventFallback
+	Provider string
+	Attempts []providers.FallbackAttempt
+
+	// EventToolCall and EventToolResult
+	ToolCallID string
+	Tool       string
+	Arguments  map[string]any
+	Result     string
+	IsError    bool
+	Duration   time.Duration
+}
+
+// EventHandler receives turn events. It is called synchronously from the
+// agent loop, so it must not block.
+type EventHandler func(Event)
+
+// SetEventHandler installs a handler for turn events; nil removes it.
+func (al *AgentLoop) SetEventHandler(handler EventHandler) {
+	if handler == nil {
+		al.onEvent.Store(nil)
+		return
+	}
+	al.onEvent.Store(&handler)
+}
+
+func (al *AgentLoop) emit(ev Event) {
+	if handler := al.onEvent.Load(); handler != nil {
+		(*handler)(ev)
+	}
+}
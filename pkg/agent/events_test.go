@@ -0,0 +1,114 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// toolThenAnswerProvider asks for one mock_custom call, then answers.
+type toolThenAnswerProvider struct {
+	calls int
+}
+
+func (p *toolThenAnswerProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	p.calls++
+	if p.calls == 1 {
+		return &providers.LLMResponse{
+			ToolCalls: []providers.ToolCall{{
+				ID:        "call_1",
+				Name:      "mock_custom",
+				Arguments: map[string]any{"x": 1},
+			}},
+			Usage: &providers.UsageInfo{PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12},
+		}, nil
+	}
+	return &providers.LLMResponse{
+		Content: "done",
+		Usage:   &providers.UsageInfo{PromptTokens: 20, CompletionTokens: 3, TotalTokens: 23},
+	}, nil
+}
+
+func (p *toolThenAnswerProvider) GetDefaultModel() string {
+	return "mock-model"
+}
+
+func TestAgentLoop_EmitsTurnEvents(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "agent-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         tmpDir,
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &toolThenAnswerProvider{})
+	al.RegisterTool(&mockCustomTool{})
+
+	var events []Event
+	al.SetEventHandler(func(ev Event) { events = append(events, ev) })
+
+	response, err := al.ProcessDirect(context.Background(), "go", "agent:main:events")
+	if err != nil {
+		t.Fatalf("ProcessDirect: %v", err)
+	}
+	if response != "done" {
+		t.Fatalf("response = %q, want done", response)
+	}
+
+	want := []EventKind{
+		EventIteration, EventLLMResponse, EventToolCall, EventToolResult,
+		EventIteration, EventLLMResponse,
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events (%+v), want %d", len(events), events, len(want))
+	}
+	for i, kind := range want {
+		if events[i].Kind != kind {
+			t.Errorf("event %d kind = %s, want %s", i, events[i].Kind, kind)
+		}
+		if events[i].SessionKey != "agent:main:events" {
+			t.Errorf("event %d session = %q", i, events[i].SessionKey)
+		}
+	}
+
+	if call := events[2]; call.Tool != "mock_custom" || call.ToolCallID != "call_1" || call.Arguments["x"] != 1 {
+		t.Errorf("tool call event = %+v", call)
+	}
+	if result := events[3]; result.Result != "Custom tool executed" || result.IsError {
+		t.Errorf("tool result event = %+v", result)
+	}
+	if usage := events[5].Usage; usage == nil || usage.TotalTokens != 23 {
+		t.Errorf("final usage = %+v, want 23 total tokens", usage)
+	}
+	if events[4].Iteration != 2 {
+		t.Errorf("second iteration = %d, want 2", events[4].Iteration)
+	}
+
+	al.SetEventHandler(nil)
+	if _, err := al.ProcessDirect(context.Background(), "again", "agent:main:events"); err != nil {
+		t.Fatalf("ProcessDirect: %v", err)
+	}
+	if len(events) != len(want) {
+		t.Errorf("events delivered after the handler was removed")
+	}
+}
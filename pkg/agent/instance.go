@@ -1,14 +1,19 @@
 package agent
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/routing"
 	"github.com/sipeed/picoclaw/pkg/session"
+	"github.com/sipeed/picoclaw/pkg/skills"
 	"github.com/sipeed/picoclaw/pkg/tools"
 )
 
@@ -24,7 +29,6 @@ type AgentInstance struct {
 	MaxTokens         int
 	MaxTokensFallback int
 	Temperature       float64
-	ContextWindow     int
 	Provider          providers.LLMProvider
 	Sessions          *session.SessionManager
 	ContextBuilder    *ContextBuilder
@@ -32,6 +36,36 @@ type AgentInstance struct {
 	Subagents         *config.SubagentsConfig
 	SkillsFilter      []string
 	Candidates        []providers.FallbackCandidate
+	Router            *ModelRouter
+	Reasoning         *config.ReasoningConfig
+
+	window     contextWindow
+	skillTools skillToolSet
+}
+
+// skillToolSet tracks the script tools registered from the agent's skills.
+type skillToolSet struct {
+	mu         sync.Mutex
+	names      []string
+	generation uint64 // ContextBuilder.PromptGeneration they were registered at
+	loaded     bool
+}
+
+// Backoff between failed context window probes.
+const (
+	contextWindowRetryMin = 30 * time.Second
+	contextWindowRetryMax = 30 * time.Minute
+)
+
+// contextWindow is the model's context length: MaxTokens until a provider
+// reports the real value.
+type contextWindow struct {
+	mu      sync.Mutex
+	size    int
+	probed  bool // the provider reported the size
+	probing bool
+	retryAt time.Time
+	backoff time.Duration
 }
 
 // NewAgentInstance creates an agent instance from config.
@@ -60,17 +94,23 @@ func NewAgentInstance(
 	sessionsManager := session.NewSessionManager(sessionsDir)
 
 	contextBuilder := NewContextBuilder(workspace)
+	contextBuilder.SkillsLoader().SetTrustStore(newSkillTrustStore(cfg))
+	contextBuilder.SetSkillActivation(defaults.SkillActivation)
 
 	agentID := routing.DefaultAgentID
 	agentName := ""
 	var subagents *config.SubagentsConfig
 	var skillsFilter []string
+	reasoning := defaults.Reasoning
 
 	if agentCfg != nil {
 		agentID = routing.NormalizeAgentID(agentCfg.ID)
 		agentName = agentCfg.Name
 		subagents = agentCfg.Subagents
 		skillsFilter = agentCfg.Skills
+		if agentCfg.Reasoning != nil {
+			reasoning = agentCfg.Reasoning
+		}
 	}
 
 	maxIter := defaults.MaxToolIterations
@@ -109,7 +149,7 @@ func NewAgentInstance(
 		MaxTokens:         maxTokens,
 		MaxTokensFallback: maxTokensFallback,
 		Temperature:       temperature,
-		ContextWindow:     maxTokens,
+		window:            contextWindow{size: maxTokens},
 		Provider:          provider,
 		Sessions:          sessionsManager,
 		ContextBuilder:    contextBuilder,
@@ -117,9 +157,71 @@ func NewAgentInstance(
 		Subagents:         subagents,
 		SkillsFilter:      skillsFilter,
 		Candidates:        candidates,
+		Router:            NewModelRouter(defaults.Routing, cfg),
+		Reasoning:         reasoning,
+	}
+}
+
+// applyReasoningOptions adds the agent's thinking controls to LLM call options;
+// each provider translates them into its native request fields.
+func (a *AgentInstance) applyReasoningOptions(options map[string]any) {
+	if a.Reasoning == nil {
+		return
+	}
+	if a.Reasoning.Effort != "" {
+		options["reasoning_effort"] = a.Reasoning.Effort
+	}
+	if a.Reasoning.BudgetTokens > 0 {
+		options["thinking_budget"] = a.Reasoning.BudgetTokens
 	}
 }
 
+// ContextWindow returns the model's context length in tokens, used for
+// summarization thresholds.
+func (a *AgentInstance) ContextWindow() int {
+	a.window.mu.Lock()
+	defer a.window.mu.Unlock()
+	return a.window.size
+}
+
+// probeContextWindow asks providers that can report a model's context length
+// (e.g. Ollama) for the real value, and uses it for summarization thresholds.
+// A failed probe, e.g. while Ollama is still starting, is retried on a later
+// turn with backoff.
+func (a *AgentInstance) probeContextWindow(ctx context.Context) {
+	cwp, ok := a.Provider.(providers.ContextWindowProvider)
+	if !ok {
+		return
+	}
+	w := &a.window
+	w.mu.Lock()
+	if w.probed || w.probing || time.Now().Before(w.retryAt) {
+		w.mu.Unlock()
+		return
+	}
+	w.probing = true
+	w.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	n, err := cwp.ContextWindow(ctx, a.Model)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.probing = false
+	if err != nil {
+		w.backoff = min(max(2*w.backoff, contextWindowRetryMin), contextWindowRetryMax)
+		w.retryAt = time.Now().Add(w.backoff)
+		logger.DebugCF("agent", "Context window probe failed",
+			map[string]any{"agent_id": a.ID, "model": a.Model, "error": err.Error(), "retry_in": w.backoff.String()})
+		return
+	}
+	w.size = n
+	w.probed = true
+	logger.InfoCF("agent", "Context window reported by provider",
+		map[string]any{"agent_id": a.ID, "model": a.Model, "context_window": n})
+}
+
 // resolveAgentWorkspace determines the workspace directory for an agent.
 func resolveAgentWorkspace(agentCfg *config.AgentConfig, defaults *config.AgentDefaults) string {
 	if agentCfg != nil && strings.TrimSpace(agentCfg.Workspace) != "" {
@@ -162,3 +264,19 @@ func expandHome(path string) string {
 	}
 	return path
 }
+
+// newSkillTrustStore builds the skill trust store from config. An invalid
+// trust config fails closed: skills from registries are refused until it
+// is fixed.
+func newSkillTrustStore(cfg *config.Config) *skills.TrustStore {
+	trust, err := skills.NewTrustStore(skills.TrustConfig(cfg.Tools.Skills.Trust))
+	if err == nil {
+		return trust
+	}
+	logger.ErrorCF("agent", "Invalid skill trust config; refusing registry skills until it is fixed",
+		map[string]any{"error": err.Error()})
+	trust, _ = skills.NewTrustStore(skills.TrustConfig{
+		Policies: map[string]string{skills.DefaultPolicyKey: skills.PolicyRequireSigned},
+	})
+	return trust
+}
@@ -1,8 +1,11 @@
 package agent
 
 import (
+	"context"
+	"errors"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/config"
 )
@@ -97,3 +100,52 @@ func TestNewAgentInstance_DefaultsTemperatureWhenUnset(t *testing.T) {
 		t.Fatalf("Temperature = %f, want %f", agent.Temperature, 0.7)
 	}
 }
+
+// windowProvider reports a context window after failing a number of times.
+type windowProvider struct {
+	mockProvider
+	failures int
+	calls    int
+}
+
+func (p *windowProvider) ContextWindow(ctx context.Context, model string) (int, error) {
+	p.calls++
+	if p.calls <= p.failures {
+		return 0, errors.New("connection refused")
+	}
+	return 32768, nil
+}
+
+func TestAgentInstance_ProbeContextWindowRetries(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 5,
+			},
+		},
+	}
+	provider := &windowProvider{failures: 1}
+	agent := NewAgentInstance(nil, &cfg.Agents.Defaults, cfg, provider)
+
+	agent.probeContextWindow(context.Background())
+	if got := agent.ContextWindow(); got != 4096 {
+		t.Fatalf("ContextWindow after a failed probe = %d, want MaxTokens", got)
+	}
+	agent.probeContextWindow(context.Background())
+	if provider.calls != 1 {
+		t.Fatalf("probe retried %d times within the backoff", provider.calls-1)
+	}
+
+	agent.window.retryAt = time.Time{}
+	agent.probeContextWindow(context.Background())
+	if got := agent.ContextWindow(); got != 32768 {
+		t.Fatalf("ContextWindow after a retry = %d, want 32768", got)
+	}
+	agent.probeContextWindow(context.Background())
+	if provider.calls != 2 {
+		t.Errorf("provider asked %d times, want no probes after success", provider.calls)
+	}
+}
@@ -10,12 +10,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 	"unicode/utf8"
 
+	"github.com/sipeed/picoclaw/pkg/access"
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
 	"github.com/sipeed/picoclaw/pkg/config"
@@ -23,6 +25,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/routing"
+	"github.com/sipeed/picoclaw/pkg/session"
 	"github.com/sipeed/picoclaw/pkg/skills"
 	"github.com/sipeed/picoclaw/pkg/state"
 	"github.com/sipeed/picoclaw/pkg/tools"
@@ -39,18 +42,28 @@ type AgentLoop struct {
 	conversations  sync.Map
 	fallback       *providers.FallbackChain
 	channelManager *channels.Manager
+	onEvent        atomic.Pointer[EventHandler]
+	models         *modelResolver     // providers for model overrides
+	access         *access.Controller // roles; nil when access control is off
+
+	globalMu sync.RWMutex
+	global   session.Overrides // set by admins with /global
 }
 
 // processOptions configures how a message is processed
 type processOptions struct {
-	SessionKey      string // Session identifier for history/context
-	Channel         string // Target channel for tool execution
-	ChatID          string // Target chat ID for tool execution
-	UserMessage     string // User message content (may include prefix)
-	DefaultResponse string // Response when LLM returns empty
-	EnableSummary   bool   // Whether to trigger summarization
-	SendResponse    bool   // Whether to send response via bus
-	NoHistory       bool   // If true, don't load session history (for heartbeat)
+	SessionKey      string            // Session identifier for history/context
+	Channel         string            // Target channel for tool execution
+	ChatID          string            // Target chat ID for tool execution
+	UserMessage     string            // User message content (may include prefix)
+	Media           []string          // Media attached to the user message (used for routing)
+	DefaultResponse string            // Response when LLM returns empty
+	EnableSummary   bool              // Whether to trigger summarization
+	SendResponse    bool              // Whether to send response via bus
+	NoHistory       bool              // If true, don't load session history (for heartbeat)
+	Overrides       session.Overrides // Chat settings (model, temperature, persona)
+	Persona         string            // Persona text loaded from Overrides.Persona
+	Principal       *access.Principal // Sender's role, applied to routed models (nil: unrestricted)
 }
 
 func NewAgentLoop(cfg *config.Config, msgBus *bus.MessageBus, provider providers.LLMProvider) *AgentLoop {
@@ -78,16 +91,35 @@ func NewAgentLoop(cfg *config.Config, msgBus *bus.MessageBus, provider providers
 		summarizing:   sync.Map{},
 		conversations: sync.Map{},
 		fallback:      fallbackChain,
+		models:        newModelResolver(cfg),
+		access:        access.NewController(cfg),
 	}
 }
 
-// registerSharedTools registers tools that are shared across all agents (web, message, spawn).
+// registerSharedTools registers tools that are shared across all agents (web, message, spawn, ...).
 func registerSharedTools(
 	cfg *config.Config,
 	msgBus *bus.MessageBus,
 	registry *AgentRegistry,
 	provider providers.LLMProvider,
 ) {
+	// GPIO, PWM, ADC and serial tools only exist for configured allowlists.
+	// They are shared instances, since the GPIO and serial tools hold the
+	// lines and ports they have open.
+	var hardwareTools []tools.Tool
+	if hw := cfg.Tools.Hardware; len(hw.GPIO.Lines) > 0 {
+		hardwareTools = append(hardwareTools, tools.NewGPIOTool(hw.GPIO))
+	}
+	if hw := cfg.Tools.Hardware; len(hw.PWM.Channels) > 0 {
+		hardwareTools = append(hardwareTools, tools.NewPWMTool(hw.PWM))
+	}
+	if hw := cfg.Tools.Hardware; len(hw.ADC.Channels) > 0 {
+		hardwareTools = append(hardwareTools, tools.NewADCTool(hw.ADC))
+	}
+	if hw := cfg.Tools.Hardware; len(hw.Serial.Ports) > 0 {
+		hardwareTools = append(hardwareTools, tools.NewSerialTool(hw.Serial))
+	}
+
 	for _, agentID := range registry.ListAgentIDs() {
 		agent, ok := registry.GetAgent(agentID)
 		if !ok {
@@ -114,9 +146,12 @@ func registerSharedTools(
 		}
 		agent.Tools.Register(tools.NewWebFetchToolWithProxy(50000, cfg.Tools.Web.Proxy))
 
-		// Hardware tools (I2C, SPI) - Linux only, returns error on other platforms
+		// Hardware tools (I2C, SPI, GPIO, PWM, ADC, serial) - Linux only, returns error on other platforms
 		agent.Tools.Register(tools.NewI2CTool())
 		agent.Tools.Register(tools.NewSPITool())
+		for _, tool := range hardwareTools {
+			agent.Tools.Register(tool)
+		}
 
 		// Message tool
 		messageTool := tools.NewMessageTool()
@@ -131,10 +166,18 @@ func registerSharedTools(
 		agent.Tools.Register(messageTool)
 
 		// Skill discovery and installation tools
-		registryMgr := skills.NewRegistryManagerFromConfig(skills.RegistryConfig{
+		registryCfg := skills.RegistryConfig{
 			MaxConcurrentSearches: cfg.Tools.Skills.MaxConcurrentSearches,
 			ClawHub:               skills.ClawHubConfig(cfg.Tools.Skills.Registries.ClawHub),
-		})
+			Trust:                 agent.ContextBuilder.SkillsLoader().TrustStore(),
+		}
+		for _, g := range cfg.Tools.Skills.Registries.Git {
+			registryCfg.Git = append(registryCfg.Git, skills.GitRegistryConfig(g))
+		}
+		for _, i := range cfg.Tools.Skills.Registries.Index {
+			registryCfg.Index = append(registryCfg.Index, skills.IndexRegistryConfig(i))
+		}
+		registryMgr := skills.NewRegistryManagerFromConfig(registryCfg)
 		searchCache := skills.NewSearchCache(
 			cfg.Tools.Skills.SearchCache.MaxSize,
 			time.Duration(cfg.Tools.Skills.SearchCache.TTLSeconds)*time.Second,
@@ -142,6 +185,14 @@ func registerSharedTools(
 		agent.Tools.Register(tools.NewFindSkillsTool(registryMgr, searchCache))
 		agent.Tools.Register(tools.NewInstallSkillTool(registryMgr, agent.Workspace))
 
+		// Schema-validated JSON extraction for skills
+		agent.Tools.Register(tools.NewStructuredExtractTool(provider, agent.Model))
+
+		// Tools declared by skills, backed by their scripts
+		if cfg.Tools.Skills.ScriptTools.Enabled {
+			registerSkillScriptTools(cfg, agent)
+		}
+
 		// Spawn tool with allowlist checker
 		subagentManager := tools.NewSubagentManager(provider, agent.Model, agent.Workspace, msgBus)
 		subagentManager.SetLLMOptions(agent.MaxTokens, agent.MaxTokensFallback, agent.Temperature)
@@ -154,6 +205,46 @@ func registerSharedTools(
 	}
 }
 
+// registerSkillScriptTools registers the tools declared in the agent's skills.
+// When the system prompt cache has been rebuilt since, because skills were
+// installed, updated or removed, the tools are registered again. Skill tools
+// never replace built-in tools or tools of another skill.
+func registerSkillScriptTools(cfg *config.Config, agent *AgentInstance) {
+	set := &agent.skillTools
+	generation := agent.ContextBuilder.PromptGeneration()
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	if set.loaded && set.generation == generation {
+		return
+	}
+	for _, name := range set.names {
+		agent.Tools.Unregister(name)
+	}
+	set.names = nil
+	set.generation, set.loaded = generation, true
+
+	opts := tools.SkillScriptOptions{
+		Workspace:      agent.Workspace,
+		DefaultTimeout: time.Duration(cfg.Tools.Skills.ScriptTools.TimeoutSeconds) * time.Second,
+		AllowedEnv:     cfg.Tools.Skills.ScriptTools.AllowedEnv,
+	}
+	for _, spec := range agent.ContextBuilder.SkillsLoader().ListSkillTools() {
+		if _, exists := agent.Tools.Get(spec.Name); exists {
+			logger.WarnCF("agent", "Skipping skill tool with a conflicting name",
+				map[string]any{"agent_id": agent.ID, "skill": spec.Skill, "tool": spec.Name})
+			continue
+		}
+		tool, err := tools.NewSkillScriptTool(spec, opts)
+		if err != nil {
+			logger.WarnCF("agent", "Skipping invalid skill tool",
+				map[string]any{"agent_id": agent.ID, "error": err.Error()})
+			continue
+		}
+		agent.Tools.Register(tool)
+		set.names = append(set.names, spec.Name)
+	}
+}
+
 func (al *AgentLoop) Run(ctx context.Context) error {
 	al.running.Store(true)
 
@@ -216,6 +307,62 @@ func (al *AgentLoop) SetChannelManager(cm *channels.Manager) {
 	al.channelManager = cm
 }
 
+// DefaultAgentID returns the ID of the agent that handles unrouted messages.
+func (al *AgentLoop) DefaultAgentID() string {
+	if agent := al.registry.GetDefaultAgent(); agent != nil {
+		return agent.ID
+	}
+	return routing.DefaultAgentID
+}
+
+// RestrictTools removes every tool except the named ones from all agents,
+// e.g. to run the CLI agent with a fixed tool set. An empty list removes
+// all tools. Nothing is removed if a name is not a registered tool.
+func (al *AgentLoop) RestrictTools(names []string) error {
+	keep := make(map[string]bool, len(names))
+	for _, name := range names {
+		keep[name] = true
+	}
+
+	known := make(map[string]bool)
+	agentIDs := al.registry.ListAgentIDs()
+	for _, agentID := range agentIDs {
+		if agent, ok := al.registry.GetAgent(agentID); ok {
+			for _, name := range agent.Tools.List() {
+				known[name] = true
+			}
+		}
+	}
+	for _, name := range names {
+		if !known[name] {
+			available := make([]string, 0, len(known))
+			for k := range known {
+				available = append(available, k)
+			}
+			sort.Strings(available)
+			return fmt.Errorf("unknown tool %q (available: %s)", name, strings.Join(available, ", "))
+		}
+	}
+
+	for _, agentID := range agentIDs {
+		if agent, ok := al.registry.GetAgent(agentID); ok {
+			for _, name := range agent.Tools.List() {
+				if !keep[name] {
+					agent.Tools.Unregister(name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// StateManager returns the workspace state the loop records the last channel
+// in, or nil when there is no default agent. Other services writing the same
+// state must share it.
+func (al *AgentLoop) StateManager() *state.Manager {
+	return al.state
+}
+
 // RecordLastChannel records the last active channel for this workspace.
 // This uses the atomic state save mechanism to prevent data loss on crash.
 func (al *AgentLoop) RecordLastChannel(channel string) error {
@@ -266,6 +413,7 @@ func (al *AgentLoop) ProcessHeartbeat(ctx context.Context, content, channel, cha
 		EnableSummary:   false,
 		SendResponse:    false,
 		NoHistory:       true, // Don't load session history for heartbeat
+		Overrides:       al.globalOverrides(),
 	})
 }
 
@@ -283,11 +431,6 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 		return al.processSystemMessage(ctx, msg)
 	}
 
-	// Check for commands
-	if response, handled := al.handleCommand(ctx, msg); handled {
-		return response, nil
-	}
-
 	// Route to determine agent and session key
 	route := al.registry.ResolveRoute(routing.RouteInput{
 		Channel:    msg.Channel,
@@ -308,6 +451,35 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 	if msg.SessionKey != "" && strings.HasPrefix(msg.SessionKey, "agent:") {
 		sessionKey = msg.SessionKey
 	}
+	// Apply the chat's agent binding and continue in its active branch
+	// (see /switch, /fork and /sessions)
+	chat := al.resolveChat(agent, sessionKey)
+
+	// Apply the sender's role
+	chat.principal = al.principal(ctx, msg)
+	if !chat.principal.AllowAgent(chat.agent.ID) {
+		return fmt.Sprintf("You don't have access to the %s agent.", chat.agent.ID), nil
+	}
+	if chat.settings.Model != "" && !chat.principal.AllowModel(chat.settings.Model) {
+		chat.settings.Model = ""
+	}
+
+	// Check for commands
+	if strings.TrimSpace(msg.Content) == "/retry" {
+		if refusal, denied := checkCommand(chat, "/retry"); denied {
+			return refusal, nil
+		}
+		return al.retryTurn(ctx, chat, msg)
+	}
+	if response, handled := al.handleCommand(ctx, msg, chat); handled {
+		return response, nil
+	}
+	agent, sessionKey = chat.agent, chat.sessionKey
+
+	ctx, err := admitTurn(ctx, chat)
+	if err != nil {
+		return fmt.Sprintf("Sorry, %v.", err), nil
+	}
 
 	al.logConversationStart(agent, sessionKey, msg.Channel, msg.ChatID)
 
@@ -323,9 +495,12 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 		Channel:         msg.Channel,
 		ChatID:          msg.ChatID,
 		UserMessage:     msg.Content,
+		Media:           msg.Media,
 		DefaultResponse: "I've completed processing but have no response to give.",
 		EnableSummary:   true,
 		SendResponse:    false,
+		Overrides:       chat.settings,
+		Principal:       chat.principal,
 	})
 }
 
@@ -382,6 +557,7 @@ func (al *AgentLoop) processSystemMessage(ctx context.Context, msg bus.InboundMe
 		DefaultResponse: "Background task completed.",
 		EnableSummary:   false,
 		SendResponse:    true,
+		Overrides:       al.globalOverrides(),
 	})
 }
 
@@ -400,6 +576,23 @@ func (al *AgentLoop) runAgentLoop(ctx context.Context, agent *AgentInstance, opt
 
 	// 1. Update tool contexts
 	al.updateToolContexts(agent, opts.Channel, opts.ChatID)
+	agent.probeContextWindow(ctx)
+
+	// Pick a model for this turn: one chosen with /switch or /global wins over
+	// the routing tiers (routing hints are stripped from the message)
+	var route *RouteDecision
+	if opts.Overrides.Model != "" {
+		route = al.overrideRoute(agent, opts.Overrides.Model, opts.UserMessage, opts.Principal)
+	} else {
+		route = agent.Router.Route(ctx, agent.ID, RouteRequest{
+			Message:   opts.UserMessage,
+			Media:     opts.Media,
+			Principal: opts.Principal,
+		})
+	}
+	if route != nil {
+		opts.UserMessage = route.Message
+	}
 
 	// 2. Build messages (skip history for heartbeat)
 	var history []providers.Message
@@ -408,20 +601,34 @@ func (al *AgentLoop) runAgentLoop(ctx context.Context, agent *AgentInstance, opt
 		history = agent.Sessions.GetHistory(opts.SessionKey)
 		summary = agent.Sessions.GetSummary(opts.SessionKey)
 	}
+	if opts.Overrides.Persona != "" {
+		if persona, err := loadPersona(agent.Workspace, opts.Overrides.Persona); err != nil {
+			logger.WarnCF("agent", "Failed to load persona",
+				map[string]any{"agent_id": agent.ID, "persona": opts.Overrides.Persona, "error": err.Error()})
+		} else {
+			opts.Persona = persona
+		}
+	}
 	messages := agent.ContextBuilder.BuildMessages(
 		history,
 		summary,
 		opts.UserMessage,
-		nil,
+		opts.Media,
 		opts.Channel,
 		opts.ChatID,
+		opts.SessionKey,
 	)
+	applyPersona(messages, opts.Persona)
+	if al.cfg.Tools.Skills.ScriptTools.Enabled {
+		// Follow skills installed or removed since the last turn
+		registerSkillScriptTools(al.cfg, agent)
+	}
 
 	// 3. Save user message to session
 	agent.Sessions.AddMessage(opts.SessionKey, "user", opts.UserMessage)
 
 	// 4. Run LLM iteration loop
-	finalContent, iteration, err := al.runLLMIteration(ctx, agent, messages, opts)
+	finalContent, reasoning, iteration, err := al.runLLMIteration(ctx, agent, messages, opts, route)
 	if err != nil {
 		return "", err
 	}
@@ -443,7 +650,17 @@ func (al *AgentLoop) runAgentLoop(ctx context.Context, agent *AgentInstance, opt
 		al.maybeSummarize(agent, opts.SessionKey, opts.Channel, opts.ChatID)
 	}
 
-	// 8. Optional: send response via bus
+	// 8. Optional: show the model's thinking ahead of the reply on channels that opt in
+	if reasoning != "" && agent.Reasoning.DisplayMode(opts.Channel) == "collapsed" &&
+		!constants.IsInternalChannel(opts.Channel) {
+		al.bus.PublishOutbound(bus.OutboundMessage{
+			Channel:   opts.Channel,
+			ChatID:    opts.ChatID,
+			Reasoning: reasoning,
+		})
+	}
+
+	// 9. Optional: send response via bus
 	if opts.SendResponse {
 		al.bus.PublishOutbound(bus.OutboundMessage{
 			Channel: opts.Channel,
@@ -452,7 +669,7 @@ func (al *AgentLoop) runAgentLoop(ctx context.Context, agent *AgentInstance, opt
 		})
 	}
 
-	// 9. Log response
+	// 10. Log response
 	logger.InfoCF("agent", "Response generated",
 		map[string]any{
 			"agent_id":     agent.ID,
@@ -465,14 +682,30 @@ func (al *AgentLoop) runAgentLoop(ctx context.Context, agent *AgentInstance, opt
 }
 
 // runLLMIteration executes the LLM call loop with tool handling.
+// It returns the final content and the model's reasoning across all iterations.
 func (al *AgentLoop) runLLMIteration(
 	ctx context.Context,
 	agent *AgentInstance,
 	messages []providers.Message,
 	opts processOptions,
-) (string, int, error) {
+	route *RouteDecision,
+) (string, string, int, error) {
 	iteration := 0
 	var finalContent string
+	var reasoning []string
+
+	model := agent.Model
+	provider := agent.Provider
+	candidates := agent.Candidates
+	temperature := agent.Temperature
+	if opts.Overrides.Temperature != nil {
+		temperature = *opts.Overrides.Temperature
+	}
+	if route != nil && route.Provider != nil {
+		model = route.Model
+		provider = route.Provider
+		candidates = route.Candidates(agent.Candidates)
+	}
 
 	for iteration < agent.MaxIterations {
 		iteration++
@@ -484,8 +717,16 @@ func (al *AgentLoop) runLLMIteration(
 				"max":       agent.MaxIterations,
 			})
 
+		al.emit(Event{
+			Kind:       EventIteration,
+			AgentID:    agent.ID,
+			SessionKey: opts.SessionKey,
+			Iteration:  iteration,
+			Model:      model,
+		})
+
 		// Build tool definitions
-		providerToolDefs := agent.Tools.ToProviderDefs()
+		providerToolDefs := tools.FilterDefs(ctx, agent.Tools.ToProviderDefs())
 
 		// Log LLM request details
 		logger.DebugCF("agent", "LLM request",
@@ -496,7 +737,7 @@ func (al *AgentLoop) runLLMIteration(
 				"messages_count":    len(messages),
 				"tools_count":       len(providerToolDefs),
 				"max_tokens":        agent.MaxTokens,
-				"temperature":       agent.Temperature,
+				"temperature":       temperature,
 				"system_prompt_len": len(messages[0].Content),
 			})
 
@@ -515,19 +756,21 @@ func (al *AgentLoop) runLLMIteration(
 		callLLM := func(maxTokens int) (*providers.LLMResponse, error) {
 			baseOptions := map[string]any{
 				"max_tokens":       maxTokens,
-				"temperature":      agent.Temperature,
+				"temperature":      temperature,
 				"prompt_cache_key": agent.ID,
 			}
+			agent.applyReasoningOptions(baseOptions)
 
-			if len(agent.Candidates) > 1 && al.fallback != nil {
-				fbResult, fbErr := al.fallback.Execute(ctx, agent.Candidates,
-					func(ctx context.Context, provider, model string) (*providers.LLMResponse, error) {
+			if len(candidates) > 1 && al.fallback != nil {
+				fbResult, fbErr := al.fallback.Execute(ctx, candidates,
+					func(ctx context.Context, providerName, candidateModel string) (*providers.LLMResponse, error) {
 						return al.callProviderWithMaxTokensFallback(
 							ctx,
 							agent,
+							route.ProviderFor(providerName, candidateModel, agent.Provider),
 							messages,
 							providerToolDefs,
-							model,
+							candidateModel,
 							baseOptions,
 						)
 					},
@@ -539,15 +782,25 @@ func (al *AgentLoop) runLLMIteration(
 					logger.InfoCF("agent", fmt.Sprintf("Fallback: succeeded with %s/%s after %d attempts",
 						fbResult.Provider, fbResult.Model, len(fbResult.Attempts)+1),
 						map[string]any{"agent_id": agent.ID, "iteration": iteration})
+					al.emit(Event{
+						Kind:       EventFallback,
+						AgentID:    agent.ID,
+						SessionKey: opts.SessionKey,
+						Iteration:  iteration,
+						Provider:   fbResult.Provider,
+						Model:      fbResult.Model,
+						Attempts:   fbResult.Attempts,
+					})
 				}
 				return fbResult.Response, nil
 			}
 			return al.callProviderWithMaxTokensFallback(
 				ctx,
 				agent,
+				provider,
 				messages,
 				providerToolDefs,
-				agent.Model,
+				model,
 				baseOptions,
 			)
 		}
@@ -586,8 +839,9 @@ func (al *AgentLoop) runLLMIteration(
 				newSummary := agent.Sessions.GetSummary(opts.SessionKey)
 				messages = agent.ContextBuilder.BuildMessages(
 					newHistory, newSummary, "",
-					nil, opts.Channel, opts.ChatID,
+					nil, opts.Channel, opts.ChatID, opts.SessionKey,
 				)
+				applyPersona(messages, opts.Persona)
 				continue
 			}
 			break
@@ -600,7 +854,21 @@ func (al *AgentLoop) runLLMIteration(
 					"iteration": iteration,
 					"error":     err.Error(),
 				})
-			return "", iteration, fmt.Errorf("LLM call failed after retries: %w", err)
+			return "", "", iteration, fmt.Errorf("LLM call failed after retries: %w", err)
+		}
+
+		al.emit(Event{
+			Kind:          EventLLMResponse,
+			AgentID:       agent.ID,
+			SessionKey:    opts.SessionKey,
+			Iteration:     iteration,
+			Model:         model,
+			Usage:         response.Usage,
+			ToolCallCount: len(response.ToolCalls),
+		})
+
+		if r := strings.TrimSpace(response.ReasoningContent); r != "" {
+			reasoning = append(reasoning, r)
 		}
 
 		// Check if no tool calls - we're done
@@ -638,6 +906,7 @@ func (al *AgentLoop) runLLMIteration(
 			Role:             "assistant",
 			Content:          response.Content,
 			ReasoningContent: response.ReasoningContent,
+			ThinkingBlocks:   response.ThinkingBlocks,
 		}
 		for _, tc := range normalizedToolCalls {
 			argumentsJSON, _ := json.Marshal(tc.Arguments)
@@ -691,6 +960,17 @@ func (al *AgentLoop) runLLMIteration(
 				}
 			}
 
+			al.emit(Event{
+				Kind:       EventToolCall,
+				AgentID:    agent.ID,
+				SessionKey: opts.SessionKey,
+				Iteration:  iteration,
+				ToolCallID: tc.ID,
+				Tool:       tc.Name,
+				Arguments:  tc.Arguments,
+			})
+			toolStart := time.Now()
+
 			toolResult := agent.Tools.ExecuteWithContext(
 				ctx,
 				tc.Name,
@@ -720,6 +1000,18 @@ func (al *AgentLoop) runLLMIteration(
 				contentForLLM = toolResult.Err.Error()
 			}
 
+			al.emit(Event{
+				Kind:       EventToolResult,
+				AgentID:    agent.ID,
+				SessionKey: opts.SessionKey,
+				Iteration:  iteration,
+				ToolCallID: tc.ID,
+				Tool:       tc.Name,
+				Result:     contentForLLM,
+				IsError:    toolResult.IsError,
+				Duration:   time.Since(toolStart),
+			})
+
 			toolResultMsg := providers.Message{
 				Role:       "tool",
 				Content:    contentForLLM,
@@ -732,12 +1024,13 @@ func (al *AgentLoop) runLLMIteration(
 		}
 	}
 
-	return finalContent, iteration, nil
+	return finalContent, strings.Join(reasoning, "\n\n"), iteration, nil
 }
 
 func (al *AgentLoop) callProviderWithMaxTokensFallback(
 	ctx context.Context,
 	agent *AgentInstance,
+	provider providers.LLMProvider,
 	messages []providers.Message,
 	toolsDefs []providers.ToolDefinition,
 	model string,
@@ -745,7 +1038,7 @@ func (al *AgentLoop) callProviderWithMaxTokensFallback(
 ) (*providers.LLMResponse, error) {
 	options := cloneLLMOptions(baseOptions)
 
-	response, err := agent.Provider.Chat(ctx, messages, toolsDefs, model, options)
+	response, err := provider.Chat(ctx, messages, toolsDefs, model, options)
 	if err == nil {
 		return response, nil
 	}
@@ -768,7 +1061,7 @@ func (al *AgentLoop) callProviderWithMaxTokensFallback(
 		"original_error":      err.Error(),
 	})
 
-	return agent.Provider.Chat(ctx, messages, toolsDefs, model, options)
+	return provider.Chat(ctx, messages, toolsDefs, model, options)
 }
 
 func cloneLLMOptions(options map[string]any) map[string]any {
@@ -864,7 +1157,7 @@ func (al *AgentLoop) updateToolContexts(agent *AgentInstance, channel, chatID st
 func (al *AgentLoop) maybeSummarize(agent *AgentInstance, sessionKey, channel, chatID string) {
 	newHistory := agent.Sessions.GetHistory(sessionKey)
 	tokenEstimate := al.estimateTokens(newHistory)
-	threshold := agent.ContextWindow * 75 / 100
+	threshold := agent.ContextWindow() * 75 / 100
 
 	if len(newHistory) > 20 || tokenEstimate > threshold {
 		summarizeKey := agent.ID + ":" + sessionKey
@@ -1034,7 +1327,7 @@ func (al *AgentLoop) summarizeSession(agent *AgentInstance, sessionKey string) {
 	toSummarize := history[:len(history)-4]
 
 	// Oversized Message Guard
-	maxMessageTokens := agent.ContextWindow / 2
+	maxMessageTokens := agent.ContextWindow() / 2
 	validMessages := make([]providers.Message, 0)
 	omitted := false
 
@@ -1072,6 +1365,7 @@ func (al *AgentLoop) summarizeSession(agent *AgentInstance, sessionKey string) {
 		resp, err := al.callProviderWithMaxTokensFallback(
 			ctx,
 			agent,
+			agent.Provider,
 			[]providers.Message{{Role: "user", Content: mergePrompt}},
 			nil,
 			agent.Model,
@@ -1124,6 +1418,7 @@ func (al *AgentLoop) summarizeBatch(
 	response, err := al.callProviderWithMaxTokensFallback(
 		ctx,
 		agent,
+		agent.Provider,
 		[]providers.Message{{Role: "user", Content: prompt}},
 		nil,
 		agent.Model,
@@ -1151,7 +1446,7 @@ func (al *AgentLoop) estimateTokens(messages []providers.Message) int {
 	return totalChars * 2 / 5
 }
 
-func (al *AgentLoop) handleCommand(ctx context.Context, msg bus.InboundMessage) (string, bool) {
+func (al *AgentLoop) handleCommand(ctx context.Context, msg bus.InboundMessage, chat *chatContext) (string, bool) {
 	content := strings.TrimSpace(msg.Content)
 	if !strings.HasPrefix(content, "/") {
 		return "", false
@@ -1163,82 +1458,94 @@ func (al *AgentLoop) handleCommand(ctx context.Context, msg bus.InboundMessage)
 	}
 
 	cmd := parts[0]
-	args := parts[1:]
+	run, ok := chatCommands[cmd]
+	if !ok {
+		return "", false
+	}
+	if refusal, denied := checkCommand(chat, cmd); denied {
+		return refusal, true
+	}
+	return run(al, msg, chat, cmd, parts[1:]), true
+}
 
-	switch cmd {
-	case "/show":
-		if len(args) < 1 {
-			return "Usage: /show [model|channel|agents]", true
-		}
-		switch args[0] {
-		case "model":
-			defaultAgent := al.registry.GetDefaultAgent()
-			if defaultAgent == nil {
-				return "No default agent configured", true
-			}
-			return fmt.Sprintf("Current model: %s", defaultAgent.Model), true
-		case "channel":
-			return fmt.Sprintf("Current channel: %s", msg.Channel), true
-		case "agents":
-			agentIDs := al.registry.ListAgentIDs()
-			return fmt.Sprintf("Registered agents: %s", strings.Join(agentIDs, ", ")), true
-		default:
-			return fmt.Sprintf("Unknown show target: %s", args[0]), true
-		}
+// chatCommand runs a slash command for a chat and returns the reply.
+type chatCommand func(al *AgentLoop, msg bus.InboundMessage, chat *chatContext, cmd string, args []string) string
+
+// chatCommands are the slash commands handled by handleCommand, each subject
+// to the sender's role. /retry runs a turn and is handled in processMessage.
+var chatCommands = map[string]chatCommand{
+	"/undo":     sessionCommand,
+	"/new":      sessionCommand,
+	"/reset":    sessionCommand,
+	"/fork":     sessionCommand,
+	"/sessions": sessionCommand,
+	"/show": func(al *AgentLoop, msg bus.InboundMessage, chat *chatContext, _ string, args []string) string {
+		return al.handleShow(msg, chat, args)
+	},
+	"/list": func(al *AgentLoop, _ bus.InboundMessage, _ *chatContext, _ string, args []string) string {
+		return al.handleList(args)
+	},
+	"/switch": func(al *AgentLoop, _ bus.InboundMessage, chat *chatContext, _ string, args []string) string {
+		return al.handleSwitch(chat, args)
+	},
+	"/global": func(al *AgentLoop, msg bus.InboundMessage, chat *chatContext, _ string, args []string) string {
+		return al.handleGlobal(msg, chat, args)
+	},
+}
 
-	case "/list":
-		if len(args) < 1 {
-			return "Usage: /list [models|channels|agents]", true
-		}
-		switch args[0] {
-		case "models":
-			return "Available models: configured in config.json per agent", true
-		case "channels":
-			if al.channelManager == nil {
-				return "Channel manager not initialized", true
-			}
-			channels := al.channelManager.GetEnabledChannels()
-			if len(channels) == 0 {
-				return "No channels enabled", true
-			}
-			return fmt.Sprintf("Enabled channels: %s", strings.Join(channels, ", ")), true
-		case "agents":
-			agentIDs := al.registry.ListAgentIDs()
-			return fmt.Sprintf("Registered agents: %s", strings.Join(agentIDs, ", ")), true
-		default:
-			return fmt.Sprintf("Unknown list target: %s", args[0]), true
+func sessionCommand(al *AgentLoop, _ bus.InboundMessage, chat *chatContext, cmd string, args []string) string {
+	return al.handleSessionCommand(chat.agent, chat.sessionKey, cmd, args)
+}
+
+func (al *AgentLoop) handleShow(msg bus.InboundMessage, chat *chatContext, args []string) string {
+	if len(args) < 1 {
+		return describeSettings(chat)
+	}
+	switch args[0] {
+	case "model":
+		model := chat.agent.Model
+		if chat.settings.Model != "" {
+			model = chat.settings.Model
 		}
+		return fmt.Sprintf("Current model: %s", model)
+	case "agent":
+		return fmt.Sprintf("Current agent: %s", chat.agent.ID)
+	case "channel":
+		return fmt.Sprintf("Current channel: %s", msg.Channel)
+	case "agents":
+		agentIDs := al.registry.ListAgentIDs()
+		return fmt.Sprintf("Registered agents: %s", strings.Join(agentIDs, ", "))
+	default:
+		return fmt.Sprintf("Unknown show target: %s", args[0])
+	}
+}
 
-	case "/switch":
-		if len(args) < 3 || args[1] != "to" {
-			return "Usage: /switch [model|channel] to <name>", true
+func (al *AgentLoop) handleList(args []string) string {
+	if len(args) < 1 {
+		return "Usage: /list [models|channels|agents]"
+	}
+	switch args[0] {
+	case "models":
+		names := al.modelNames()
+		if len(names) == 0 {
+			return "No models in model_list"
 		}
-		target := args[0]
-		value := args[2]
-
-		switch target {
-		case "model":
-			defaultAgent := al.registry.GetDefaultAgent()
-			if defaultAgent == nil {
-				return "No default agent configured", true
-			}
-			oldModel := defaultAgent.Model
-			defaultAgent.Model = value
-			return fmt.Sprintf("Switched model from %s to %s", oldModel, value), true
-		case "channel":
-			if al.channelManager == nil {
-				return "Channel manager not initialized", true
-			}
-			if _, exists := al.channelManager.GetChannel(value); !exists && value != "cli" {
-				return fmt.Sprintf("Channel '%s' not found or not enabled", value), true
-			}
-			return fmt.Sprintf("Switched target channel to %s", value), true
-		default:
-			return fmt.Sprintf("Unknown switch target: %s", target), true
+		return fmt.Sprintf("Available models: %s", strings.Join(names, ", "))
+	case "channels":
+		if al.channelManager == nil {
+			return "Channel manager not initialized"
 		}
+		channels := al.channelManager.GetEnabledChannels()
+		if len(channels) == 0 {
+			return "No channels enabled"
+		}
+		return fmt.Sprintf("Enabled channels: %s", strings.Join(channels, ", "))
+	case "agents":
+		agentIDs := al.registry.ListAgentIDs()
+		return fmt.Sprintf("Registered agents: %s", strings.Join(agentIDs, ", "))
+	default:
+		return fmt.Sprintf("Unknown list target: %s", args[0])
 	}
-
-	return "", false
 }
 
 // extractPeer extracts the routing peer from inbound message metadata.
@@ -187,6 +187,45 @@ func TestToolRegistry_ToolRegistration(t *testing.T) {
 	}
 }
 
+func TestAgentLoop_RestrictTools(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         tmpDir,
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &mockProvider{})
+	al.RegisterTool(&mockCustomTool{})
+
+	if err := al.RestrictTools([]string{"mock_custom", "no_such_tool"}); err == nil {
+		t.Fatal("expected error for unknown tool")
+	}
+	agent := al.registry.GetDefaultAgent()
+	if agent.Tools.Count() < 2 {
+		t.Fatalf("expected tools to be unchanged after a failed restrict, got %v", agent.Tools.List())
+	}
+
+	if err := al.RestrictTools([]string{"mock_custom"}); err != nil {
+		t.Fatalf("RestrictTools failed: %v", err)
+	}
+	if names := agent.Tools.List(); len(names) != 1 || names[0] != "mock_custom" {
+		t.Errorf("expected only mock_custom, got %v", names)
+	}
+
+	if err := al.RestrictTools(nil); err != nil {
+		t.Fatalf("RestrictTools(nil) failed: %v", err)
+	}
+	if agent.Tools.Count() != 0 {
+		t.Errorf("expected no tools, got %v", agent.Tools.List())
+	}
+}
+
 // TestToolContext_Updates verifies tool context is updated with channel/chatID
 func TestToolContext_Updates(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "agent-test-*")
@@ -706,3 +745,133 @@ func TestAgentLoop_MaxTokensFallbackRetry(t *testing.T) {
 		t.Errorf("max_tokens calls = %v, want [20000 8192]", provider.maxTokens)
 	}
 }
+
+// reasoningMockProvider returns a fixed reasoning trace and records call options.
+type reasoningMockProvider struct {
+	options map[string]any
+}
+
+func (m *reasoningMockProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	m.options = opts
+	return &providers.LLMResponse{
+		Content:          "It will be sunny.",
+		ReasoningContent: "The forecast says sun.",
+	}, nil
+}
+
+func (m *reasoningMockProvider) GetDefaultModel() string {
+	return "mock-model"
+}
+
+func TestAgentLoop_ReasoningOptionsAndDisplay(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         tmpDir,
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+				Reasoning: &config.ReasoningConfig{
+					Effort:  "high",
+					Display: map[string]string{"telegram": "collapsed"},
+				},
+			},
+		},
+	}
+
+	msgBus := bus.NewMessageBus()
+	provider := &reasoningMockProvider{}
+	al := NewAgentLoop(cfg, msgBus, provider)
+	helper := testHelper{al: al}
+
+	response := helper.executeAndGetResponse(t, context.Background(), bus.InboundMessage{
+		Channel:    "telegram",
+		SenderID:   "user1",
+		ChatID:     "chat1",
+		Content:    "weather?",
+		SessionKey: "reasoning-session",
+	})
+	if response != "It will be sunny." {
+		t.Errorf("response = %q", response)
+	}
+	if provider.options["reasoning_effort"] != "high" {
+		t.Errorf("reasoning_effort option = %v, want high", provider.options["reasoning_effort"])
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), responseTimeout)
+	defer cancel()
+	out, ok := msgBus.SubscribeOutbound(ctx)
+	if !ok {
+		t.Fatal("expected a reasoning outbound message")
+	}
+	if out.Reasoning != "The forecast says sun." || out.Content != "" {
+		t.Errorf("unexpected outbound message: %+v", out)
+	}
+
+	// Channels without a display mode get no reasoning message.
+	helper.executeAndGetResponse(t, context.Background(), bus.InboundMessage{
+		Channel:    "slack",
+		SenderID:   "user1",
+		ChatID:     "chat1",
+		Content:    "weather?",
+		SessionKey: "reasoning-session-2",
+	})
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel2()
+	if out, ok := msgBus.SubscribeOutbound(ctx2); ok {
+		t.Errorf("unexpected outbound message for slack: %+v", out)
+	}
+}
+
+func TestAgentLoop_SkillScriptToolsFollowSkills(t *testing.T) {
+	workspace := t.TempDir()
+	writeSkill := func(name, tool string) {
+		t.Helper()
+		dir := filepath.Join(workspace, "skills", name)
+		if err := os.MkdirAll(filepath.Join(dir, "scripts"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		skill := fmt.Sprintf("---\nname: %s\ndescription: Test skill\ntools:\n"+
+			"  - name: %s\n    description: Test tool\n    entrypoint: scripts/run.sh\n---\n# %s", name, tool, name)
+		os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(skill), 0o644)
+		os.WriteFile(filepath.Join(dir, "scripts", "run.sh"), []byte("echo ok"), 0o755)
+	}
+	writeSkill("weather", "get_forecast")
+
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         workspace,
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	cfg.Tools.Skills.ScriptTools.Enabled = true
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &mockProvider{})
+	agent := al.registry.GetDefaultAgent()
+	if _, ok := agent.Tools.Get("get_forecast"); !ok {
+		t.Fatal("get_forecast should be registered at startup")
+	}
+
+	// A skill installed and one removed while running.
+	os.RemoveAll(filepath.Join(workspace, "skills", "weather"))
+	writeSkill("deploy", "deploy_service")
+	if _, err := al.ProcessDirect(context.Background(), "hello", "agent:main:test"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := agent.Tools.Get("get_forecast"); ok {
+		t.Error("get_forecast should be gone with its skill")
+	}
+	if _, ok := agent.Tools.Get("deploy_service"); !ok {
+		t.Error("deploy_service should be registered after its skill was installed")
+	}
+}
@@ -0,0 +1,337 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sipeed/picoclaw/pkg/access"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+const defaultClassifierTimeout = 10 * time.Second
+
+// ModelRouter picks a model tier for each turn based on configurable signals:
+// explicit user hints, message length, attached media, mentioned tools, keywords
+// and, as a last resort, a cheap classifier model.
+type ModelRouter struct {
+	cfg config.ModelRoutingConfig
+	*modelResolver
+}
+
+// modelResolver creates providers for model_list entries on demand.
+type modelResolver struct {
+	root    *config.Config
+	factory func(*config.ModelConfig) (providers.LLMProvider, string, error)
+
+	mu     sync.Mutex
+	models map[string]*routedModel // keyed by model_list model_name
+}
+
+func newModelResolver(cfg *config.Config) *modelResolver {
+	return &modelResolver{
+		root:    cfg,
+		factory: providers.CreateProviderFromConfig,
+		models:  make(map[string]*routedModel),
+	}
+}
+
+// routedModel is a provider instance resolved from a model_list entry.
+type routedModel struct {
+	provider     providers.LLMProvider
+	providerName string
+	modelID      string
+}
+
+// RouteRequest carries the per-turn signals used for routing.
+type RouteRequest struct {
+	Message   string
+	Media     []string
+	Principal *access.Principal // sender's role; tiers it may not use are skipped
+}
+
+// RouteDecision is the outcome of routing a single turn.
+type RouteDecision struct {
+	Tier      string
+	ModelName string // model_list alias
+	Model     string // model ID passed to the provider
+	Provider  providers.LLMProvider
+	Reason    string
+	Message   string // user message with routing hints removed
+
+	providerName string
+	principal    *access.Principal
+}
+
+// NewModelRouter creates a router from config. It returns nil when routing is
+// disabled or no tiers are configured, so callers can treat nil as "no routing".
+func NewModelRouter(routingCfg *config.ModelRoutingConfig, cfg *config.Config) *ModelRouter {
+	if routingCfg == nil || !routingCfg.Enabled || len(routingCfg.Tiers) == 0 || cfg == nil {
+		return nil
+	}
+	return &ModelRouter{
+		cfg:           *routingCfg,
+		modelResolver: newModelResolver(cfg),
+	}
+}
+
+// Route selects a tier for the request. It returns nil when the turn should use
+// the agent's default model (no match and no default tier, or resolution failed).
+func (r *ModelRouter) Route(ctx context.Context, agentID string, req RouteRequest) *RouteDecision {
+	if r == nil {
+		return nil
+	}
+
+	message := req.Message
+	tier, reason := "", ""
+
+	if hintTier, hint, stripped := r.matchHint(message); hintTier != "" {
+		tier, reason, message = hintTier, "hint "+hint, stripped
+	} else if ruleTier, ruleReason := r.matchRules(message, len(req.Media) > 0); ruleTier != "" {
+		tier, reason = ruleTier, ruleReason
+	} else if classTier := r.classify(ctx, message); classTier != "" {
+		tier, reason = classTier, "classifier"
+	} else if r.cfg.DefaultTier != "" {
+		tier, reason = r.cfg.DefaultTier, "default"
+	}
+
+	if tier == "" {
+		if message != req.Message {
+			return &RouteDecision{Message: message}
+		}
+		return nil
+	}
+
+	modelName, ok := r.cfg.Tiers[tier]
+	if !ok || modelName == "" {
+		logger.WarnCF("agent", "Routing tier has no model configured",
+			map[string]any{"agent_id": agentID, "tier": tier})
+		return &RouteDecision{Message: message}
+	}
+	if !req.Principal.AllowModel(modelName) {
+		logger.InfoCF("agent", "Routed model not allowed for sender, using agent default",
+			map[string]any{"agent_id": agentID, "tier": tier, "model_name": modelName})
+		return &RouteDecision{Message: message}
+	}
+
+	rm, err := r.resolve(modelName)
+	if err != nil {
+		logger.WarnCF("agent", "Failed to resolve routed model, using agent default",
+			map[string]any{"agent_id": agentID, "tier": tier, "model_name": modelName, "error": err.Error()})
+		return &RouteDecision{Message: message}
+	}
+
+	decision := &RouteDecision{
+		Tier:         tier,
+		ModelName:    modelName,
+		Model:        rm.modelID,
+		Provider:     rm.provider,
+		Reason:       reason,
+		Message:      message,
+		providerName: rm.providerName,
+		principal:    req.Principal,
+	}
+
+	logger.InfoCF("agent", "Model routed",
+		map[string]any{
+			"agent_id":    agentID,
+			"tier":        tier,
+			"model_name":  modelName,
+			"model":       rm.modelID,
+			"reason":      reason,
+			"message_len": utf8.RuneCountInString(message),
+			"media_count": len(req.Media),
+		})
+
+	return decision
+}
+
+// Candidates returns the fallback candidates for a routed turn: the routed model
+// first, followed by the agent's own candidates (deduplicated). The agent's
+// default model is always kept; its fallbacks are dropped when the sender's
+// role may not use them.
+func (d *RouteDecision) Candidates(agentCandidates []providers.FallbackCandidate) []providers.FallbackCandidate {
+	primary := providers.FallbackCandidate{Provider: d.providerName, Model: d.Model}
+	out := []providers.FallbackCandidate{primary}
+	seen := map[string]bool{providers.ModelKey(primary.Provider, primary.Model): true}
+	for i, c := range agentCandidates {
+		key := providers.ModelKey(c.Provider, c.Model)
+		if seen[key] {
+			continue
+		}
+		if i > 0 && !d.principal.AllowModel(c.Model) && !d.principal.AllowModel(c.Provider+"/"+c.Model) {
+			continue
+		}
+		seen[key] = true
+		out = append(out, c)
+	}
+	return out
+}
+
+// ProviderFor returns the provider to use for a fallback candidate: the routed
+// provider for the routed model, otherwise the given default.
+func (d *RouteDecision) ProviderFor(provider, model string, def providers.LLMProvider) providers.LLMProvider {
+	if d != nil && d.Provider != nil && providers.ModelKey(provider, model) == providers.ModelKey(d.providerName, d.Model) {
+		return d.Provider
+	}
+	return def
+}
+
+// matchHint checks whether the message starts with an explicit routing hint.
+func (r *ModelRouter) matchHint(message string) (tier, hint, stripped string) {
+	trimmed := strings.TrimSpace(message)
+	first, rest, _ := strings.Cut(trimmed, " ")
+	if first == "" {
+		return "", "", message
+	}
+	for _, rule := range r.cfg.Rules {
+		for _, h := range rule.Hints {
+			if strings.EqualFold(first, h) {
+				return rule.Tier, h, strings.TrimSpace(rest)
+			}
+		}
+	}
+	return "", "", message
+}
+
+// matchRules returns the tier of the first non-hint rule whose conditions all match.
+func (r *ModelRouter) matchRules(message string, hasMedia bool) (string, string) {
+	length := utf8.RuneCountInString(message)
+	lower := strings.ToLower(message)
+
+	for i, rule := range r.cfg.Rules {
+		if !ruleHasConditions(rule) {
+			continue
+		}
+		if rule.MinChars > 0 && length < rule.MinChars {
+			continue
+		}
+		if rule.MaxChars > 0 && length > rule.MaxChars {
+			continue
+		}
+		if rule.HasMedia != nil && *rule.HasMedia != hasMedia {
+			continue
+		}
+		if len(rule.Keywords) > 0 && !containsAny(lower, rule.Keywords) {
+			continue
+		}
+		if len(rule.Tools) > 0 && !containsAny(lower, rule.Tools) {
+			continue
+		}
+		return rule.Tier, fmt.Sprintf("rule %d", i)
+	}
+	return "", ""
+}
+
+// ruleHasConditions reports whether a rule has any condition besides hints.
+// Hint-only rules are handled by matchHint and must not match every message.
+func ruleHasConditions(rule config.ModelRoutingRule) bool {
+	return rule.MinChars > 0 || rule.MaxChars > 0 || rule.HasMedia != nil ||
+		len(rule.Keywords) > 0 || len(rule.Tools) > 0
+}
+
+func containsAny(lower string, needles []string) bool {
+	for _, n := range needles {
+		n = strings.ToLower(strings.TrimSpace(n))
+		if n != "" && strings.Contains(lower, n) {
+			return true
+		}
+	}
+	return false
+}
+
+// classify asks the classifier model to pick one of the configured tiers.
+func (r *ModelRouter) classify(ctx context.Context, message string) string {
+	if r.cfg.Classifier == nil || r.cfg.Classifier.Model == "" || strings.TrimSpace(message) == "" {
+		return ""
+	}
+
+	rm, err := r.resolve(r.cfg.Classifier.Model)
+	if err != nil {
+		logger.WarnCF("agent", "Routing classifier unavailable",
+			map[string]any{"model_name": r.cfg.Classifier.Model, "error": err.Error()})
+		return ""
+	}
+
+	timeout := defaultClassifierTimeout
+	if r.cfg.Classifier.TimeoutSeconds > 0 {
+		timeout = time.Duration(r.cfg.Classifier.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	tiers := r.tierNames()
+	prompt := fmt.Sprintf(
+		"Classify how much capability the following request needs. "+
+			"Reply with exactly one word from this list: %s.\n\nREQUEST:\n%s",
+		strings.Join(tiers, ", "), message)
+
+	resp, err := rm.provider.Chat(ctx,
+		[]providers.Message{{Role: "user", Content: prompt}},
+		nil,
+		rm.modelID,
+		map[string]any{"max_tokens": 16, "temperature": 0.0},
+	)
+	if err != nil {
+		logger.WarnCF("agent", "Routing classifier call failed", map[string]any{"error": err.Error()})
+		return ""
+	}
+
+	answer := strings.ToLower(strings.TrimSpace(resp.Content))
+	// Prefer longer names first so "frontier-plus" is not shadowed by "frontier".
+	sort.Slice(tiers, func(i, j int) bool { return len(tiers[i]) > len(tiers[j]) })
+	for _, t := range tiers {
+		if strings.Contains(answer, strings.ToLower(t)) {
+			return t
+		}
+	}
+	return ""
+}
+
+func (r *ModelRouter) tierNames() []string {
+	names := make([]string, 0, len(r.cfg.Tiers))
+	for name := range r.cfg.Tiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolve creates (or returns the cached) provider for a model_list entry.
+func (r *modelResolver) resolve(modelName string) (*routedModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if rm, ok := r.models[modelName]; ok {
+		return rm, nil
+	}
+
+	modelCfg, err := r.root.GetModelConfig(modelName)
+	if err != nil {
+		return nil, err
+	}
+	mc := *modelCfg
+	if mc.Workspace == "" {
+		mc.Workspace = r.root.WorkspacePath()
+	}
+
+	provider, modelID, err := r.factory(&mc)
+	if err != nil {
+		return nil, err
+	}
+	protocol, _ := providers.ExtractProtocol(mc.Model)
+
+	rm := &routedModel{
+		provider:     provider,
+		providerName: providers.NormalizeProvider(protocol),
+		modelID:      modelID,
+	}
+	r.models[modelName] = rm
+	return rm, nil
+}
@@ -0,0 +1,278 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+type namedMockProvider struct {
+	reply  string
+	calls  int
+	models []string
+}
+
+func (m *namedMockProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	m.calls++
+	m.models = append(m.models, model)
+	return &providers.LLMResponse{Content: m.reply}, nil
+}
+
+func (m *namedMockProvider) GetDefaultModel() string {
+	return "named-mock"
+}
+
+func newTestRouter(t *testing.T, routing *config.ModelRoutingConfig, byName map[string]*namedMockProvider) *ModelRouter {
+	t.Helper()
+	cfg := &config.Config{
+		ModelList: []config.ModelConfig{
+			{ModelName: "local", Model: "ollama/qwen3:4b", APIBase: "http://localhost:11434/v1"},
+			{ModelName: "standard", Model: "openai/gpt-4o-mini", APIKey: "k"},
+			{ModelName: "frontier", Model: "anthropic/claude-opus", APIKey: "k"},
+		},
+	}
+	r := NewModelRouter(routing, cfg)
+	if r == nil {
+		t.Fatal("expected router to be created")
+	}
+	r.factory = func(mc *config.ModelConfig) (providers.LLMProvider, string, error) {
+		_, modelID := providers.ExtractProtocol(mc.Model)
+		return byName[mc.ModelName], modelID, nil
+	}
+	return r
+}
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestNewModelRouter_Disabled(t *testing.T) {
+	if NewModelRouter(nil, &config.Config{}) != nil {
+		t.Error("nil routing config should disable routing")
+	}
+	disabled := &config.ModelRoutingConfig{Enabled: false, Tiers: map[string]string{"a": "b"}}
+	if NewModelRouter(disabled, &config.Config{}) != nil {
+		t.Error("disabled routing should return nil router")
+	}
+	var r *ModelRouter
+	if r.Route(context.Background(), "main", RouteRequest{Message: "hi"}) != nil {
+		t.Error("nil router should not route")
+	}
+}
+
+func TestModelRouter_HintStripsPrefix(t *testing.T) {
+	frontier := &namedMockProvider{}
+	r := newTestRouter(t, &config.ModelRoutingConfig{
+		Enabled: true,
+		Tiers:   map[string]string{"frontier": "frontier", "local": "local"},
+		Rules: []config.ModelRoutingRule{
+			{Tier: "frontier", Hints: []string{"/deep"}},
+		},
+	}, map[string]*namedMockProvider{"frontier": frontier})
+
+	d := r.Route(context.Background(), "main", RouteRequest{Message: "/deep prove the theorem"})
+	if d == nil {
+		t.Fatal("expected a decision")
+	}
+	if d.Tier != "frontier" || d.Model != "claude-opus" || d.Provider != frontier {
+		t.Errorf("unexpected decision: %+v", d)
+	}
+	if d.Message != "prove the theorem" {
+		t.Errorf("hint not stripped: %q", d.Message)
+	}
+
+	// Hint-only rules must not match ordinary messages.
+	if d := r.Route(context.Background(), "main", RouteRequest{Message: "hello"}); d != nil {
+		t.Errorf("expected no routing, got %+v", d)
+	}
+}
+
+func TestModelRouter_RulesAndDefault(t *testing.T) {
+	local := &namedMockProvider{}
+	standard := &namedMockProvider{}
+	frontier := &namedMockProvider{}
+	r := newTestRouter(t, &config.ModelRoutingConfig{
+		Enabled:     true,
+		Tiers:       map[string]string{"local": "local", "standard": "standard", "frontier": "frontier"},
+		DefaultTier: "standard",
+		Rules: []config.ModelRoutingRule{
+			{Tier: "frontier", HasMedia: boolPtr(true)},
+			{Tier: "frontier", Tools: []string{"exec"}},
+			{Tier: "local", MaxChars: 20},
+		},
+	}, map[string]*namedMockProvider{"local": local, "standard": standard, "frontier": frontier})
+
+	tests := []struct {
+		name string
+		req  RouteRequest
+		tier string
+	}{
+		{"media", RouteRequest{Message: "what is this?", Media: []string{"/tmp/a.png"}}, "frontier"},
+		{"tool mention", RouteRequest{Message: "please use exec to list the files in my home"}, "frontier"},
+		{"short", RouteRequest{Message: "hi there"}, "local"},
+		{"default", RouteRequest{Message: strings.Repeat("long message ", 5)}, "standard"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := r.Route(context.Background(), "main", tt.req)
+			if d == nil || d.Tier != tt.tier {
+				t.Fatalf("expected tier %q, got %+v", tt.tier, d)
+			}
+		})
+	}
+}
+
+func TestModelRouter_Classifier(t *testing.T) {
+	classifier := &namedMockProvider{reply: "Frontier"}
+	frontier := &namedMockProvider{}
+	r := newTestRouter(t, &config.ModelRoutingConfig{
+		Enabled:    true,
+		Tiers:      map[string]string{"local": "local", "frontier": "frontier"},
+		Classifier: &config.ModelRoutingClassifier{Model: "local"},
+	}, map[string]*namedMockProvider{"local": classifier, "frontier": frontier})
+
+	d := r.Route(context.Background(), "main", RouteRequest{Message: "design a distributed database"})
+	if d == nil || d.Tier != "frontier" || d.Reason != "classifier" {
+		t.Fatalf("expected classifier to pick frontier, got %+v", d)
+	}
+	if classifier.calls != 1 {
+		t.Errorf("expected 1 classifier call, got %d", classifier.calls)
+	}
+}
+
+func TestRouteDecision_Candidates(t *testing.T) {
+	d := &RouteDecision{Model: "claude-opus", providerName: "anthropic"}
+	got := d.Candidates([]providers.FallbackCandidate{
+		{Provider: "openai", Model: "gpt-4o"},
+		{Provider: "anthropic", Model: "claude-opus"},
+	})
+	if len(got) != 2 || got[0].Model != "claude-opus" || got[1].Model != "gpt-4o" {
+		t.Errorf("unexpected candidates: %+v", got)
+	}
+}
+
+func TestAgentLoop_UsesRoutedProvider(t *testing.T) {
+	tmpDir := t.TempDir()
+	local := &namedMockProvider{reply: "from local"}
+	defaultProvider := &namedMockProvider{reply: "from default"}
+
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         tmpDir,
+				Model:             "default-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+				Routing: &config.ModelRoutingConfig{
+					Enabled: true,
+					Tiers:   map[string]string{"local": "local"},
+					Rules:   []config.ModelRoutingRule{{Tier: "local", Hints: []string{"/fast"}}},
+				},
+			},
+		},
+		ModelList: []config.ModelConfig{
+			{ModelName: "local", Model: "ollama/qwen3:4b", APIBase: "http://localhost:11434/v1"},
+		},
+	}
+
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), defaultProvider)
+	agent := al.registry.GetDefaultAgent()
+	agent.Router.factory = func(mc *config.ModelConfig) (providers.LLMProvider, string, error) {
+		return local, "qwen3:4b", nil
+	}
+
+	resp, err := al.ProcessDirect(context.Background(), "/fast hello", "test-session")
+	if err != nil {
+		t.Fatalf("ProcessDirect failed: %v", err)
+	}
+	if resp != "from local" {
+		t.Errorf("expected routed response, got %q", resp)
+	}
+	if len(local.models) != 1 || local.models[0] != "qwen3:4b" {
+		t.Errorf("routed provider got unexpected models: %v", local.models)
+	}
+
+	resp, err = al.ProcessDirect(context.Background(), "hello", "test-session")
+	if err != nil {
+		t.Fatalf("ProcessDirect failed: %v", err)
+	}
+	if resp != "from default" {
+		t.Errorf("expected default provider response, got %q", resp)
+	}
+}
+
+func TestAgentLoop_RoutingHonorsModelPolicy(t *testing.T) {
+	frontier := &namedMockProvider{reply: "from frontier"}
+	defaultProvider := &namedMockProvider{reply: "from default"}
+
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "default-model",
+				ModelFallbacks:    []string{"frontier"},
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+				Routing: &config.ModelRoutingConfig{
+					Enabled: true,
+					Tiers:   map[string]string{"frontier": "frontier"},
+					Rules:   []config.ModelRoutingRule{{Tier: "frontier", Hints: []string{"/deep"}}},
+				},
+			},
+		},
+		ModelList: []config.ModelConfig{
+			{ModelName: "frontier", Model: "anthropic/claude-opus", APIKey: "k"},
+		},
+		Access: &config.AccessConfig{
+			DefaultRole: "guest",
+			Members:     map[string]string{"telegram:42": "admin"},
+			Roles: map[string]config.RolePolicy{
+				"admin": {Admin: true},
+				"guest": {Models: []string{}},
+			},
+		},
+	}
+
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), defaultProvider)
+	agent := al.registry.GetDefaultAgent()
+	agent.Router.factory = func(mc *config.ModelConfig) (providers.LLMProvider, string, error) {
+		return frontier, "claude-opus", nil
+	}
+
+	send := func(sender string) string {
+		t.Helper()
+		resp, err := al.processMessage(context.Background(), bus.InboundMessage{
+			Channel: "telegram", SenderID: sender, ChatID: sender, Content: "/deep prove the theorem",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	if got := send("7"); got != "from default" {
+		t.Errorf("guest /deep reply = %q, want the agent default", got)
+	}
+	if frontier.calls != 0 {
+		t.Errorf("guest reached the frontier tier %d times", frontier.calls)
+	}
+	if got := send("42"); got != "from frontier" {
+		t.Errorf("admin /deep reply = %q, want the frontier tier", got)
+	}
+
+	// Fallbacks of a routed turn are held to the same policy.
+	d := &RouteDecision{Model: "qwen3:4b", providerName: "ollama", principal: al.access.Resolve("telegram", "7")}
+	for _, c := range d.Candidates(agent.Candidates) {
+		if c.Model == "frontier" {
+			t.Errorf("guest fallbacks include %v", c)
+		}
+	}
+}
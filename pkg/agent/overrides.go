@@ -0,0 +1,349 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/access"
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/routing"
+	"github.com/sipeed/picoclaw/pkg/session"
+)
+
+// maxPersonaSize caps persona files, which are added to every request.
+const maxPersonaSize = 64 << 10
+
+// chatContext is where a message is handled once the chat's agent binding
+// and active branch are applied.
+type chatContext struct {
+	agent      *AgentInstance
+	sessionKey string // active branch in agent.Sessions
+
+	// The chat's own settings are stored with its routed session in the
+	// routed agent's store, so they cover every branch and survive binding
+	// the chat to another agent.
+	store    *session.SessionManager
+	storeKey string
+	own      session.Overrides // set with /switch
+	settings session.Overrides // own settings over the /global ones
+
+	principal *access.Principal // the sender's role; nil for full access
+}
+
+// resolveChat applies the chat's settings to a routed agent and session.
+func (al *AgentLoop) resolveChat(agent *AgentInstance, sessionKey string) *chatContext {
+	chat := &chatContext{store: agent.Sessions, storeKey: session.BaseKey(sessionKey)}
+	chat.own = chat.store.GetOverrides(chat.storeKey)
+	chat.settings = chat.own.Merge(al.globalOverrides())
+
+	if id := chat.settings.Agent; id != "" && routing.NormalizeAgentID(id) != agent.ID {
+		if bound, ok := al.registry.GetAgent(id); ok {
+			agent = bound
+			sessionKey = rebindSessionKey(sessionKey, bound.ID)
+		} else {
+			logger.WarnCF("agent", "Chat is bound to an unknown agent, using the routed one",
+				map[string]any{"agent_id": id, "session_key": sessionKey})
+		}
+	}
+
+	chat.agent = agent
+	chat.sessionKey = agent.Sessions.Active(sessionKey)
+	return chat
+}
+
+// rebindSessionKey moves an agent-scoped session key to another agent.
+func rebindSessionKey(key, agentID string) string {
+	parsed := routing.ParseAgentSessionKey(key)
+	if parsed == nil {
+		return key
+	}
+	return fmt.Sprintf("agent:%s:%s", agentID, parsed.Rest)
+}
+
+func (al *AgentLoop) globalOverrides() session.Overrides {
+	al.globalMu.RLock()
+	defer al.globalMu.RUnlock()
+	return al.global
+}
+
+// overrideRoute resolves the model picked with /switch or /global into a
+// route for this turn, or nil if the model can't be used.
+func (al *AgentLoop) overrideRoute(
+	agent *AgentInstance,
+	modelName, message string,
+	principal *access.Principal,
+) *RouteDecision {
+	rm, err := al.models.resolve(modelName)
+	if err != nil {
+		logger.WarnCF("agent", "Failed to resolve model override, using agent default",
+			map[string]any{"agent_id": agent.ID, "model_name": modelName, "error": err.Error()})
+		return nil
+	}
+	return &RouteDecision{
+		ModelName:    modelName,
+		Model:        rm.modelID,
+		Provider:     rm.provider,
+		Reason:       "override",
+		Message:      message,
+		providerName: rm.providerName,
+		principal:    principal,
+	}
+}
+
+// loadPersona reads a persona file from the agent workspace.
+func loadPersona(workspace, name string) (string, error) {
+	rel := filepath.Clean(filepath.FromSlash(name))
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("persona must be a file inside the agent workspace")
+	}
+	path := filepath.Join(workspace, rel)
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%s is not a regular file", name)
+	}
+	if info.Size() > maxPersonaSize {
+		return "", fmt.Errorf("%s is larger than %d KB", name, maxPersonaSize>>10)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// applyPersona puts a chat's persona right after the static system prompt,
+// where it takes precedence over SOUL.md and IDENTITY.md.
+func applyPersona(messages []providers.Message, persona string) {
+	if persona == "" || len(messages) == 0 || messages[0].Role != "system" || len(messages[0].SystemParts) == 0 {
+		return
+	}
+	block := "# Persona\n\nIn this conversation, take on the persona below. " +
+		"It takes precedence over the identity and personality described above.\n\n" + persona
+
+	sys := &messages[0]
+	static := sys.SystemParts[0].Text
+	sys.Content = static + "\n\n---\n\n" + block + strings.TrimPrefix(sys.Content, static)
+
+	parts := []providers.ContentBlock{
+		sys.SystemParts[0],
+		{Type: "text", Text: block, CacheControl: &providers.CacheControl{Type: "ephemeral"}},
+	}
+	sys.SystemParts = append(parts, sys.SystemParts[1:]...)
+}
+
+// setOverride validates value and applies it to one setting of o. The
+// value "default" clears the setting. Personas are checked against
+// workspace. It returns a description of the change.
+func (al *AgentLoop) setOverride(o *session.Overrides, workspace, target, value string) (string, error) {
+	reset := value == "default"
+
+	switch target {
+	case "model":
+		if reset {
+			o.Model = ""
+			return "model back to the default", nil
+		}
+		if _, err := al.cfg.GetModelConfig(value); err != nil {
+			return "", fmt.Errorf("unknown model %q (available: %s)", value, strings.Join(al.modelNames(), ", "))
+		}
+		o.Model = value
+		return "model to " + value, nil
+
+	case "agent":
+		if reset {
+			o.Agent = ""
+			return "agent back to the routed one", nil
+		}
+		agent, ok := al.registry.GetAgent(value)
+		if !ok {
+			ids := al.registry.ListAgentIDs()
+			sort.Strings(ids)
+			return "", fmt.Errorf("unknown agent %q (registered: %s)", value, strings.Join(ids, ", "))
+		}
+		o.Agent = agent.ID
+		return "agent to " + agent.ID, nil
+
+	case "temperature":
+		if reset {
+			o.Temperature = nil
+			return "temperature back to the default", nil
+		}
+		t, err := strconv.ParseFloat(value, 64)
+		if err != nil || t < 0 || t > 2 {
+			return "", fmt.Errorf("temperature must be a number between 0 and 2")
+		}
+		o.Temperature = &t
+		return fmt.Sprintf("temperature to %g", t), nil
+
+	case "persona":
+		if reset {
+			o.Persona = ""
+			return "persona off", nil
+		}
+		if _, err := loadPersona(workspace, value); err != nil {
+			return "", err
+		}
+		o.Persona = filepath.ToSlash(filepath.Clean(value))
+		return "persona to " + o.Persona, nil
+	}
+
+	return "", fmt.Errorf("unknown setting %q (use model, agent, temperature or persona)", target)
+}
+
+// modelNames lists the model_list entries, for error messages and /list.
+func (al *AgentLoop) modelNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, mc := range al.cfg.ModelList {
+		if mc.ModelName != "" && !seen[mc.ModelName] {
+			seen[mc.ModelName] = true
+			names = append(names, mc.ModelName)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// handleSwitch changes a setting for the current chat.
+func (al *AgentLoop) handleSwitch(chat *chatContext, args []string) string {
+	if len(args) < 3 || args[1] != "to" {
+		return "Usage: /switch [model|agent|temperature|persona] to <value|default>"
+	}
+	target, value := args[0], strings.Join(args[2:], " ")
+	if target == "channel" {
+		return "Replies always go to the chat a message came from, so the channel can't be switched."
+	}
+
+	if value != "default" {
+		if target == "model" && !chat.principal.AllowModel(value) {
+			return fmt.Sprintf("You don't have permission to use model %s.", value)
+		}
+		if target == "agent" && !chat.principal.AllowAgent(value) {
+			return fmt.Sprintf("You don't have access to the %s agent.", value)
+		}
+	}
+
+	own := chat.own
+	change, err := al.setOverride(&own, chat.agent.Workspace, target, value)
+	if err != nil {
+		return fmt.Sprintf("Cannot switch %s: %v", target, err)
+	}
+	chat.store.SetOverrides(chat.storeKey, own)
+	chat.store.Save(chat.storeKey)
+	return fmt.Sprintf("Switched %s for this chat.", change)
+}
+
+// handleGlobal changes a setting for every chat that doesn't override it.
+func (al *AgentLoop) handleGlobal(msg bus.InboundMessage, chat *chatContext, args []string) string {
+	if !al.isAdmin(msg, chat.principal) {
+		return "Only admins can change global settings."
+	}
+	if len(args) == 0 {
+		return "Global settings:\n" + formatOverrides(al.globalOverrides())
+	}
+	if len(args) < 3 || args[1] != "to" {
+		return "Usage: /global [model|agent|temperature|persona] to <value|default>"
+	}
+	target, value := args[0], strings.Join(args[2:], " ")
+
+	workspace := ""
+	if agent := al.registry.GetDefaultAgent(); agent != nil {
+		workspace = agent.Workspace
+	}
+
+	al.globalMu.Lock()
+	defer al.globalMu.Unlock()
+	global := al.global
+	change, err := al.setOverride(&global, workspace, target, value)
+	if err != nil {
+		return fmt.Sprintf("Cannot switch %s: %v", target, err)
+	}
+	al.global = global
+
+	logger.InfoCF("agent", "Global setting changed",
+		map[string]any{"setting": target, "value": value, "channel": msg.Channel, "sender_id": msg.SenderID})
+	return fmt.Sprintf("Switched %s for all chats.", change)
+}
+
+func formatOverrides(o session.Overrides) string {
+	if o.IsZero() {
+		return "  (none)"
+	}
+	var lines []string
+	if o.Agent != "" {
+		lines = append(lines, "  agent: "+o.Agent)
+	}
+	if o.Model != "" {
+		lines = append(lines, "  model: "+o.Model)
+	}
+	if o.Temperature != nil {
+		lines = append(lines, fmt.Sprintf("  temperature: %g", *o.Temperature))
+	}
+	if o.Persona != "" {
+		lines = append(lines, "  persona: "+o.Persona)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// describeSettings shows the settings in effect for a chat and where each
+// one comes from.
+func describeSettings(chat *chatContext) string {
+	source := func(own, set bool) string {
+		switch {
+		case own:
+			return " (this chat)"
+		case set:
+			return " (global)"
+		}
+		return ""
+	}
+	s, own := chat.settings, chat.own
+
+	model := chat.agent.Model
+	if s.Model != "" {
+		model = s.Model
+	}
+	temperature := chat.agent.Temperature
+	if s.Temperature != nil {
+		temperature = *s.Temperature
+	}
+	persona := "none"
+	if s.Persona != "" {
+		persona = s.Persona
+	}
+
+	lines := []string{
+		"Agent: " + chat.agent.ID + source(own.Agent != "", s.Agent != ""),
+		"Model: " + model + source(own.Model != "", s.Model != ""),
+		fmt.Sprintf("Temperature: %g%s", temperature, source(own.Temperature != nil, s.Temperature != nil)),
+		"Persona: " + persona + source(own.Persona != "", s.Persona != ""),
+		fmt.Sprintf("Session: %s (%s)", session.BranchName(chat.sessionKey), chat.sessionKey),
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isAdmin reports whether the sender may change global settings: the local
+// CLI user, a sender whose role is an admin role, or one listed in
+// session.admins by ID, "channel:id" or identity_links name.
+func (al *AgentLoop) isAdmin(msg bus.InboundMessage, principal *access.Principal) bool {
+	if msg.Channel == "cli" || principal.IsAdmin() {
+		return true
+	}
+	for _, id := range access.Identities(al.cfg.Session.IdentityLinks, msg.Channel, msg.SenderID) {
+		for _, admin := range al.cfg.Session.Admins {
+			if strings.EqualFold(strings.TrimSpace(admin), id) {
+				return true
+			}
+		}
+	}
+	return false
+}
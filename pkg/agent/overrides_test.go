@@ -0,0 +1,212 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/session"
+)
+
+// settingsProvider records the model, temperature and system prompt of
+// each call.
+type settingsProvider struct {
+	reply        string
+	models       []string
+	temperatures []any
+	systems      []string
+}
+
+func (p *settingsProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	p.models = append(p.models, model)
+	p.temperatures = append(p.temperatures, opts["temperature"])
+	p.systems = append(p.systems, messages[0].Content)
+	return &providers.LLMResponse{Content: p.reply}, nil
+}
+
+func (p *settingsProvider) GetDefaultModel() string { return "settings-model" }
+
+func newOverridesTestLoop(t *testing.T, base, fast *settingsProvider) *AgentLoop {
+	t.Helper()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "default-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+			List: []config.AgentConfig{
+				{ID: "main", Default: true},
+				{ID: "coder", Workspace: t.TempDir()},
+			},
+		},
+		Session: config.SessionConfig{
+			Admins:        []string{"ops"},
+			IdentityLinks: map[string][]string{"ops": {"telegram:42"}},
+		},
+		ModelList: []config.ModelConfig{
+			{ModelName: "fast", Model: "openai/gpt-fast", APIKey: "k"},
+		},
+	}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), base)
+	al.models.factory = func(mc *config.ModelConfig) (providers.LLMProvider, string, error) {
+		return fast, "gpt-fast", nil
+	}
+	return al
+}
+
+func TestAgentLoop_ChatOverrides(t *testing.T) {
+	base := &settingsProvider{reply: "from default"}
+	fast := &settingsProvider{reply: "from fast"}
+	al := newOverridesTestLoop(t, base, fast)
+	ctx := context.Background()
+
+	main := al.registry.GetDefaultAgent()
+	os.MkdirAll(filepath.Join(main.Workspace, "personas"), 0o755)
+	os.WriteFile(filepath.Join(main.Workspace, "personas", "pirate.md"), []byte("Talk like a pirate."), 0o644)
+
+	chatA := "agent:main:telegram:direct:1"
+	chatB := "agent:main:telegram:direct:2"
+	send := func(key, content string) string {
+		t.Helper()
+		resp, err := al.ProcessDirectWithChannel(ctx, content, key, "telegram", strings.TrimPrefix(key, "agent:main:"))
+		if err != nil {
+			t.Fatalf("%s: %v", content, err)
+		}
+		return resp
+	}
+
+	for cmd, want := range map[string]string{
+		"/switch model to nope":             "unknown model",
+		"/switch agent to nope":             "unknown agent",
+		"/switch temperature to 3":          "between 0 and 2",
+		"/switch persona to ../secret.md":   "inside the agent workspace",
+		"/switch persona to personas/no.md": "Cannot switch persona",
+		"/switch channel to slack":          "can't be switched",
+		"/switch model":                     "Usage",
+	} {
+		if got := send(chatA, cmd); !strings.Contains(got, want) {
+			t.Errorf("%s = %q, want it to mention %q", cmd, got, want)
+		}
+	}
+
+	send(chatA, "/switch model to fast")
+	send(chatA, "/switch temperature to 0.1")
+	send(chatA, "/switch persona to personas/pirate.md")
+
+	if got := send(chatA, "hello"); got != "from fast" {
+		t.Errorf("chat A reply = %q, want the overridden model", got)
+	}
+	if fast.models[0] != "gpt-fast" || fast.temperatures[0] != 0.1 {
+		t.Errorf("fast provider got model %v, temperature %v", fast.models, fast.temperatures)
+	}
+	if !strings.Contains(fast.systems[0], "Talk like a pirate.") {
+		t.Error("persona missing from the system prompt")
+	}
+
+	if got := send(chatB, "hello"); got != "from default" {
+		t.Errorf("chat B reply = %q, other chats must keep the defaults", got)
+	}
+	if strings.Contains(base.systems[0], "pirate") || base.temperatures[0] == 0.1 {
+		t.Error("chat A's settings leaked into chat B")
+	}
+
+	show := send(chatA, "/show")
+	for _, want := range []string{"Model: fast (this chat)", "Temperature: 0.1 (this chat)", "Persona: personas/pirate.md"} {
+		if !strings.Contains(show, want) {
+			t.Errorf("/show missing %q:\n%s", want, show)
+		}
+	}
+
+	// Binding the chat to another agent moves its history there.
+	send(chatA, "/switch agent to coder")
+	send(chatA, "/switch persona to default")
+	send(chatA, "in coder")
+	coder, _ := al.registry.GetAgent("coder")
+	if history := coder.Sessions.GetHistory("agent:coder:telegram:direct:1"); len(history) != 2 {
+		t.Errorf("coder history = %+v", history)
+	}
+	if got := send(chatA, "/show agent"); got != "Current agent: coder" {
+		t.Errorf("/show agent = %q", got)
+	}
+
+	// Settings are stored with the session.
+	reloaded := session.NewSessionManager(filepath.Join(main.Workspace, "sessions"))
+	if o := reloaded.GetOverrides(chatA); o.Agent != "coder" || o.Model != "fast" || o.Persona != "" {
+		t.Errorf("stored overrides = %+v", o)
+	}
+}
+
+func TestAgentLoop_GlobalOverridesAdminOnly(t *testing.T) {
+	base := &settingsProvider{reply: "from default"}
+	fast := &settingsProvider{reply: "from fast"}
+	al := newOverridesTestLoop(t, base, fast)
+	ctx := context.Background()
+
+	global := func(sender, content string) string {
+		t.Helper()
+		resp, err := al.processMessage(ctx, bus.InboundMessage{
+			Channel: "telegram", SenderID: sender, ChatID: "1", Content: content,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	if got := global("7", "/global model to fast"); !strings.Contains(got, "Only admins") {
+		t.Errorf("non-admin /global = %q", got)
+	}
+	if got := global("42|ops_user", "/global model to fast"); !strings.Contains(got, "for all chats") {
+		t.Errorf("admin /global = %q", got)
+	}
+	if got := global("7", "hello"); got != "from fast" {
+		t.Errorf("reply after /global = %q, want the global model", got)
+	}
+
+	resp, err := al.ProcessDirect(ctx, "/global model to default", "agent:main:main")
+	if err != nil || !strings.Contains(resp, "for all chats") {
+		t.Errorf("CLI /global = %q, %v", resp, err)
+	}
+	if !al.globalOverrides().IsZero() {
+		t.Errorf("global overrides = %+v", al.globalOverrides())
+	}
+}
+
+func TestIsAdmin(t *testing.T) {
+	al := &AgentLoop{cfg: &config.Config{Session: config.SessionConfig{
+		Admins:        []string{"123", "discord:555", "alice"},
+		IdentityLinks: map[string][]string{"alice": {"slack:u1"}},
+	}}}
+
+	cases := []struct {
+		channel, sender string
+		want            bool
+	}{
+		{"cli", "", true},
+		{"telegram", "123|bob", true},
+		{"discord", "555", true},
+		{"telegram", "555", false},
+		{"slack", "U1", true},
+		{"slack", "u2", false},
+		{"telegram", "", false},
+	}
+	for _, c := range cases {
+		got := al.isAdmin(bus.InboundMessage{Channel: c.channel, SenderID: c.sender}, nil)
+		if got != c.want {
+			t.Errorf("isAdmin(%s, %q) = %v, want %v", c.channel, c.sender, got, c.want)
+		}
+	}
+}
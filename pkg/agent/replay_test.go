@@ -0,0 +1,86 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// notesProvider reads notes.txt and then reports what it found.
+type notesProvider struct{}
+
+func (p *notesProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	last := messages[len(messages)-1]
+	if last.Role == "tool" {
+		return &providers.LLMResponse{Content: "Notes: " + last.Content}, nil
+	}
+	return &providers.LLMResponse{
+		ToolCalls: []providers.ToolCall{{
+			ID:        "call_1",
+			Name:      "read_file",
+			Arguments: map[string]any{"path": "notes.txt"},
+		}},
+	}, nil
+}
+
+func (p *notesProvider) GetDefaultModel() string { return "notes-model" }
+
+func replayTestLoop(t *testing.T, provider providers.LLMProvider) *AgentLoop {
+	t.Helper()
+	workspace := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workspace, "notes.txt"), []byte("buy milk"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:           workspace,
+				Model:               "test-model",
+				MaxTokens:           4096,
+				MaxToolIterations:   10,
+				RestrictToWorkspace: true,
+			},
+		},
+	}
+	return NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+}
+
+// TestAgentLoop_ReplayCassette records a tool-using turn and replays it
+// offline in a fresh workspace.
+func TestAgentLoop_ReplayCassette(t *testing.T) {
+	cassette := filepath.Join(t.TempDir(), "notes.json")
+	ctx := context.Background()
+
+	recorder := providers.NewRecordingProvider(&notesProvider{}, cassette)
+	recorded, err := replayTestLoop(t, recorder).ProcessDirect(ctx, "What is in my notes?", "agent:main:main")
+	if err != nil {
+		t.Fatalf("recording run: %v", err)
+	}
+
+	replay, err := providers.NewReplayProvider(cassette)
+	if err != nil {
+		t.Fatalf("NewReplayProvider: %v", err)
+	}
+	replayed, err := replayTestLoop(t, replay).ProcessDirect(ctx, "What is in my notes?", "agent:main:main")
+	if err != nil {
+		t.Fatalf("replay run: %v", err)
+	}
+	if replayed != recorded || replayed == "" {
+		t.Errorf("replayed %q, recorded %q", replayed, recorded)
+	}
+
+	if _, err := replayTestLoop(t, replay).ProcessDirect(ctx, "Something new", "agent:main:main"); err == nil {
+		t.Error("expected an unrecorded prompt to fail")
+	}
+}
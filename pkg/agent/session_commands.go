@@ -0,0 +1,127 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/session"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// handleSessionCommand runs the chat commands that edit or switch the
+// session history: /undo, /new (/reset), /fork and /sessions. sessionKey
+// is the branch currently active for the chat.
+func (al *AgentLoop) handleSessionCommand(agent *AgentInstance, sessionKey, cmd string, args []string) string {
+	sessions := agent.Sessions
+	base := session.BaseKey(sessionKey)
+
+	switch cmd {
+	case "/undo":
+		user, ok := sessions.PopTurn(sessionKey)
+		if !ok {
+			return "Nothing to undo in this session."
+		}
+		sessions.Save(sessionKey)
+		return fmt.Sprintf("Removed the last exchange (%q).", utils.Truncate(user.Content, 60))
+
+	case "/new", "/reset":
+		archived := sessions.Archive(sessionKey)
+		sessions.Save(sessionKey)
+		agent.ContextBuilder.ResetActiveSkills(sessionKey)
+		if archived == "" {
+			return "Started a new session."
+		}
+		sessions.Save(archived)
+		return fmt.Sprintf("Started a new session. The previous conversation was archived as %s (see /sessions).",
+			session.BranchName(archived))
+
+	case "/fork":
+		if len(args) != 1 {
+			return "Usage: /fork <name>"
+		}
+		name := args[0]
+		if err := session.ValidateBranchName(name); err != nil {
+			return err.Error()
+		}
+		if name == session.MainBranch {
+			return fmt.Sprintf("%q is the chat's main session; pick another name.", name)
+		}
+		forkKey := session.BranchKey(base, name)
+		if err := sessions.Fork(sessionKey, forkKey); err != nil {
+			return fmt.Sprintf("Cannot fork: %v", err)
+		}
+		sessions.SetActive(forkKey)
+		sessions.Save(forkKey)
+		sessions.Save(base)
+		return fmt.Sprintf("Forked %s into %s and switched to it. Use /sessions %s to go back.",
+			session.BranchName(sessionKey), name, session.BranchName(sessionKey))
+
+	case "/sessions":
+		if len(args) == 0 {
+			return formatBranches(sessions.Branches(base))
+		}
+		key := session.BranchKey(base, args[0])
+		if key == sessionKey {
+			return fmt.Sprintf("Already in session %s.", args[0])
+		}
+		if key != base && !sessions.Exists(key) {
+			return fmt.Sprintf("No session named %s. Use /sessions to list them.", args[0])
+		}
+		sessions.SetActive(key)
+		sessions.Save(base)
+		return fmt.Sprintf("Switched to session %s (%d messages).",
+			session.BranchName(key), len(sessions.GetHistory(key)))
+	}
+
+	return fmt.Sprintf("Unknown session command: %s", cmd)
+}
+
+func formatBranches(branches []session.BranchInfo) string {
+	var sb strings.Builder
+	sb.WriteString("Sessions in this chat:\n")
+	for _, b := range branches {
+		marker := " "
+		if b.Active {
+			marker = "*"
+		}
+		fmt.Fprintf(&sb, "%s %s (%d messages", marker, b.Name, b.Messages)
+		if !b.Updated.IsZero() {
+			fmt.Fprintf(&sb, ", updated %s", b.Updated.Format("2006-01-02 15:04"))
+		}
+		sb.WriteString(")\n")
+	}
+	sb.WriteString("Switch with /sessions <name>, branch with /fork <name>.")
+	return sb.String()
+}
+
+// retryTurn drops the last exchange of the session and runs its user
+// message again.
+func (al *AgentLoop) retryTurn(ctx context.Context, chat *chatContext, msg bus.InboundMessage) (string, error) {
+	agent, sessionKey := chat.agent, chat.sessionKey
+
+	// Check the quota first so a refused retry leaves the history alone.
+	ctx, err := admitTurn(ctx, chat)
+	if err != nil {
+		return fmt.Sprintf("Sorry, %v.", err), nil
+	}
+	user, ok := agent.Sessions.PopTurn(sessionKey)
+	if !ok {
+		return "Nothing to retry in this session.", nil
+	}
+
+	al.logConversationStart(agent, sessionKey, msg.Channel, msg.ChatID)
+
+	return al.runAgentLoop(ctx, agent, processOptions{
+		SessionKey:      sessionKey,
+		Channel:         msg.Channel,
+		ChatID:          msg.ChatID,
+		UserMessage:     user.Content,
+		DefaultResponse: "I've completed processing but have no response to give.",
+		EnableSummary:   true,
+		SendResponse:    false,
+		Overrides:       chat.settings,
+		Principal:       chat.principal,
+	})
+}
@@ -0,0 +1,117 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/session"
+)
+
+// countingProvider numbers its replies so regenerated turns are visible.
+type countingProvider struct {
+	calls int
+}
+
+func (p *countingProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	p.calls++
+	return &providers.LLMResponse{Content: fmt.Sprintf("reply %d", p.calls)}, nil
+}
+
+func (p *countingProvider) GetDefaultModel() string { return "counting-model" }
+
+func TestAgentLoop_SessionCommands(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), &countingProvider{})
+	sessions := al.registry.GetDefaultAgent().Sessions
+	ctx := context.Background()
+	base := "agent:main:main"
+
+	send := func(content string) string {
+		t.Helper()
+		resp, err := al.ProcessDirect(ctx, content, base)
+		if err != nil {
+			t.Fatalf("%s: %v", content, err)
+		}
+		return resp
+	}
+	contents := func(key string) []string {
+		var out []string
+		for _, m := range sessions.GetHistory(key) {
+			out = append(out, m.Content)
+		}
+		return out
+	}
+
+	send("one")
+	send("two")
+	if got := send("/undo"); !strings.Contains(got, `"two"`) {
+		t.Errorf("/undo = %q", got)
+	}
+	if got := contents(base); len(got) != 2 || got[0] != "one" {
+		t.Errorf("history after /undo = %v", got)
+	}
+
+	if got := send("/retry"); got != "reply 3" {
+		t.Errorf("/retry = %q, want a regenerated reply", got)
+	}
+	if got := contents(base); len(got) != 2 || got[1] != "reply 3" {
+		t.Errorf("history after /retry = %v", got)
+	}
+
+	if got := send("/fork idea"); !strings.Contains(got, "switched") {
+		t.Errorf("/fork = %q", got)
+	}
+	send("in the fork")
+	fork := session.BranchKey(base, "idea")
+	if len(contents(base)) != 2 || len(contents(fork)) != 4 {
+		t.Errorf("main = %v, fork = %v", contents(base), contents(fork))
+	}
+
+	list := send("/sessions")
+	if !strings.Contains(list, "* idea (4 messages") || !strings.Contains(list, "  main (2 messages") {
+		t.Errorf("/sessions =\n%s", list)
+	}
+
+	if got := send("/new"); !strings.Contains(got, "archived as archive-") {
+		t.Errorf("/new = %q", got)
+	}
+	if len(contents(fork)) != 0 {
+		t.Errorf("fork after /new = %v", contents(fork))
+	}
+
+	send("/sessions main")
+	send("back in main")
+	if got := contents(base); len(got) != 4 || got[2] != "back in main" {
+		t.Errorf("main after switching back = %v", got)
+	}
+
+	for cmd, want := range map[string]string{
+		"/sessions nope": "No session named nope",
+		"/fork main":     "main session",
+		"/fork":          "Usage",
+	} {
+		if got := send(cmd); !strings.Contains(got, want) {
+			t.Errorf("%s = %q, want it to mention %q", cmd, got, want)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package agent
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+const (
+	SkillActivationSummary   = "summary"
+	SkillActivationRelevance = "relevance"
+
+	defaultMaxActiveSkills   = 3
+	defaultSkillMinScore     = 0.3
+	defaultSkillStickyTurns  = 3
+	defaultActiveSkillsChars = 16000
+
+	// maxSkillSessions bounds the per-session selection state.
+	maxSkillSessions = 256
+)
+
+// skillActivation picks the skills whose content is loaded for each turn.
+// Selections are sticky per session: a skill stays active for stickyTurns
+// turns after it last matched, and active skills keep their order, so the
+// active-skills prompt block only changes when the topic does.
+type skillActivation struct {
+	maxActive   int
+	minScore    float64
+	stickyTurns int
+	maxChars    int
+
+	mu       sync.Mutex
+	sessions map[string]*activeSkills
+	clock    int // increments per selection, for LRU eviction of sessions
+}
+
+type activeSkills struct {
+	names    []string
+	lastHit  map[string]int // skill -> turn it last matched
+	turn     int
+	lastUsed int
+}
+
+// newSkillActivation returns nil unless relevance-based activation is
+// configured.
+func newSkillActivation(cfg *config.SkillActivationConfig) *skillActivation {
+	if cfg == nil || cfg.Mode != SkillActivationRelevance {
+		return nil
+	}
+	a := &skillActivation{
+		maxActive:   defaultMaxActiveSkills,
+		minScore:    defaultSkillMinScore,
+		stickyTurns: defaultSkillStickyTurns,
+		maxChars:    defaultActiveSkillsChars,
+		sessions:    make(map[string]*activeSkills),
+	}
+	if cfg.MaxActive > 0 {
+		a.maxActive = cfg.MaxActive
+	}
+	if cfg.MinScore > 0 {
+		a.minScore = cfg.MinScore
+	}
+	if cfg.StickyTurns > 0 {
+		a.stickyTurns = cfg.StickyTurns
+	}
+	if cfg.MaxChars > 0 {
+		a.maxChars = cfg.MaxChars
+	}
+	return a
+}
+
+// reset drops the selection of a session.
+func (a *skillActivation) reset(sessionKey string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.sessions, sessionKey)
+}
+
+// selectSkills updates the session's active skills with the ranked matches
+// for a new user message and returns them. An empty message (e.g. a retry
+// of the same turn) returns the current selection unchanged.
+func (a *skillActivation) selectSkills(sessionKey, message string, ranked []skills.SkillMatch) []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.clock++
+	s, ok := a.sessions[sessionKey]
+	if !ok {
+		a.evictLocked()
+		s = &activeSkills{lastHit: make(map[string]int)}
+		a.sessions[sessionKey] = s
+	}
+	s.lastUsed = a.clock
+
+	if message == "" {
+		return slices.Clone(s.names)
+	}
+	s.turn++
+
+	hits := 0
+	for _, m := range ranked {
+		if hits == a.maxActive || m.Score < a.minScore {
+			break
+		}
+		hits++
+		s.lastHit[m.Name] = s.turn
+		if !slices.Contains(s.names, m.Name) {
+			s.names = append(s.names, m.Name)
+		}
+	}
+
+	// Expire skills that haven't matched recently.
+	s.names = slices.DeleteFunc(s.names, func(name string) bool {
+		if s.turn-s.lastHit[name] > a.stickyTurns {
+			delete(s.lastHit, name)
+			return true
+		}
+		return false
+	})
+
+	// Over the limit: drop the least recently matched, keeping order.
+	for len(s.names) > a.maxActive {
+		oldest := 0
+		for i, name := range s.names {
+			if s.lastHit[name] < s.lastHit[s.names[oldest]] {
+				oldest = i
+			}
+		}
+		delete(s.lastHit, s.names[oldest])
+		s.names = slices.Delete(s.names, oldest, oldest+1)
+	}
+
+	return slices.Clone(s.names)
+}
+
+// evictLocked drops the least recently used session when the map is full.
+func (a *skillActivation) evictLocked() {
+	if len(a.sessions) < maxSkillSessions {
+		return
+	}
+	var lruKey string
+	lru := a.clock
+	for key, s := range a.sessions {
+		if s.lastUsed < lru {
+			lru, lruKey = s.lastUsed, key
+		}
+	}
+	delete(a.sessions, lruKey)
+}
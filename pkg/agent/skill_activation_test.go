@@ -0,0 +1,131 @@
+package agent
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+func TestNewSkillActivationMode(t *testing.T) {
+	if newSkillActivation(nil) != nil {
+		t.Error("nil config should disable activation")
+	}
+	if newSkillActivation(&config.SkillActivationConfig{Mode: SkillActivationSummary}) != nil {
+		t.Error("summary mode should disable activation")
+	}
+	a := newSkillActivation(&config.SkillActivationConfig{Mode: SkillActivationRelevance, MaxActive: 5})
+	if a == nil {
+		t.Fatal("relevance mode should enable activation")
+	}
+	if a.maxActive != 5 || a.stickyTurns != defaultSkillStickyTurns {
+		t.Errorf("unexpected settings: maxActive=%d stickyTurns=%d", a.maxActive, a.stickyTurns)
+	}
+}
+
+func TestSelectSkillsSticky(t *testing.T) {
+	a := newSkillActivation(&config.SkillActivationConfig{Mode: SkillActivationRelevance, StickyTurns: 2})
+	weather := []skills.SkillMatch{{Name: "weather", Score: 1.5}}
+
+	if got := a.selectSkills("s", "rain?", weather); !equalNames(got, "weather") {
+		t.Fatalf("turn 1: got %v", got)
+	}
+	// Unrelated turns keep the skill for stickyTurns turns...
+	for turn := 2; turn <= 3; turn++ {
+		if got := a.selectSkills("s", "thanks", nil); !equalNames(got, "weather") {
+			t.Fatalf("turn %d: got %v", turn, got)
+		}
+	}
+	// ...then it expires.
+	if got := a.selectSkills("s", "ok", nil); len(got) != 0 {
+		t.Fatalf("turn 4: expected no skills, got %v", got)
+	}
+	// Other sessions are independent.
+	if got := a.selectSkills("other", "", nil); len(got) != 0 {
+		t.Fatalf("other session: got %v", got)
+	}
+}
+
+func TestSelectSkillsLimitAndThreshold(t *testing.T) {
+	a := newSkillActivation(&config.SkillActivationConfig{Mode: SkillActivationRelevance, MaxActive: 2})
+
+	got := a.selectSkills("s", "first", []skills.SkillMatch{
+		{Name: "a", Score: 2}, {Name: "b", Score: 1}, {Name: "c", Score: 0.9},
+	})
+	if !equalNames(got, "a", "b") {
+		t.Fatalf("expected top 2, got %v", got)
+	}
+	got = a.selectSkills("s", "second", []skills.SkillMatch{{Name: "c", Score: 1}, {Name: "low", Score: 0.1}})
+	if !equalNames(got, "a", "c") && !equalNames(got, "b", "c") {
+		t.Fatalf("expected c to replace an older skill, got %v", got)
+	}
+	// An empty message (same turn) leaves the selection alone.
+	if again := a.selectSkills("s", "", nil); !equalNames(again, got...) {
+		t.Fatalf("empty message changed selection: %v -> %v", got, again)
+	}
+}
+
+func TestBuildMessagesActiveSkills(t *testing.T) {
+	tmpDir := setupWorkspace(t, map[string]string{
+		"skills/weather/SKILL.md": "---\nname: weather\ndescription: Weather forecasts\ntriggers: [umbrella]\n---\n" +
+			"Call the forecast API.",
+		"skills/deploy/SKILL.md": "---\nname: deploy\ndescription: Deploy services\n---\nRun make deploy.",
+	})
+	defer os.RemoveAll(tmpDir)
+
+	cb := NewContextBuilder(tmpDir)
+	cb.SetSkillActivation(&config.SkillActivationConfig{Mode: SkillActivationRelevance})
+
+	static := cb.BuildSystemPromptWithCache()
+	if strings.Contains(static, "Call the forecast API.") {
+		t.Error("skill content should not be in the static prompt")
+	}
+	if !strings.Contains(static, "weather") || !strings.Contains(static, "deploy") {
+		t.Error("static prompt should list installed skills")
+	}
+
+	msgs := cb.BuildMessages(nil, "", "do I need an umbrella?", nil, "test", "chat1", "s1")
+	parts := msgs[0].SystemParts
+	if len(parts) != 3 {
+		t.Fatalf("expected static, skills and dynamic blocks, got %d", len(parts))
+	}
+	if parts[0].Text != static {
+		t.Error("static block should be the cached system prompt")
+	}
+	if !strings.Contains(parts[1].Text, "Call the forecast API.") || strings.Contains(parts[1].Text, "make deploy") {
+		t.Errorf("unexpected active skills block: %q", parts[1].Text)
+	}
+	if parts[1].CacheControl == nil || parts[2].CacheControl != nil {
+		t.Error("active skills block should be cached, dynamic context should not")
+	}
+
+	// A follow-up on the same topic keeps the identical skills block.
+	next := cb.BuildMessages(nil, "", "and tomorrow?", nil, "test", "chat1", "s1")
+	if next[0].SystemParts[0].Text != static || next[0].SystemParts[1].Text != parts[1].Text {
+		t.Error("prompt blocks should be unchanged across turns on the same topic")
+	}
+
+	// Without a match in a new session there is no skills block.
+	other := cb.BuildMessages(nil, "", "hello", nil, "test", "chat2", "s2")
+	if len(other[0].SystemParts) != 2 {
+		t.Errorf("expected no skills block, got %d parts", len(other[0].SystemParts))
+	}
+
+	// Selections belong to the session, not the chat: a fork of the same chat
+	// starts without them, and /new clears them.
+	forked := cb.BuildMessages(nil, "", "hello", nil, "test", "chat1", "s1#fork")
+	if len(forked[0].SystemParts) != 2 {
+		t.Errorf("expected no skills block in another session of the chat, got %d parts", len(forked[0].SystemParts))
+	}
+	cb.ResetActiveSkills("s1")
+	reset := cb.BuildMessages(nil, "", "hello", nil, "test", "chat1", "s1")
+	if len(reset[0].SystemParts) != 2 {
+		t.Errorf("expected no skills block after a reset, got %d parts", len(reset[0].SystemParts))
+	}
+}
+
+func equalNames(got []string, want ...string) bool {
+	return strings.Join(got, ",") == strings.Join(want, ",")
+}
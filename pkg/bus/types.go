@@ -11,9 +11,10 @@ type InboundMessage struct {
 }
 
 type OutboundMessage struct {
-	Channel string `json:"channel"`
-	ChatID  string `json:"chat_id"`
-	Content string `json:"content"`
+	Channel   string `json:"channel"`
+	ChatID    string `json:"chat_id"`
+	Content   string `json:"content"`
+	Reasoning string `json:"reasoning,omitempty"` // model thinking, rendered collapsed where the channel supports it
 }
 
 type MessageHandler func(InboundMessage) error
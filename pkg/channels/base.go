@@ -16,6 +16,35 @@ type Channel interface {
 	IsAllowed(senderID string) bool
 }
 
+// reasoningRenderer is implemented by channels that render
+// OutboundMessage.Reasoning natively as a collapsed section.
+type reasoningRenderer interface {
+	rendersReasoning()
+}
+
+// foldReasoning inlines the model's thinking as quoted text ahead of the
+// reply, for channels without a collapsible presentation.
+func foldReasoning(msg bus.OutboundMessage) bus.OutboundMessage {
+	if msg.Reasoning == "" {
+		return msg
+	}
+	lines := strings.Split(strings.TrimSpace(msg.Reasoning), "\n")
+	var sb strings.Builder
+	sb.WriteString("💭 Thinking\n")
+	for _, line := range lines {
+		sb.WriteString("> ")
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	if msg.Content != "" {
+		sb.WriteString("\n")
+		sb.WriteString(msg.Content)
+	}
+	msg.Content = strings.TrimRight(sb.String(), "\n")
+	msg.Reasoning = ""
+	return msg
+}
+
 type BaseChannel struct {
 	config    any
 	bus       *bus.MessageBus
@@ -1,6 +1,10 @@
 package channels
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
 
 func TestBaseChannelIsAllowed(t *testing.T) {
 	tests := []struct {
@@ -50,3 +54,23 @@ func TestBaseChannelIsAllowed(t *testing.T) {
 		})
 	}
 }
+
+func TestFoldReasoning(t *testing.T) {
+	msg := foldReasoning(bus.OutboundMessage{
+		Channel:   "slack",
+		Content:   "It is sunny.",
+		Reasoning: "Check the forecast.\nIt says sun.",
+	})
+	want := "💭 Thinking\n> Check the forecast.\n> It says sun.\n\nIt is sunny."
+	if msg.Content != want {
+		t.Errorf("Content = %q, want %q", msg.Content, want)
+	}
+	if msg.Reasoning != "" {
+		t.Errorf("Reasoning = %q, want cleared", msg.Reasoning)
+	}
+
+	plain := bus.OutboundMessage{Content: "hi"}
+	if got := foldReasoning(plain); got != plain {
+		t.Errorf("foldReasoning changed a message without reasoning: %+v", got)
+	}
+}
@@ -109,6 +109,9 @@ func (c *DiscordChannel) Stop(ctx context.Context) error {
 	return nil
 }
 
+// rendersReasoning marks the channel as showing thinking in a thread.
+func (c *DiscordChannel) rendersReasoning() {}
+
 func (c *DiscordChannel) Send(ctx context.Context, msg bus.OutboundMessage) error {
 	c.stopTyping(msg.ChatID)
 
@@ -121,6 +124,14 @@ func (c *DiscordChannel) Send(ctx context.Context, msg bus.OutboundMessage) erro
 		return fmt.Errorf("channel ID is empty")
 	}
 
+	if msg.Reasoning != "" {
+		if err := c.sendReasoning(ctx, channelID, msg.Reasoning); err != nil {
+			logger.ErrorCF("discord", "Failed to send reasoning", map[string]any{
+				"error": err.Error(),
+			})
+		}
+	}
+
 	runes := []rune(msg.Content)
 	if len(runes) == 0 {
 		return nil
@@ -159,6 +170,38 @@ func (c *DiscordChannel) sendChunk(ctx context.Context, channelID, content strin
 	}
 }
 
+// sendReasoning posts the model's thinking into a thread under a short header
+// message so it stays out of the way. Where threads are unavailable (DMs),
+// it falls back to spoiler-wrapped text in the channel itself.
+func (c *DiscordChannel) sendReasoning(ctx context.Context, channelID, reasoning string) error {
+	sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	header, err := c.session.ChannelMessageSend(channelID, "💭 Thinking", discordgo.WithContext(sendCtx))
+	if err != nil {
+		return fmt.Errorf("failed to send reasoning header: %w", err)
+	}
+
+	target := channelID
+	spoiler := true
+	thread, err := c.session.MessageThreadStart(channelID, header.ID, "Thinking", 60, discordgo.WithContext(sendCtx))
+	if err == nil {
+		target = thread.ID
+		spoiler = false
+	}
+
+	// Leave room for the spoiler markers within Discord's 2000 char limit.
+	for _, chunk := range utils.SplitMessage(reasoning, 1990) {
+		if spoiler {
+			chunk = "||" + chunk + "||"
+		}
+		if err := c.sendChunk(ctx, target, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // appendContent safely appends content to existing text
 func appendContent(content, suffix string) string {
 	if content == "" {
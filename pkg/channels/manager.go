@@ -202,6 +202,19 @@ func (m *Manager) initChannels() error {
 		}
 	}
 
+	if m.config.Channels.Serial.Enabled && m.config.Channels.Serial.Device != "" {
+		logger.DebugC("channels", "Attempting to initialize serial channel")
+		serialChannel, err := NewSerialChannel(m.config.Channels.Serial, m.bus)
+		if err != nil {
+			logger.ErrorCF("channels", "Failed to initialize serial channel", map[string]any{
+				"error": err.Error(),
+			})
+		} else {
+			m.channels["serial"] = serialChannel
+			logger.InfoC("channels", "Serial channel enabled successfully")
+		}
+	}
+
 	logger.InfoCF("channels", "Channel initialization completed", map[string]any{
 		"enabled_channels": len(m.channels),
 	})
@@ -298,6 +311,10 @@ func (m *Manager) dispatchOutbound(ctx context.Context) {
 				continue
 			}
 
+			if _, ok := channel.(reasoningRenderer); !ok {
+				msg = foldReasoning(msg)
+			}
+
 			if err := channel.Send(ctx, msg); err != nil {
 				logger.ErrorCF("channels", "Error sending message to channel", map[string]any{
 					"channel": msg.Channel,
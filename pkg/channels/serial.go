@@ -0,0 +1,257 @@
+package channels
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/devices/serial"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	serialReopenInterval = 5 * time.Second
+	serialMaxLineLength  = 4096
+)
+
+// SerialChannel exchanges line-delimited messages over a serial port. Each
+// line received is a chat message; replies are written back as lines. The
+// port is reopened if it goes away, e.g. when a USB adapter is unplugged.
+type SerialChannel struct {
+	*BaseChannel
+	config     config.SerialConfig
+	portConfig serial.Config
+	lineEnding string
+	reopen     time.Duration
+
+	mu     sync.Mutex
+	port   *serial.Port
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewSerialChannel(cfg config.SerialConfig, bus *bus.MessageBus) (*SerialChannel, error) {
+	if cfg.Device == "" {
+		return nil, fmt.Errorf("serial device is required")
+	}
+	portConfig := serial.Config{
+		Baud:     cfg.Baud,
+		DataBits: cfg.DataBits,
+		Parity:   cfg.Parity,
+		StopBits: cfg.StopBits,
+	}
+	if err := portConfig.Validate(); err != nil {
+		return nil, err
+	}
+	lineEnding := "\n"
+	switch cfg.LineEnding {
+	case "", "lf":
+	case "crlf":
+		lineEnding = "\r\n"
+	default:
+		return nil, fmt.Errorf("line_ending must be lf or crlf, got %q", cfg.LineEnding)
+	}
+
+	return &SerialChannel{
+		BaseChannel: NewBaseChannel("serial", cfg, bus, nil),
+		config:      cfg,
+		portConfig:  portConfig,
+		lineEnding:  lineEnding,
+		reopen:      serialReopenInterval,
+	}, nil
+}
+
+func (c *SerialChannel) Start(ctx context.Context) error {
+	logger.InfoCF("serial", "Starting serial channel", map[string]any{
+		"device":   c.config.Device,
+		"settings": c.portConfig.String(),
+	})
+
+	// A missing device is not fatal; it is retried until it appears.
+	if err := c.openPort(ctx); err != nil {
+		logger.WarnCF("serial", "Serial device not available, will retry", map[string]any{
+			"device": c.config.Device,
+			"error":  err.Error(),
+		})
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	c.mu.Lock()
+	c.cancel = cancel
+	c.done = done
+	c.mu.Unlock()
+	c.setRunning(true)
+
+	go c.run(runCtx, done)
+	return nil
+}
+
+func (c *SerialChannel) Stop(ctx context.Context) error {
+	logger.InfoC("serial", "Stopping serial channel")
+	c.setRunning(false)
+
+	c.mu.Lock()
+	cancel, done := c.cancel, c.done
+	c.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+
+	// Cancelling closes the port, which ends the pending read.
+	cancel()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+func (c *SerialChannel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	if !c.IsRunning() {
+		return fmt.Errorf("serial channel not running")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.port == nil {
+		return fmt.Errorf("serial device %s is not connected", c.config.Device)
+	}
+	if _, err := c.port.Write(c.frame(msg.Content)); err != nil {
+		return fmt.Errorf("failed to write to %s: %w", c.config.Device, err)
+	}
+	return nil
+}
+
+// frame turns a reply into lines terminated by the configured line ending.
+func (c *SerialChannel) frame(content string) []byte {
+	content = strings.TrimRight(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	return []byte(strings.ReplaceAll(content, "\n", c.lineEnding) + c.lineEnding)
+}
+
+func (c *SerialChannel) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	// Close the port on cancellation to unblock a pending read.
+	go func() {
+		<-ctx.Done()
+		c.mu.Lock()
+		c.closePortLocked()
+		c.mu.Unlock()
+	}()
+
+	for ctx.Err() == nil {
+		c.mu.Lock()
+		port := c.port
+		c.mu.Unlock()
+
+		if port == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(c.reopen):
+			}
+			if err := c.openPort(ctx); err != nil {
+				logger.DebugCF("serial", "Serial device still not available", map[string]any{
+					"device": c.config.Device,
+					"error":  err.Error(),
+				})
+				continue
+			}
+			logger.InfoCF("serial", "Serial device connected", map[string]any{"device": c.config.Device})
+			continue
+		}
+
+		err := c.readLines(ctx, port)
+		if ctx.Err() != nil {
+			return
+		}
+		logger.WarnCF("serial", "Serial device disconnected", map[string]any{
+			"device": c.config.Device,
+			"error":  err.Error(),
+		})
+		c.mu.Lock()
+		if c.port == port {
+			c.closePortLocked()
+		}
+		c.mu.Unlock()
+	}
+}
+
+// readLines delivers lines from port until a read fails.
+func (c *SerialChannel) readLines(ctx context.Context, port *serial.Port) error {
+	var line []byte
+	buf := make([]byte, 1024)
+	for {
+		n, err := port.Read(buf)
+		if err != nil {
+			if errors.Is(err, os.ErrClosed) && ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		data := buf[:n]
+		for len(data) > 0 {
+			i := bytes.IndexByte(data, '\n')
+			if i < 0 {
+				line = append(line, data...)
+				if len(line) >= serialMaxLineLength {
+					c.handleLine(string(line))
+					line = line[:0]
+				}
+				break
+			}
+			line = append(line, data[:i]...)
+			c.handleLine(string(line))
+			line = line[:0]
+			data = data[i+1:]
+		}
+	}
+}
+
+func (c *SerialChannel) handleLine(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+	senderID := filepath.Base(c.config.Device)
+	logger.DebugCF("serial", "Received line", map[string]any{
+		"device": c.config.Device,
+		"length": len(line),
+	})
+	c.HandleMessage(senderID, c.config.Device, line, nil, map[string]string{
+		"peer_kind": "direct",
+		"peer_id":   senderID,
+	})
+}
+
+func (c *SerialChannel) openPort(ctx context.Context) error {
+	port, err := serial.Open(c.config.Device, c.portConfig)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Stop may have run while the port was opening.
+	if ctx.Err() != nil {
+		port.Close()
+		return ctx.Err()
+	}
+	c.closePortLocked()
+	c.port = port
+	return nil
+}
+
+func (c *SerialChannel) closePortLocked() {
+	if c.port != nil {
+		c.port.Close()
+		c.port = nil
+	}
+}
@@ -0,0 +1,109 @@
+//go:build linux
+
+package channels
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/devices/serial"
+)
+
+func startTestSerialChannel(t *testing.T, lineEnding string) (*SerialChannel, *bus.MessageBus, *os.File) {
+	t.Helper()
+	master, path, err := serial.OpenPTY()
+	if err != nil {
+		t.Skipf("pseudo-terminals not available: %v", err)
+	}
+	t.Cleanup(func() { master.Close() })
+
+	mb := bus.NewMessageBus()
+	ch, err := NewSerialChannel(config.SerialConfig{Device: path, Baud: 9600, LineEnding: lineEnding}, mb)
+	if err != nil {
+		t.Fatalf("NewSerialChannel() error: %v", err)
+	}
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	t.Cleanup(func() { ch.Stop(context.Background()) })
+	return ch, mb, master
+}
+
+func TestSerialChannelReceivesLines(t *testing.T) {
+	_, mb, master := startTestSerialChannel(t, "")
+
+	// A line split across writes, a blank line and a CRLF line.
+	master.Write([]byte("temp="))
+	master.Write([]byte("21.5\n\r\nstatus?\r\n"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	for _, want := range []string{"temp=21.5", "status?"} {
+		msg, ok := mb.ConsumeInbound(ctx)
+		if !ok {
+			t.Fatalf("no inbound message for %q", want)
+		}
+		if msg.Content != want || msg.Channel != "serial" {
+			t.Errorf("inbound = %+v, want content %q", msg, want)
+		}
+		if msg.ChatID == "" || msg.SenderID == "" {
+			t.Errorf("inbound message missing chat or sender: %+v", msg)
+		}
+	}
+}
+
+func TestSerialChannelSend(t *testing.T) {
+	ch, _, master := startTestSerialChannel(t, "crlf")
+
+	if err := ch.Send(context.Background(), bus.OutboundMessage{Content: "line one\nline two\n"}); err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+
+	want := "line one\r\nline two\r\n"
+	var got []byte
+	buf := make([]byte, 64)
+	master.SetReadDeadline(time.Now().Add(time.Second))
+	for len(got) < len(want) {
+		n, err := master.Read(buf)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		got = append(got, buf[:n]...)
+	}
+	if string(got) != want {
+		t.Errorf("device received %q, want %q", got, want)
+	}
+}
+
+func TestSerialChannelStop(t *testing.T) {
+	ch, _, _ := startTestSerialChannel(t, "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := ch.Stop(ctx); err != nil {
+		t.Fatalf("Stop() error: %v", err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("Stop() did not end the reader")
+	}
+	if err := ch.Send(context.Background(), bus.OutboundMessage{Content: "x"}); err == nil {
+		t.Error("Send() after Stop() should fail")
+	}
+}
+
+func TestNewSerialChannelValidation(t *testing.T) {
+	mb := bus.NewMessageBus()
+	for _, cfg := range []config.SerialConfig{
+		{},
+		{Device: "/dev/ttyUSB0", Parity: "mark"},
+		{Device: "/dev/ttyUSB0", LineEnding: "cr"},
+	} {
+		if _, err := NewSerialChannel(cfg, mb); err == nil {
+			t.Errorf("NewSerialChannel(%+v) expected error", cfg)
+		}
+	}
+}
@@ -146,6 +146,9 @@ func (c *TelegramChannel) Stop(ctx context.Context) error {
 	return nil
 }
 
+// rendersReasoning marks the channel as showing thinking in a spoiler.
+func (c *TelegramChannel) rendersReasoning() {}
+
 func (c *TelegramChannel) Send(ctx context.Context, msg bus.OutboundMessage) error {
 	if !c.IsRunning() {
 		return fmt.Errorf("telegram bot not running")
@@ -164,7 +167,7 @@ func (c *TelegramChannel) Send(ctx context.Context, msg bus.OutboundMessage) err
 		c.stopThinking.Delete(msg.ChatID)
 	}
 
-	htmlContent := markdownToTelegramHTML(msg.Content)
+	htmlContent := reasoningToTelegramHTML(msg.Reasoning) + markdownToTelegramHTML(msg.Content)
 
 	// Try to edit placeholder
 	if pID, ok := c.placeholders.Load(msg.ChatID); ok {
@@ -521,6 +524,22 @@ func extractInlineCodes(text string) inlineCodeMatch {
 	return inlineCodeMatch{text: text, codes: codes}
 }
 
+// telegramReasoningLimit keeps the thinking section well inside Telegram's
+// 4096 character message limit, leaving room for the reply itself.
+const telegramReasoningLimit = 2000
+
+// reasoningToTelegramHTML renders model thinking as a collapsed spoiler section.
+func reasoningToTelegramHTML(reasoning string) string {
+	reasoning = strings.TrimSpace(reasoning)
+	if reasoning == "" {
+		return ""
+	}
+	if runes := []rune(reasoning); len(runes) > telegramReasoningLimit {
+		reasoning = string(runes[:telegramReasoningLimit]) + "…"
+	}
+	return "<b>💭 Thinking</b>\n<tg-spoiler>" + escapeHTML(reasoning) + "</tg-spoiler>\n\n"
+}
+
 func escapeHTML(text string) string {
 	text = strings.ReplaceAll(text, "&", "&amp;")
 	text = strings.ReplaceAll(text, "<", "&lt;")
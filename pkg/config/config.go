@@ -50,6 +50,7 @@ type Config struct {
 	Agents    AgentsConfig    `json:"agents"`
 	Bindings  []AgentBinding  `json:"bindings,omitempty"`
 	Session   SessionConfig   `json:"session,omitempty"`
+	Access    *AccessConfig   `json:"access,omitempty"`
 	Channels  ChannelsConfig  `json:"channels"`
 	Providers ProvidersConfig `json:"providers,omitempty"`
 	ModelList []ModelConfig   `json:"model_list"` // New model-centric provider configuration
@@ -77,7 +78,7 @@ func (c Config) MarshalJSON() ([]byte, error) {
 	}
 
 	// Only include session if not empty
-	if c.Session.DMScope != "" || len(c.Session.IdentityLinks) > 0 {
+	if c.Session.DMScope != "" || len(c.Session.IdentityLinks) > 0 || len(c.Session.Admins) > 0 {
 		aux.Session = &c.Session
 	}
 
@@ -136,6 +137,7 @@ type AgentConfig struct {
 	Model     *AgentModelConfig `json:"model,omitempty"`
 	Skills    []string          `json:"skills,omitempty"`
 	Subagents *SubagentsConfig  `json:"subagents,omitempty"`
+	Reasoning *ReasoningConfig  `json:"reasoning,omitempty"` // Overrides agents.defaults.reasoning
 }
 
 type SubagentsConfig struct {
@@ -164,6 +166,27 @@ type AgentBinding struct {
 type SessionConfig struct {
 	DMScope       string              `json:"dm_scope,omitempty"`
 	IdentityLinks map[string][]string `json:"identity_links,omitempty"`
+	Admins        []string            `json:"admins,omitempty"` // Sender IDs, "channel:id" or identity_links names allowed to run /global
+}
+
+// AccessConfig assigns roles to senders across channels. Without it, every
+// sender a channel allows has full access.
+type AccessConfig struct {
+	DefaultRole string                `json:"default_role,omitempty"` // Role for senders not listed in members
+	Members     map[string]string     `json:"members,omitempty"`      // Sender ID, "channel:id" or identity_links name -> role
+	Roles       map[string]RolePolicy `json:"roles"`
+}
+
+// RolePolicy limits what a role may do. An omitted (null) list allows
+// everything and an empty list nothing; "*" matches any name.
+type RolePolicy struct {
+	Admin            bool     `json:"admin,omitempty"` // May change global settings with /global
+	Agents           []string `json:"agents"`
+	Tools            []string `json:"tools"`
+	Commands         []string `json:"commands"` // Slash commands, e.g. "/switch"
+	Models           []string `json:"models"`   // model_list names for /switch model
+	MessagesPerHour  int      `json:"messages_per_hour,omitempty"`
+	ToolCallsPerHour int      `json:"tool_calls_per_hour,omitempty"`
 }
 
 type AgentDefaults struct {
@@ -179,6 +202,77 @@ type AgentDefaults struct {
 	MaxTokensFallback   int      `json:"max_tokens_fallback"             env:"PICOCLAW_AGENTS_DEFAULTS_MAX_TOKENS_FALLBACK"`
 	Temperature         *float64 `json:"temperature,omitempty"           env:"PICOCLAW_AGENTS_DEFAULTS_TEMPERATURE"`
 	MaxToolIterations   int      `json:"max_tool_iterations"             env:"PICOCLAW_AGENTS_DEFAULTS_MAX_TOOL_ITERATIONS"`
+
+	Routing         *ModelRoutingConfig    `json:"routing,omitempty"`
+	Reasoning       *ReasoningConfig       `json:"reasoning,omitempty"`
+	SkillActivation *SkillActivationConfig `json:"skill_activation,omitempty"`
+}
+
+// SkillActivationConfig selects which skills are put in context. Mode
+// "summary" (default) lists every skill and lets the model read SKILL.md
+// itself; "relevance" ranks skills against each user message, loads the full
+// content of the best matches and keeps only a compact list of the rest.
+// Matched skills stay loaded for StickyTurns turns so the prompt prefix, and
+// with it the provider's prompt cache, stays stable across a conversation.
+type SkillActivationConfig struct {
+	Mode        string  `json:"mode,omitempty"`
+	MaxActive   int     `json:"max_active,omitempty"`   // default 3
+	MinScore    float64 `json:"min_score,omitempty"`    // default 0.3
+	StickyTurns int     `json:"sticky_turns,omitempty"` // default 3
+	MaxChars    int     `json:"max_chars,omitempty"`    // default 16000, content budget for loaded skills
+}
+
+// ReasoningConfig enables extended thinking for models that support it.
+// Effort ("low", "medium", "high") is sent as-is to providers that take a level
+// (OpenAI reasoning_effort, Gemini 3) and converted to a token budget for those
+// that take one (Anthropic, Gemini 2.5, Qwen); BudgetTokens, when set, wins there.
+// Display maps a channel name to how the model's thinking is shown to users:
+// "off" (default) or "collapsed" (e.g. a Telegram spoiler or a Discord thread).
+type ReasoningConfig struct {
+	Effort       string            `json:"effort,omitempty"`
+	BudgetTokens int               `json:"budget_tokens,omitempty"`
+	Display      map[string]string `json:"display,omitempty"`
+}
+
+// DisplayMode returns the thinking display mode for a channel ("off" if unset).
+func (r *ReasoningConfig) DisplayMode(channel string) string {
+	if r == nil {
+		return "off"
+	}
+	if mode, ok := r.Display[channel]; ok && mode != "" {
+		return mode
+	}
+	return "off"
+}
+
+// ModelRoutingConfig configures per-turn model selection across model_list tiers.
+// Tiers map a tier name (e.g. "local", "standard", "frontier") to a model_list model_name.
+// Rules are evaluated in order and the first match wins; if none match, the optional
+// classifier is consulted, and finally DefaultTier is used (empty means the agent's model).
+type ModelRoutingConfig struct {
+	Enabled     bool                    `json:"enabled"`
+	Tiers       map[string]string       `json:"tiers"`
+	DefaultTier string                  `json:"default_tier,omitempty"`
+	Rules       []ModelRoutingRule      `json:"rules,omitempty"`
+	Classifier  *ModelRoutingClassifier `json:"classifier,omitempty"`
+}
+
+// ModelRoutingRule selects a tier when all of its configured conditions match.
+// Hints are explicit user prefixes (e.g. "/deep") that are stripped from the message.
+type ModelRoutingRule struct {
+	Tier     string   `json:"tier"`
+	Hints    []string `json:"hints,omitempty"`
+	MinChars int      `json:"min_chars,omitempty"`
+	MaxChars int      `json:"max_chars,omitempty"`
+	HasMedia *bool    `json:"has_media,omitempty"`
+	Keywords []string `json:"keywords,omitempty"`
+	Tools    []string `json:"tools,omitempty"` // matches when the message mentions one of these tools
+}
+
+// ModelRoutingClassifier asks a cheap model to pick a tier when no rule matches.
+type ModelRoutingClassifier struct {
+	Model          string `json:"model"` // model_list model_name
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"`
 }
 
 // GetModelName returns the effective model name for the agent defaults.
@@ -203,6 +297,7 @@ type ChannelsConfig struct {
 	OneBot   OneBotConfig   `json:"onebot"`
 	WeCom    WeComConfig    `json:"wecom"`
 	WeComApp WeComAppConfig `json:"wecom_app"`
+	Serial   SerialConfig   `json:"serial"`
 }
 
 type WhatsAppConfig struct {
@@ -307,14 +402,62 @@ type WeComAppConfig struct {
 	ReplyTimeout   int                 `json:"reply_timeout"    env:"PICOCLAW_CHANNELS_WECOM_APP_REPLY_TIMEOUT"`
 }
 
+// SerialConfig treats each line received on a serial port as a chat message
+// and writes replies back as lines, so a microcontroller can talk to the
+// agent over a UART.
+type SerialConfig struct {
+	Enabled    bool   `json:"enabled"     env:"PICOCLAW_CHANNELS_SERIAL_ENABLED"`
+	Device     string `json:"device"      env:"PICOCLAW_CHANNELS_SERIAL_DEVICE"`
+	Baud       int    `json:"baud"        env:"PICOCLAW_CHANNELS_SERIAL_BAUD"`
+	DataBits   int    `json:"data_bits"   env:"PICOCLAW_CHANNELS_SERIAL_DATA_BITS"`
+	Parity     string `json:"parity"      env:"PICOCLAW_CHANNELS_SERIAL_PARITY"` // "none", "even" or "odd"
+	StopBits   int    `json:"stop_bits"   env:"PICOCLAW_CHANNELS_SERIAL_STOP_BITS"`
+	LineEnding string `json:"line_ending" env:"PICOCLAW_CHANNELS_SERIAL_LINE_ENDING"` // "lf" (default) or "crlf"
+}
+
 type HeartbeatConfig struct {
 	Enabled  bool `json:"enabled"  env:"PICOCLAW_HEARTBEAT_ENABLED"`
 	Interval int  `json:"interval" env:"PICOCLAW_HEARTBEAT_INTERVAL"` // minutes, min 5
 }
 
 type DevicesConfig struct {
-	Enabled    bool `json:"enabled"     env:"PICOCLAW_DEVICES_ENABLED"`
-	MonitorUSB bool `json:"monitor_usb" env:"PICOCLAW_DEVICES_MONITOR_USB"`
+	Enabled           bool               `json:"enabled"                      env:"PICOCLAW_DEVICES_ENABLED"`
+	MonitorUSB        bool               `json:"monitor_usb"                  env:"PICOCLAW_DEVICES_MONITOR_USB"`
+	USBBackend        string             `json:"usb_backend,omitempty"        env:"PICOCLAW_DEVICES_USB_BACKEND"` // "udevadm" (default) or "netlink"
+	MonitorBluetooth  bool               `json:"monitor_bluetooth,omitempty"  env:"PICOCLAW_DEVICES_MONITOR_BLUETOOTH"`
+	MonitorSubsystems []string           `json:"monitor_subsystems,omitempty"`
+	MonitorNetwork    bool               `json:"monitor_network,omitempty"    env:"PICOCLAW_DEVICES_MONITOR_NETWORK"`
+	MonitorPower      bool               `json:"monitor_power,omitempty"      env:"PICOCLAW_DEVICES_MONITOR_POWER"`
+	PowerPollSeconds  int                `json:"power_poll_seconds,omitempty" env:"PICOCLAW_DEVICES_POWER_POLL_SECONDS"` // default 30
+	GPIO              []GPIOEventConfig  `json:"gpio,omitempty"`
+	Rules             []DeviceRuleConfig `json:"rules,omitempty"`
+}
+
+// GPIOEventConfig watches a GPIO input line for edges.
+type GPIOEventConfig struct {
+	Name       string `json:"name,omitempty"`
+	Chip       string `json:"chip,omitempty"` // default "gpiochip0"
+	Line       uint32 `json:"line"`
+	Edge       string `json:"edge,omitempty"` // "rising", "falling" or "both" (default)
+	ActiveLow  bool   `json:"active_low,omitempty"`
+	DebounceMs int    `json:"debounce_ms,omitempty"`
+}
+
+// DeviceRuleConfig runs an agent prompt when a device event matches. Empty
+// match fields match anything.
+type DeviceRuleConfig struct {
+	Name       string `json:"name"`
+	Kind       string `json:"kind,omitempty"`       // "usb", ...
+	Action     string `json:"action,omitempty"`     // "add", "remove", "change"
+	VendorID   string `json:"vendor_id,omitempty"`  // e.g. "0781"
+	ProductID  string `json:"product_id,omitempty"` // e.g. "5567"
+	Serial     string `json:"serial,omitempty"`
+	Capability string `json:"capability,omitempty"` // substring, e.g. "mass storage"
+	Prompt     string `json:"prompt,omitempty"`
+	Skill      string `json:"skill,omitempty"`
+	Channel    string `json:"channel,omitempty"` // default: last active channel
+	ChatID     string `json:"chat_id,omitempty"`
+	Debounce   int    `json:"debounce_seconds,omitempty"` // default 2
 }
 
 type ProvidersConfig struct {
@@ -387,7 +530,7 @@ type OpenAIProviderConfig struct {
 // ModelConfig represents a model-centric provider configuration.
 // It allows adding new providers (especially OpenAI-compatible ones) via configuration only.
 // The model field uses protocol prefix format: [protocol/]model-identifier
-// Supported protocols: openai, anthropic, antigravity, claude-cli, codex-cli, github-copilot
+// Supported protocols: openai, anthropic, gemini, ollama, antigravity, claude-cli, codex-cli, github-copilot
 // Default protocol is "openai" if no prefix is specified.
 type ModelConfig struct {
 	// Required fields
@@ -404,6 +547,15 @@ type ModelConfig struct {
 	ConnectMode string `json:"connect_mode,omitempty"` // Connection mode: stdio, grpc
 	Workspace   string `json:"workspace,omitempty"`    // Workspace path for CLI-based providers
 
+	// Ollama-specific
+	KeepAlive string `json:"keep_alive,omitempty"` // How long Ollama keeps the model loaded (e.g. "10m", "-1")
+
+	// Gemini-specific
+	SafetySettings map[string]string `json:"safety_settings,omitempty"` // Harm category -> block threshold (e.g. "HARM_CATEGORY_HARASSMENT": "BLOCK_ONLY_HIGH")
+
+	// Record/replay (record/ and replay/ prefixes)
+	Cassette string `json:"cassette,omitempty"` // Cassette file (default: <workspace>/cassettes/<model_name>.json)
+
 	// Optional optimizations
 	RPM            int    `json:"rpm,omitempty"`              // Requests per minute limit
 	MaxTokensField string `json:"max_tokens_field,omitempty"` // Field name for max tokens (e.g., "max_completion_tokens")
@@ -461,6 +613,7 @@ type WebToolsConfig struct {
 
 type CronToolsConfig struct {
 	ExecTimeoutMinutes int `json:"exec_timeout_minutes" env:"PICOCLAW_TOOLS_CRON_EXEC_TIMEOUT_MINUTES"` // 0 means no timeout
+	MaxConcurrentJobs  int `json:"max_concurrent_jobs" env:"PICOCLAW_TOOLS_CRON_MAX_CONCURRENT_JOBS"`
 }
 
 type ExecConfig struct {
@@ -469,16 +622,101 @@ type ExecConfig struct {
 }
 
 type ToolsConfig struct {
-	Web    WebToolsConfig    `json:"web"`
-	Cron   CronToolsConfig   `json:"cron"`
-	Exec   ExecConfig        `json:"exec"`
-	Skills SkillsToolsConfig `json:"skills"`
+	Web      WebToolsConfig      `json:"web"`
+	Cron     CronToolsConfig     `json:"cron"`
+	Exec     ExecConfig          `json:"exec"`
+	Skills   SkillsToolsConfig   `json:"skills"`
+	Hardware HardwareToolsConfig `json:"hardware"`
+}
+
+// HardwareToolsConfig lists the GPIO lines, PWM channels, ADC channels and
+// serial ports the agent may use. Each tool is only registered when its
+// allowlist is not empty.
+type HardwareToolsConfig struct {
+	GPIO   GPIOToolConfig   `json:"gpio"`
+	PWM    PWMToolConfig    `json:"pwm"`
+	ADC    ADCToolConfig    `json:"adc"`
+	Serial SerialToolConfig `json:"serial"`
+}
+
+type GPIOToolConfig struct {
+	Lines []GPIOToolLine `json:"lines,omitempty"`
+}
+
+// GPIOToolLine allows a GPIO line. Lines are read-only unless Output is set.
+type GPIOToolLine struct {
+	Name      string `json:"name,omitempty"`
+	Chip      string `json:"chip,omitempty"` // default "gpiochip0"
+	Line      uint32 `json:"line"`
+	Output    bool   `json:"output,omitempty"`
+	ActiveLow bool   `json:"active_low,omitempty"`
+}
+
+type PWMToolConfig struct {
+	Channels []PWMToolChannel `json:"channels,omitempty"`
+}
+
+// PWMToolChannel allows channel Channel of /sys/class/pwm/pwmchip<Chip>,
+// optionally bounding the period and duty cycle that may be set.
+type PWMToolChannel struct {
+	Name           string  `json:"name,omitempty"`
+	Chip           int     `json:"chip"`
+	Channel        int     `json:"channel"`
+	MinPeriodNs    int64   `json:"min_period_ns,omitempty"`
+	MaxPeriodNs    int64   `json:"max_period_ns,omitempty"`
+	MaxDutyPercent float64 `json:"max_duty_percent,omitempty"`
+}
+
+type ADCToolConfig struct {
+	Channels []ADCToolChannel `json:"channels,omitempty"`
+}
+
+// ADCToolChannel allows an IIO channel, e.g. Device "iio:device0" and
+// Channel "voltage0" for in_voltage0_raw.
+type ADCToolChannel struct {
+	Name    string `json:"name,omitempty"`
+	Device  string `json:"device"`
+	Channel string `json:"channel"`
+}
+
+type SerialToolConfig struct {
+	Ports []SerialToolPort `json:"ports,omitempty"`
+}
+
+// SerialToolPort allows a serial device such as "/dev/ttyUSB0". The line
+// settings are defaults; the agent can change them when opening the port.
+type SerialToolPort struct {
+	Name     string `json:"name,omitempty"`
+	Device   string `json:"device"`
+	Baud     int    `json:"baud,omitempty"`      // default 115200
+	DataBits int    `json:"data_bits,omitempty"` // default 8
+	Parity   string `json:"parity,omitempty"`    // "none" (default), "even" or "odd"
+	StopBits int    `json:"stop_bits,omitempty"` // default 1
 }
 
 type SkillsToolsConfig struct {
 	Registries            SkillsRegistriesConfig `json:"registries"`
 	MaxConcurrentSearches int                    `json:"max_concurrent_searches" env:"PICOCLAW_SKILLS_MAX_CONCURRENT_SEARCHES"`
 	SearchCache           SearchCacheConfig      `json:"search_cache"`
+	ScriptTools           SkillScriptToolsConfig `json:"script_tools"`
+	Trust                 SkillTrustConfig       `json:"trust"`
+}
+
+// SkillTrustConfig configures signature verification of skills. Keys maps a
+// publisher key ID to its base64 ed25519 public key. Policies maps a registry
+// name ("clawhub", "github"), "local" or "default" to "require-signed",
+// "warn" or "off".
+type SkillTrustConfig struct {
+	Keys     map[string]string `json:"keys,omitempty"`
+	Policies map[string]string `json:"policies,omitempty"`
+}
+
+// SkillScriptToolsConfig controls tools declared in skill frontmatter, which
+// run the skill's own scripts.
+type SkillScriptToolsConfig struct {
+	Enabled        bool     `json:"enabled"         env:"PICOCLAW_SKILLS_SCRIPT_TOOLS_ENABLED"`
+	TimeoutSeconds int      `json:"timeout_seconds" env:"PICOCLAW_SKILLS_SCRIPT_TOOLS_TIMEOUT_SECONDS"`
+	AllowedEnv     []string `json:"allowed_env"     env:"PICOCLAW_SKILLS_SCRIPT_TOOLS_ALLOWED_ENV"`
 }
 
 type SearchCacheConfig struct {
@@ -487,7 +725,29 @@ type SearchCacheConfig struct {
 }
 
 type SkillsRegistriesConfig struct {
-	ClawHub ClawHubRegistryConfig `json:"clawhub"`
+	ClawHub ClawHubRegistryConfig      `json:"clawhub"`
+	Git     []GitSkillRegistryConfig   `json:"git,omitempty"`
+	Index   []IndexSkillRegistryConfig `json:"index,omitempty"`
+}
+
+// GitSkillRegistryConfig is a skill registry backed by a Git repository,
+// indexed from the SKILL.md files in its subdirectories.
+type GitSkillRegistryConfig struct {
+	Name            string `json:"name"`
+	URL             string `json:"url"`
+	Ref             string `json:"ref,omitempty"`
+	CacheDir        string `json:"cache_dir,omitempty"`
+	RefreshInterval int    `json:"refresh_interval,omitempty"`
+}
+
+// IndexSkillRegistryConfig is a skill registry served as a static JSON index.
+type IndexSkillRegistryConfig struct {
+	Name            string `json:"name"`
+	URL             string `json:"url"`
+	AuthToken       string `json:"auth_token,omitempty"`
+	Timeout         int    `json:"timeout,omitempty"`
+	MaxZipSize      int    `json:"max_zip_size,omitempty"`
+	MaxResponseSize int    `json:"max_response_size,omitempty"`
 }
 
 type ClawHubRegistryConfig struct {
@@ -114,6 +114,12 @@ func DefaultConfig() *Config {
 				AllowFrom:      FlexibleStringSlice{},
 				ReplyTimeout:   5,
 			},
+			Serial: SerialConfig{
+				Enabled:    false,
+				Device:     "",
+				Baud:       115200,
+				LineEnding: "lf",
+			},
 		},
 		Providers: ProvidersConfig{
 			OpenAI: OpenAIProviderConfig{WebSearch: true},
@@ -252,7 +258,7 @@ func DefaultConfig() *Config {
 			{
 				ModelName: "llama3",
 				Model:     "ollama/llama3",
-				APIBase:   "http://localhost:11434/v1",
+				APIBase:   "http://localhost:11434",
 				APIKey:    "ollama",
 			},
 
@@ -296,6 +302,7 @@ func DefaultConfig() *Config {
 			},
 			Cron: CronToolsConfig{
 				ExecTimeoutMinutes: 5,
+				MaxConcurrentJobs:  4,
 			},
 			Exec: ExecConfig{
 				EnableDenyPatterns: true,
@@ -312,6 +319,10 @@ func DefaultConfig() *Config {
 					MaxSize:    50,
 					TTLSeconds: 300,
 				},
+				ScriptTools: SkillScriptToolsConfig{
+					Enabled:        true,
+					TimeoutSeconds: 30,
+				},
 			},
 		},
 		Heartbeat: HeartbeatConfig{
@@ -0,0 +1,377 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// Concurrency policies decide what happens when a job comes due while an
+// earlier run of it is still queued or running, as in Kubernetes CronJob.
+const (
+	ConcurrencyAllow   = "allow"   // start another run alongside
+	ConcurrencyForbid  = "forbid"  // skip the new run (default)
+	ConcurrencyReplace = "replace" // cancel the earlier run and start the new one
+)
+
+const defaultMaxConcurrent = 4
+
+var (
+	errReplaced = errors.New("replaced by a newer run")
+	errStopped  = errors.New("cron service stopped")
+)
+
+// RunningJob describes a run that is waiting for a worker or executing.
+type RunningJob struct {
+	JobID       string `json:"jobId"`
+	Name        string `json:"name"`
+	Queued      bool   `json:"queued"`
+	Manual      bool   `json:"manual,omitempty"`
+	StartedAtMS int64  `json:"startedAtMs,omitempty"`
+	DeadlineMS  int64  `json:"deadlineMs,omitempty"`
+}
+
+type activeRun struct {
+	job    CronJob // snapshot taken at dispatch
+	manual bool
+	// afterRun defers scheduling the next run until this one finishes,
+	// for one-time jobs and catch-up runs.
+	afterRun bool
+	ctx      context.Context
+	cancel   context.CancelCauseFunc
+	info     RunningJob
+}
+
+// SetMaxConcurrent sets the number of jobs that may run at once. Call it
+// before Start.
+func (cs *CronService) SetMaxConcurrent(n int) {
+	if n <= 0 {
+		n = defaultMaxConcurrent
+	}
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.slots = make(chan struct{}, n)
+}
+
+// Running returns the runs that are queued or executing.
+func (cs *CronService) Running() []RunningJob {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	var out []RunningJob
+	for _, runs := range cs.active {
+		for _, run := range runs {
+			out = append(out, run.info)
+		}
+	}
+	return out
+}
+
+// RunJob runs a job immediately, regardless of its schedule, enabled state
+// or concurrency policy, and returns the recorded run.
+func (cs *CronService) RunJob(jobID string) (*CronRun, error) {
+	cs.mu.Lock()
+	job := cs.findJobUnsafe(jobID)
+	if job == nil {
+		cs.mu.Unlock()
+		return nil, fmt.Errorf("job %s not found", jobID)
+	}
+	run := cs.trackUnsafe(job, true, false)
+	cs.mu.Unlock()
+
+	return cs.execute(run, nil), nil
+}
+
+func (cs *CronService) checkJobs() {
+	cs.mu.Lock()
+
+	if !cs.running {
+		cs.mu.Unlock()
+		return
+	}
+
+	now := time.Now().UnixMilli()
+	var runs []*activeRun
+	for i := range cs.store.Jobs {
+		job := &cs.store.Jobs[i]
+		if job.Enabled && job.State.NextRunAtMS != nil && *job.State.NextRunAtMS <= now {
+			if run := cs.dispatchUnsafe(job, now); run != nil {
+				runs = append(runs, run)
+			}
+		}
+	}
+
+	if len(runs) > 0 {
+		if err := cs.saveStoreUnsafe(); err != nil {
+			log.Printf("[cron] failed to save store: %v", err)
+		}
+	}
+	slots := cs.slots
+
+	cs.mu.Unlock()
+
+	// Runs wait for a worker slot in their own goroutines so a slow job
+	// never holds up the ticker.
+	for _, run := range runs {
+		go cs.execute(run, slots)
+	}
+}
+
+// executeJobByID dispatches a job as if it had come due and runs it
+// synchronously, bypassing the worker pool.
+func (cs *CronService) executeJobByID(jobID string) {
+	cs.mu.Lock()
+	job := cs.findJobUnsafe(jobID)
+	var run *activeRun
+	if job != nil {
+		run = cs.dispatchUnsafe(job, time.Now().UnixMilli())
+	}
+	cs.mu.Unlock()
+
+	if run != nil {
+		cs.execute(run, nil)
+	}
+}
+
+// dispatchUnsafe applies the job's concurrency policy to a due run,
+// advances its schedule and registers the run. It returns nil when the run
+// is skipped.
+func (cs *CronService) dispatchUnsafe(job *CronJob, nowMS int64) *activeRun {
+	afterRun := job.Schedule.Kind == "at" || job.State.CatchUpRuns > 0
+
+	if active := cs.active[job.ID]; len(active) > 0 {
+		switch job.Concurrency {
+		case ConcurrencyAllow:
+		case ConcurrencyReplace:
+			for _, run := range active {
+				run.cancel(errReplaced)
+			}
+		default:
+			if afterRun {
+				// Keep it due; it starts once the earlier run is done.
+				return nil
+			}
+			log.Printf("[cron] skipping run of job %s (%s): previous run still active", job.ID, job.Name)
+			job.State.History = appendRun(job.State.History, CronRun{
+				StartedAtMS: nowMS,
+				EndedAtMS:   nowMS,
+				Status:      "skipped",
+				Error:       "previous run still active",
+				Attempt:     job.State.RetryAttempt + 1,
+			})
+			job.State.NextRunAtMS = cs.computeNextRun(&job.Schedule, nowMS)
+			return nil
+		}
+	}
+
+	if afterRun {
+		job.State.NextRunAtMS = nil
+	} else {
+		job.State.NextRunAtMS = cs.computeNextRun(&job.Schedule, nowMS)
+	}
+	return cs.trackUnsafe(job, false, afterRun)
+}
+
+func (cs *CronService) trackUnsafe(job *CronJob, manual, afterRun bool) *activeRun {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	run := &activeRun{
+		job:      *job,
+		manual:   manual,
+		afterRun: afterRun,
+		ctx:      ctx,
+		cancel:   cancel,
+		info: RunningJob{
+			JobID:  job.ID,
+			Name:   job.Name,
+			Queued: true,
+			Manual: manual,
+		},
+	}
+	cs.active[job.ID] = append(cs.active[job.ID], run)
+	return run
+}
+
+// execute waits for a worker slot (when slots is non-nil), runs the job
+// under its max runtime and records the outcome.
+func (cs *CronService) execute(run *activeRun, slots chan struct{}) *CronRun {
+	if slots != nil {
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+		case <-run.ctx.Done():
+			now := time.Now().UnixMilli()
+			return cs.finish(run, now, "", context.Cause(run.ctx))
+		}
+	}
+
+	startTime := time.Now()
+	ctx := run.ctx
+	if run.job.MaxRuntimeMS > 0 {
+		limit := time.Duration(run.job.MaxRuntimeMS) * time.Millisecond
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, limit, fmt.Errorf("exceeded max runtime of %s", limit))
+		defer cancel()
+	}
+
+	cs.mu.Lock()
+	run.info.Queued = false
+	run.info.StartedAtMS = startTime.UnixMilli()
+	if deadline, ok := ctx.Deadline(); ok {
+		run.info.DeadlineMS = deadline.UnixMilli()
+	}
+	onJob := cs.onJob
+	cs.mu.Unlock()
+
+	var (
+		output string
+		err    error
+	)
+	if onJob != nil {
+		jobCopy := run.job
+		output, err = onJob(ctx, &jobCopy)
+	}
+	if ctx.Err() != nil {
+		// Report why the run was cut short, even if the handler ignored
+		// the context and returned normally.
+		err = context.Cause(ctx)
+	}
+
+	return cs.finish(run, startTime.UnixMilli(), output, err)
+}
+
+// finish records a completed run and decides what happens next: a retry,
+// the next scheduled run, or a failure notification.
+func (cs *CronService) finish(run *activeRun, startMS int64, output string, err error) *CronRun {
+	record := CronRun{
+		StartedAtMS: startMS,
+		EndedAtMS:   time.Now().UnixMilli(),
+		Status:      "ok",
+		Output:      utils.Truncate(output, maxRunOutput),
+		Attempt:     1,
+		Manual:      run.manual,
+	}
+	record.DurationMS = record.EndedAtMS - record.StartedAtMS
+	canceled := errors.Is(err, errReplaced) || errors.Is(err, errStopped)
+	switch {
+	case canceled:
+		record.Status = "canceled"
+		record.Error = err.Error()
+	case err != nil:
+		record.Status = "error"
+		record.Error = utils.Truncate(err.Error(), maxRunOutput)
+	}
+
+	cs.mu.Lock()
+
+	if run.job.Payload.Command == "" && !run.job.Payload.Deliver {
+		record.SessionKey = cs.sessionKeyUnsafe(&run.job)
+	}
+
+	cs.untrackUnsafe(run)
+	run.cancel(nil)
+
+	job := cs.findJobUnsafe(run.job.ID)
+	if job == nil {
+		cs.mu.Unlock()
+		log.Printf("[cron] job %s disappeared before state update", run.job.ID)
+		return &record
+	}
+
+	if !run.manual {
+		record.Attempt = job.State.RetryAttempt + 1
+	}
+	startedAt := record.StartedAtMS
+	job.State.LastRunAtMS = &startedAt
+	job.State.LastStatus = record.Status
+	job.State.LastError = record.Error
+	job.State.History = appendRun(job.State.History, record)
+	job.UpdatedAtMS = time.Now().UnixMilli()
+
+	var failed *CronJob
+	switch {
+	case run.manual || canceled:
+		// Leave the schedule to whoever replaced or stopped the run.
+	case err != nil && job.Retry != nil && record.Attempt <= job.Retry.MaxRetries:
+		job.State.RetryAttempt = record.Attempt
+		nextRun := time.Now().UnixMilli() + job.Retry.backoffMS(record.Attempt)
+		job.State.NextRunAtMS = &nextRun
+		log.Printf("[cron] job %s failed (attempt %d), retrying in %dms",
+			job.ID, record.Attempt, nextRun-time.Now().UnixMilli())
+	default:
+		job.State.RetryAttempt = 0
+		if err != nil && job.Notify != nil {
+			jobCopy := *job
+			failed = &jobCopy
+		}
+		if run.afterRun {
+			cs.scheduleAfterRunUnsafe(job)
+		}
+	}
+
+	if err := cs.saveStoreUnsafe(); err != nil {
+		log.Printf("[cron] failed to save store: %v", err)
+	}
+	onFailure := cs.onFailure
+	cs.mu.Unlock()
+
+	if failed != nil && onFailure != nil {
+		onFailure(failed, record)
+	}
+	return &record
+}
+
+// scheduleAfterRunUnsafe computes the next run of a one-time job or a job
+// catching up on missed runs, once the current run is over.
+func (cs *CronService) scheduleAfterRunUnsafe(job *CronJob) {
+	if job.Schedule.Kind == "at" {
+		if job.DeleteAfterRun {
+			cs.removeJobUnsafe(job.ID)
+		} else {
+			job.Enabled = false
+			job.State.NextRunAtMS = nil
+		}
+	} else if job.State.CatchUpRuns > 0 {
+		job.State.CatchUpRuns--
+		nextRun := time.Now().UnixMilli()
+		job.State.NextRunAtMS = &nextRun
+	} else {
+		nextRun := cs.computeNextRun(&job.Schedule, time.Now().UnixMilli())
+		job.State.NextRunAtMS = nextRun
+	}
+}
+
+func (cs *CronService) untrackUnsafe(run *activeRun) {
+	runs := cs.active[run.job.ID]
+	for i, r := range runs {
+		if r == run {
+			runs = append(runs[:i:i], runs[i+1:]...)
+			break
+		}
+	}
+	if len(runs) == 0 {
+		delete(cs.active, run.job.ID)
+	} else {
+		cs.active[run.job.ID] = runs
+	}
+}
+
+func (cs *CronService) findJobUnsafe(jobID string) *CronJob {
+	for i := range cs.store.Jobs {
+		if cs.store.Jobs[i].ID == jobID {
+			return &cs.store.Jobs[i]
+		}
+	}
+	return nil
+}
+
+func appendRun(history []CronRun, run CronRun) []CronRun {
+	history = append(history, run)
+	if over := len(history) - maxRunHistory; over > 0 {
+		history = append([]CronRun(nil), history[over:]...)
+	}
+	return history
+}
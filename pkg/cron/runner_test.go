@@ -0,0 +1,213 @@
+package cron
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingHandler runs until release is closed or the run's context ends.
+type blockingHandler struct {
+	mu      sync.Mutex
+	started chan string
+	release chan struct{}
+	calls   int
+}
+
+func newBlockingHandler() *blockingHandler {
+	return &blockingHandler{started: make(chan string, 16), release: make(chan struct{})}
+}
+
+func (h *blockingHandler) run(ctx context.Context, job *CronJob) (string, error) {
+	h.mu.Lock()
+	h.calls++
+	h.mu.Unlock()
+	h.started <- job.ID
+	select {
+	case <-h.release:
+		return "done", nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (h *blockingHandler) waitStarted(t *testing.T) string {
+	t.Helper()
+	select {
+	case id := <-h.started:
+		return id
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a run to start")
+		return ""
+	}
+}
+
+func newRunnerService(t *testing.T, handler JobHandler, jobs ...CronJob) *CronService {
+	t.Helper()
+	cs := NewCronService(filepath.Join(t.TempDir(), "jobs.json"), handler)
+	cs.store.Jobs = jobs
+	cs.running = true
+	return cs
+}
+
+func dueJob(id, concurrency string) CronJob {
+	past := time.Now().Add(-time.Second).UnixMilli()
+	return CronJob{
+		ID:          id,
+		Name:        id,
+		Enabled:     true,
+		Schedule:    CronSchedule{Kind: "every", EveryMS: int64Ptr(time.Hour.Milliseconds())},
+		Payload:     CronPayload{Deliver: true},
+		State:       CronJobState{NextRunAtMS: &past},
+		Concurrency: concurrency,
+	}
+}
+
+func makeDue(cs *CronService, id string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	past := time.Now().Add(-time.Second).UnixMilli()
+	cs.findJobUnsafe(id).State.NextRunAtMS = &past
+}
+
+func waitIdle(t *testing.T, cs *CronService) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for len(cs.Running()) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("runs still active: %+v", cs.Running())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCheckJobs_BoundedWorkerPool(t *testing.T) {
+	h := newBlockingHandler()
+	cs := newRunnerService(t, h.run, dueJob("a", ""), dueJob("b", ""), dueJob("c", ""))
+	cs.SetMaxConcurrent(2)
+
+	cs.checkJobs() // must not block on the running jobs
+	h.waitStarted(t)
+	h.waitStarted(t)
+
+	var running, queued int
+	for _, r := range cs.Running() {
+		if r.Queued {
+			queued++
+		} else {
+			running++
+		}
+	}
+	if running != 2 || queued != 1 {
+		t.Fatalf("running=%d queued=%d, want 2 and 1", running, queued)
+	}
+	if got := cs.Status()["running"]; got != 3 {
+		t.Errorf("Status running = %v, want 3", got)
+	}
+
+	close(h.release)
+	h.waitStarted(t)
+	waitIdle(t, cs)
+
+	for _, job := range cs.ListJobs(true) {
+		if job.State.LastStatus != "ok" {
+			t.Errorf("job %s LastStatus = %q, want ok", job.ID, job.State.LastStatus)
+		}
+	}
+}
+
+func TestCheckJobs_ConcurrencyPolicies(t *testing.T) {
+	tests := []struct {
+		policy       string
+		wantCalls    int
+		wantStatuses []string
+	}{
+		{policy: "", wantCalls: 1, wantStatuses: []string{"skipped", "ok"}},
+		{policy: ConcurrencyForbid, wantCalls: 1, wantStatuses: []string{"skipped", "ok"}},
+		{policy: ConcurrencyAllow, wantCalls: 2, wantStatuses: []string{"ok", "ok"}},
+		{policy: ConcurrencyReplace, wantCalls: 2, wantStatuses: []string{"canceled", "ok"}},
+	}
+
+	for _, tt := range tests {
+		t.Run("policy="+tt.policy, func(t *testing.T) {
+			h := newBlockingHandler()
+			cs := newRunnerService(t, h.run, dueJob("j", tt.policy))
+
+			cs.checkJobs()
+			h.waitStarted(t)
+
+			makeDue(cs, "j")
+			cs.checkJobs()
+			if tt.wantCalls == 2 {
+				h.waitStarted(t)
+			}
+			if tt.policy == ConcurrencyReplace {
+				// Let the canceled run record itself before releasing the new one.
+				deadline := time.Now().Add(2 * time.Second)
+				for len(cs.Running()) > 1 && time.Now().Before(deadline) {
+					time.Sleep(5 * time.Millisecond)
+				}
+			}
+
+			close(h.release)
+			waitIdle(t, cs)
+
+			if h.calls != tt.wantCalls {
+				t.Errorf("handler calls = %d, want %d", h.calls, tt.wantCalls)
+			}
+			runs, _ := cs.History("j")
+			var statuses []string
+			for _, r := range runs {
+				statuses = append(statuses, r.Status)
+			}
+			if strings.Join(statuses, ",") != strings.Join(tt.wantStatuses, ",") {
+				t.Errorf("history statuses = %v, want %v", statuses, tt.wantStatuses)
+			}
+
+			next := cs.ListJobs(true)[0].State.NextRunAtMS
+			if next == nil || *next <= time.Now().UnixMilli() {
+				t.Errorf("next run = %v, want a future run", next)
+			}
+		})
+	}
+}
+
+func TestExecuteJob_MaxRuntime(t *testing.T) {
+	h := newBlockingHandler()
+	job := dueJob("slow", "")
+	job.MaxRuntimeMS = 50
+	job.Retry = &CronRetry{MaxRetries: 1, BackoffMS: 1000}
+	cs := newRunnerService(t, h.run, job)
+
+	start := time.Now()
+	cs.executeJobByID("slow")
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("run took %v, want it cut off after 50ms", elapsed)
+	}
+
+	state := cs.ListJobs(true)[0].State
+	if state.LastStatus != "error" || !strings.Contains(state.LastError, "exceeded max runtime of 50ms") {
+		t.Errorf("state = %+v, want a max runtime error", state)
+	}
+	if state.RetryAttempt != 1 {
+		t.Errorf("RetryAttempt = %d, want 1 (timeouts are retried)", state.RetryAttempt)
+	}
+}
+
+func TestStop_CancelsActiveRuns(t *testing.T) {
+	h := newBlockingHandler()
+	cs := newRunnerService(t, h.run, dueJob("j", ""))
+	cs.stopChan = make(chan struct{})
+
+	cs.checkJobs()
+	h.waitStarted(t)
+	cs.Stop()
+	waitIdle(t, cs)
+
+	if state := cs.ListJobs(true)[0].State; state.LastStatus != "canceled" || state.RetryAttempt != 0 {
+		t.Errorf("state = %+v, want a canceled run without retry", state)
+	}
+}
@@ -1,6 +1,7 @@
 package cron
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
@@ -12,14 +13,91 @@ import (
 	"time"
 
 	"github.com/adhocore/gronx"
+
+	"github.com/sipeed/picoclaw/pkg/routing"
+)
+
+// CurrentStoreVersion is the schema version written to the job store.
+// Version 2 added per-job misfire policies and made TZ effective.
+const CurrentStoreVersion = 2
+
+// Misfire policies decide what happens to runs missed while the service
+// was stopped.
+const (
+	MisfireSkip    = "skip"     // drop missed runs (default)
+	MisfireRunOnce = "run_once" // run once on startup, however many were missed
+	MisfireRunAll  = "run_all"  // run every missed occurrence, up to MaxRuns
+)
+
+const (
+	defaultMisfireMaxRuns = 10
+	maxMisfireMaxRuns     = 1000
 )
 
 type CronSchedule struct {
-	Kind    string `json:"kind"`
-	AtMS    *int64 `json:"atMs,omitempty"`
-	EveryMS *int64 `json:"everyMs,omitempty"`
-	Expr    string `json:"expr,omitempty"`
-	TZ      string `json:"tz,omitempty"`
+	Kind    string       `json:"kind"`
+	AtMS    *int64       `json:"atMs,omitempty"`
+	EveryMS *int64       `json:"everyMs,omitempty"`
+	Expr    string       `json:"expr,omitempty"`
+	TZ      string       `json:"tz,omitempty"`
+	Misfire *CronMisfire `json:"misfire,omitempty"`
+}
+
+// CronMisfire configures catch-up of runs missed during downtime. A missed
+// run scheduled no more than GraceMS before startup always runs once,
+// whatever the policy.
+type CronMisfire struct {
+	Policy  string `json:"policy,omitempty"`
+	MaxRuns int    `json:"maxRuns,omitempty"`
+	GraceMS int64  `json:"graceMs,omitempty"`
+}
+
+func (m *CronMisfire) withDefaults() CronMisfire {
+	var out CronMisfire
+	if m != nil {
+		out = *m
+	}
+	if out.Policy == "" {
+		out.Policy = MisfireSkip
+	}
+	if out.MaxRuns <= 0 {
+		out.MaxRuns = defaultMisfireMaxRuns
+	}
+	return out
+}
+
+// Validate checks the schedule's time zone and misfire policy.
+func (s *CronSchedule) Validate() error {
+	if _, err := s.location(); err != nil {
+		return err
+	}
+	if m := s.Misfire; m != nil {
+		switch m.Policy {
+		case "", MisfireSkip, MisfireRunOnce, MisfireRunAll:
+		default:
+			return fmt.Errorf("unknown misfire policy %q", m.Policy)
+		}
+		if m.MaxRuns < 0 || m.MaxRuns > maxMisfireMaxRuns {
+			return fmt.Errorf("misfire max runs must be between 0 and %d", maxMisfireMaxRuns)
+		}
+		if m.GraceMS < 0 {
+			return fmt.Errorf("misfire grace must not be negative")
+		}
+	}
+	return nil
+}
+
+// location returns the time zone cron expressions are evaluated in: TZ when
+// set, otherwise the host's local zone.
+func (s *CronSchedule) location() (*time.Location, error) {
+	if s.TZ == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(s.TZ)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %w", s.TZ, err)
+	}
+	return loc, nil
 }
 
 type CronPayload struct {
@@ -36,6 +114,81 @@ type CronJobState struct {
 	LastRunAtMS *int64 `json:"lastRunAtMs,omitempty"`
 	LastStatus  string `json:"lastStatus,omitempty"`
 	LastError   string `json:"lastError,omitempty"`
+	// CatchUpRuns counts missed runs still to be replayed after the
+	// current one under the run_all misfire policy.
+	CatchUpRuns int `json:"catchUpRuns,omitempty"`
+	// RetryAttempt counts consecutive failed attempts of the current run.
+	RetryAttempt int       `json:"retryAttempt,omitempty"`
+	History      []CronRun `json:"history,omitempty"`
+}
+
+// CronRun records one execution of a job. Only the latest maxRunHistory
+// runs are kept.
+type CronRun struct {
+	StartedAtMS int64  `json:"startedAtMs"`
+	EndedAtMS   int64  `json:"endedAtMs"`
+	DurationMS  int64  `json:"durationMs"`
+	Status      string `json:"status"`
+	Output      string `json:"output,omitempty"`
+	Error       string `json:"error,omitempty"`
+	SessionKey  string `json:"sessionKey,omitempty"`
+	Attempt     int    `json:"attempt"`
+	Manual      bool   `json:"manual,omitempty"`
+}
+
+const (
+	maxRunHistory = 20
+	maxRunOutput  = 500
+
+	defaultRetryBackoffMS    = 60 * 1000
+	defaultRetryMaxBackoffMS = 60 * 60 * 1000
+)
+
+// CronRetry re-runs a failed job up to MaxRetries times, waiting BackoffMS
+// before the first retry and doubling the wait up to MaxBackoffMS.
+type CronRetry struct {
+	MaxRetries   int   `json:"maxRetries"`
+	BackoffMS    int64 `json:"backoffMs,omitempty"`
+	MaxBackoffMS int64 `json:"maxBackoffMs,omitempty"`
+}
+
+const maxRetries = 100
+
+func (r *CronRetry) Validate() error {
+	if r.MaxRetries < 0 || r.MaxRetries > maxRetries {
+		return fmt.Errorf("max retries must be between 0 and %d", maxRetries)
+	}
+	if r.BackoffMS < 0 || r.MaxBackoffMS < 0 {
+		return fmt.Errorf("retry backoff must not be negative")
+	}
+	return nil
+}
+
+func (r *CronRetry) backoffMS(attempt int) int64 {
+	backoff, limit := r.BackoffMS, r.MaxBackoffMS
+	if backoff <= 0 {
+		backoff = defaultRetryBackoffMS
+	}
+	if limit <= 0 {
+		limit = defaultRetryMaxBackoffMS
+	}
+	for i := 1; i < attempt && backoff < limit; i++ {
+		backoff *= 2
+	}
+	return min(backoff, limit)
+}
+
+// CronNotify is where failures are reported once retries are exhausted.
+type CronNotify struct {
+	Channel string `json:"channel"`
+	To      string `json:"to"`
+}
+
+// CronSender is who created a job from a chat. The job runs under their
+// access role as it is when the job runs.
+type CronSender struct {
+	Channel  string `json:"channel"`
+	SenderID string `json:"senderId"`
 }
 
 type CronJob struct {
@@ -48,6 +201,34 @@ type CronJob struct {
 	CreatedAtMS    int64        `json:"createdAtMs"`
 	UpdatedAtMS    int64        `json:"updatedAtMs"`
 	DeleteAfterRun bool         `json:"deleteAfterRun"`
+	Retry          *CronRetry   `json:"retry,omitempty"`
+	Notify         *CronNotify  `json:"notify,omitempty"`
+	Concurrency    string       `json:"concurrency,omitempty"`
+	MaxRuntimeMS   int64        `json:"maxRuntimeMs,omitempty"`
+	CreatedBy      *CronSender  `json:"createdBy,omitempty"`
+}
+
+func (j *CronJob) validate() error {
+	if err := j.Schedule.Validate(); err != nil {
+		return err
+	}
+	if j.Retry != nil {
+		if err := j.Retry.Validate(); err != nil {
+			return err
+		}
+	}
+	if j.Notify != nil && (j.Notify.Channel == "" || j.Notify.To == "") {
+		return fmt.Errorf("failure notification needs both a channel and a recipient")
+	}
+	switch j.Concurrency {
+	case "", ConcurrencyAllow, ConcurrencyForbid, ConcurrencyReplace:
+	default:
+		return fmt.Errorf("unknown concurrency policy %q", j.Concurrency)
+	}
+	if j.MaxRuntimeMS < 0 {
+		return fmt.Errorf("max runtime must not be negative")
+	}
+	return nil
 }
 
 type CronStore struct {
@@ -55,16 +236,26 @@ type CronStore struct {
 	Jobs    []CronJob `json:"jobs"`
 }
 
-type JobHandler func(job *CronJob) (string, error)
+// JobHandler runs a job. ctx is canceled when the job exceeds its max
+// runtime, is replaced by a newer run, or the service stops.
+type JobHandler func(ctx context.Context, job *CronJob) (string, error)
+
+// FailureHandler is called when a job with a Notify target has failed and
+// has no retries left.
+type FailureHandler func(job *CronJob, run CronRun)
 
 type CronService struct {
 	storePath string
 	store     *CronStore
 	onJob     JobHandler
+	onFailure FailureHandler
 	mu        sync.RWMutex
 	running   bool
 	stopChan  chan struct{}
 	gronx     *gronx.Gronx
+	slots     chan struct{}           // worker pool semaphore
+	active    map[string][]*activeRun // queued and running runs by job ID
+	agentID   string                  // agent whose sessions hold agent job turns
 }
 
 func NewCronService(storePath string, onJob JobHandler) *CronService {
@@ -72,6 +263,8 @@ func NewCronService(storePath string, onJob JobHandler) *CronService {
 		storePath: storePath,
 		onJob:     onJob,
 		gronx:     gronx.New(),
+		slots:     make(chan struct{}, defaultMaxConcurrent),
+		active:    make(map[string][]*activeRun),
 	}
 	// Initialize and load store on creation
 	cs.loadStore()
@@ -90,7 +283,7 @@ func (cs *CronService) Start() error {
 		return fmt.Errorf("failed to load store: %w", err)
 	}
 
-	cs.recomputeNextRuns()
+	cs.reconcileMissedRuns(time.Now().UnixMilli())
 	if err := cs.saveStoreUnsafe(); err != nil {
 		return fmt.Errorf("failed to save store: %w", err)
 	}
@@ -115,6 +308,11 @@ func (cs *CronService) Stop() {
 		close(cs.stopChan)
 		cs.stopChan = nil
 	}
+	for _, runs := range cs.active {
+		for _, run := range runs {
+			run.cancel(errStopped)
+		}
+	}
 }
 
 func (cs *CronService) runLoop(stopChan chan struct{}) {
@@ -131,117 +329,6 @@ func (cs *CronService) runLoop(stopChan chan struct{}) {
 	}
 }
 
-func (cs *CronService) checkJobs() {
-	cs.mu.Lock()
-
-	if !cs.running {
-		cs.mu.Unlock()
-		return
-	}
-
-	now := time.Now().UnixMilli()
-	var dueJobIDs []string
-
-	// Collect jobs that are due (we need to copy them to execute outside lock)
-	for i := range cs.store.Jobs {
-		job := &cs.store.Jobs[i]
-		if job.Enabled && job.State.NextRunAtMS != nil && *job.State.NextRunAtMS <= now {
-			dueJobIDs = append(dueJobIDs, job.ID)
-		}
-	}
-
-	// Reset next run for due jobs before unlocking to avoid duplicate execution.
-	dueMap := make(map[string]bool, len(dueJobIDs))
-	for _, jobID := range dueJobIDs {
-		dueMap[jobID] = true
-	}
-	for i := range cs.store.Jobs {
-		if dueMap[cs.store.Jobs[i].ID] {
-			cs.store.Jobs[i].State.NextRunAtMS = nil
-		}
-	}
-
-	if err := cs.saveStoreUnsafe(); err != nil {
-		log.Printf("[cron] failed to save store: %v", err)
-	}
-
-	cs.mu.Unlock()
-
-	// Execute jobs outside lock.
-	for _, jobID := range dueJobIDs {
-		cs.executeJobByID(jobID)
-	}
-}
-
-func (cs *CronService) executeJobByID(jobID string) {
-	startTime := time.Now().UnixMilli()
-
-	cs.mu.RLock()
-	var callbackJob *CronJob
-	for i := range cs.store.Jobs {
-		job := &cs.store.Jobs[i]
-		if job.ID == jobID {
-			jobCopy := *job
-			callbackJob = &jobCopy
-			break
-		}
-	}
-	cs.mu.RUnlock()
-
-	if callbackJob == nil {
-		return
-	}
-
-	var err error
-	if cs.onJob != nil {
-		_, err = cs.onJob(callbackJob)
-	}
-
-	// Now acquire lock to update state
-	cs.mu.Lock()
-	defer cs.mu.Unlock()
-
-	var job *CronJob
-	for i := range cs.store.Jobs {
-		if cs.store.Jobs[i].ID == jobID {
-			job = &cs.store.Jobs[i]
-			break
-		}
-	}
-	if job == nil {
-		log.Printf("[cron] job %s disappeared before state update", jobID)
-		return
-	}
-
-	job.State.LastRunAtMS = &startTime
-	job.UpdatedAtMS = time.Now().UnixMilli()
-
-	if err != nil {
-		job.State.LastStatus = "error"
-		job.State.LastError = err.Error()
-	} else {
-		job.State.LastStatus = "ok"
-		job.State.LastError = ""
-	}
-
-	// Compute next run time
-	if job.Schedule.Kind == "at" {
-		if job.DeleteAfterRun {
-			cs.removeJobUnsafe(job.ID)
-		} else {
-			job.Enabled = false
-			job.State.NextRunAtMS = nil
-		}
-	} else {
-		nextRun := cs.computeNextRun(&job.Schedule, time.Now().UnixMilli())
-		job.State.NextRunAtMS = nextRun
-	}
-
-	if err := cs.saveStoreUnsafe(); err != nil {
-		log.Printf("[cron] failed to save store: %v", err)
-	}
-}
-
 func (cs *CronService) computeNextRun(schedule *CronSchedule, nowMS int64) *int64 {
 	if schedule.Kind == "at" {
 		if schedule.AtMS != nil && *schedule.AtMS > nowMS {
@@ -263,9 +350,12 @@ func (cs *CronService) computeNextRun(schedule *CronSchedule, nowMS int64) *int6
 			return nil
 		}
 
-		// Use gronx to calculate next run time
-		now := time.UnixMilli(nowMS)
-		nextTime, err := gronx.NextTickAfter(schedule.Expr, now, false)
+		loc, err := schedule.location()
+		if err != nil {
+			log.Printf("[cron] failed to compute next run for expr '%s': %v", schedule.Expr, err)
+			return nil
+		}
+		nextTime, err := nextCronTick(schedule.Expr, time.UnixMilli(nowMS), loc)
 		if err != nil {
 			log.Printf("[cron] failed to compute next run for expr '%s': %v", schedule.Expr, err)
 			return nil
@@ -278,13 +368,137 @@ func (cs *CronService) computeNextRun(schedule *CronSchedule, nowMS int64) *int6
 	return nil
 }
 
-func (cs *CronService) recomputeNextRuns() {
-	now := time.Now().UnixMilli()
+// nextCronTick returns the first instant after `after` whose wall-clock time
+// in loc matches expr. gronx steps through the calendar in the reference
+// time's zone and misbehaves across DST transitions, so the expression is
+// walked in UTC (which has none) and each match is mapped back into loc.
+// A wall-clock time skipped by a spring-forward jump fires at the
+// corresponding instant after the jump; times repeated by a fall-back are
+// not replayed.
+func nextCronTick(expr string, after time.Time, loc *time.Location) (time.Time, error) {
+	local := after.In(loc)
+	wall := time.Date(local.Year(), local.Month(), local.Day(),
+		local.Hour(), local.Minute(), local.Second(), local.Nanosecond(), time.UTC)
+
+	// A repeated hour can hold at most 3600 one-second matches.
+	for i := 0; i < 4000; i++ {
+		next, err := gronx.NextTickAfter(expr, wall, false)
+		if err != nil {
+			return time.Time{}, err
+		}
+		t := time.Date(next.Year(), next.Month(), next.Day(),
+			next.Hour(), next.Minute(), next.Second(), 0, loc)
+		if t.Hour() != next.Hour() || t.Minute() != next.Minute() {
+			// The wall-clock time falls in a spring-forward gap. Read it
+			// with the pre-transition offset, which lands it after the jump
+			// (02:30 becomes 03:30 for a one-hour gap).
+			naive := time.Date(next.Year(), next.Month(), next.Day(),
+				next.Hour(), next.Minute(), next.Second(), 0, time.UTC)
+			_, offBefore := t.Add(-12 * time.Hour).Zone()
+			_, offAfter := t.Add(12 * time.Hour).Zone()
+			t = naive.Add(-time.Duration(min(offBefore, offAfter)) * time.Second).In(loc)
+		}
+		if t.After(after) {
+			return t, nil
+		}
+		wall = next
+	}
+	return time.Time{}, fmt.Errorf("no run time found after %s", after.Format(time.RFC3339))
+}
+
+// reconcileMissedRuns schedules every enabled job after a (re)start. Runs
+// missed while the service was down are replayed according to the job's
+// misfire policy; everything else is scheduled from nowMS.
+func (cs *CronService) reconcileMissedRuns(nowMS int64) {
 	for i := range cs.store.Jobs {
 		job := &cs.store.Jobs[i]
-		if job.Enabled {
-			job.State.NextRunAtMS = cs.computeNextRun(&job.Schedule, now)
+		if !job.Enabled {
+			continue
+		}
+
+		runs := 0
+		if missed := job.State.NextRunAtMS; missed != nil && *missed <= nowMS {
+			runs = cs.missedRuns(&job.Schedule, *missed, nowMS)
+			if runs == 0 {
+				log.Printf("[cron] skipping missed runs of job %s (%s)", job.ID, job.Name)
+			}
+		}
+
+		if runs > 0 {
+			log.Printf("[cron] catching up %d missed run(s) of job %s (%s)", runs, job.ID, job.Name)
+			next := nowMS
+			job.State.NextRunAtMS = &next
+			job.State.CatchUpRuns = runs - 1
+			continue
+		}
+
+		job.State.NextRunAtMS = cs.computeNextRun(&job.Schedule, nowMS)
+		job.State.CatchUpRuns = 0
+	}
+}
+
+// missedRuns returns how many runs to replay for a schedule whose first
+// missed occurrence was firstMS.
+func (cs *CronService) missedRuns(schedule *CronSchedule, firstMS, nowMS int64) int {
+	policy := schedule.Misfire.withDefaults()
+
+	runs := 0
+	switch policy.Policy {
+	case MisfireRunOnce:
+		runs = 1
+	case MisfireRunAll:
+		runs = cs.countRuns(schedule, firstMS, nowMS, policy.MaxRuns)
+	}
+
+	if runs == 0 && policy.GraceMS > 0 && cs.hasRunSince(schedule, firstMS, nowMS, nowMS-policy.GraceMS) {
+		runs = 1
+	}
+	return runs
+}
+
+// countRuns counts the occurrences in [firstMS, nowMS], stopping at limit.
+func (cs *CronService) countRuns(schedule *CronSchedule, firstMS, nowMS int64, limit int) int {
+	switch schedule.Kind {
+	case "every":
+		if schedule.EveryMS == nil || *schedule.EveryMS <= 0 {
+			return 1
+		}
+		n := (nowMS-firstMS) / *schedule.EveryMS + 1
+		return int(min(n, int64(limit)))
+	case "cron":
+		n, t := 1, firstMS
+		for n < limit {
+			next := cs.computeNextRun(schedule, t)
+			if next == nil || *next > nowMS {
+				break
+			}
+			n++
+			t = *next
 		}
+		return n
+	default:
+		return 1
+	}
+}
+
+// hasRunSince reports whether an occurrence in [firstMS, nowMS] falls at or
+// after sinceMS.
+func (cs *CronService) hasRunSince(schedule *CronSchedule, firstMS, nowMS, sinceMS int64) bool {
+	if firstMS >= sinceMS {
+		return true
+	}
+	switch schedule.Kind {
+	case "every":
+		if schedule.EveryMS == nil || *schedule.EveryMS <= 0 {
+			return false
+		}
+		last := firstMS + (nowMS-firstMS) / *schedule.EveryMS * *schedule.EveryMS
+		return last >= sinceMS
+	case "cron":
+		next := cs.computeNextRun(schedule, sinceMS-1)
+		return next != nil && *next <= nowMS
+	default:
+		return false
 	}
 }
 
@@ -312,9 +526,49 @@ func (cs *CronService) SetOnJob(handler JobHandler) {
 	cs.onJob = handler
 }
 
+func (cs *CronService) SetOnFailure(handler FailureHandler) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.onFailure = handler
+}
+
+// SetAgentID sets the agent whose sessions hold the turns of jobs processed
+// through the agent. It defaults to routing.DefaultAgentID.
+func (cs *CronService) SetAgentID(agentID string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.agentID = agentID
+}
+
+// SessionKey returns the agent session used by a job processed through the
+// agent. It is agent-scoped so the agent loop keeps it instead of the
+// routed chat session.
+func (cs *CronService) SessionKey(job *CronJob) string {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return cs.sessionKeyUnsafe(job)
+}
+
+func (cs *CronService) sessionKeyUnsafe(job *CronJob) string {
+	return "agent:" + routing.NormalizeAgentID(cs.agentID) + ":cron:" + job.ID
+}
+
+// History returns the recorded runs of a job, oldest first.
+func (cs *CronService) History(jobID string) ([]CronRun, bool) {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	for _, job := range cs.store.Jobs {
+		if job.ID == jobID {
+			return append([]CronRun(nil), job.State.History...), true
+		}
+	}
+	return nil, false
+}
+
 func (cs *CronService) loadStore() error {
 	cs.store = &CronStore{
-		Version: 1,
+		Version: CurrentStoreVersion,
 		Jobs:    []CronJob{},
 	}
 
@@ -326,7 +580,20 @@ func (cs *CronService) loadStore() error {
 		return err
 	}
 
-	return json.Unmarshal(data, cs.store)
+	// Stores written before versioning carry no version field.
+	cs.store.Version = 1
+	if err := json.Unmarshal(data, cs.store); err != nil {
+		return err
+	}
+	if cs.store.Version > CurrentStoreVersion {
+		return fmt.Errorf("cron store version %d is newer than supported version %d",
+			cs.store.Version, CurrentStoreVersion)
+	}
+
+	// Version 1 jobs have no misfire policy, which defaults to the old
+	// behavior of skipping missed runs, so migration only bumps the version.
+	cs.store.Version = CurrentStoreVersion
+	return nil
 }
 
 func (cs *CronService) saveStoreUnsafe() error {
@@ -350,6 +617,23 @@ func (cs *CronService) AddJob(
 	deliver bool,
 	channel, to string,
 ) (*CronJob, error) {
+	return cs.AddJobAs(nil, name, schedule, message, deliver, channel, to)
+}
+
+// AddJobAs adds a job created by a sender, recording them on the job
+// before it can first run. A nil sender is the device owner.
+func (cs *CronService) AddJobAs(
+	createdBy *CronSender,
+	name string,
+	schedule CronSchedule,
+	message string,
+	deliver bool,
+	channel, to string,
+) (*CronJob, error) {
+	if err := schedule.Validate(); err != nil {
+		return nil, err
+	}
+
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
 
@@ -376,6 +660,7 @@ func (cs *CronService) AddJob(
 		CreatedAtMS:    now,
 		UpdatedAtMS:    now,
 		DeleteAfterRun: deleteAfterRun,
+		CreatedBy:      createdBy,
 	}
 
 	cs.store.Jobs = append(cs.store.Jobs, job)
@@ -387,6 +672,10 @@ func (cs *CronService) AddJob(
 }
 
 func (cs *CronService) UpdateJob(job *CronJob) error {
+	if err := job.validate(); err != nil {
+		return err
+	}
+
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
 
@@ -442,6 +731,8 @@ func (cs *CronService) EnableJob(jobID string, enabled bool) *CronJob {
 			} else {
 				job.State.NextRunAtMS = nil
 			}
+			job.State.CatchUpRuns = 0
+			job.State.RetryAttempt = 0
 
 			if err := cs.saveStoreUnsafe(); err != nil {
 				log.Printf("[cron] failed to save store after enable: %v", err)
@@ -482,9 +773,15 @@ func (cs *CronService) Status() map[string]any {
 		}
 	}
 
+	var running int
+	for _, runs := range cs.active {
+		running += len(runs)
+	}
+
 	return map[string]any{
 		"enabled":      cs.running,
 		"jobs":         len(cs.store.Jobs),
+		"running":      running,
 		"nextWakeAtMS": cs.getNextWakeMS(),
 	}
 }
@@ -1,10 +1,14 @@
 package cron
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestSaveStore_FilePermissions(t *testing.T) {
@@ -33,6 +37,395 @@ func TestSaveStore_FilePermissions(t *testing.T) {
 	}
 }
 
+func TestComputeNextRun_TimeZone(t *testing.T) {
+	cs := NewCronService(filepath.Join(t.TempDir(), "jobs.json"), nil)
+
+	now := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	schedule := CronSchedule{Kind: "cron", Expr: "0 9 * * *", TZ: "Asia/Shanghai"}
+
+	next := cs.computeNextRun(&schedule, now.UnixMilli())
+	if next == nil {
+		t.Fatal("computeNextRun returned nil")
+	}
+	want := time.Date(2026, 10, 19, 1, 0, 0, 0, time.UTC)
+	if got := time.UnixMilli(*next).UTC(); !got.Equal(want) {
+		t.Errorf("next run = %v, want %v", got, want)
+	}
+}
+
+func TestComputeNextRun_DST(t *testing.T) {
+	cs := NewCronService(filepath.Join(t.TempDir(), "jobs.json"), nil)
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want []time.Time
+	}{
+		{
+			// 02:30 does not exist on 2026-03-08; it fires right after the jump.
+			name: "spring forward",
+			expr: "30 2 * * *",
+			from: time.Date(2026, 3, 7, 12, 0, 0, 0, ny),
+			want: []time.Time{
+				time.Date(2026, 3, 8, 7, 30, 0, 0, time.UTC),
+				time.Date(2026, 3, 9, 6, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			// 01:30 happens twice on 2026-11-01; it fires only once.
+			name: "fall back",
+			expr: "30 1 * * *",
+			from: time.Date(2026, 10, 31, 12, 0, 0, 0, ny),
+			want: []time.Time{
+				time.Date(2026, 11, 1, 5, 30, 0, 0, time.UTC),
+				time.Date(2026, 11, 2, 6, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "hourly across fall back",
+			expr: "0 * * * *",
+			from: time.Date(2026, 11, 1, 0, 30, 0, 0, ny),
+			want: []time.Time{
+				time.Date(2026, 11, 1, 5, 0, 0, 0, time.UTC),
+				time.Date(2026, 11, 1, 7, 0, 0, 0, time.UTC),
+				time.Date(2026, 11, 1, 8, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := CronSchedule{Kind: "cron", Expr: tt.expr, TZ: "America/New_York"}
+			nowMS := tt.from.UnixMilli()
+			for i, want := range tt.want {
+				next := cs.computeNextRun(&schedule, nowMS)
+				if next == nil {
+					t.Fatalf("run %d: computeNextRun returned nil", i)
+				}
+				if got := time.UnixMilli(*next).UTC(); !got.Equal(want) {
+					t.Fatalf("run %d = %v, want %v", i, got, want)
+				}
+				nowMS = *next
+			}
+		})
+	}
+}
+
+func TestReconcileMissedRuns(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC).UnixMilli()
+	hour := time.Hour.Milliseconds()
+
+	tests := []struct {
+		name        string
+		misfire     *CronMisfire
+		missedAt    int64
+		wantRunNow  bool
+		wantCatchUp int
+	}{
+		{name: "default skips", missedAt: now - 5*hour},
+		{name: "skip", misfire: &CronMisfire{Policy: MisfireSkip}, missedAt: now - 5*hour},
+		{name: "run once", misfire: &CronMisfire{Policy: MisfireRunOnce}, missedAt: now - 5*hour, wantRunNow: true},
+		{
+			name:        "run all",
+			misfire:     &CronMisfire{Policy: MisfireRunAll},
+			missedAt:    now - 5*hour,
+			wantRunNow:  true,
+			wantCatchUp: 5,
+		},
+		{
+			name:        "run all capped",
+			misfire:     &CronMisfire{Policy: MisfireRunAll, MaxRuns: 3},
+			missedAt:    now - 5*hour,
+			wantRunNow:  true,
+			wantCatchUp: 2,
+		},
+		{
+			name:       "skip within grace",
+			misfire:    &CronMisfire{Policy: MisfireSkip, GraceMS: hour},
+			missedAt:   now - 5*hour - hour/2,
+			wantRunNow: true,
+		},
+		{
+			name:     "skip outside grace",
+			misfire:  &CronMisfire{Policy: MisfireSkip, GraceMS: hour / 4},
+			missedAt: now - 5*hour - hour/2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := NewCronService(filepath.Join(t.TempDir(), "jobs.json"), nil)
+			missedAt := tt.missedAt
+			cs.store.Jobs = []CronJob{{
+				ID:       "job",
+				Enabled:  true,
+				Schedule: CronSchedule{Kind: "every", EveryMS: int64Ptr(hour), Misfire: tt.misfire},
+				State:    CronJobState{NextRunAtMS: &missedAt},
+			}}
+
+			cs.reconcileMissedRuns(now)
+
+			state := cs.store.Jobs[0].State
+			if state.NextRunAtMS == nil {
+				t.Fatal("NextRunAtMS is nil")
+			}
+			if runNow := *state.NextRunAtMS == now; runNow != tt.wantRunNow {
+				t.Errorf("run now = %v, want %v (next %d)", runNow, tt.wantRunNow, *state.NextRunAtMS-now)
+			}
+			if !tt.wantRunNow && *state.NextRunAtMS <= now {
+				t.Errorf("next run %d is not in the future", *state.NextRunAtMS-now)
+			}
+			if state.CatchUpRuns != tt.wantCatchUp {
+				t.Errorf("CatchUpRuns = %d, want %d", state.CatchUpRuns, tt.wantCatchUp)
+			}
+		})
+	}
+}
+
+func TestReconcileMissedRuns_CronGrace(t *testing.T) {
+	cs := NewCronService(filepath.Join(t.TempDir(), "jobs.json"), nil)
+
+	// Daily 09:00 Shanghai job, last due 2026-10-17; restarted 30 minutes
+	// after the 2026-10-19 run was due.
+	missedAt := time.Date(2026, 10, 17, 1, 0, 0, 0, time.UTC).UnixMilli()
+	now := time.Date(2026, 10, 19, 1, 30, 0, 0, time.UTC).UnixMilli()
+	schedule := CronSchedule{Kind: "cron", Expr: "0 9 * * *", TZ: "Asia/Shanghai"}
+
+	for _, tc := range []struct {
+		misfire     CronMisfire
+		wantRunNow  bool
+		wantCatchUp int
+	}{
+		{misfire: CronMisfire{GraceMS: time.Hour.Milliseconds()}, wantRunNow: true},
+		{misfire: CronMisfire{GraceMS: time.Minute.Milliseconds()}},
+		{misfire: CronMisfire{Policy: MisfireRunAll}, wantRunNow: true, wantCatchUp: 2},
+	} {
+		misfire := tc.misfire
+		sched := schedule
+		sched.Misfire = &misfire
+		next := missedAt
+		cs.store.Jobs = []CronJob{{ID: "job", Enabled: true, Schedule: sched, State: CronJobState{NextRunAtMS: &next}}}
+
+		cs.reconcileMissedRuns(now)
+
+		state := cs.store.Jobs[0].State
+		if runNow := state.NextRunAtMS != nil && *state.NextRunAtMS == now; runNow != tc.wantRunNow {
+			t.Errorf("%+v: run now = %v, want %v", misfire, runNow, tc.wantRunNow)
+		}
+		if state.CatchUpRuns != tc.wantCatchUp {
+			t.Errorf("%+v: CatchUpRuns = %d, want %d", misfire, state.CatchUpRuns, tc.wantCatchUp)
+		}
+	}
+}
+
+func TestExecuteJob_CatchUpRuns(t *testing.T) {
+	runs := 0
+	cs := NewCronService(filepath.Join(t.TempDir(), "jobs.json"), func(context.Context, *CronJob) (string, error) {
+		runs++
+		return "ok", nil
+	})
+
+	now := time.Now().UnixMilli()
+	cs.store.Jobs = []CronJob{{
+		ID:       "job",
+		Enabled:  true,
+		Schedule: CronSchedule{Kind: "every", EveryMS: int64Ptr(time.Hour.Milliseconds())},
+		State:    CronJobState{NextRunAtMS: &now, CatchUpRuns: 1},
+	}}
+
+	cs.executeJobByID("job")
+	state := cs.store.Jobs[0].State
+	if state.CatchUpRuns != 0 || state.NextRunAtMS == nil || *state.NextRunAtMS > time.Now().UnixMilli() {
+		t.Fatalf("after first run: state = %+v, want an immediate catch-up run", state)
+	}
+
+	cs.executeJobByID("job")
+	state = cs.store.Jobs[0].State
+	if state.NextRunAtMS == nil || *state.NextRunAtMS < now+time.Hour.Milliseconds() {
+		t.Fatalf("after catch-up: state = %+v, want next run an hour out", state)
+	}
+	if runs != 2 {
+		t.Errorf("runs = %d, want 2", runs)
+	}
+}
+
+func TestLoadStore_Versions(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "jobs.json")
+
+	v1 := `{"jobs":[{"id":"a","name":"a","enabled":true,"schedule":{"kind":"cron","expr":"0 9 * * *"}}]}`
+	if err := os.WriteFile(storePath, []byte(v1), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cs := NewCronService(storePath, nil)
+	if err := cs.Load(); err != nil {
+		t.Fatalf("Load v1: %v", err)
+	}
+	if cs.store.Version != CurrentStoreVersion || len(cs.store.Jobs) != 1 {
+		t.Errorf("migrated store = version %d with %d jobs", cs.store.Version, len(cs.store.Jobs))
+	}
+
+	if err := os.WriteFile(storePath, []byte(`{"version":99,"jobs":[]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.Load(); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("Load v99 error = %v, want newer-version error", err)
+	}
+}
+
+func TestAddJob_ValidatesSchedule(t *testing.T) {
+	cs := NewCronService(filepath.Join(t.TempDir(), "jobs.json"), nil)
+
+	bad := []CronSchedule{
+		{Kind: "cron", Expr: "0 9 * * *", TZ: "Mars/Olympus_Mons"},
+		{Kind: "every", EveryMS: int64Ptr(1000), Misfire: &CronMisfire{Policy: "sometimes"}},
+		{Kind: "every", EveryMS: int64Ptr(1000), Misfire: &CronMisfire{Policy: MisfireRunAll, MaxRuns: -1}},
+	}
+	for _, schedule := range bad {
+		if _, err := cs.AddJob("bad", schedule, "hi", false, "cli", "direct"); err == nil {
+			t.Errorf("AddJob(%+v) succeeded, want error", schedule)
+		}
+	}
+
+	job, err := cs.AddJob("ok", CronSchedule{Kind: "cron", Expr: "0 9 * * *", TZ: "Asia/Shanghai"}, "hi", false, "cli", "direct")
+	if err != nil {
+		t.Fatalf("AddJob: %v", err)
+	}
+	next := time.UnixMilli(*job.State.NextRunAtMS).In(time.FixedZone("CST", 8*3600))
+	if next.Hour() != 9 || next.Minute() != 0 {
+		t.Errorf("next run = %v, want 09:00 +08:00", next)
+	}
+}
+
+func TestExecuteJob_HistoryIsBounded(t *testing.T) {
+	calls := 0
+	cs := NewCronService(filepath.Join(t.TempDir(), "jobs.json"), func(context.Context, *CronJob) (string, error) {
+		calls++
+		if calls%2 == 0 {
+			return "", errors.New("boom")
+		}
+		return strings.Repeat("x", 2*maxRunOutput), nil
+	})
+	job, err := cs.AddJob("h", CronSchedule{Kind: "every", EveryMS: int64Ptr(60000)}, "hi", false, "cli", "direct")
+	if err != nil {
+		t.Fatalf("AddJob: %v", err)
+	}
+
+	for range maxRunHistory + 5 {
+		cs.executeJobByID(job.ID)
+	}
+
+	runs, ok := cs.History(job.ID)
+	if !ok {
+		t.Fatal("History: job not found")
+	}
+	if len(runs) != maxRunHistory {
+		t.Fatalf("history has %d runs, want %d", len(runs), maxRunHistory)
+	}
+	last := runs[len(runs)-1]
+	if last.Status != "ok" || len([]rune(last.Output)) != maxRunOutput {
+		t.Errorf("last run = %+v, want ok with a truncated output", last)
+	}
+	if prev := runs[len(runs)-2]; prev.Status != "error" || prev.Error != "boom" {
+		t.Errorf("previous run = %+v, want error boom", prev)
+	}
+	if want := "agent:main:cron:" + job.ID; last.SessionKey != want {
+		t.Errorf("SessionKey = %q, want %q", last.SessionKey, want)
+	}
+
+	// The history survives a reload.
+	reloaded := NewCronService(cs.storePath, nil)
+	if runs, _ := reloaded.History(job.ID); len(runs) != maxRunHistory {
+		t.Errorf("reloaded history has %d runs, want %d", len(runs), maxRunHistory)
+	}
+}
+
+func TestExecuteJob_RetriesThenNotifies(t *testing.T) {
+	var notified []CronRun
+	cs := NewCronService(filepath.Join(t.TempDir(), "jobs.json"), func(context.Context, *CronJob) (string, error) {
+		return "", errors.New("provider down")
+	})
+	cs.SetOnFailure(func(job *CronJob, run CronRun) {
+		if job.Notify == nil || job.Notify.Channel != "telegram" {
+			t.Errorf("notified job has notify target %+v", job.Notify)
+		}
+		notified = append(notified, run)
+	})
+
+	job, err := cs.AddJob("r", CronSchedule{Kind: "every", EveryMS: int64Ptr(time.Hour.Milliseconds())},
+		"hi", false, "cli", "direct")
+	if err != nil {
+		t.Fatalf("AddJob: %v", err)
+	}
+	job.Retry = &CronRetry{MaxRetries: 2, BackoffMS: 1000}
+	job.Notify = &CronNotify{Channel: "telegram", To: "42"}
+	if err := cs.UpdateJob(job); err != nil {
+		t.Fatalf("UpdateJob: %v", err)
+	}
+
+	for attempt, wantDelay := range []int64{1000, 2000} {
+		before := time.Now().UnixMilli()
+		cs.executeJobByID(job.ID)
+		state := cs.store.Jobs[0].State
+		delay := *state.NextRunAtMS - before
+		if state.RetryAttempt != attempt+1 || delay < wantDelay || delay > wantDelay+500 {
+			t.Fatalf("after attempt %d: retry %d, next in %dms; want retry %d in %dms",
+				attempt+1, state.RetryAttempt, delay, attempt+1, wantDelay)
+		}
+		if len(notified) != 0 {
+			t.Fatalf("notified after attempt %d, want no notification while retrying", attempt+1)
+		}
+	}
+
+	cs.executeJobByID(job.ID)
+	state := cs.store.Jobs[0].State
+	if state.RetryAttempt != 0 || *state.NextRunAtMS < time.Now().UnixMilli()+time.Hour.Milliseconds()-1000 {
+		t.Errorf("after exhausting retries: state = %+v, want the regular schedule", state)
+	}
+	if len(notified) != 1 || notified[0].Attempt != 3 || notified[0].Error != "provider down" {
+		t.Errorf("notifications = %+v, want one for attempt 3", notified)
+	}
+}
+
+func TestRunJob_LeavesScheduleAlone(t *testing.T) {
+	cs := NewCronService(filepath.Join(t.TempDir(), "jobs.json"), func(context.Context, *CronJob) (string, error) {
+		return "", errors.New("fail")
+	})
+	cs.SetOnFailure(func(*CronJob, CronRun) { t.Error("manual run triggered a failure notification") })
+
+	atMS := time.Now().Add(time.Hour).UnixMilli()
+	job, err := cs.AddJob("once", CronSchedule{Kind: "at", AtMS: &atMS}, "hi", true, "cli", "direct")
+	if err != nil {
+		t.Fatalf("AddJob: %v", err)
+	}
+	job.Retry = &CronRetry{MaxRetries: 3}
+	job.Notify = &CronNotify{Channel: "cli", To: "direct"}
+	if err := cs.UpdateJob(job); err != nil {
+		t.Fatalf("UpdateJob: %v", err)
+	}
+
+	run, err := cs.RunJob(job.ID)
+	if err != nil {
+		t.Fatalf("RunJob: %v", err)
+	}
+	if !run.Manual || run.Status != "error" || run.SessionKey != "" {
+		t.Errorf("run = %+v, want a manual failed run without session", run)
+	}
+
+	jobs := cs.ListJobs(true)
+	if len(jobs) != 1 || !jobs[0].Enabled || *jobs[0].State.NextRunAtMS != atMS || jobs[0].State.RetryAttempt != 0 {
+		t.Errorf("job after manual run = %+v, want it unchanged", jobs)
+	}
+
+	if _, err := cs.RunJob("missing"); err == nil {
+		t.Error("RunJob(missing) succeeded, want error")
+	}
+}
+
 func int64Ptr(v int64) *int64 {
 	return &v
 }
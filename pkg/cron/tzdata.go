@@ -0,0 +1,5 @@
+package cron
+
+// Embed the time zone database so per-job TZ works on devices that ship
+// without /usr/share/zoneinfo.
+import _ "time/tzdata"
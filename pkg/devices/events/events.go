@@ -1,6 +1,10 @@
 package events
 
-import "context"
+import (
+	"context"
+	"sort"
+	"strings"
+)
 
 type EventSource interface {
 	Kind() Kind
@@ -14,6 +18,12 @@ const (
 	ActionAdd    Action = "add"
 	ActionRemove Action = "remove"
 	ActionChange Action = "change"
+
+	// ActionOnline and ActionOffline report state rather than presence: a
+	// network link gaining or losing connectivity, a power supply being
+	// plugged in or unplugged.
+	ActionOnline  Action = "online"
+	ActionOffline Action = "offline"
 )
 
 type Kind string
@@ -23,6 +33,9 @@ const (
 	KindBluetooth Kind = "bluetooth"
 	KindPCI       Kind = "pci"
 	KindGeneric   Kind = "generic"
+	KindNetwork   Kind = "network"
+	KindPower     Kind = "power"
+	KindGPIO      Kind = "gpio"
 )
 
 type DeviceEvent struct {
@@ -31,6 +44,8 @@ type DeviceEvent struct {
 	DeviceID     string            // e.g. "1-2" for USB bus 1 dev 2
 	Vendor       string            // Vendor name or ID
 	Product      string            // Product name or ID
+	VendorID     string            // Numeric vendor ID if available, e.g. "0781"
+	ProductID    string            // Numeric product ID if available, e.g. "5567"
 	Serial       string            // Serial number if available
 	Capabilities string            // Human-readable capability description
 	Raw          map[string]string // Raw properties for extensibility
@@ -39,14 +54,23 @@ type DeviceEvent struct {
 func (e *DeviceEvent) FormatMessage() string {
 	actionEmoji := "🔌"
 	actionText := "Connected"
-	if e.Action == ActionRemove {
-		actionEmoji = "🔌"
+	switch e.Action {
+	case ActionRemove:
 		actionText = "Disconnected"
+	case ActionChange:
+		actionEmoji = "🔄"
+		actionText = "Changed"
+	case ActionOnline:
+		actionEmoji = "🟢"
+		actionText = "Online"
+	case ActionOffline:
+		actionEmoji = "🔴"
+		actionText = "Offline"
 	}
 
 	msg := actionEmoji + " Device " + actionText + "\n\n"
 	msg += "Type: " + string(e.Kind) + "\n"
-	msg += "Device: " + e.Vendor + " " + e.Product + "\n"
+	msg += "Device: " + strings.TrimSpace(e.Vendor+" "+e.Product) + "\n"
 	if e.Capabilities != "" {
 		msg += "Capabilities: " + e.Capabilities + "\n"
 	}
@@ -55,3 +79,33 @@ func (e *DeviceEvent) FormatMessage() string {
 	}
 	return msg
 }
+
+// Describe returns the event as "key: value" lines, for use as context in
+// agent prompts.
+func (e *DeviceEvent) Describe() string {
+	var sb strings.Builder
+	field := func(key, value string) {
+		if value != "" {
+			sb.WriteString(key + ": " + value + "\n")
+		}
+	}
+	field("action", string(e.Action))
+	field("kind", string(e.Kind))
+	field("device_id", e.DeviceID)
+	field("vendor", e.Vendor)
+	field("product", e.Product)
+	field("vendor_id", e.VendorID)
+	field("product_id", e.ProductID)
+	field("serial", e.Serial)
+	field("capabilities", e.Capabilities)
+
+	keys := make([]string, 0, len(e.Raw))
+	for k := range e.Raw {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		field("raw."+k, e.Raw[k])
+	}
+	return sb.String()
+}
@@ -0,0 +1,57 @@
+// Package gpio drives GPIO lines through the Linux GPIO character device
+// (/dev/gpiochipN, v2 uAPI, Linux 5.10+).
+package gpio
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Edges to detect.
+const (
+	EdgeNone    = ""
+	EdgeRising  = "rising"
+	EdgeFalling = "falling"
+	EdgeBoth    = "both"
+)
+
+// ErrUnsupported is returned on platforms without the GPIO character device.
+var ErrUnsupported = errors.New("GPIO is only supported on Linux")
+
+// LineConfig configures a requested line.
+type LineConfig struct {
+	Consumer  string // label shown in gpioinfo, default "picoclaw"
+	Output    bool
+	Value     int // initial output value
+	ActiveLow bool
+	Edge      string        // input only: EdgeRising, EdgeFalling or EdgeBoth
+	Debounce  time.Duration // input only
+}
+
+// Event is an edge detected on a line.
+type Event struct {
+	Edge        string // EdgeRising or EdgeFalling
+	TimestampNs uint64
+	Seqno       uint32
+}
+
+// ChipPath returns the device path for a chip name ("gpiochip0") or path.
+func ChipPath(chip string) string {
+	if chip == "" {
+		chip = "gpiochip0"
+	}
+	if strings.HasPrefix(chip, "/") {
+		return chip
+	}
+	return "/dev/" + chip
+}
+
+// ValidEdge reports whether edge is one of the edge constants.
+func ValidEdge(edge string) bool {
+	switch edge {
+	case EdgeNone, EdgeRising, EdgeFalling, EdgeBoth:
+		return true
+	}
+	return false
+}
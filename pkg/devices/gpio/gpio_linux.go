@@ -0,0 +1,192 @@
+//go:build linux
+
+package gpio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// GPIO character device v2 uAPI constants from <linux/gpio.h>.
+const (
+	lineRequestSize = 592 // struct gpio_v2_line_request
+	lineValuesSize  = 16  // struct gpio_v2_line_values
+	lineEventSize   = 48  // struct gpio_v2_line_event
+
+	// _IOWR(0xB4, nr, size)
+	getLineIoctl   = 3<<30 | lineRequestSize<<16 | 0xB4<<8 | 0x07
+	getValuesIoctl = 3<<30 | lineValuesSize<<16 | 0xB4<<8 | 0x0E
+	setValuesIoctl = 3<<30 | lineValuesSize<<16 | 0xB4<<8 | 0x0F
+
+	lineFlagActiveLow   = 1 << 1
+	lineFlagInput       = 1 << 2
+	lineFlagOutput      = 1 << 3
+	lineFlagEdgeRising  = 1 << 4
+	lineFlagEdgeFalling = 1 << 5
+
+	lineAttrIDOutputValues = 2
+	lineAttrIDDebounce     = 3
+
+	lineEventRisingEdge = 1
+
+	// Offsets into struct gpio_v2_line_request.
+	reqConsumerOffset = 256
+	reqFlagsOffset    = 288
+	reqNumAttrsOffset = 296
+	reqAttrsOffset    = 320
+	reqAttrSize       = 24
+	reqNumLinesOffset = 560
+	reqFdOffset       = 588
+)
+
+// Line is a requested GPIO line. The line stays claimed, and an output keeps
+// its value, until Close.
+type Line struct {
+	file *os.File
+}
+
+// RequestLine claims line offset on chip ("gpiochip0" or a device path).
+func RequestLine(chip string, offset uint32, cfg LineConfig) (*Line, error) {
+	if !ValidEdge(cfg.Edge) {
+		return nil, fmt.Errorf("invalid edge %q", cfg.Edge)
+	}
+	f, err := os.Open(ChipPath(chip))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ne := binary.NativeEndian
+	req := make([]byte, lineRequestSize)
+	ne.PutUint32(req[0:], offset) // offsets[0]
+	consumer := cfg.Consumer
+	if consumer == "" {
+		consumer = "picoclaw"
+	}
+	copy(req[reqConsumerOffset:reqConsumerOffset+31], consumer)
+
+	var flags uint64
+	var attrs [][3]uint64 // id, value, mask
+	if cfg.Output {
+		flags |= lineFlagOutput
+		attrs = append(attrs, [3]uint64{lineAttrIDOutputValues, uint64(cfg.Value & 1), 1})
+	} else {
+		flags |= lineFlagInput
+		switch cfg.Edge {
+		case EdgeRising:
+			flags |= lineFlagEdgeRising
+		case EdgeFalling:
+			flags |= lineFlagEdgeFalling
+		case EdgeBoth:
+			flags |= lineFlagEdgeRising | lineFlagEdgeFalling
+		}
+		if cfg.Debounce > 0 {
+			attrs = append(attrs, [3]uint64{lineAttrIDDebounce, uint64(cfg.Debounce.Microseconds()), 1})
+		}
+	}
+	if cfg.ActiveLow {
+		flags |= lineFlagActiveLow
+	}
+	ne.PutUint64(req[reqFlagsOffset:], flags)
+	ne.PutUint32(req[reqNumAttrsOffset:], uint32(len(attrs)))
+	for i, a := range attrs {
+		attr := req[reqAttrsOffset+i*reqAttrSize:]
+		ne.PutUint32(attr[0:], uint32(a[0]))
+		ne.PutUint64(attr[8:], a[1]) // union: values or debounce_period_us
+		ne.PutUint64(attr[16:], a[2])
+	}
+	ne.PutUint32(req[reqNumLinesOffset:], 1)
+
+	if err := ioctl(f, getLineIoctl, req); err != nil {
+		return nil, fmt.Errorf("line %d request failed: %w", offset, err)
+	}
+
+	fd := int(int32(ne.Uint32(req[reqFdOffset:])))
+	if err := syscall.SetNonblock(fd, true); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	return &Line{file: os.NewFile(uintptr(fd), fmt.Sprintf("gpio-line-%d", offset))}, nil
+}
+
+// Value returns the line's logical value (0 or 1).
+func (l *Line) Value() (int, error) {
+	vals := make([]byte, lineValuesSize)
+	binary.NativeEndian.PutUint64(vals[8:], 1) // mask
+	if err := ioctl(l.file, getValuesIoctl, vals); err != nil {
+		return 0, err
+	}
+	return int(binary.NativeEndian.Uint64(vals[0:]) & 1), nil
+}
+
+// SetValue sets an output line's logical value.
+func (l *Line) SetValue(value int) error {
+	vals := make([]byte, lineValuesSize)
+	binary.NativeEndian.PutUint64(vals[0:], uint64(value&1))
+	binary.NativeEndian.PutUint64(vals[8:], 1) // mask
+	return ioctl(l.file, setValuesIoctl, vals)
+}
+
+// ReadEvent waits for the next edge on a line requested with an edge. A
+// timeout of 0 waits until the line is closed; on timeout the error is
+// os.ErrDeadlineExceeded.
+func (l *Line) ReadEvent(timeout time.Duration) (Event, error) {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	if err := l.file.SetReadDeadline(deadline); err != nil {
+		return Event{}, err
+	}
+	buf := make([]byte, lineEventSize)
+	n, err := l.file.Read(buf)
+	if err != nil {
+		return Event{}, err
+	}
+	if n < lineEventSize {
+		return Event{}, fmt.Errorf("short GPIO event read: %d bytes", n)
+	}
+	return parseEvent(buf), nil
+}
+
+func (l *Line) Close() error {
+	return l.file.Close()
+}
+
+// parseEvent decodes a struct gpio_v2_line_event.
+func parseEvent(buf []byte) Event {
+	ne := binary.NativeEndian
+	ev := Event{
+		Edge:        EdgeFalling,
+		TimestampNs: ne.Uint64(buf[0:]),
+		Seqno:       ne.Uint32(buf[16:]),
+	}
+	if ne.Uint32(buf[8:]) == lineEventRisingEdge {
+		ev.Edge = EdgeRising
+	}
+	return ev
+}
+
+// ioctl runs an ioctl without taking the file out of non-blocking mode
+// (which calling Fd would).
+func ioctl(f *os.File, req uintptr, arg []byte) error {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var errno syscall.Errno
+	err = conn.Control(func(fd uintptr) {
+		_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, fd, req, uintptr(unsafe.Pointer(&arg[0])))
+	})
+	if err != nil {
+		return err
+	}
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+//go:build linux
+
+package gpio
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestIoctlNumbers(t *testing.T) {
+	// GPIO_V2_GET_LINE_IOCTL, GPIO_V2_LINE_GET_VALUES_IOCTL and
+	// GPIO_V2_LINE_SET_VALUES_IOCTL from <linux/gpio.h>.
+	if getLineIoctl != 0xC250B407 || getValuesIoctl != 0xC010B40E || setValuesIoctl != 0xC010B40F {
+		t.Errorf("ioctl numbers = %#x %#x %#x", getLineIoctl, getValuesIoctl, setValuesIoctl)
+	}
+}
+
+func TestParseEvent(t *testing.T) {
+	buf := make([]byte, lineEventSize)
+	binary.NativeEndian.PutUint64(buf[0:], 42)
+	binary.NativeEndian.PutUint32(buf[8:], lineEventRisingEdge)
+	binary.NativeEndian.PutUint32(buf[16:], 3)
+	if ev := parseEvent(buf); ev != (Event{Edge: EdgeRising, TimestampNs: 42, Seqno: 3}) {
+		t.Errorf("event = %+v", ev)
+	}
+	binary.NativeEndian.PutUint32(buf[8:], 2)
+	if ev := parseEvent(buf); ev.Edge != EdgeFalling {
+		t.Errorf("edge = %s", ev.Edge)
+	}
+}
+
+func TestChipPath(t *testing.T) {
+	for in, want := range map[string]string{"": "/dev/gpiochip0", "gpiochip2": "/dev/gpiochip2", "/dev/gpiochip1": "/dev/gpiochip1"} {
+		if got := ChipPath(in); got != want {
+			t.Errorf("ChipPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
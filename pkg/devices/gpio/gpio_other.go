@@ -0,0 +1,28 @@
+//go:build !linux
+
+package gpio
+
+import "time"
+
+// Line is a requested GPIO line.
+type Line struct{}
+
+func RequestLine(chip string, offset uint32, cfg LineConfig) (*Line, error) {
+	return nil, ErrUnsupported
+}
+
+func (l *Line) Value() (int, error) {
+	return 0, ErrUnsupported
+}
+
+func (l *Line) SetValue(value int) error {
+	return ErrUnsupported
+}
+
+func (l *Line) ReadEvent(timeout time.Duration) (Event, error) {
+	return Event{}, ErrUnsupported
+}
+
+func (l *Line) Close() error {
+	return nil
+}
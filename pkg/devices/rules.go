@@ -0,0 +1,111 @@
+package devices
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/devices/events"
+)
+
+const defaultRuleDebounce = 2 * time.Second
+
+// Rule runs an agent prompt when a matching device event arrives. Empty match
+// fields match anything; IDs and capability are compared case-insensitively
+// and Capability matches a substring (e.g. "mass storage").
+type Rule struct {
+	Name string
+
+	Kind       events.Kind
+	Action     events.Action
+	VendorID   string
+	ProductID  string
+	Serial     string
+	Capability string
+
+	Prompt string // instructions for the agent; the event is appended as context
+	Skill  string // optional skill the agent should use
+
+	// Channel and ChatID select where the agent's reply goes; empty means
+	// the last active channel.
+	Channel string
+	ChatID  string
+
+	// Debounce drops repeat events for the same rule and device within this
+	// window (hot-plug often fires several events per insertion).
+	Debounce time.Duration
+}
+
+// RuleHandler runs a rule's prompt through the agent for channel and chatID
+// and returns the reply, which the service sends there.
+type RuleHandler func(ctx context.Context, prompt, sessionKey, channel, chatID string) (string, error)
+
+// Validate checks that the rule has something to do.
+func (r *Rule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("device rule: name is required")
+	}
+	if strings.TrimSpace(r.Prompt) == "" && r.Skill == "" {
+		return fmt.Errorf("device rule %q: prompt or skill is required", r.Name)
+	}
+	if (r.Channel == "") != (r.ChatID == "") {
+		return fmt.Errorf("device rule %q: channel and chat_id must be set together", r.Name)
+	}
+	return nil
+}
+
+// Matches reports whether ev satisfies all of the rule's match fields.
+func (r *Rule) Matches(ev *events.DeviceEvent) bool {
+	if r.Kind != "" && r.Kind != ev.Kind {
+		return false
+	}
+	if r.Action != "" && r.Action != ev.Action {
+		return false
+	}
+	if r.VendorID != "" && !strings.EqualFold(r.VendorID, ev.VendorID) {
+		return false
+	}
+	if r.ProductID != "" && !strings.EqualFold(r.ProductID, ev.ProductID) {
+		return false
+	}
+	if r.Serial != "" && r.Serial != ev.Serial {
+		return false
+	}
+	if r.Capability != "" &&
+		!strings.Contains(strings.ToLower(ev.Capabilities), strings.ToLower(r.Capability)) {
+		return false
+	}
+	return true
+}
+
+// BuildPrompt returns the agent prompt for ev.
+func (r *Rule) BuildPrompt(ev *events.DeviceEvent) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[Device event rule %q]\n\n", r.Name)
+	if r.Skill != "" {
+		fmt.Fprintf(&sb, "Use the %q skill for this task; read its SKILL.md first if it is not loaded.\n\n", r.Skill)
+	}
+	if prompt := strings.TrimSpace(r.Prompt); prompt != "" {
+		sb.WriteString(prompt + "\n\n")
+	}
+	sb.WriteString("Device event:\n")
+	sb.WriteString(ev.Describe())
+	return sb.String()
+}
+
+func (r *Rule) debounce() time.Duration {
+	if r.Debounce > 0 {
+		return r.Debounce
+	}
+	return defaultRuleDebounce
+}
+
+// debounceKey identifies repeats of the same event for a rule.
+func (r *Rule) debounceKey(ev *events.DeviceEvent) string {
+	device := ev.DeviceID
+	if ev.Serial != "" {
+		device = ev.Serial
+	}
+	return r.Name + "|" + string(ev.Action) + "|" + device
+}
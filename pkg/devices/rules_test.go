@@ -0,0 +1,156 @@
+package devices
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/devices/events"
+	"github.com/sipeed/picoclaw/pkg/state"
+)
+
+func usbStorageEvent() *events.DeviceEvent {
+	return &events.DeviceEvent{
+		Action:       events.ActionAdd,
+		Kind:         events.KindUSB,
+		DeviceID:     "1:4",
+		Vendor:       "SanDisk",
+		Product:      "Cruzer",
+		VendorID:     "0781",
+		ProductID:    "5567",
+		Serial:       "ABC123",
+		Capabilities: "Mass Storage (USB Flash Drive/Hard Disk)",
+	}
+}
+
+func TestRuleMatches(t *testing.T) {
+	ev := usbStorageEvent()
+	tests := []struct {
+		name string
+		rule Rule
+		want bool
+	}{
+		{"empty matches all", Rule{}, true},
+		{"kind and action", Rule{Kind: events.KindUSB, Action: events.ActionAdd}, true},
+		{"wrong action", Rule{Action: events.ActionRemove}, false},
+		{"ids are case-insensitive", Rule{VendorID: "0781", ProductID: "5567"}, true},
+		{"wrong vendor", Rule{VendorID: "046d"}, false},
+		{"serial", Rule{Serial: "ABC123"}, true},
+		{"wrong serial", Rule{Serial: "abc123"}, false},
+		{"capability substring", Rule{Capability: "mass storage"}, true},
+		{"wrong capability", Rule{Capability: "audio"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Matches(ev); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	if err := (&Rule{Name: "r"}).Validate(); err == nil {
+		t.Error("expected error for rule without prompt or skill")
+	}
+	if err := (&Rule{Name: "r", Prompt: "x", Channel: "telegram"}).Validate(); err == nil {
+		t.Error("expected error for channel without chat_id")
+	}
+	if err := (&Rule{Name: "r", Skill: "files"}).Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestRuleBuildPrompt(t *testing.T) {
+	rule := Rule{Name: "usb-storage", Prompt: "List its top-level files.", Skill: "files"}
+	prompt := rule.BuildPrompt(usbStorageEvent())
+	for _, want := range []string{`"usb-storage"`, `"files" skill`, "List its top-level files.", "vendor_id: 0781", "serial: ABC123"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestServiceRunRules(t *testing.T) {
+	stateMgr := state.NewManager(t.TempDir())
+	if err := stateMgr.SetLastChannel("telegram:42"); err != nil {
+		t.Fatal(err)
+	}
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+
+	s := NewService(Config{Enabled: true, Rules: []Rule{
+		{Name: "storage", Capability: "mass storage", Prompt: "Summarize it.", Channel: "slack", ChatID: "C1"},
+		{Name: "any-usb", Kind: events.KindUSB, Prompt: "Log it.", Debounce: time.Minute},
+		{Name: "invalid"},
+	}}, stateMgr)
+	if len(s.rules) != 2 {
+		t.Fatalf("expected invalid rule to be dropped, got %d rules", len(s.rules))
+	}
+	s.SetBus(msgBus)
+
+	calls := make(chan [3]string, 4)
+	s.SetRuleHandler(func(ctx context.Context, prompt, sessionKey, channel, chatID string) (string, error) {
+		calls <- [3]string{sessionKey, channel, chatID}
+		return "done", nil
+	})
+
+	now := time.Unix(1000, 0)
+	s.now = func() time.Time { return now }
+
+	if !s.runRules(usbStorageEvent()) {
+		t.Fatal("expected rules to match")
+	}
+	got := map[string][3]string{}
+	for range 2 {
+		select {
+		case c := <-calls:
+			got[c[0]] = c
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for rule handler")
+		}
+	}
+	if got["agent:main:device-rule:storage"] != [3]string{"agent:main:device-rule:storage", "slack", "C1"} {
+		t.Errorf("storage rule: got %v", got["agent:main:device-rule:storage"])
+	}
+	if got["agent:main:device-rule:any-usb"] != [3]string{"agent:main:device-rule:any-usb", "telegram", "42"} {
+		t.Errorf("any-usb rule should use the last channel, got %v", got["agent:main:device-rule:any-usb"])
+	}
+
+	// Within the default window both rules are debounced; after it, only
+	// the rule with the longer window still is.
+	now = now.Add(time.Second)
+	if !s.runRules(usbStorageEvent()) {
+		t.Fatal("debounced events still count as matched")
+	}
+	now = now.Add(5 * time.Second)
+	s.runRules(usbStorageEvent())
+	select {
+	case c := <-calls:
+		if c[0] != "agent:main:device-rule:storage" {
+			t.Errorf("unexpected rule fired: %v", c)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for rule handler")
+	}
+	select {
+	case c := <-calls:
+		t.Errorf("unexpected extra call: %v", c)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if out, ok := msgBus.SubscribeOutbound(ctx); !ok || out.Content != "done" {
+		t.Errorf("expected rule reply on the bus, got %+v", out)
+	}
+}
+
+func TestServiceRunRulesWithoutHandler(t *testing.T) {
+	s := NewService(Config{Enabled: true, Rules: []Rule{{Name: "r", Prompt: "x"}}}, state.NewManager(t.TempDir()))
+	if s.runRules(usbStorageEvent()) {
+		t.Error("rules should not run without a handler")
+	}
+}
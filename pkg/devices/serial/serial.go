@@ -0,0 +1,66 @@
+// Package serial opens and configures serial ports (UARTs, USB serial
+// adapters) as raw, non-blocking files with read deadlines.
+package serial
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Parity settings.
+const (
+	ParityNone = "none"
+	ParityEven = "even"
+	ParityOdd  = "odd"
+)
+
+// ErrUnsupported is returned on platforms without termios support.
+var ErrUnsupported = errors.New("serial ports are only supported on Linux")
+
+// Config is a port's line settings. Zero fields take the 115200 8N1 defaults.
+type Config struct {
+	Baud     int
+	DataBits int    // 5-8
+	Parity   string // ParityNone, ParityEven or ParityOdd
+	StopBits int    // 1 or 2
+}
+
+// WithDefaults returns c with zero fields set to 115200 8N1.
+func (c Config) WithDefaults() Config {
+	if c.Baud == 0 {
+		c.Baud = 115200
+	}
+	if c.DataBits == 0 {
+		c.DataBits = 8
+	}
+	if c.Parity == "" {
+		c.Parity = ParityNone
+	}
+	if c.StopBits == 0 {
+		c.StopBits = 1
+	}
+	return c
+}
+
+// Validate checks c after defaults are applied. Baud rates are checked when
+// the port is configured, since the supported set depends on the platform.
+func (c Config) Validate() error {
+	c = c.WithDefaults()
+	if c.DataBits < 5 || c.DataBits > 8 {
+		return fmt.Errorf("data bits must be between 5 and 8, got %d", c.DataBits)
+	}
+	switch c.Parity {
+	case ParityNone, ParityEven, ParityOdd:
+	default:
+		return fmt.Errorf("parity must be none, even or odd, got %q", c.Parity)
+	}
+	if c.StopBits != 1 && c.StopBits != 2 {
+		return fmt.Errorf("stop bits must be 1 or 2, got %d", c.StopBits)
+	}
+	return nil
+}
+
+func (c Config) String() string {
+	c = c.WithDefaults()
+	return fmt.Sprintf("%d %d%c%d", c.Baud, c.DataBits, c.Parity[0]-'a'+'A', c.StopBits)
+}
@@ -0,0 +1,166 @@
+//go:build linux
+
+package serial
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+var baudRates = map[int]uint32{
+	1200:    unix.B1200,
+	2400:    unix.B2400,
+	4800:    unix.B4800,
+	9600:    unix.B9600,
+	19200:   unix.B19200,
+	38400:   unix.B38400,
+	57600:   unix.B57600,
+	115200:  unix.B115200,
+	230400:  unix.B230400,
+	460800:  unix.B460800,
+	500000:  unix.B500000,
+	576000:  unix.B576000,
+	921600:  unix.B921600,
+	1000000: unix.B1000000,
+	1500000: unix.B1500000,
+	2000000: unix.B2000000,
+	3000000: unix.B3000000,
+	4000000: unix.B4000000,
+}
+
+var dataBits = map[int]uint32{5: unix.CS5, 6: unix.CS6, 7: unix.CS7, 8: unix.CS8}
+
+// Port is an open serial port in raw mode. Reads honour deadlines, and Close
+// unblocks a pending Read.
+type Port struct {
+	file *os.File
+	path string
+}
+
+// Open opens the terminal device at path and applies cfg.
+func Open(path string, cfg Config) (*Port, error) {
+	fd, err := unix.Open(path, unix.O_RDWR|unix.O_NOCTTY|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: path, Err: err}
+	}
+	// os.NewFile registers non-blocking descriptors with the poller, which
+	// is what makes read deadlines work.
+	p := &Port{file: os.NewFile(uintptr(fd), path), path: path}
+	if err := p.Configure(cfg); err != nil {
+		p.file.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// Configure applies cfg, switching the port to raw mode.
+func (p *Port) Configure(cfg Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	cfg = cfg.WithDefaults()
+	speed, ok := baudRates[cfg.Baud]
+	if !ok {
+		return fmt.Errorf("unsupported baud rate %d", cfg.Baud)
+	}
+
+	return p.control(func(fd int) error {
+		t, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+		if err != nil {
+			return fmt.Errorf("%s is not a terminal device: %w", p.path, err)
+		}
+
+		// cfmakeraw
+		t.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP |
+			unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON | unix.IXOFF | unix.INPCK
+		t.Oflag &^= unix.OPOST
+		t.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+
+		t.Cflag &^= unix.CBAUD | unix.CSIZE | unix.PARENB | unix.PARODD | unix.CSTOPB | unix.CRTSCTS
+		t.Cflag |= speed | dataBits[cfg.DataBits] | unix.CREAD | unix.CLOCAL
+		switch cfg.Parity {
+		case ParityEven:
+			t.Cflag |= unix.PARENB
+		case ParityOdd:
+			t.Cflag |= unix.PARENB | unix.PARODD
+		}
+		if cfg.Parity != ParityNone {
+			t.Iflag |= unix.INPCK
+		}
+		if cfg.StopBits == 2 {
+			t.Cflag |= unix.CSTOPB
+		}
+		t.Ispeed = uint32(cfg.Baud)
+		t.Ospeed = uint32(cfg.Baud)
+		t.Cc[unix.VMIN] = 1
+		t.Cc[unix.VTIME] = 0
+
+		if err := unix.IoctlSetTermios(fd, unix.TCSETS, t); err != nil {
+			return fmt.Errorf("failed to configure %s: %w", p.path, err)
+		}
+		return nil
+	})
+}
+
+// Read reads available bytes, waiting until the read deadline if there are
+// none. A timed out read returns os.ErrDeadlineExceeded.
+func (p *Port) Read(b []byte) (int, error) {
+	return p.file.Read(b)
+}
+
+func (p *Port) Write(b []byte) (int, error) {
+	return p.file.Write(b)
+}
+
+// SetReadDeadline sets the deadline for Read. A zero time means no deadline.
+func (p *Port) SetReadDeadline(t time.Time) error {
+	return p.file.SetReadDeadline(t)
+}
+
+// Flush discards data received but not yet read.
+func (p *Port) Flush() error {
+	return p.control(func(fd int) error {
+		return unix.IoctlSetInt(fd, unix.TCFLSH, unix.TCIFLUSH)
+	})
+}
+
+func (p *Port) Close() error {
+	return p.file.Close()
+}
+
+// control runs fn on the descriptor without taking the file out of
+// non-blocking mode (which calling Fd would).
+func (p *Port) control(fn func(fd int) error) error {
+	conn, err := p.file.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var fnErr error
+	if err := conn.Control(func(fd uintptr) { fnErr = fn(int(fd)) }); err != nil {
+		return err
+	}
+	return fnErr
+}
+
+// OpenPTY opens a pseudo-terminal and returns its controlling side and the
+// path of the terminal side, which can be opened like a serial port. It lets
+// serial code be exercised without hardware.
+func OpenPTY() (*os.File, string, error) {
+	fd, err := unix.Open("/dev/ptmx", unix.O_RDWR|unix.O_NOCTTY|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+		unix.Close(fd)
+		return nil, "", err
+	}
+	n, err := unix.IoctlGetUint32(fd, unix.TIOCGPTN)
+	if err != nil {
+		unix.Close(fd)
+		return nil, "", err
+	}
+	return os.NewFile(uintptr(fd), "/dev/ptmx"), fmt.Sprintf("/dev/pts/%d", n), nil
+}
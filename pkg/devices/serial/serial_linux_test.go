@@ -0,0 +1,96 @@
+//go:build linux
+
+package serial
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func openTestPTY(t *testing.T) (*os.File, string) {
+	t.Helper()
+	master, path, err := OpenPTY()
+	if err != nil {
+		t.Skipf("pseudo-terminals not available: %v", err)
+	}
+	t.Cleanup(func() { master.Close() })
+	return master, path
+}
+
+func TestPortReadWrite(t *testing.T) {
+	master, path := openTestPTY(t)
+	port, err := Open(path, Config{Baud: 9600, Parity: ParityEven})
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	defer port.Close()
+
+	if _, err := master.Write([]byte("ping\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	port.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 64)
+	n, err := port.Read(buf)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	// Raw mode: no CR/LF translation.
+	if got := string(buf[:n]); got != "ping\r\n" {
+		t.Errorf("Read() = %q, want %q", got, "ping\r\n")
+	}
+
+	if _, err := port.Write([]byte("pong\n")); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	master.SetReadDeadline(time.Now().Add(time.Second))
+	n, err = master.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(buf[:n]); got != "pong\n" {
+		t.Errorf("master read = %q, want %q", got, "pong\n")
+	}
+}
+
+func TestPortReadDeadline(t *testing.T) {
+	_, path := openTestPTY(t)
+	port, err := Open(path, Config{})
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	defer port.Close()
+
+	port.SetReadDeadline(time.Now().Add(20 * time.Millisecond))
+	if _, err := port.Read(make([]byte, 8)); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("Read() error = %v, want deadline exceeded", err)
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	_, path := openTestPTY(t)
+	if _, err := Open(path, Config{Baud: 12345}); err == nil {
+		t.Error("expected error for unsupported baud rate")
+	}
+	if _, err := Open("/dev/null", Config{}); err == nil {
+		t.Error("expected error for a device that is not a terminal")
+	}
+	if _, err := Open("/dev/does-not-exist", Config{}); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Open() error = %v, want not exist", err)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	if got := (Config{}).String(); got != "115200 8N1" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := (Config{Baud: 9600, DataBits: 7, Parity: ParityOdd, StopBits: 2}).String(); got != "9600 7O2" {
+		t.Errorf("String() = %q", got)
+	}
+	for _, cfg := range []Config{{DataBits: 9}, {Parity: "mark"}, {StopBits: 3}} {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Validate(%+v) expected error", cfg)
+		}
+	}
+}
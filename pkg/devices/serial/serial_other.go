@@ -0,0 +1,43 @@
+//go:build !linux
+
+package serial
+
+import (
+	"os"
+	"time"
+)
+
+// Port is an open serial port.
+type Port struct{}
+
+func Open(path string, cfg Config) (*Port, error) {
+	return nil, ErrUnsupported
+}
+
+func (p *Port) Configure(cfg Config) error {
+	return ErrUnsupported
+}
+
+func (p *Port) Read(b []byte) (int, error) {
+	return 0, ErrUnsupported
+}
+
+func (p *Port) Write(b []byte) (int, error) {
+	return 0, ErrUnsupported
+}
+
+func (p *Port) SetReadDeadline(t time.Time) error {
+	return ErrUnsupported
+}
+
+func (p *Port) Flush() error {
+	return ErrUnsupported
+}
+
+func (p *Port) Close() error {
+	return nil
+}
+
+func OpenPTY() (*os.File, string, error) {
+	return nil, "", ErrUnsupported
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/constants"
 	"github.com/sipeed/picoclaw/pkg/devices/events"
 	"github.com/sipeed/picoclaw/pkg/devices/sources"
 	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/routing"
 	"github.com/sipeed/picoclaw/pkg/state"
 )
 
@@ -21,34 +23,113 @@ type Service struct {
 	ctx     context.Context
 	cancel  context.CancelFunc
 	mu      sync.RWMutex
+
+	rules     []Rule
+	agentID   string
+	handler   RuleHandler
+	lastFired map[string]time.Time // debounce key -> last time a rule fired
+	now       func() time.Time
 }
 
+// USB monitoring backends.
+const (
+	USBBackendUdevadm = "udevadm" // udev events via the udevadm binary (default)
+	USBBackendNetlink = "netlink" // kernel uevents, no external binary
+)
+
 type Config struct {
 	Enabled    bool
-	MonitorUSB bool // When true, monitor USB hotplug (Linux only)
-	// Future: MonitorBluetooth, MonitorPCI, etc.
+	MonitorUSB bool   // When true, monitor USB hotplug (Linux only)
+	USBBackend string // USBBackendUdevadm (default) or USBBackendNetlink
+
+	// Sources below are Linux only.
+	MonitorBluetooth  bool          // Bluetooth adapters and connections, via kernel uevents
+	MonitorSubsystems []string      // further uevent subsystems (e.g. "block"), as generic events
+	MonitorNetwork    bool          // interface add/remove and online/offline, including Wi-Fi
+	MonitorPower      bool          // AC/USB power and battery changes
+	PowerPollInterval time.Duration // 0 = 30s
+	GPIO              []sources.GPIOLine
+
+	// Sources are additional event sources, e.g. for board-specific hardware.
+	Sources []EventSource
+
+	// Rules run agent prompts for matching events. Events that match no
+	// rule are sent as plain notifications to the last channel.
+	Rules []Rule
+
+	// AgentID is the agent whose sessions hold rule turns, one session per
+	// rule. Empty means routing.DefaultAgentID.
+	AgentID string
 }
 
 func NewService(cfg Config, stateMgr *state.Manager) *Service {
 	s := &Service{
-		state:   stateMgr,
-		enabled: cfg.Enabled,
-		sources: make([]EventSource, 0),
+		state:     stateMgr,
+		enabled:   cfg.Enabled,
+		agentID:   routing.NormalizeAgentID(cfg.AgentID),
+		sources:   make([]EventSource, 0),
+		lastFired: make(map[string]time.Time),
+		now:       time.Now,
 	}
 
-	if cfg.Enabled && cfg.MonitorUSB {
-		s.sources = append(s.sources, sources.NewUSBMonitor())
+	for _, rule := range cfg.Rules {
+		if err := rule.Validate(); err != nil {
+			logger.WarnCF("devices", "Skipping invalid device rule", map[string]any{"error": err.Error()})
+			continue
+		}
+		s.rules = append(s.rules, rule)
+	}
+
+	if cfg.Enabled {
+		s.sources = append(s.sources, configuredSources(cfg)...)
 	}
 
 	return s
 }
 
+func configuredSources(cfg Config) []EventSource {
+	var srcs []EventSource
+	var subsystems []string
+	if cfg.MonitorUSB {
+		if cfg.USBBackend == USBBackendNetlink {
+			subsystems = append(subsystems, "usb")
+		} else {
+			srcs = append(srcs, sources.NewUSBMonitor())
+		}
+	}
+	if cfg.MonitorBluetooth {
+		subsystems = append(subsystems, "bluetooth")
+	}
+	subsystems = append(subsystems, cfg.MonitorSubsystems...)
+	if len(subsystems) > 0 {
+		srcs = append(srcs, sources.NewUEventMonitor(subsystems))
+	}
+	if cfg.MonitorNetwork {
+		srcs = append(srcs, sources.NewNetworkMonitor())
+	}
+	if cfg.MonitorPower {
+		srcs = append(srcs, sources.NewPowerMonitor(cfg.PowerPollInterval))
+	}
+	if len(cfg.GPIO) > 0 {
+		srcs = append(srcs, sources.NewGPIOMonitor(cfg.GPIO))
+	}
+	return append(srcs, cfg.Sources...)
+}
+
 func (s *Service) SetBus(msgBus *bus.MessageBus) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.bus = msgBus
 }
 
+// SetRuleHandler sets the function that runs rule prompts through the agent.
+// Without a handler, rules are ignored and every event is a notification.
+func (s *Service) SetRuleHandler(handler RuleHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handler = handler
+}
+
 func (s *Service) Start(ctx context.Context) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -100,6 +181,9 @@ func (s *Service) handleEvents(kind events.Kind, eventCh <-chan *events.DeviceEv
 		if ev == nil {
 			continue
 		}
+		if s.runRules(ev) {
+			continue
+		}
 		s.sendNotification(ev)
 	}
 }
@@ -140,6 +224,93 @@ func (s *Service) sendNotification(ev *events.DeviceEvent) {
 	})
 }
 
+// runRules starts the agent for every rule matching ev, and reports whether
+// any rule matched (including debounced repeats).
+func (s *Service) runRules(ev *events.DeviceEvent) bool {
+	s.mu.Lock()
+	handler := s.handler
+	msgBus := s.bus
+	ctx := s.ctx
+	if handler == nil || len(s.rules) == 0 {
+		s.mu.Unlock()
+		return false
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	now := s.now()
+	var fire []Rule
+	matched := false
+	for _, rule := range s.rules {
+		if !rule.Matches(ev) {
+			continue
+		}
+		matched = true
+		key := rule.debounceKey(ev)
+		if last, ok := s.lastFired[key]; ok && now.Sub(last) < rule.debounce() {
+			logger.DebugCF("devices", "Device rule debounced", map[string]any{"rule": rule.Name})
+			continue
+		}
+		s.lastFired[key] = now
+		fire = append(fire, rule)
+	}
+	s.pruneLocked(now)
+	s.mu.Unlock()
+
+	for _, rule := range fire {
+		channel, chatID := rule.Channel, rule.ChatID
+		if channel == "" {
+			channel, chatID = parseLastChannel(s.state.GetLastChannel())
+		}
+		if channel == "" || chatID == "" || constants.IsInternalChannel(channel) {
+			logger.WarnCF("devices", "No target channel for device rule", map[string]any{"rule": rule.Name})
+			continue
+		}
+
+		logger.InfoCF("devices", "Device rule triggered", map[string]any{
+			"rule":   rule.Name,
+			"kind":   ev.Kind,
+			"action": ev.Action,
+			"to":     channel,
+		})
+		go func(rule Rule, channel, chatID string) {
+			// An agent-scoped key keeps rule turns out of the chat's own
+			// session.
+			sessionKey := "agent:" + s.agentID + ":device-rule:" + rule.Name
+			response, err := handler(ctx, rule.BuildPrompt(ev), sessionKey, channel, chatID)
+			if err != nil {
+				logger.ErrorCF("devices", "Device rule failed", map[string]any{
+					"rule":  rule.Name,
+					"error": err.Error(),
+				})
+				return
+			}
+			if response != "" && msgBus != nil {
+				msgBus.PublishOutbound(bus.OutboundMessage{
+					Channel: channel,
+					ChatID:  chatID,
+					Content: response,
+				})
+			}
+		}(rule, channel, chatID)
+	}
+	return matched
+}
+
+// pruneLocked forgets debounce entries older than any rule's window.
+func (s *Service) pruneLocked(now time.Time) {
+	var longest time.Duration
+	for _, rule := range s.rules {
+		longest = max(longest, rule.debounce())
+	}
+	for key, last := range s.lastFired {
+		if now.Sub(last) >= longest {
+			delete(s.lastFired, key)
+		}
+	}
+}
+
 func parseLastChannel(lastChannel string) (platform, userID string) {
 	if lastChannel == "" {
 		return "", ""
@@ -0,0 +1,43 @@
+package sources
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/devices/gpio"
+)
+
+// GPIO edges to watch.
+const (
+	EdgeRising  = gpio.EdgeRising
+	EdgeFalling = gpio.EdgeFalling
+	EdgeBoth    = gpio.EdgeBoth
+)
+
+// GPIOLine is an input line watched for edges.
+type GPIOLine struct {
+	Name      string        // label for events, default "<chip>:<line>"
+	Chip      string        // "gpiochip0" or a device path
+	Line      uint32        // line offset on the chip
+	Edge      string        // EdgeRising, EdgeFalling or EdgeBoth (default)
+	ActiveLow bool          // invert the line, so rising means becoming active
+	Debounce  time.Duration // kernel debounce period, 0 = none
+}
+
+func (l GPIOLine) chip() string {
+	if l.Chip == "" {
+		return "gpiochip0"
+	}
+	return l.Chip
+}
+
+func (l GPIOLine) id() string {
+	return fmt.Sprintf("%s:%d", l.chip(), l.Line)
+}
+
+func (l GPIOLine) validate() error {
+	if !gpio.ValidEdge(l.Edge) {
+		return fmt.Errorf("gpio %s: invalid edge %q", l.id(), l.Edge)
+	}
+	return nil
+}
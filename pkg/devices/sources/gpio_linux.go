@@ -0,0 +1,131 @@
+//go:build linux
+
+package sources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/sipeed/picoclaw/pkg/devices/events"
+	"github.com/sipeed/picoclaw/pkg/devices/gpio"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// GPIOMonitor reports edges on GPIO input lines as change events, using the
+// GPIO character device rather than deprecated sysfs.
+type GPIOMonitor struct {
+	lines     []GPIOLine
+	requested []*gpio.Line
+	mu        sync.Mutex
+}
+
+func NewGPIOMonitor(lines []GPIOLine) *GPIOMonitor {
+	return &GPIOMonitor{lines: lines}
+}
+
+func (m *GPIOMonitor) Kind() events.Kind {
+	return events.KindGPIO
+}
+
+func (m *GPIOMonitor) Start(ctx context.Context) (<-chan *events.DeviceEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, line := range m.lines {
+		if err := line.validate(); err != nil {
+			return nil, err
+		}
+	}
+	for _, line := range m.lines {
+		edge := line.Edge
+		if edge == "" {
+			edge = EdgeBoth
+		}
+		l, err := gpio.RequestLine(line.chip(), line.Line, gpio.LineConfig{
+			Edge:      edge,
+			ActiveLow: line.ActiveLow,
+			Debounce:  line.Debounce,
+		})
+		if err != nil {
+			m.closeLocked()
+			return nil, fmt.Errorf("gpio %s: %w", line.id(), err)
+		}
+		m.requested = append(m.requested, l)
+	}
+
+	eventCh := make(chan *events.DeviceEvent, 16)
+	var wg sync.WaitGroup
+	for i, line := range m.lines {
+		wg.Add(1)
+		go func(line GPIOLine, l *gpio.Line) {
+			defer wg.Done()
+			for {
+				ev, err := l.ReadEvent(0)
+				if err != nil {
+					if ctx.Err() == nil && !errors.Is(err, os.ErrClosed) {
+						logger.ErrorCF("devices", "GPIO read error", map[string]any{
+							"line":  line.id(),
+							"error": err.Error(),
+						})
+					}
+					return
+				}
+				select {
+				case eventCh <- gpioEvent(line, ev):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(line, m.requested[i])
+	}
+
+	go func() {
+		wg.Wait()
+		close(eventCh)
+	}()
+	go func() {
+		<-ctx.Done()
+		m.Stop()
+	}()
+
+	return eventCh, nil
+}
+
+func (m *GPIOMonitor) Stop() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closeLocked()
+	return nil
+}
+
+func (m *GPIOMonitor) closeLocked() {
+	for _, l := range m.requested {
+		l.Close()
+	}
+	m.requested = nil
+}
+
+func gpioEvent(line GPIOLine, ev gpio.Event) *events.DeviceEvent {
+	name := line.Name
+	if name == "" {
+		name = line.id()
+	}
+	return &events.DeviceEvent{
+		Action:       events.ActionChange,
+		Kind:         events.KindGPIO,
+		DeviceID:     line.id(),
+		Product:      name,
+		Capabilities: "GPIO " + ev.Edge + " edge",
+		Raw: map[string]string{
+			"GPIO_CHIP":    line.chip(),
+			"GPIO_LINE":    strconv.FormatUint(uint64(line.Line), 10),
+			"EDGE":         ev.Edge,
+			"TIMESTAMP_NS": strconv.FormatUint(ev.TimestampNs, 10),
+			"SEQNO":        strconv.FormatUint(uint64(ev.Seqno), 10),
+		},
+	}
+}
@@ -0,0 +1,29 @@
+//go:build !linux
+
+package sources
+
+import (
+	"context"
+
+	"github.com/sipeed/picoclaw/pkg/devices/events"
+)
+
+type GPIOMonitor struct{}
+
+func NewGPIOMonitor(lines []GPIOLine) *GPIOMonitor {
+	return &GPIOMonitor{}
+}
+
+func (m *GPIOMonitor) Kind() events.Kind {
+	return events.KindGPIO
+}
+
+func (m *GPIOMonitor) Start(ctx context.Context) (<-chan *events.DeviceEvent, error) {
+	ch := make(chan *events.DeviceEvent)
+	close(ch) // Immediately close, no events
+	return ch, nil
+}
+
+func (m *GPIOMonitor) Stop() error {
+	return nil
+}
@@ -0,0 +1,38 @@
+//go:build linux
+
+package sources
+
+import (
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/devices/events"
+	"github.com/sipeed/picoclaw/pkg/devices/gpio"
+)
+
+func TestGPIOEvent(t *testing.T) {
+	ev := gpioEvent(GPIOLine{Name: "door", Chip: "gpiochip1", Line: 17},
+		gpio.Event{Edge: gpio.EdgeRising, TimestampNs: 123456789, Seqno: 7})
+	if ev.Kind != events.KindGPIO || ev.Action != events.ActionChange {
+		t.Errorf("kind/action = %s/%s", ev.Kind, ev.Action)
+	}
+	if ev.DeviceID != "gpiochip1:17" || ev.Product != "door" || ev.Capabilities != "GPIO rising edge" {
+		t.Errorf("event = %+v", ev)
+	}
+	if ev.Raw["TIMESTAMP_NS"] != "123456789" || ev.Raw["SEQNO"] != "7" {
+		t.Errorf("raw = %v", ev.Raw)
+	}
+
+	ev = gpioEvent(GPIOLine{Line: 3}, gpio.Event{Edge: gpio.EdgeFalling})
+	if ev.Raw["EDGE"] != EdgeFalling || ev.Product != "gpiochip0:3" || ev.Raw["GPIO_CHIP"] != "gpiochip0" {
+		t.Errorf("falling event = %+v", ev)
+	}
+}
+
+func TestGPIOLineValidate(t *testing.T) {
+	if err := (GPIOLine{Edge: "sideways"}).validate(); err == nil {
+		t.Error("expected invalid edge error")
+	}
+	if err := (GPIOLine{Edge: EdgeBoth}).validate(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,28 @@
+//go:build linux
+
+package sources
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// openNetlink opens a netlink socket subscribed to groups. The socket is
+// non-blocking and wrapped in an *os.File, so reads go through the runtime
+// poller and Close unblocks a pending Read.
+func openNetlink(proto int, groups uint32) (*os.File, error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, proto)
+	if err != nil {
+		return nil, fmt.Errorf("netlink socket: %w", err)
+	}
+	if err := syscall.Bind(fd, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK, Groups: groups}); err != nil {
+		syscall.Close(fd)
+		return nil, fmt.Errorf("netlink bind: %w", err)
+	}
+	if err := syscall.SetNonblock(fd, true); err != nil {
+		syscall.Close(fd)
+		return nil, fmt.Errorf("netlink nonblock: %w", err)
+	}
+	return os.NewFile(uintptr(fd), "netlink"), nil
+}
@@ -0,0 +1,234 @@
+//go:build linux
+
+package sources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/sipeed/picoclaw/pkg/devices/events"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const rtmgrpLink = 0x1 // RTMGRP_LINK
+
+// NetworkMonitor reports network interfaces appearing and disappearing
+// (add/remove) and gaining or losing connectivity (online/offline), from
+// rtnetlink link messages. For Wi-Fi interfaces online/offline follows
+// association with an access point.
+type NetworkMonitor struct {
+	sysRoot string
+	file    *os.File
+	mu      sync.Mutex
+}
+
+func NewNetworkMonitor() *NetworkMonitor {
+	return &NetworkMonitor{sysRoot: "/sys"}
+}
+
+func (m *NetworkMonitor) Kind() events.Kind {
+	return events.KindNetwork
+}
+
+func (m *NetworkMonitor) Start(ctx context.Context) (<-chan *events.DeviceEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	file, err := openNetlink(syscall.NETLINK_ROUTE, rtmgrpLink)
+	if err != nil {
+		return nil, fmt.Errorf("network monitor: %w", err)
+	}
+	m.file = file
+
+	tracker := newLinkTracker(m.sysRoot)
+	if ifaces, err := net.Interfaces(); err == nil {
+		for _, iface := range ifaces {
+			tracker.seed(linkUpdate{
+				index:  int32(iface.Index),
+				name:   iface.Name,
+				online: iface.Flags&net.FlagUp != 0 && iface.Flags&net.FlagRunning != 0,
+			})
+		}
+	}
+
+	eventCh := make(chan *events.DeviceEvent, 16)
+	go func() {
+		defer close(eventCh)
+		buf := make([]byte, 64*1024)
+		for {
+			n, err := file.Read(buf)
+			if err != nil {
+				if ctx.Err() == nil && !errors.Is(err, os.ErrClosed) {
+					logger.ErrorCF("devices", "rtnetlink read error", map[string]any{"error": err.Error()})
+				}
+				return
+			}
+			for _, u := range parseLinkMessages(buf[:n]) {
+				for _, ev := range tracker.update(u) {
+					select {
+					case eventCh <- ev:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		m.Stop()
+	}()
+
+	return eventCh, nil
+}
+
+func (m *NetworkMonitor) Stop() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.file != nil {
+		m.file.Close()
+		m.file = nil
+	}
+	return nil
+}
+
+type linkUpdate struct {
+	index   int32
+	name    string
+	online  bool
+	removed bool
+}
+
+// parseLinkMessages extracts RTM_NEWLINK/RTM_DELLINK updates from an
+// rtnetlink datagram.
+func parseLinkMessages(buf []byte) []linkUpdate {
+	msgs, err := syscall.ParseNetlinkMessage(buf)
+	if err != nil {
+		return nil
+	}
+	var updates []linkUpdate
+	for i := range msgs {
+		msg := &msgs[i]
+		if msg.Header.Type != syscall.RTM_NEWLINK && msg.Header.Type != syscall.RTM_DELLINK {
+			continue
+		}
+		if len(msg.Data) < syscall.SizeofIfInfomsg {
+			continue
+		}
+		info := (*syscall.IfInfomsg)(unsafe.Pointer(&msg.Data[0]))
+		u := linkUpdate{
+			index:   info.Index,
+			online:  info.Flags&syscall.IFF_UP != 0 && info.Flags&syscall.IFF_RUNNING != 0,
+			removed: msg.Header.Type == syscall.RTM_DELLINK,
+		}
+		attrs, err := syscall.ParseNetlinkRouteAttr(msg)
+		if err != nil {
+			continue
+		}
+		for _, a := range attrs {
+			if a.Attr.Type == syscall.IFLA_IFNAME {
+				u.name = strings.TrimRight(string(a.Value), "\x00")
+			}
+		}
+		updates = append(updates, u)
+	}
+	return updates
+}
+
+type linkState struct {
+	name     string
+	online   bool
+	wireless bool
+}
+
+// linkTracker turns link updates, which the kernel sends for any attribute
+// change, into events for the transitions that matter.
+type linkTracker struct {
+	sysRoot string
+	links   map[int32]linkState
+}
+
+func newLinkTracker(sysRoot string) *linkTracker {
+	return &linkTracker{sysRoot: sysRoot, links: make(map[int32]linkState)}
+}
+
+func (t *linkTracker) seed(u linkUpdate) {
+	t.links[u.index] = linkState{name: u.name, online: u.online, wireless: t.isWireless(u.name)}
+}
+
+// isWireless reports whether the interface is a Wi-Fi interface. Only
+// present interfaces can be checked, so the result is remembered per link.
+func (t *linkTracker) isWireless(name string) bool {
+	_, err := os.Stat(filepath.Join(t.sysRoot, "class", "net", name, "wireless"))
+	return err == nil
+}
+
+func (t *linkTracker) update(u linkUpdate) []*events.DeviceEvent {
+	prev, known := t.links[u.index]
+	if u.name == "" {
+		u.name = prev.name
+	}
+	if u.name == "" || u.name == "lo" {
+		return nil
+	}
+
+	if u.removed {
+		delete(t.links, u.index)
+		if !known {
+			return nil
+		}
+		return []*events.DeviceEvent{linkEvent(events.ActionRemove, u, prev.wireless)}
+	}
+
+	state := linkState{name: u.name, online: u.online, wireless: prev.wireless}
+	if !known {
+		state.wireless = t.isWireless(u.name)
+	}
+	t.links[u.index] = state
+
+	var evs []*events.DeviceEvent
+	if !known {
+		evs = append(evs, linkEvent(events.ActionAdd, u, state.wireless))
+		if u.online {
+			evs = append(evs, linkEvent(events.ActionOnline, u, state.wireless))
+		}
+		return evs
+	}
+	if u.online != prev.online {
+		action := events.ActionOffline
+		if u.online {
+			action = events.ActionOnline
+		}
+		evs = append(evs, linkEvent(action, u, state.wireless))
+	}
+	return evs
+}
+
+func linkEvent(action events.Action, u linkUpdate, wireless bool) *events.DeviceEvent {
+	capability := "Network interface"
+	if wireless {
+		capability = "Wi-Fi"
+	}
+	return &events.DeviceEvent{
+		Action:       action,
+		Kind:         events.KindNetwork,
+		DeviceID:     u.name,
+		Product:      u.name,
+		Capabilities: capability,
+		Raw: map[string]string{
+			"INTERFACE": u.name,
+			"IFINDEX":   strconv.Itoa(int(u.index)),
+			"WIRELESS":  strconv.FormatBool(wireless),
+		},
+	}
+}
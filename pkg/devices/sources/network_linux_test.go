@@ -0,0 +1,87 @@
+//go:build linux
+
+package sources
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/devices/events"
+)
+
+// linkMsg builds an rtnetlink link message with an IFLA_IFNAME attribute.
+func linkMsg(typ uint16, index int32, flags uint32, name string) []byte {
+	ne := binary.NativeEndian
+	attrLen := syscall.SizeofRtAttr + len(name) + 1
+	attrSpace := (attrLen + 3) &^ 3
+	total := syscall.SizeofNlMsghdr + syscall.SizeofIfInfomsg + attrSpace
+	buf := make([]byte, total)
+
+	ne.PutUint32(buf[0:], uint32(total))
+	ne.PutUint16(buf[4:], typ)
+	info := buf[syscall.SizeofNlMsghdr:]
+	ne.PutUint32(info[4:], uint32(index))
+	ne.PutUint32(info[8:], flags)
+	attr := info[syscall.SizeofIfInfomsg:]
+	ne.PutUint16(attr[0:], uint16(attrLen))
+	ne.PutUint16(attr[2:], syscall.IFLA_IFNAME)
+	copy(attr[syscall.SizeofRtAttr:], name)
+	return buf
+}
+
+func TestParseLinkMessages(t *testing.T) {
+	buf := append(linkMsg(syscall.RTM_NEWLINK, 3, syscall.IFF_UP|syscall.IFF_RUNNING, "wlan0"),
+		linkMsg(syscall.RTM_DELLINK, 4, 0, "usb0")...)
+	updates := parseLinkMessages(buf)
+	if len(updates) != 2 {
+		t.Fatalf("expected 2 updates, got %d", len(updates))
+	}
+	if u := updates[0]; u.index != 3 || u.name != "wlan0" || !u.online || u.removed {
+		t.Errorf("first update = %+v", u)
+	}
+	if u := updates[1]; u.index != 4 || u.name != "usb0" || u.online || !u.removed {
+		t.Errorf("second update = %+v", u)
+	}
+}
+
+func TestLinkTracker(t *testing.T) {
+	sysRoot := t.TempDir()
+	os.MkdirAll(filepath.Join(sysRoot, "class", "net", "wlan0", "wireless"), 0o755)
+
+	tracker := newLinkTracker(sysRoot)
+	tracker.seed(linkUpdate{index: 1, name: "lo", online: true})
+	tracker.seed(linkUpdate{index: 2, name: "wlan0", online: false})
+
+	actions := func(evs []*events.DeviceEvent) []events.Action {
+		var out []events.Action
+		for _, ev := range evs {
+			out = append(out, ev.Action)
+		}
+		return out
+	}
+
+	// Attribute-only updates don't produce events.
+	if evs := tracker.update(linkUpdate{index: 2, name: "wlan0"}); len(evs) != 0 {
+		t.Errorf("expected no events, got %v", actions(evs))
+	}
+	evs := tracker.update(linkUpdate{index: 2, name: "wlan0", online: true})
+	if len(evs) != 1 || evs[0].Action != events.ActionOnline || evs[0].Capabilities != "Wi-Fi" {
+		t.Fatalf("expected Wi-Fi online, got %+v", evs)
+	}
+	if evs := tracker.update(linkUpdate{index: 1, name: "lo", online: false}); len(evs) != 0 {
+		t.Error("loopback should be ignored")
+	}
+
+	// A new interface that comes up at once.
+	evs = tracker.update(linkUpdate{index: 5, name: "eth1", online: true})
+	if got := actions(evs); len(got) != 2 || got[0] != events.ActionAdd || got[1] != events.ActionOnline {
+		t.Errorf("expected add+online, got %v", got)
+	}
+	evs = tracker.update(linkUpdate{index: 5, removed: true})
+	if len(evs) != 1 || evs[0].Action != events.ActionRemove || evs[0].DeviceID != "eth1" {
+		t.Errorf("expected eth1 remove, got %+v", evs)
+	}
+}
@@ -0,0 +1,29 @@
+//go:build !linux
+
+package sources
+
+import (
+	"context"
+
+	"github.com/sipeed/picoclaw/pkg/devices/events"
+)
+
+type NetworkMonitor struct{}
+
+func NewNetworkMonitor() *NetworkMonitor {
+	return &NetworkMonitor{}
+}
+
+func (m *NetworkMonitor) Kind() events.Kind {
+	return events.KindNetwork
+}
+
+func (m *NetworkMonitor) Start(ctx context.Context) (<-chan *events.DeviceEvent, error) {
+	ch := make(chan *events.DeviceEvent)
+	close(ch) // Immediately close, no events
+	return ch, nil
+}
+
+func (m *NetworkMonitor) Stop() error {
+	return nil
+}
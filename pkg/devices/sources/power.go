@@ -0,0 +1,223 @@
+package sources
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/devices/events"
+)
+
+const (
+	defaultPowerPollInterval = 30 * time.Second
+
+	batteryLowPercent      = 20
+	batteryCriticalPercent = 5
+)
+
+// PowerMonitor polls /sys/class/power_supply and reports AC/USB power being
+// plugged in or unplugged (online/offline), batteries appearing or
+// disappearing (add/remove), and battery status changes or the level
+// dropping below 20% and 5% (change). Many boards don't send uevents for
+// battery changes, hence polling.
+type PowerMonitor struct {
+	root     string
+	interval time.Duration
+	cancel   context.CancelFunc
+	mu       sync.Mutex
+}
+
+// NewPowerMonitor polls every interval; 0 means every 30 seconds.
+func NewPowerMonitor(interval time.Duration) *PowerMonitor {
+	if interval <= 0 {
+		interval = defaultPowerPollInterval
+	}
+	return &PowerMonitor{root: "/sys/class/power_supply", interval: interval}
+}
+
+func (m *PowerMonitor) Kind() events.Kind {
+	return events.KindPower
+}
+
+func (m *PowerMonitor) Start(ctx context.Context) (<-chan *events.DeviceEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ctx, m.cancel = context.WithCancel(ctx)
+	eventCh := make(chan *events.DeviceEvent, 16)
+	prev := readPowerSupplies(m.root)
+
+	go func() {
+		defer close(eventCh)
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			cur := readPowerSupplies(m.root)
+			for _, ev := range diffPowerSupplies(prev, cur) {
+				select {
+				case eventCh <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+			prev = cur
+		}
+	}()
+
+	return eventCh, nil
+}
+
+func (m *PowerMonitor) Stop() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+	return nil
+}
+
+type powerSupply struct {
+	name     string
+	typ      string // "Mains", "USB", "Battery", ...
+	online   bool
+	status   string // batteries: "Charging", "Discharging", "Full", ...
+	capacity int    // batteries: percent, -1 if unknown
+}
+
+func (p powerSupply) isBattery() bool {
+	return p.typ == "Battery"
+}
+
+func readPowerSupplies(root string) map[string]powerSupply {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+	supplies := make(map[string]powerSupply, len(entries))
+	for _, e := range entries {
+		dir := filepath.Join(root, e.Name())
+		p := powerSupply{
+			name:     e.Name(),
+			typ:      readAttr(dir, "type"),
+			online:   readAttr(dir, "online") == "1",
+			status:   readAttr(dir, "status"),
+			capacity: -1,
+		}
+		if c, err := strconv.Atoi(readAttr(dir, "capacity")); err == nil {
+			p.capacity = c
+		}
+		supplies[p.name] = p
+	}
+	return supplies
+}
+
+// diffPowerSupplies returns the events between two snapshots.
+func diffPowerSupplies(prev, cur map[string]powerSupply) []*events.DeviceEvent {
+	var evs []*events.DeviceEvent
+	for name, p := range cur {
+		old, ok := prev[name]
+		switch {
+		case !ok:
+			if p.isBattery() {
+				evs = append(evs, powerEvent(events.ActionAdd, p, ""))
+			} else if p.online {
+				evs = append(evs, powerEvent(events.ActionOnline, p, ""))
+			}
+		case !p.isBattery():
+			if p.online != old.online {
+				action := events.ActionOffline
+				if p.online {
+					action = events.ActionOnline
+				}
+				evs = append(evs, powerEvent(action, p, ""))
+			}
+		default:
+			reason := ""
+			if p.status != old.status {
+				reason = "status"
+			}
+			if level := batteryLevel(p.capacity); level != "" && level != batteryLevel(old.capacity) &&
+				p.capacity < old.capacity {
+				reason = "level"
+			}
+			if reason != "" {
+				evs = append(evs, powerEvent(events.ActionChange, p, reason))
+			}
+		}
+	}
+	for name, old := range prev {
+		if _, ok := cur[name]; ok {
+			continue
+		}
+		if old.isBattery() {
+			evs = append(evs, powerEvent(events.ActionRemove, old, ""))
+		} else if old.online {
+			evs = append(evs, powerEvent(events.ActionOffline, old, ""))
+		}
+	}
+	return evs
+}
+
+// batteryLevel buckets a capacity into "critical", "low" or "".
+func batteryLevel(capacity int) string {
+	switch {
+	case capacity < 0:
+		return ""
+	case capacity <= batteryCriticalPercent:
+		return "critical"
+	case capacity <= batteryLowPercent:
+		return "low"
+	}
+	return ""
+}
+
+func powerEvent(action events.Action, p powerSupply, reason string) *events.DeviceEvent {
+	ev := &events.DeviceEvent{
+		Action:   action,
+		Kind:     events.KindPower,
+		DeviceID: p.name,
+		Product:  p.name,
+		Raw: map[string]string{
+			"POWER_SUPPLY_NAME": p.name,
+			"POWER_SUPPLY_TYPE": p.typ,
+		},
+	}
+	if p.isBattery() {
+		ev.Capabilities = "Battery"
+		if p.status != "" {
+			ev.Capabilities += " " + strings.ToLower(p.status)
+			ev.Raw["POWER_SUPPLY_STATUS"] = p.status
+		}
+		if p.capacity >= 0 {
+			ev.Raw["POWER_SUPPLY_CAPACITY"] = strconv.Itoa(p.capacity)
+			if level := batteryLevel(p.capacity); level != "" {
+				ev.Capabilities += ", " + level
+			}
+		}
+		if reason != "" {
+			ev.Raw["CHANGE"] = reason
+		}
+	} else {
+		ev.Capabilities = p.typ + " power"
+		ev.Raw["POWER_SUPPLY_ONLINE"] = strconv.FormatBool(p.online)
+	}
+	return ev
+}
+
+func readAttr(dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,81 @@
+package sources
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/devices/events"
+)
+
+func writePowerSupply(t *testing.T, root, name string, attrs map[string]string) {
+	t.Helper()
+	dir := filepath.Join(root, name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for attr, value := range attrs {
+		if err := os.WriteFile(filepath.Join(dir, attr), []byte(value+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDiffPowerSupplies(t *testing.T) {
+	root := t.TempDir()
+	writePowerSupply(t, root, "AC", map[string]string{"type": "Mains", "online": "1"})
+	writePowerSupply(t, root, "BAT0", map[string]string{"type": "Battery", "status": "Charging", "capacity": "50"})
+	prev := readPowerSupplies(root)
+
+	if evs := diffPowerSupplies(prev, readPowerSupplies(root)); len(evs) != 0 {
+		t.Fatalf("expected no events without changes, got %d", len(evs))
+	}
+
+	// Unplugging AC: AC goes offline and the battery starts discharging.
+	writePowerSupply(t, root, "AC", map[string]string{"online": "0"})
+	writePowerSupply(t, root, "BAT0", map[string]string{"status": "Discharging"})
+	cur := readPowerSupplies(root)
+	evs := diffPowerSupplies(prev, cur)
+	got := map[string]*events.DeviceEvent{}
+	for _, ev := range evs {
+		got[ev.DeviceID] = ev
+	}
+	if len(evs) != 2 || got["AC"].Action != events.ActionOffline || got["BAT0"].Action != events.ActionChange {
+		t.Fatalf("unexpected events: %+v", evs)
+	}
+	if got["AC"].Kind != events.KindPower || got["AC"].Capabilities != "Mains power" {
+		t.Errorf("AC event = %+v", got["AC"])
+	}
+	if got["BAT0"].Raw["CHANGE"] != "status" || got["BAT0"].Capabilities != "Battery discharging" {
+		t.Errorf("battery event = %+v", got["BAT0"])
+	}
+	prev = cur
+
+	// Small drops are quiet; crossing the low threshold is reported once.
+	writePowerSupply(t, root, "BAT0", map[string]string{"capacity": "30"})
+	cur = readPowerSupplies(root)
+	if evs := diffPowerSupplies(prev, cur); len(evs) != 0 {
+		t.Errorf("expected no events, got %+v", evs)
+	}
+	prev = cur
+	writePowerSupply(t, root, "BAT0", map[string]string{"capacity": "19"})
+	cur = readPowerSupplies(root)
+	evs = diffPowerSupplies(prev, cur)
+	if len(evs) != 1 || evs[0].Raw["CHANGE"] != "level" || evs[0].Capabilities != "Battery discharging, low" {
+		t.Errorf("expected low battery event, got %+v", evs)
+	}
+	prev = cur
+	writePowerSupply(t, root, "BAT0", map[string]string{"capacity": "18"})
+	cur = readPowerSupplies(root)
+	if evs := diffPowerSupplies(prev, cur); len(evs) != 0 {
+		t.Errorf("expected no repeat, got %+v", evs)
+	}
+	prev = cur
+
+	// Removing the battery.
+	os.RemoveAll(filepath.Join(root, "BAT0"))
+	evs = diffPowerSupplies(prev, readPowerSupplies(root))
+	if len(evs) != 1 || evs[0].Action != events.ActionRemove {
+		t.Errorf("expected battery remove, got %+v", evs)
+	}
+}
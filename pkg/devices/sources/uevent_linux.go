@@ -0,0 +1,244 @@
+//go:build linux
+
+package sources
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/devices/events"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	netlinkKobjectUEvent = 15 // NETLINK_KOBJECT_UEVENT
+	ueventKernelGroup    = 1  // kernel broadcasts; udev rebroadcasts on group 2
+
+	usbInterfaceRetries = 5
+	usbInterfaceDelay   = 100 * time.Millisecond
+)
+
+// UEventMonitor reads kernel uevents from a netlink socket, so unlike
+// USBMonitor it needs no udevadm binary. Kernel events carry fewer
+// properties than udev's; for USB devices names and serial numbers are
+// read from sysfs when the device is added.
+type UEventMonitor struct {
+	subsystems map[string]bool
+	sysRoot    string
+	file       *os.File
+	mu         sync.Mutex
+}
+
+// NewUEventMonitor monitors the given subsystems ("usb", "bluetooth", "pci",
+// "block", ...). USB, Bluetooth and PCI events get their own kinds; other
+// subsystems are reported as generic events.
+func NewUEventMonitor(subsystems []string) *UEventMonitor {
+	m := &UEventMonitor{subsystems: make(map[string]bool), sysRoot: "/sys"}
+	for _, s := range subsystems {
+		m.subsystems[s] = true
+	}
+	return m
+}
+
+func (m *UEventMonitor) Kind() events.Kind {
+	return events.KindGeneric
+}
+
+func (m *UEventMonitor) Start(ctx context.Context) (<-chan *events.DeviceEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	file, err := openNetlink(netlinkKobjectUEvent, ueventKernelGroup)
+	if err != nil {
+		return nil, fmt.Errorf("uevent: %w", err)
+	}
+	m.file = file
+	eventCh := make(chan *events.DeviceEvent, 16)
+
+	go func() {
+		defer close(eventCh)
+		buf := make([]byte, 64*1024)
+		for {
+			n, err := file.Read(buf)
+			if err != nil {
+				if ctx.Err() == nil && !errors.Is(err, os.ErrClosed) {
+					logger.ErrorCF("devices", "uevent read error", map[string]any{"error": err.Error()})
+				}
+				return
+			}
+			action, props := parseUEvent(buf[:n])
+			if action == "" || !m.subsystems[props["SUBSYSTEM"]] {
+				continue
+			}
+			ev := m.toDeviceEvent(action, props)
+			if ev == nil {
+				continue
+			}
+			select {
+			case eventCh <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		m.Stop()
+	}()
+
+	return eventCh, nil
+}
+
+func (m *UEventMonitor) Stop() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.file != nil {
+		m.file.Close()
+		m.file = nil
+	}
+	return nil
+}
+
+// parseUEvent parses a kernel uevent: "action@devpath" followed by
+// NUL-separated KEY=value pairs. Messages from udev (prefixed "libudev")
+// are ignored.
+func parseUEvent(msg []byte) (string, map[string]string) {
+	if bytes.HasPrefix(msg, []byte("libudev")) {
+		return "", nil
+	}
+	fields := bytes.Split(msg, []byte{0})
+	header, _, ok := strings.Cut(string(fields[0]), "@")
+	if !ok {
+		return "", nil
+	}
+	props := make(map[string]string)
+	for _, f := range fields[1:] {
+		if key, val, ok := strings.Cut(string(f), "="); ok && key != "" {
+			props[key] = val
+		}
+	}
+	action := props["ACTION"]
+	if action == "" {
+		action = header
+	}
+	return action, props
+}
+
+func (m *UEventMonitor) toDeviceEvent(action string, props map[string]string) *events.DeviceEvent {
+	ev := &events.DeviceEvent{Raw: props}
+	switch action {
+	case "add":
+		ev.Action = events.ActionAdd
+	case "remove":
+		ev.Action = events.ActionRemove
+	case "change":
+		ev.Action = events.ActionChange
+	default:
+		return nil // bind, unbind, move, ...
+	}
+
+	devpath := props["DEVPATH"]
+	ev.DeviceID = filepath.Base(devpath)
+
+	switch props["SUBSYSTEM"] {
+	case "usb":
+		// Interfaces repeat their device's events.
+		if props["DEVTYPE"] != "usb_device" {
+			return nil
+		}
+		ev.Kind = events.KindUSB
+		m.fillUSB(ev, props)
+	case "bluetooth":
+		ev.Kind = events.KindBluetooth
+		ev.Product = ev.DeviceID
+		if props["DEVTYPE"] == "link" {
+			ev.Capabilities = "Bluetooth connection"
+		} else {
+			ev.Capabilities = "Bluetooth adapter"
+		}
+	case "pci":
+		ev.Kind = events.KindPCI
+		ev.VendorID, ev.ProductID, _ = strings.Cut(strings.ToLower(props["PCI_ID"]), ":")
+		ev.Vendor, ev.Product = ev.VendorID, ev.ProductID
+		if slot := props["PCI_SLOT_NAME"]; slot != "" {
+			ev.DeviceID = slot
+		}
+		ev.Capabilities = "PCI device"
+	default:
+		ev.Kind = events.KindGeneric
+		ev.Product = ev.DeviceID
+		if name := props["DEVNAME"]; name != "" {
+			ev.Product = name
+		}
+		ev.Capabilities = props["SUBSYSTEM"]
+		if devType := props["DEVTYPE"]; devType != "" {
+			ev.Capabilities += " " + devType
+		}
+	}
+	return ev
+}
+
+// fillUSB sets USB identity from the uevent, and from sysfs when the device
+// is still present.
+func (m *UEventMonitor) fillUSB(ev *events.DeviceEvent, props map[string]string) {
+	// PRODUCT is "vid/pid/bcdDevice" in unpadded hex.
+	parts := strings.Split(props["PRODUCT"], "/")
+	if len(parts) >= 2 {
+		ev.VendorID = padHex4(parts[0])
+		ev.ProductID = padHex4(parts[1])
+	}
+	if bus, dev := props["BUSNUM"], props["DEVNUM"]; bus != "" && dev != "" {
+		ev.DeviceID = bus + ":" + dev
+	}
+
+	// TYPE is "class/subclass/protocol" in decimal.
+	class := ""
+	if c, err := strconv.Atoi(strings.Split(props["TYPE"], "/")[0]); err == nil {
+		class = fmt.Sprintf("%02x", c)
+	}
+
+	if props["ACTION"] != "remove" {
+		dir := filepath.Join(m.sysRoot, props["DEVPATH"])
+		ev.Vendor = readAttr(dir, "manufacturer")
+		ev.Product = readAttr(dir, "product")
+		ev.Serial = readAttr(dir, "serial")
+		// Most devices declare their class per interface. Interfaces are
+		// registered just after the device, so give them a moment.
+		if class == "" || class == "00" {
+			for i := 0; i < usbInterfaceRetries; i++ {
+				if matches, _ := filepath.Glob(filepath.Join(dir, "*:*", "bInterfaceClass")); len(matches) > 0 {
+					class = strings.ToLower(readAttr(filepath.Dir(matches[0]), "bInterfaceClass"))
+					break
+				}
+				time.Sleep(usbInterfaceDelay)
+			}
+		}
+	}
+	if ev.Vendor == "" {
+		ev.Vendor = ev.VendorID
+	}
+	if ev.Product == "" {
+		ev.Product = ev.ProductID
+	}
+	ev.Capabilities = usbClassToCapability[class]
+	if ev.Capabilities == "" {
+		ev.Capabilities = "USB Device"
+	}
+}
+
+func padHex4(s string) string {
+	s = strings.ToLower(s)
+	if len(s) < 4 {
+		s = strings.Repeat("0", 4-len(s)) + s
+	}
+	return s
+}
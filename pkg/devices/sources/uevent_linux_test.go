@@ -0,0 +1,107 @@
+//go:build linux
+
+package sources
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/devices/events"
+)
+
+func ueventMsg(header string, props ...string) []byte {
+	return []byte(header + "\x00" + strings.Join(props, "\x00") + "\x00")
+}
+
+func TestParseUEvent(t *testing.T) {
+	action, props := parseUEvent(ueventMsg("add@/devices/pci0000:00/usb1/1-2",
+		"ACTION=add", "DEVPATH=/devices/pci0000:00/usb1/1-2", "SUBSYSTEM=usb", "SEQNUM=42"))
+	if action != "add" || props["SUBSYSTEM"] != "usb" || props["SEQNUM"] != "42" {
+		t.Errorf("unexpected parse: %q %v", action, props)
+	}
+
+	if action, _ := parseUEvent([]byte("libudev\x00\xfe\xed")); action != "" {
+		t.Error("udev messages should be ignored")
+	}
+	if action, _ := parseUEvent([]byte("garbage")); action != "" {
+		t.Error("messages without a header should be ignored")
+	}
+}
+
+func TestUEventUSBDevice(t *testing.T) {
+	sysRoot := t.TempDir()
+	devpath := "/devices/pci0000:00/usb1/1-2"
+	dir := filepath.Join(sysRoot, devpath)
+	for name, content := range map[string]string{
+		"manufacturer":            "SanDisk\n",
+		"product":                 "Cruzer Blade\n",
+		"serial":                  "4C530001\n",
+		"1-2:1.0/bInterfaceClass": "08\n",
+	} {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := NewUEventMonitor([]string{"usb"})
+	m.sysRoot = sysRoot
+	props := map[string]string{
+		"ACTION": "add", "DEVPATH": devpath, "SUBSYSTEM": "usb", "DEVTYPE": "usb_device",
+		"PRODUCT": "781/5567/100", "TYPE": "0/0/0", "BUSNUM": "001", "DEVNUM": "004",
+	}
+	ev := m.toDeviceEvent("add", props)
+	if ev == nil {
+		t.Fatal("expected an event")
+	}
+	if ev.Kind != events.KindUSB || ev.Action != events.ActionAdd {
+		t.Errorf("kind/action = %s/%s", ev.Kind, ev.Action)
+	}
+	if ev.VendorID != "0781" || ev.ProductID != "5567" || ev.DeviceID != "001:004" {
+		t.Errorf("ids = %s/%s/%s", ev.VendorID, ev.ProductID, ev.DeviceID)
+	}
+	if ev.Vendor != "SanDisk" || ev.Product != "Cruzer Blade" || ev.Serial != "4C530001" {
+		t.Errorf("names = %q %q %q", ev.Vendor, ev.Product, ev.Serial)
+	}
+	if !strings.Contains(ev.Capabilities, "Mass Storage") {
+		t.Errorf("capabilities = %q", ev.Capabilities)
+	}
+
+	props["DEVTYPE"] = "usb_interface"
+	if m.toDeviceEvent("add", props) != nil {
+		t.Error("interface events should be skipped")
+	}
+}
+
+func TestUEventOtherSubsystems(t *testing.T) {
+	m := NewUEventMonitor([]string{"bluetooth", "pci", "block"})
+
+	ev := m.toDeviceEvent("add", map[string]string{
+		"DEVPATH": "/devices/platform/soc/bluetooth/hci0", "SUBSYSTEM": "bluetooth", "DEVTYPE": "host",
+	})
+	if ev == nil || ev.Kind != events.KindBluetooth || ev.Product != "hci0" {
+		t.Errorf("bluetooth event = %+v", ev)
+	}
+
+	ev = m.toDeviceEvent("remove", map[string]string{
+		"DEVPATH": "/devices/pci0000:00/0000:00:1c.0", "SUBSYSTEM": "pci",
+		"PCI_ID": "8086:A110", "PCI_SLOT_NAME": "0000:00:1c.0",
+	})
+	if ev == nil || ev.Kind != events.KindPCI || ev.VendorID != "8086" || ev.ProductID != "a110" {
+		t.Errorf("pci event = %+v", ev)
+	}
+
+	ev = m.toDeviceEvent("add", map[string]string{
+		"DEVPATH": "/devices/virtual/block/sdb/sdb1", "SUBSYSTEM": "block", "DEVTYPE": "partition", "DEVNAME": "sdb1",
+	})
+	if ev == nil || ev.Kind != events.KindGeneric || ev.Product != "sdb1" || ev.Capabilities != "block partition" {
+		t.Errorf("block event = %+v", ev)
+	}
+
+	if m.toDeviceEvent("bind", map[string]string{"SUBSYSTEM": "pci"}) != nil {
+		t.Error("bind events should be skipped")
+	}
+}
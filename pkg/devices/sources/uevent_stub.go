@@ -0,0 +1,29 @@
+//go:build !linux
+
+package sources
+
+import (
+	"context"
+
+	"github.com/sipeed/picoclaw/pkg/devices/events"
+)
+
+type UEventMonitor struct{}
+
+func NewUEventMonitor(subsystems []string) *UEventMonitor {
+	return &UEventMonitor{}
+}
+
+func (m *UEventMonitor) Kind() events.Kind {
+	return events.KindGeneric
+}
+
+func (m *UEventMonitor) Start(ctx context.Context) (<-chan *events.DeviceEvent, error) {
+	ch := make(chan *events.DeviceEvent)
+	close(ch) // Immediately close, no events
+	return ch, nil
+}
+
+func (m *UEventMonitor) Stop() error {
+	return nil
+}
@@ -177,6 +177,8 @@ func parseUSBEvent(action string, props map[string]string) *events.DeviceEvent {
 		ev.Product = "Unknown Device"
 	}
 
+	ev.VendorID = props["ID_VENDOR_ID"]
+	ev.ProductID = props["ID_MODEL_ID"]
 	ev.Serial = props["ID_SERIAL_SHORT"]
 	ev.DeviceID = props["DEVPATH"]
 	if bus := props["BUSNUM"]; bus != "" {
@@ -185,9 +187,17 @@ func parseUSBEvent(action string, props map[string]string) *events.DeviceEvent {
 		}
 	}
 
-	// Map USB class to capability
-	if class := props["ID_USB_CLASS"]; class != "" {
-		ev.Capabilities = usbClassToCapability[strings.ToLower(class)]
+	// Map USB class to capability. Most devices declare their class per
+	// interface (device class 00), so fall back to the first interface,
+	// listed as ":CCSSPP:" in ID_USB_INTERFACES.
+	class := strings.ToLower(props["ID_USB_CLASS"])
+	if class == "" || class == "00" {
+		if ifaces := strings.Trim(props["ID_USB_INTERFACES"], ":"); len(ifaces) >= 2 {
+			class = strings.ToLower(ifaces[:2])
+		}
+	}
+	if class != "" {
+		ev.Capabilities = usbClassToCapability[class]
 	}
 	if ev.Capabilities == "" {
 		ev.Capabilities = "USB Device"
@@ -0,0 +1,307 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// CheckResult is the outcome of one expectation or assertion.
+type CheckResult struct {
+	Check  string `json:"check"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Judge grades responses against free-form criteria with an LLM.
+type Judge struct {
+	Provider providers.LLMProvider
+	Model    string
+}
+
+const judgePrompt = `You are grading an AI assistant's reply in an automated test.
+
+User prompt:
+%s
+
+Assistant reply:
+%s
+
+Criterion: %s
+
+Does the reply meet the criterion? Answer PASS or FAIL on the first line, then give a one-sentence reason.`
+
+// Grade asks the judge model whether response meets criterion.
+func (j *Judge) Grade(ctx context.Context, prompt, response, criterion string) (bool, string, error) {
+	resp, err := j.Provider.Chat(ctx, []providers.Message{
+		{Role: "user", Content: fmt.Sprintf(judgePrompt, prompt, response, criterion)},
+	}, nil, j.Model, map[string]any{"temperature": 0.0, "max_tokens": 256})
+	if err != nil {
+		return false, "", err
+	}
+	verdict, reason, _ := strings.Cut(strings.TrimSpace(resp.Content), "\n")
+	verdict = strings.ToUpper(strings.Trim(strings.TrimSpace(verdict), "*.:"))
+	reason = strings.TrimSpace(reason)
+	switch {
+	case strings.HasPrefix(verdict, "PASS"):
+		return true, reason, nil
+	case strings.HasPrefix(verdict, "FAIL"):
+		return false, reason, nil
+	}
+	return false, "", fmt.Errorf("judge gave no verdict: %s", utils.Truncate(resp.Content, 200))
+}
+
+// checkTools checks the expected and forbidden tool calls.
+func checkTools(c *Case, calls []ToolCall) []CheckResult {
+	var results []CheckResult
+
+	next := 0
+	for _, want := range c.Tools {
+		r := CheckResult{Check: "tool " + want.Name}
+		if len(want.Args) > 0 {
+			args, _ := json.Marshal(want.Args)
+			r.Check += " " + string(args)
+		}
+		for next < len(calls) && !toolMatches(want, calls[next]) {
+			next++
+		}
+		if next < len(calls) {
+			r.Passed = true
+			next++
+		} else {
+			r.Detail = "not called (in order); calls: " + toolNames(calls)
+		}
+		results = append(results, r)
+	}
+
+	for _, name := range c.ForbidTools {
+		r := CheckResult{Check: "no tool " + name, Passed: true}
+		for _, call := range calls {
+			if call.Name == name {
+				r.Passed = false
+				r.Detail = "was called"
+				break
+			}
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+func toolMatches(want ToolExpectation, call ToolCall) bool {
+	if want.Name != call.Name {
+		return false
+	}
+	for k, v := range want.Args {
+		got, ok := call.Arguments[k]
+		if !ok || !sameValue(v, got) {
+			return false
+		}
+	}
+	return true
+}
+
+func toolNames(calls []ToolCall) string {
+	if len(calls) == 0 {
+		return "none"
+	}
+	names := make([]string, len(calls))
+	for i, c := range calls {
+		names[i] = c.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// checkAssertion evaluates one assertion against the final response.
+func checkAssertion(ctx context.Context, a Assertion, prompt, response string, judge *Judge) CheckResult {
+	switch {
+	case a.Contains != "":
+		r := CheckResult{Check: "contains " + strconv.Quote(a.Contains)}
+		r.Passed = strings.Contains(response, a.Contains)
+		return r
+	case a.NotContains != "":
+		r := CheckResult{Check: "not contains " + strconv.Quote(a.NotContains)}
+		r.Passed = !strings.Contains(response, a.NotContains)
+		return r
+	case a.Regex != "":
+		r := CheckResult{Check: "regex " + a.Regex}
+		r.Passed = regexp.MustCompile(a.Regex).MatchString(response)
+		return r
+	case a.JSONPath != "":
+		return checkJSONPath(a, response)
+	default:
+		r := CheckResult{Check: "judge " + strconv.Quote(a.Judge)}
+		if judge == nil || judge.Provider == nil {
+			r.Detail = "no judge model configured"
+			return r
+		}
+		passed, reason, err := judge.Grade(ctx, prompt, response, a.Judge)
+		if err != nil {
+			r.Detail = err.Error()
+			return r
+		}
+		r.Passed, r.Detail = passed, reason
+		return r
+	}
+}
+
+func checkJSONPath(a Assertion, response string) CheckResult {
+	r := CheckResult{Check: "json_path " + a.JSONPath}
+	if a.Equals != nil {
+		want, _ := json.Marshal(a.Equals)
+		r.Check += " == " + string(want)
+	}
+
+	doc, ok := extractJSON(response)
+	if !ok {
+		r.Detail = "no JSON found in response"
+		return r
+	}
+	steps, _ := parseJSONPath(a.JSONPath)
+	got, ok := lookupJSONPath(doc, steps)
+	if !ok {
+		r.Detail = "path not found"
+		return r
+	}
+	if a.Equals == nil || sameValue(a.Equals, got) {
+		r.Passed = true
+		return r
+	}
+	data, _ := json.Marshal(got)
+	r.Detail = "got " + utils.Truncate(string(data), 200)
+	return r
+}
+
+// extractJSON finds the JSON document in a response: the whole response,
+// a fenced code block, or the first object or array in the text.
+func extractJSON(response string) (any, bool) {
+	var doc any
+	text := strings.TrimSpace(response)
+	if json.Unmarshal([]byte(text), &doc) == nil {
+		return doc, true
+	}
+	for rest := text; ; {
+		_, block, found := strings.Cut(rest, "```")
+		if !found {
+			break
+		}
+		body, after, _ := strings.Cut(block, "```")
+		// Drop the language tag, if any.
+		if i := strings.IndexByte(body, '\n'); i >= 0 && !strings.ContainsAny(body[:i], "{[") {
+			body = body[i+1:]
+		}
+		if json.Unmarshal([]byte(strings.TrimSpace(body)), &doc) == nil {
+			return doc, true
+		}
+		rest = after
+	}
+	for i, ch := range text {
+		if ch != '{' && ch != '[' {
+			continue
+		}
+		if json.NewDecoder(strings.NewReader(text[i:])).Decode(&doc) == nil {
+			return doc, true
+		}
+	}
+	return nil, false
+}
+
+// parseJSONPath parses the subset of JSONPath used in assertions:
+// $.a.b, $.a[0] and $["a b"].
+func parseJSONPath(path string) ([]any, error) {
+	p := strings.TrimSpace(path)
+	if !strings.HasPrefix(p, "$") {
+		return nil, fmt.Errorf("json_path %q must start with $", path)
+	}
+	p = p[1:]
+
+	var steps []any
+	for p != "" {
+		switch p[0] {
+		case '.':
+			p = p[1:]
+			end := strings.IndexAny(p, ".[")
+			if end < 0 {
+				end = len(p)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("json_path %q: empty field name", path)
+			}
+			steps = append(steps, p[:end])
+			p = p[end:]
+		case '[':
+			end := strings.IndexByte(p, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("json_path %q: missing ]", path)
+			}
+			inner := strings.TrimSpace(p[1:end])
+			p = p[end+1:]
+			if n, err := strconv.Atoi(inner); err == nil {
+				steps = append(steps, n)
+				continue
+			}
+			name, err := strconv.Unquote(strings.ReplaceAll(inner, "'", `"`))
+			if err != nil {
+				return nil, fmt.Errorf("json_path %q: invalid index %s", path, inner)
+			}
+			steps = append(steps, name)
+		default:
+			return nil, fmt.Errorf("json_path %q: unexpected %q", path, p[0])
+		}
+	}
+	return steps, nil
+}
+
+func lookupJSONPath(doc any, steps []any) (any, bool) {
+	cur := doc
+	for _, step := range steps {
+		switch s := step.(type) {
+		case string:
+			m, ok := cur.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if cur, ok = m[s]; !ok {
+				return nil, false
+			}
+		case int:
+			a, ok := cur.([]any)
+			if !ok {
+				return nil, false
+			}
+			if s < 0 {
+				s += len(a)
+			}
+			if s < 0 || s >= len(a) {
+				return nil, false
+			}
+			cur = a[s]
+		}
+	}
+	return cur, true
+}
+
+// sameValue compares values from YAML and JSON, which decode numbers and
+// maps into different types.
+func sameValue(want, got any) bool {
+	return reflect.DeepEqual(normalize(want), normalize(got))
+}
+
+func normalize(v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out any
+	if json.Unmarshal(data, &out) != nil {
+		return v
+	}
+	return out
+}
@@ -0,0 +1,147 @@
+package eval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+type judgeProvider struct {
+	reply string
+}
+
+func (p *judgeProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	options map[string]any,
+) (*providers.LLMResponse, error) {
+	return &providers.LLMResponse{Content: p.reply}, nil
+}
+
+func (p *judgeProvider) GetDefaultModel() string { return "judge" }
+
+func TestExtractJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		ok       bool
+	}{
+		{"whole", `{"a": 1}`, true},
+		{"fenced", "Here you go:\n```json\n{\"a\": 1}\n```\nDone.", true},
+		{"inline", `The result is {"a": 1} as requested.`, true},
+		{"array", `Items: [1, 2]`, true},
+		{"none", "no json here", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := extractJSON(tt.response); ok != tt.ok {
+				t.Errorf("extractJSON(%q) ok = %v, want %v", tt.response, ok, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCheckAssertion_JSONPath(t *testing.T) {
+	response := "```json\n{\"items\": [{\"name\": \"milk\", \"qty\": 2}], \"the key\": true}\n```"
+	tests := []struct {
+		a    Assertion
+		pass bool
+	}{
+		{Assertion{JSONPath: "$.items[0].name", Equals: "milk"}, true},
+		{Assertion{JSONPath: "$.items[0].qty", Equals: 2}, true},
+		{Assertion{JSONPath: "$.items[-1].qty", Equals: 2.0}, true},
+		{Assertion{JSONPath: `$["the key"]`, Equals: true}, true},
+		{Assertion{JSONPath: "$.items[0]", Equals: map[string]any{"name": "milk", "qty": 2}}, true},
+		{Assertion{JSONPath: "$.items"}, true},
+		{Assertion{JSONPath: "$.items[0].name", Equals: "eggs"}, false},
+		{Assertion{JSONPath: "$.items[3]"}, false},
+		{Assertion{JSONPath: "$.missing"}, false},
+	}
+	for _, tt := range tests {
+		r := checkAssertion(context.Background(), tt.a, "", response, nil)
+		if r.Passed != tt.pass {
+			t.Errorf("%s: passed = %v, want %v (%s)", r.Check, r.Passed, tt.pass, r.Detail)
+		}
+	}
+}
+
+func TestCheckAssertion_Text(t *testing.T) {
+	response := "You need to buy Milk."
+	tests := []struct {
+		a    Assertion
+		pass bool
+	}{
+		{Assertion{Contains: "Milk"}, true},
+		{Assertion{Contains: "milk"}, false},
+		{Assertion{NotContains: "sorry"}, true},
+		{Assertion{Regex: `(?i)\bmilk\b`}, true},
+		{Assertion{Regex: `^Milk`}, false},
+	}
+	for _, tt := range tests {
+		r := checkAssertion(context.Background(), tt.a, "", response, nil)
+		if r.Passed != tt.pass {
+			t.Errorf("%s: passed = %v, want %v", r.Check, r.Passed, tt.pass)
+		}
+	}
+}
+
+func TestCheckAssertion_Judge(t *testing.T) {
+	a := Assertion{Judge: "mentions milk"}
+
+	r := checkAssertion(context.Background(), a, "q", "buy milk", &Judge{Provider: &judgeProvider{"PASS\nIt does."}})
+	if !r.Passed || r.Detail != "It does." {
+		t.Errorf("PASS verdict: %+v", r)
+	}
+	r = checkAssertion(context.Background(), a, "q", "buy eggs", &Judge{Provider: &judgeProvider{"**FAIL**\nNo milk."}})
+	if r.Passed || r.Detail != "No milk." {
+		t.Errorf("FAIL verdict: %+v", r)
+	}
+	r = checkAssertion(context.Background(), a, "q", "x", &Judge{Provider: &judgeProvider{"Maybe?"}})
+	if r.Passed || r.Detail == "" {
+		t.Errorf("unclear verdict should fail with a reason: %+v", r)
+	}
+	r = checkAssertion(context.Background(), a, "q", "x", nil)
+	if r.Passed {
+		t.Error("judge assertion without a judge should fail")
+	}
+}
+
+func TestCheckTools(t *testing.T) {
+	calls := []ToolCall{
+		{Name: "list_dir", Arguments: map[string]any{"path": "."}},
+		{Name: "read_file", Arguments: map[string]any{"path": "notes.txt", "limit": float64(10)}},
+		{Name: "write_file", Arguments: map[string]any{"path": "out.txt"}},
+	}
+	c := &Case{
+		Tools: []ToolExpectation{
+			{Name: "read_file", Args: map[string]any{"path": "notes.txt", "limit": 10}},
+			{Name: "write_file"},
+		},
+		ForbidTools: []string{"exec"},
+	}
+	for _, r := range checkTools(c, calls) {
+		if !r.Passed {
+			t.Errorf("%s failed: %s", r.Check, r.Detail)
+		}
+	}
+
+	// Out of order, wrong args and forbidden calls fail.
+	c = &Case{
+		Tools: []ToolExpectation{
+			{Name: "write_file"},
+			{Name: "read_file"},
+		},
+		ForbidTools: []string{"list_dir"},
+	}
+	results := checkTools(c, calls)
+	if !results[0].Passed || results[1].Passed || results[2].Passed {
+		t.Errorf("unexpected results: %+v", results)
+	}
+	results = checkTools(&Case{Tools: []ToolExpectation{{Name: "read_file", Args: map[string]any{"path": "x"}}}}, calls)
+	if results[0].Passed {
+		t.Error("expected args mismatch to fail")
+	}
+}
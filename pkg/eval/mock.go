@@ -0,0 +1,70 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// ScriptedProvider replays a case's mock turns in order, one per LLM call.
+// It fails once the script is used up, so a case that needs more calls
+// than it scripted is caught.
+type ScriptedProvider struct {
+	mu    sync.Mutex
+	turns []MockTurn
+	next  int
+}
+
+// NewScriptedProvider returns a provider that serves turns in order.
+func NewScriptedProvider(turns []MockTurn) *ScriptedProvider {
+	return &ScriptedProvider{turns: turns}
+}
+
+func (p *ScriptedProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	options map[string]any,
+) (*providers.LLMResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.next >= len(p.turns) {
+		return nil, fmt.Errorf("mock script exhausted after %d turn(s)", len(p.turns))
+	}
+	turn := p.turns[p.next]
+	p.next++
+
+	if turn.Error != "" {
+		return nil, errors.New(turn.Error)
+	}
+
+	resp := &providers.LLMResponse{
+		Content:      turn.Content,
+		FinishReason: "stop",
+		Usage:        &providers.UsageInfo{},
+	}
+	for i, tc := range turn.ToolCalls {
+		args := tc.Arguments
+		if args == nil {
+			args = map[string]any{}
+		}
+		resp.ToolCalls = append(resp.ToolCalls, providers.ToolCall{
+			ID:        fmt.Sprintf("mock_%d_%d", p.next, i+1),
+			Name:      tc.Name,
+			Arguments: args,
+		})
+	}
+	if len(resp.ToolCalls) > 0 {
+		resp.FinishReason = "tool_calls"
+	}
+	return resp, nil
+}
+
+func (p *ScriptedProvider) GetDefaultModel() string {
+	return "mock"
+}
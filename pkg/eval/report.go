@@ -0,0 +1,266 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// Report is the result of an eval run. It is saved as JSON so later runs
+// can be compared against it.
+type Report struct {
+	StartedAt  time.Time    `json:"started_at"`
+	DurationMS int64        `json:"duration_ms"`
+	Model      string       `json:"model,omitempty"`
+	Mode       string       `json:"mode"`
+	Passed     int          `json:"passed"`
+	Failed     int          `json:"failed"`
+	Errors     int          `json:"errors"`
+	Skipped    int          `json:"skipped"`
+	Cases      []CaseResult `json:"cases"`
+}
+
+// CaseResult is the outcome of one case.
+type CaseResult struct {
+	Suite      string        `json:"suite"`
+	Case       string        `json:"case"`
+	Status     string        `json:"status"`
+	Provider   string        `json:"provider,omitempty"`
+	Response   string        `json:"response,omitempty"`
+	ToolCalls  []ToolCall    `json:"tool_calls"`
+	Checks     []CheckResult `json:"checks,omitempty"`
+	Usage      Usage         `json:"usage"`
+	DurationMS int64         `json:"duration_ms"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// ToolCall is a tool call the agent made during a case.
+type ToolCall struct {
+	Name      string         `json:"name"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+	IsError   bool           `json:"is_error,omitempty"`
+}
+
+// Usage is the token usage of a case.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// ID identifies a case across runs.
+func (c *CaseResult) ID() string {
+	return c.Suite + "/" + c.Case
+}
+
+func (r *Report) add(c CaseResult) {
+	r.Cases = append(r.Cases, c)
+	switch c.Status {
+	case StatusPass:
+		r.Passed++
+	case StatusFail:
+		r.Failed++
+	case StatusError:
+		r.Errors++
+	case StatusSkip:
+		r.Skipped++
+	}
+}
+
+// OK reports whether no case failed or errored.
+func (r *Report) OK() bool {
+	return r.Failed == 0 && r.Errors == 0
+}
+
+// Save writes the report as JSON.
+func (r *Report) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// LoadReport reads a report saved by Save.
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r Report
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &r, nil
+}
+
+// Print writes a pass/fail summary with the details of failing cases.
+func (r *Report) Print(w io.Writer) {
+	for _, c := range r.Cases {
+		fmt.Fprintf(w, "%-5s %s", strings.ToUpper(c.Status), c.ID())
+		if c.Provider != "" {
+			fmt.Fprintf(w, " [%s]", c.Provider)
+		}
+		fmt.Fprintf(w, " (%dms)\n", c.DurationMS)
+
+		if c.Error != "" {
+			fmt.Fprintf(w, "      %s\n", c.Error)
+		}
+		if c.Status != StatusFail {
+			continue
+		}
+		for _, check := range c.Checks {
+			if check.Passed {
+				continue
+			}
+			fmt.Fprintf(w, "      ✗ %s", check.Check)
+			if check.Detail != "" {
+				fmt.Fprintf(w, ": %s", check.Detail)
+			}
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "      response: %s\n", utils.Truncate(oneLine(c.Response), 200))
+	}
+	fmt.Fprintf(w, "\n%d passed, %d failed, %d errors, %d skipped\n", r.Passed, r.Failed, r.Errors, r.Skipped)
+}
+
+// Change is a difference in one case between two runs.
+type Change struct {
+	ID   string
+	Kind string // "regressed", "fixed", "status", "added", "removed", "tools", "response"
+	From string
+	To   string
+}
+
+// Diff compares a run against a baseline run. Regressions come first.
+func Diff(base, cur *Report) []Change {
+	baseCases := make(map[string]*CaseResult, len(base.Cases))
+	for i := range base.Cases {
+		baseCases[base.Cases[i].ID()] = &base.Cases[i]
+	}
+
+	var regressions, others []Change
+	seen := make(map[string]bool)
+	for i := range cur.Cases {
+		c := &cur.Cases[i]
+		id := c.ID()
+		seen[id] = true
+		b, ok := baseCases[id]
+		if !ok {
+			others = append(others, Change{ID: id, Kind: "added", To: c.Status})
+			continue
+		}
+		switch {
+		case b.Status == StatusPass && c.Status != StatusPass && c.Status != StatusSkip:
+			regressions = append(regressions, Change{ID: id, Kind: "regressed", From: b.Status, To: c.Status})
+		case b.Status != StatusPass && b.Status != StatusSkip && c.Status == StatusPass:
+			others = append(others, Change{ID: id, Kind: "fixed", From: b.Status, To: c.Status})
+		case b.Status != c.Status:
+			others = append(others, Change{ID: id, Kind: "status", From: b.Status, To: c.Status})
+		}
+		if from, to := toolNames(b.ToolCalls), toolNames(c.ToolCalls); from != to {
+			others = append(others, Change{ID: id, Kind: "tools", From: from, To: to})
+		}
+		if b.Response != c.Response {
+			others = append(others, Change{ID: id, Kind: "response", From: b.Response, To: c.Response})
+		}
+	}
+	for i := range base.Cases {
+		if id := base.Cases[i].ID(); !seen[id] {
+			others = append(others, Change{ID: id, Kind: "removed", From: base.Cases[i].Status})
+		}
+	}
+	return append(regressions, others...)
+}
+
+// PrintDiff writes the changes between two runs, with line diffs of
+// changed responses.
+func PrintDiff(w io.Writer, changes []Change) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No differences.")
+		return
+	}
+	for _, ch := range changes {
+		switch ch.Kind {
+		case "added":
+			fmt.Fprintf(w, "+ %s: new case (%s)\n", ch.ID, ch.To)
+		case "removed":
+			fmt.Fprintf(w, "- %s: case removed (was %s)\n", ch.ID, ch.From)
+		case "tools":
+			fmt.Fprintf(w, "~ %s: tool calls changed\n    was: %s\n    now: %s\n", ch.ID, ch.From, ch.To)
+		case "response":
+			fmt.Fprintf(w, "~ %s: response changed\n", ch.ID)
+			for _, line := range lineDiff(ch.From, ch.To) {
+				fmt.Fprintf(w, "    %s\n", line)
+			}
+		default:
+			fmt.Fprintf(w, "! %s: %s (%s -> %s)\n", ch.ID, ch.Kind, ch.From, ch.To)
+		}
+	}
+}
+
+// Regressions counts the cases that passed in the baseline but not now.
+func Regressions(changes []Change) int {
+	n := 0
+	for _, ch := range changes {
+		if ch.Kind == "regressed" {
+			n++
+		}
+	}
+	return n
+}
+
+// lineDiff returns a minimal line diff of a and b, with "-" and "+"
+// prefixes for removed and added lines and "  " for common ones.
+func lineDiff(a, b string) []string {
+	x, y := strings.Split(a, "\n"), strings.Split(b, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:]
+	// and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out []string
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			out = append(out, "  "+x[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, "- "+x[i])
+			i++
+		default:
+			out = append(out, "+ "+y[j])
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		out = append(out, "- "+x[i])
+	}
+	for ; j < len(y); j++ {
+		out = append(out, "+ "+y[j])
+	}
+	return out
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
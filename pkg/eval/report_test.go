@@ -0,0 +1,82 @@
+package eval
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	base := &Report{Cases: []CaseResult{
+		{Suite: "s", Case: "a", Status: StatusPass, Response: "one\ntwo"},
+		{Suite: "s", Case: "b", Status: StatusFail, Response: "x"},
+		{Suite: "s", Case: "c", Status: StatusPass, ToolCalls: []ToolCall{{Name: "read_file"}}},
+		{Suite: "s", Case: "gone", Status: StatusPass},
+	}}
+	cur := &Report{Cases: []CaseResult{
+		{Suite: "s", Case: "a", Status: StatusFail, Response: "one\nthree"},
+		{Suite: "s", Case: "b", Status: StatusPass, Response: "x"},
+		{Suite: "s", Case: "c", Status: StatusPass, ToolCalls: []ToolCall{{Name: "exec"}}},
+		{Suite: "s", Case: "new", Status: StatusPass},
+	}}
+
+	changes := Diff(base, cur)
+	var kinds []string
+	for _, ch := range changes {
+		kinds = append(kinds, ch.ID+":"+ch.Kind)
+	}
+	want := []string{"s/a:regressed", "s/a:response", "s/b:fixed", "s/c:tools", "s/new:added", "s/gone:removed"}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Errorf("changes = %v, want %v", kinds, want)
+	}
+	if Regressions(changes) != 1 {
+		t.Errorf("Regressions = %d, want 1", Regressions(changes))
+	}
+
+	var buf bytes.Buffer
+	PrintDiff(&buf, changes)
+	out := buf.String()
+	for _, s := range []string{"- two", "+ three", "  one", "was: read_file", "now: exec"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("diff output missing %q:\n%s", s, out)
+		}
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	got := lineDiff("a\nb\nc", "a\nc\nd")
+	want := []string{"  a", "- b", "  c", "+ d"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("lineDiff = %v, want %v", got, want)
+	}
+}
+
+func TestReport_SaveLoad(t *testing.T) {
+	r := &Report{Mode: ModeMock}
+	r.add(CaseResult{Suite: "s", Case: "a", Status: StatusPass, ToolCalls: []ToolCall{}})
+	r.add(CaseResult{Suite: "s", Case: "b", Status: StatusFail, ToolCalls: []ToolCall{},
+		Checks: []CheckResult{{Check: "contains \"x\"", Detail: ""}}})
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := r.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadReport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Passed != 1 || loaded.Failed != 1 || len(loaded.Cases) != 2 {
+		t.Errorf("loaded report = %+v", loaded)
+	}
+	if len(Diff(r, loaded)) != 0 {
+		t.Error("a report should not differ from itself")
+	}
+
+	var buf bytes.Buffer
+	loaded.Print(&buf)
+	if !strings.Contains(buf.String(), "FAIL  s/b") || !strings.Contains(buf.String(), "1 passed, 1 failed") {
+		t.Errorf("unexpected summary:\n%s", buf.String())
+	}
+}
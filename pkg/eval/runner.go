@@ -0,0 +1,263 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/routing"
+)
+
+// Provider modes decide which provider a case runs against.
+const (
+	ModeAuto = "auto" // the case's mock script if it has one, else the live model
+	ModeMock = "mock" // mock scripts only; cases without one are skipped
+	ModeLive = "live" // the live model, ignoring mock scripts
+)
+
+// Case statuses.
+const (
+	StatusPass  = "pass"
+	StatusFail  = "fail"
+	StatusError = "error"
+	StatusSkip  = "skip"
+)
+
+const defaultCaseTimeout = 2 * time.Minute
+
+// bootstrapFiles are copied from the configured workspace into each case's
+// workspace, so a suite exercises the current persona and skills.
+var bootstrapFiles = []string{"AGENTS.md", "SOUL.md", "USER.md", "IDENTITY.md", "TOOLS.md"}
+
+// Runner runs suites through fresh agent loops.
+type Runner struct {
+	// Config is the base configuration; each case gets a copy with its own
+	// temporary workspace.
+	Config *config.Config
+	// Live serves cases without a mock script; nil skips them.
+	Live providers.LLMProvider
+	// Model is reported as the live model in results.
+	Model string
+	Judge *Judge
+	Mode  string
+	// Filter, if set, runs only cases whose "suite/case" name contains it.
+	Filter string
+}
+
+// Run runs every case of the suites in order.
+func (r *Runner) Run(ctx context.Context, suites []*Suite) *Report {
+	report := &Report{
+		StartedAt: time.Now(),
+		Model:     r.Model,
+		Mode:      r.mode(),
+	}
+	for _, s := range suites {
+		for i := range s.Cases {
+			c := &s.Cases[i]
+			if r.Filter != "" && !strings.Contains(s.Name+"/"+c.Name, r.Filter) {
+				continue
+			}
+			res := r.runCase(ctx, s, c)
+			report.add(res)
+		}
+	}
+	report.DurationMS = time.Since(report.StartedAt).Milliseconds()
+	return report
+}
+
+func (r *Runner) mode() string {
+	if r.Mode == "" {
+		return ModeAuto
+	}
+	return r.Mode
+}
+
+func (r *Runner) runCase(ctx context.Context, s *Suite, c *Case) CaseResult {
+	res := CaseResult{Suite: s.Name, Case: c.Name, ToolCalls: []ToolCall{}}
+
+	var provider providers.LLMProvider
+	switch mode := r.mode(); {
+	case mode != ModeLive && len(c.Mock) > 0:
+		provider = NewScriptedProvider(c.Mock)
+		res.Provider = "mock"
+	case mode == ModeMock:
+		res.Status = StatusSkip
+		res.Error = "no mock script"
+		return res
+	case r.Live == nil:
+		res.Status = StatusSkip
+		res.Error = "no live model configured"
+		return res
+	default:
+		provider = r.Live
+		res.Provider = r.Model
+	}
+
+	workspace, err := os.MkdirTemp("", "picoclaw-eval-*")
+	if err != nil {
+		res.Status, res.Error = StatusError, err.Error()
+		return res
+	}
+	defer os.RemoveAll(workspace)
+
+	if err := r.prepareWorkspace(workspace, s, c); err != nil {
+		res.Status, res.Error = StatusError, fmt.Sprintf("preparing workspace: %v", err)
+		return res
+	}
+
+	cfg := *r.Config
+	cfg.Agents.Defaults.Workspace = workspace
+	cfg.Agents.List = append([]config.AgentConfig(nil), cfg.Agents.List...)
+	for i := range cfg.Agents.List {
+		cfg.Agents.List[i].Workspace = workspace
+	}
+	loop := agent.NewAgentLoop(&cfg, bus.NewMessageBus(), provider)
+
+	session := c.Session
+	if session == "" {
+		session = c.Name
+	}
+	sessionKey := fmt.Sprintf("agent:%s:eval:%s",
+		routing.NormalizeAgentID(loop.DefaultAgentID()), strings.ReplaceAll(session, " ", "-"))
+
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	loop.SetEventHandler(func(ev agent.Event) {
+		if ev.SessionKey != sessionKey {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		switch ev.Kind {
+		case agent.EventLLMResponse:
+			if ev.Usage != nil {
+				res.Usage.PromptTokens += ev.Usage.PromptTokens
+				res.Usage.CompletionTokens += ev.Usage.CompletionTokens
+				res.Usage.TotalTokens += ev.Usage.TotalTokens
+			}
+		case agent.EventToolCall:
+			calls[ev.ToolCallID] = len(res.ToolCalls)
+			res.ToolCalls = append(res.ToolCalls, ToolCall{Name: ev.Tool, Arguments: ev.Arguments})
+		case agent.EventToolResult:
+			if i, ok := calls[ev.ToolCallID]; ok {
+				res.ToolCalls[i].IsError = ev.IsError
+			}
+		}
+	})
+
+	timeout := defaultCaseTimeout
+	if s.TimeoutSeconds > 0 {
+		timeout = time.Duration(s.TimeoutSeconds) * time.Second
+	}
+	caseCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	response, err := loop.ProcessDirect(caseCtx, c.Prompt, sessionKey)
+	loop.SetEventHandler(nil)
+
+	mu.Lock()
+	defer mu.Unlock()
+	res.DurationMS = time.Since(start).Milliseconds()
+	res.Response = response
+	if err != nil {
+		res.Status, res.Error = StatusError, err.Error()
+		return res
+	}
+
+	res.Checks = checkTools(c, res.ToolCalls)
+	for _, a := range c.Assert {
+		res.Checks = append(res.Checks, checkAssertion(ctx, a, c.Prompt, response, r.Judge))
+	}
+	res.Status = StatusPass
+	for _, check := range res.Checks {
+		if !check.Passed {
+			res.Status = StatusFail
+			break
+		}
+	}
+	return res
+}
+
+// prepareWorkspace seeds a case's workspace with the suite's workspace
+// directory (or the configured workspace's bootstrap files and skills) and
+// the case's fixture files.
+func (r *Runner) prepareWorkspace(workspace string, s *Suite, c *Case) error {
+	if s.Workspace != "" {
+		src := s.Workspace
+		if !filepath.IsAbs(src) {
+			src = filepath.Join(s.Dir(), src)
+		}
+		if err := copyDir(src, workspace); err != nil {
+			return err
+		}
+	} else if r.Config != nil {
+		src := r.Config.WorkspacePath()
+		for _, name := range bootstrapFiles {
+			if err := copyFile(filepath.Join(src, name), filepath.Join(workspace, name)); err != nil &&
+				!os.IsNotExist(err) {
+				return err
+			}
+		}
+		if err := copyDir(filepath.Join(src, "skills"), filepath.Join(workspace, "skills")); err != nil &&
+			!os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	for name, content := range c.Files {
+		rel := filepath.FromSlash(path.Clean(name))
+		if !filepath.IsLocal(rel) {
+			return fmt.Errorf("fixture file %q must be a relative path inside the workspace", name)
+		}
+		dst := filepath.Join(workspace, rel)
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dst, []byte(content), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		return copyFile(p, target)
+	})
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0o644)
+}
@@ -0,0 +1,137 @@
+package eval
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func testConfig(t *testing.T) *config.Config {
+	t.Helper()
+	return &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:           t.TempDir(),
+				Model:               "test-model",
+				MaxTokens:           4096,
+				MaxToolIterations:   10,
+				RestrictToWorkspace: true,
+			},
+		},
+	}
+}
+
+func TestRunner_MockCase(t *testing.T) {
+	cfg := testConfig(t)
+	if err := os.WriteFile(filepath.Join(cfg.WorkspacePath(), "AGENTS.md"), []byte("Be brief."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Suite{Name: "notes", Cases: []Case{
+		{
+			Name:   "reads fixture",
+			Prompt: "What is in notes.txt?",
+			Files:  map[string]string{"notes.txt": "buy milk"},
+			Mock: []MockTurn{
+				{ToolCalls: []MockToolCall{{Name: "read_file", Arguments: map[string]any{"path": "notes.txt"}}}},
+				{Content: `You need to buy milk. {"items": ["milk"]}`},
+			},
+			Tools:       []ToolExpectation{{Name: "read_file", Args: map[string]any{"path": "notes.txt"}}},
+			ForbidTools: []string{"exec"},
+			Assert: []Assertion{
+				{Contains: "milk"},
+				{JSONPath: "$.items[0]", Equals: "milk"},
+			},
+		},
+		{
+			Name:   "wrong answer",
+			Prompt: "Say hi",
+			Mock:   []MockTurn{{Content: "Goodbye"}},
+			Assert: []Assertion{{Contains: "hi"}},
+		},
+		{
+			Name:   "script too short",
+			Prompt: "Use a tool",
+			Mock:   []MockTurn{{ToolCalls: []MockToolCall{{Name: "list_dir", Arguments: map[string]any{"path": "."}}}}},
+		},
+		{
+			Name:   "live only",
+			Prompt: "hello",
+		},
+	}}
+
+	report := (&Runner{Config: cfg}).Run(context.Background(), []*Suite{s})
+
+	if len(report.Cases) != 4 {
+		t.Fatalf("got %d results, want 4", len(report.Cases))
+	}
+	want := []string{StatusPass, StatusFail, StatusError, StatusSkip}
+	for i, c := range report.Cases {
+		if c.Status != want[i] {
+			t.Errorf("%s: status = %s, want %s (error %q, checks %+v)", c.Case, c.Status, want[i], c.Error, c.Checks)
+		}
+	}
+	first := report.Cases[0]
+	if len(first.ToolCalls) != 1 || first.ToolCalls[0].Name != "read_file" || first.ToolCalls[0].IsError {
+		t.Errorf("tool calls = %+v, want a successful read_file", first.ToolCalls)
+	}
+	if report.Passed != 1 || report.Failed != 1 || report.Errors != 1 || report.Skipped != 1 || report.OK() {
+		t.Errorf("unexpected totals: %+v", report)
+	}
+
+	// Fixture files never touch the configured workspace.
+	if _, err := os.Stat(filepath.Join(cfg.WorkspacePath(), "notes.txt")); !os.IsNotExist(err) {
+		t.Errorf("fixture leaked into the configured workspace: %v", err)
+	}
+
+	filtered := (&Runner{Config: cfg, Filter: "notes/wrong"}).Run(context.Background(), []*Suite{s})
+	if len(filtered.Cases) != 1 || filtered.Cases[0].Case != "wrong answer" {
+		t.Errorf("filter ran %+v", filtered.Cases)
+	}
+}
+
+func TestRunner_SeedsWorkspace(t *testing.T) {
+	cfg := testConfig(t)
+	src := cfg.WorkspacePath()
+	os.WriteFile(filepath.Join(src, "AGENTS.md"), []byte("persona"), 0o644)
+	os.MkdirAll(filepath.Join(src, "skills", "demo"), 0o755)
+	os.WriteFile(filepath.Join(src, "skills", "demo", "SKILL.md"), []byte("skill"), 0o644)
+	os.WriteFile(filepath.Join(src, "secret.txt"), []byte("private"), 0o644)
+
+	dst := t.TempDir()
+	c := &Case{Files: map[string]string{"data/in.txt": "fixture"}}
+	if err := (&Runner{Config: cfg}).prepareWorkspace(dst, &Suite{}, c); err != nil {
+		t.Fatalf("prepareWorkspace: %v", err)
+	}
+
+	for name, want := range map[string]string{
+		"AGENTS.md":            "persona",
+		"skills/demo/SKILL.md": "skill",
+		"data/in.txt":          "fixture",
+	} {
+		data, err := os.ReadFile(filepath.Join(dst, name))
+		if err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v; want %q", name, data, err, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dst, "secret.txt")); !os.IsNotExist(err) {
+		t.Error("only bootstrap files and skills should be copied")
+	}
+}
+
+func TestRunner_RejectsFixturesOutsideWorkspace(t *testing.T) {
+	root := t.TempDir()
+	dst := filepath.Join(root, "workspace")
+	for _, name := range []string{"../escape.txt", "data/../../escape.txt", "/etc/escape.txt"} {
+		c := &Case{Files: map[string]string{name: "x"}}
+		if err := (&Runner{}).prepareWorkspace(dst, &Suite{}, c); err == nil {
+			t.Errorf("fixture %q was accepted", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "escape.txt")); !os.IsNotExist(err) {
+		t.Error("a fixture was written outside the workspace")
+	}
+}
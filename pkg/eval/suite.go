@@ -0,0 +1,222 @@
+// Package eval runs suites of agent test cases and compares their results
+// across runs, to catch regressions when models, prompts or skills change.
+package eval
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Suite is a YAML file of test cases.
+type Suite struct {
+	Name string `yaml:"name"`
+	// Workspace is a directory copied into each case's workspace, relative
+	// to the suite file. By default the configured workspace's bootstrap
+	// files (AGENTS.md, SOUL.md, ...) and skills are copied.
+	Workspace      string `yaml:"workspace,omitempty"`
+	TimeoutSeconds int    `yaml:"timeout_seconds,omitempty"`
+	Cases          []Case `yaml:"cases"`
+
+	path string
+}
+
+// Case is one prompt and what is expected of the agent's answer.
+type Case struct {
+	Name    string `yaml:"name"`
+	Prompt  string `yaml:"prompt"`
+	Session string `yaml:"session,omitempty"`
+	// Files are written into the case's workspace before it runs, keyed by
+	// path relative to the workspace.
+	Files map[string]string `yaml:"files,omitempty"`
+	// Mock scripts the provider's replies for offline runs.
+	Mock []MockTurn `yaml:"mock,omitempty"`
+
+	Tools       []ToolExpectation `yaml:"tools,omitempty"`
+	ForbidTools []string          `yaml:"forbid_tools,omitempty"`
+	Assert      []Assertion       `yaml:"assert,omitempty"`
+}
+
+// ToolExpectation is a tool call the agent must make. Expected calls must
+// appear in order, though other calls may come between them. Args only
+// needs to match the arguments it lists.
+type ToolExpectation struct {
+	Name string         `yaml:"name"`
+	Args map[string]any `yaml:"args,omitempty"`
+}
+
+// Assertion checks the final response. Exactly one kind must be set.
+type Assertion struct {
+	Contains    string `yaml:"contains,omitempty"`
+	NotContains string `yaml:"not_contains,omitempty"`
+	Regex       string `yaml:"regex,omitempty"`
+	// JSONPath checks a value in the JSON found in the response, e.g.
+	// "$.items[0].name". Without Equals it only has to exist.
+	JSONPath string `yaml:"json_path,omitempty"`
+	Equals   any    `yaml:"equals,omitempty"`
+	// Judge asks the judge model whether the response meets a criterion.
+	Judge string `yaml:"judge,omitempty"`
+}
+
+// MockTurn is one scripted provider reply.
+type MockTurn struct {
+	Content   string         `yaml:"content,omitempty"`
+	ToolCalls []MockToolCall `yaml:"tool_calls,omitempty"`
+	Error     string         `yaml:"error,omitempty"`
+}
+
+// MockToolCall is a tool call in a scripted reply.
+type MockToolCall struct {
+	Name      string         `yaml:"name"`
+	Arguments map[string]any `yaml:"arguments,omitempty"`
+}
+
+// LoadSuites loads suite files, or every *.yaml and *.yml file in the
+// given directories.
+func LoadSuites(paths []string) ([]*Suite, error) {
+	var files []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		for _, pattern := range []string{"*.yaml", "*.yml"} {
+			matches, err := filepath.Glob(filepath.Join(p, pattern))
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, matches...)
+		}
+	}
+	sort.Strings(files)
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no suite files found")
+	}
+
+	suites := make([]*Suite, 0, len(files))
+	for _, f := range files {
+		s, err := LoadSuite(f)
+		if err != nil {
+			return nil, err
+		}
+		suites = append(suites, s)
+	}
+	return suites, nil
+}
+
+// LoadSuite loads and validates a suite file.
+func LoadSuite(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Suite
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	s.path = path
+	if s.Name == "" {
+		s.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// Validate checks the suite's cases.
+func (s *Suite) Validate() error {
+	if len(s.Cases) == 0 {
+		return fmt.Errorf("suite has no cases")
+	}
+	if s.TimeoutSeconds < 0 {
+		return fmt.Errorf("timeout_seconds must not be negative")
+	}
+	seen := make(map[string]bool)
+	for i := range s.Cases {
+		c := &s.Cases[i]
+		if c.Name == "" {
+			return fmt.Errorf("case %d has no name", i+1)
+		}
+		if seen[c.Name] {
+			return fmt.Errorf("duplicate case name %q", c.Name)
+		}
+		seen[c.Name] = true
+		if err := c.validate(); err != nil {
+			return fmt.Errorf("case %q: %w", c.Name, err)
+		}
+	}
+	return nil
+}
+
+func (c *Case) validate() error {
+	if strings.TrimSpace(c.Prompt) == "" {
+		return fmt.Errorf("prompt is required")
+	}
+	for name := range c.Files {
+		if filepath.IsAbs(name) || !filepath.IsLocal(name) {
+			return fmt.Errorf("fixture file %q must be a relative path inside the workspace", name)
+		}
+	}
+	for _, t := range c.Tools {
+		if t.Name == "" {
+			return fmt.Errorf("expected tool call has no name")
+		}
+	}
+	for i, a := range c.Assert {
+		if err := a.validate(); err != nil {
+			return fmt.Errorf("assertion %d: %w", i+1, err)
+		}
+	}
+	for i, turn := range c.Mock {
+		for _, tc := range turn.ToolCalls {
+			if tc.Name == "" {
+				return fmt.Errorf("mock turn %d: tool call has no name", i+1)
+			}
+		}
+	}
+	return nil
+}
+
+func (a *Assertion) validate() error {
+	kinds := 0
+	for _, set := range []bool{a.Contains != "", a.NotContains != "", a.Regex != "", a.JSONPath != "", a.Judge != ""} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return fmt.Errorf("set exactly one of contains, not_contains, regex, json_path or judge")
+	}
+	if a.Equals != nil && a.JSONPath == "" {
+		return fmt.Errorf("equals needs json_path")
+	}
+	if a.Regex != "" {
+		if _, err := regexp.Compile(a.Regex); err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+	}
+	if a.JSONPath != "" {
+		if _, err := parseJSONPath(a.JSONPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Dir returns the directory of the suite file.
+func (s *Suite) Dir() string {
+	return filepath.Dir(s.path)
+}
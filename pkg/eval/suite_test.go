@@ -0,0 +1,87 @@
+package eval
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSuite(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadSuite(t *testing.T) {
+	dir := t.TempDir()
+	path := writeSuite(t, dir, "notes.yaml", `
+cases:
+  - name: read notes
+    prompt: What is in notes.txt?
+    files:
+      notes.txt: buy milk
+    mock:
+      - tool_calls:
+          - name: read_file
+            arguments: {path: notes.txt}
+      - content: You need to buy milk.
+    tools:
+      - name: read_file
+        args: {path: notes.txt}
+    forbid_tools: [exec]
+    assert:
+      - contains: milk
+      - json_path: $.items[0]
+        equals: 1
+`)
+
+	s, err := LoadSuite(path)
+	if err != nil {
+		t.Fatalf("LoadSuite: %v", err)
+	}
+	if s.Name != "notes" {
+		t.Errorf("Name = %q, want name from file", s.Name)
+	}
+	c := s.Cases[0]
+	if c.Files["notes.txt"] != "buy milk" || len(c.Mock) != 2 || len(c.Assert) != 2 {
+		t.Errorf("unexpected case: %+v", c)
+	}
+	if c.Mock[0].ToolCalls[0].Arguments["path"] != "notes.txt" {
+		t.Errorf("mock arguments = %v", c.Mock[0].ToolCalls[0].Arguments)
+	}
+
+	suites, err := LoadSuites([]string{dir})
+	if err != nil || len(suites) != 1 {
+		t.Fatalf("LoadSuites(dir) = %v, %v", suites, err)
+	}
+}
+
+func TestLoadSuite_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"no cases", "name: empty\n", "no cases"},
+		{"unknown field", "cases:\n  - name: a\n    prompt: hi\n    promt: typo\n", "promt"},
+		{"no prompt", "cases:\n  - name: a\n", "prompt is required"},
+		{"duplicate", "cases:\n  - {name: a, prompt: x}\n  - {name: a, prompt: y}\n", "duplicate"},
+		{"two kinds", "cases:\n  - name: a\n    prompt: x\n    assert:\n      - {contains: a, regex: b}\n", "exactly one"},
+		{"bad regex", "cases:\n  - name: a\n    prompt: x\n    assert:\n      - regex: '('\n", "invalid regex"},
+		{"bad path", "cases:\n  - name: a\n    prompt: x\n    assert:\n      - json_path: items\n", "must start with $"},
+		{"escaping file", "cases:\n  - name: a\n    prompt: x\n    files:\n      ../x: y\n", "inside the workspace"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeSuite(t, t.TempDir(), "s.yaml", tt.content)
+			_, err := LoadSuite(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
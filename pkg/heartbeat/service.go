@@ -24,6 +24,11 @@ import (
 const (
 	minIntervalMinutes     = 5
 	defaultIntervalMinutes = 30
+
+	// checkCommandTimeout bounds if_command_fails preconditions.
+	checkCommandTimeout = 30 * time.Second
+	// maxCheckOutput is how much of a failed check's output goes in the prompt.
+	maxCheckOutput = 10000
 )
 
 // HeartbeatHandler is the function type for handling heartbeat.
@@ -37,8 +42,10 @@ type HeartbeatService struct {
 	bus       *bus.MessageBus
 	state     *state.Manager
 	handler   HeartbeatHandler
+	exec      *tools.ExecTool // guards if_command_fails preconditions
 	interval  time.Duration
 	enabled   bool
+	now       func() time.Time
 	mu        sync.RWMutex
 	stopChan  chan struct{}
 }
@@ -59,6 +66,8 @@ func NewHeartbeatService(workspace string, intervalMinutes int, enabled bool) *H
 		interval:  time.Duration(intervalMinutes) * time.Minute,
 		enabled:   enabled,
 		state:     state.NewManager(workspace),
+		exec:      tools.NewExecTool(workspace, true),
+		now:       time.Now,
 	}
 }
 
@@ -69,6 +78,15 @@ func (hs *HeartbeatService) SetBus(msgBus *bus.MessageBus) {
 	hs.bus = msgBus
 }
 
+// SetStateManager shares the workspace state with the rest of the process.
+// Every writer of state.json must use the same manager, or each save
+// overwrites the others' changes with its stale copy.
+func (hs *HeartbeatService) SetStateManager(sm *state.Manager) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.state = sm
+}
+
 // SetHandler sets the heartbeat handler.
 func (hs *HeartbeatService) SetHandler(handler HeartbeatHandler) {
 	hs.mu.Lock()
@@ -159,9 +177,10 @@ func (hs *HeartbeatService) executeHeartbeat() {
 
 	logger.DebugC("heartbeat", "Executing heartbeat")
 
-	prompt := hs.buildPrompt()
+	now := hs.now()
+	prompt, due := hs.buildPrompt()
 	if prompt == "" {
-		logger.InfoC("heartbeat", "No heartbeat prompt (HEARTBEAT.md empty or missing)")
+		logger.InfoC("heartbeat", "No heartbeat prompt (HEARTBEAT.md empty or missing, or no tasks due)")
 		return
 	}
 
@@ -190,6 +209,9 @@ func (hs *HeartbeatService) executeHeartbeat() {
 		return
 	}
 
+	// Failed runs are retried on the next heartbeat; anything else counts.
+	hs.markRan(due, now)
+
 	if result.Async {
 		hs.logInfof("Async task started: %s", result.ForLLM)
 		logger.InfoCF("heartbeat", "Async heartbeat task started",
@@ -215,27 +237,34 @@ func (hs *HeartbeatService) executeHeartbeat() {
 	hs.logInfof("Heartbeat completed: %s", result.ForLLM)
 }
 
-// buildPrompt builds the heartbeat prompt from HEARTBEAT.md
-func (hs *HeartbeatService) buildPrompt() string {
+// buildPrompt builds the heartbeat prompt from HEARTBEAT.md. If the file
+// defines structured tasks, only the due ones are included, and the prompt is
+// empty when none are due.
+func (hs *HeartbeatService) buildPrompt() (string, []*dueTask) {
 	heartbeatPath := filepath.Join(hs.workspace, "HEARTBEAT.md")
 
 	data, err := os.ReadFile(heartbeatPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			hs.createDefaultHeartbeatTemplate()
-			return ""
+			return "", nil
 		}
 		hs.logErrorf("Error reading HEARTBEAT.md: %v", err)
-		return ""
+		return "", nil
 	}
 
 	content := string(data)
 	if len(content) == 0 {
-		return ""
+		return "", nil
 	}
 
-	now := time.Now().Format("2006-01-02 15:04:05")
-	return fmt.Sprintf(`# Heartbeat Check
+	now := hs.now()
+	text, tasks, warnings := parseHeartbeat(content)
+	for _, w := range warnings {
+		hs.logErrorf("HEARTBEAT.md: %s", w)
+	}
+	if len(tasks) == 0 {
+		return fmt.Sprintf(`# Heartbeat Check
 
 Current time: %s
 
@@ -244,7 +273,66 @@ Review the following tasks and execute any necessary actions using available ski
 If there is nothing that requires attention, respond ONLY with: HEARTBEAT_OK
 
 %s
-`, now, content)
+`, now.Format("2006-01-02 15:04:05"), content), nil
+	}
+
+	names := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		names = append(names, task.Name)
+	}
+	if err := hs.state.PruneHeartbeatTasks(names); err != nil {
+		hs.logErrorf("Failed to prune heartbeat task state: %v", err)
+	}
+
+	var due []*dueTask
+	for _, task := range tasks {
+		d, err := hs.evaluate(task, now)
+		if err != nil {
+			hs.logErrorf("Task %q: %v", task.Name, err)
+			continue
+		}
+		if d != nil {
+			due = append(due, d)
+		}
+	}
+	if len(due) == 0 {
+		hs.logInfof("No heartbeat tasks due (%d defined)", len(tasks))
+		return "", nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `# Heartbeat Check
+
+Current time: %s
+
+You are a proactive AI assistant. This is a scheduled heartbeat check.
+The tasks below are due. Execute them using available skills.
+If there is nothing that requires attention, respond ONLY with: HEARTBEAT_OK
+`, now.Format("2006-01-02 15:04:05"))
+	if text != "" {
+		fmt.Fprintf(&sb, "\n## Instructions\n\n%s\n", text)
+	}
+	sb.WriteString("\n## Due Tasks\n")
+	for _, d := range due {
+		fmt.Fprintf(&sb, "\n### %s\n\n", d.Name)
+		if d.Body != "" {
+			fmt.Fprintf(&sb, "%s\n", d.Body)
+		}
+		if len(d.changed) > 0 {
+			fmt.Fprintf(&sb, "\nChanged since the last run: %s\n", strings.Join(d.changed, ", "))
+		}
+		if d.commandOutput != "" {
+			fmt.Fprintf(&sb, "\nCheck command `%s` failed:\n```\n%s\n```\n",
+				d.IfCommandFails, strings.TrimSpace(d.commandOutput))
+		}
+	}
+
+	dueNames := make([]string, 0, len(due))
+	for _, d := range due {
+		dueNames = append(dueNames, d.Name)
+	}
+	hs.logInfof("Heartbeat tasks due: %s", strings.Join(dueNames, ", "))
+	return sb.String(), due
 }
 
 // createDefaultHeartbeatTemplate creates the default HEARTBEAT.md file
@@ -270,6 +358,25 @@ This file contains tasks for the heartbeat service to check periodically.
 - After spawning a subagent, CONTINUE to process remaining tasks.
 - Only respond with HEARTBEAT_OK when ALL tasks are done AND nothing needs attention.
 
+## Scheduled Tasks
+
+A section whose first lines are settings is a scheduled task. It is only sent
+to the agent when it is due, and heartbeats with no due tasks cost nothing.
+
+` + "```" + `markdown
+## Disk space
+every: 6h
+hours: 08:00-22:00
+if_command_fails: test $(df --output=pcent / | tail -1 | tr -dc 0-9) -lt 90
+
+Find what is filling the disk and tell me.
+` + "```" + `
+
+- every: minimum time between runs (e.g. 30m, 6h, 1d)
+- hours: local time window the task may run in
+- if_changed: only run when this workspace file or directory changed
+- if_command_fails: only run when this command exits non-zero
+
 ---
 
 Add your heartbeat tasks below this line:
@@ -0,0 +1,406 @@
+package heartbeat
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxFingerprintEntries bounds how much of a watched directory is scanned.
+const maxFingerprintEntries = 10000
+
+// Task is a structured heartbeat task: a "## Name" section of HEARTBEAT.md
+// whose first lines are settings, e.g.
+//
+//	## Disk space
+//	every: 6h
+//	hours: 08:00-22:00
+//	if_command_fails: test $(df --output=pcent / | tail -1 | tr -dc 0-9) -lt 90
+//
+//	Find what is filling the disk and tell me.
+//
+// A task is due when all of its conditions hold. Without conditions it is due
+// on every heartbeat.
+type Task struct {
+	Name           string
+	Every          time.Duration // minimum time between runs
+	Hours          *hoursWindow  // local time of day the task may run
+	IfChanged      []string      // paths, relative to the workspace, that must have changed
+	IfCommandFails string        // shell command that must exit non-zero
+	Body           string
+}
+
+// hoursWindow is a daily time window in minutes after midnight. End may be
+// before start for windows that span midnight.
+type hoursWindow struct {
+	start, end int
+}
+
+func (w hoursWindow) contains(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	if w.start <= w.end {
+		return m >= w.start && m < w.end
+	}
+	return m >= w.start || m < w.end
+}
+
+var settingPattern = regexp.MustCompile(`^(?:[-*]\s+)?([a-z_]+):\s*(.*)$`)
+
+var taskSettings = map[string]bool{
+	"every":            true,
+	"hours":            true,
+	"if_changed":       true,
+	"if_command_fails": true,
+}
+
+// parseHeartbeat splits HEARTBEAT.md into structured tasks and the remaining
+// free-form text. Sections whose first line is not a task setting, and
+// anything inside code fences, stay free-form.
+func parseHeartbeat(content string) (text string, tasks []Task, warnings []string) {
+	var free []string
+	var section []string
+	inFence := false
+
+	flush := func() {
+		if len(section) == 0 {
+			return
+		}
+		task, isTask, err := parseTaskSection(section)
+		switch {
+		case !isTask:
+			free = append(free, section...)
+		case err != nil:
+			warnings = append(warnings, err.Error())
+		case hasTask(tasks, task.Name):
+			warnings = append(warnings, fmt.Sprintf("duplicate task %q ignored", task.Name))
+		default:
+			tasks = append(tasks, task)
+		}
+		section = nil
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if !inFence && strings.HasPrefix(line, "## ") {
+			flush()
+			section = []string{line}
+			continue
+		}
+		if section != nil {
+			section = append(section, line)
+		} else {
+			free = append(free, line)
+		}
+	}
+	flush()
+
+	return strings.TrimSpace(strings.Join(free, "\n")), tasks, warnings
+}
+
+func hasTask(tasks []Task, name string) bool {
+	for _, t := range tasks {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTaskSection parses a "## " section. isTask is false if the section
+// does not start with a task setting.
+func parseTaskSection(lines []string) (task Task, isTask bool, err error) {
+	name := strings.TrimSpace(strings.TrimPrefix(lines[0], "## "))
+
+	i := 1
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+	if i == len(lines) {
+		return Task{}, false, nil
+	}
+	if m := settingPattern.FindStringSubmatch(strings.TrimSpace(lines[i])); m == nil || !taskSettings[m[1]] {
+		return Task{}, false, nil
+	}
+
+	task.Name = name
+	var problems []string
+	for ; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			break
+		}
+		m := settingPattern.FindStringSubmatch(line)
+		if m == nil {
+			break
+		}
+		key, value := m[1], strings.TrimSpace(m[2])
+		var err error
+		switch key {
+		case "every":
+			task.Every, err = parseEvery(value)
+		case "hours":
+			var w hoursWindow
+			w, err = parseHours(value)
+			task.Hours = &w
+		case "if_changed":
+			if value == "" {
+				err = fmt.Errorf("path is required")
+			}
+			task.IfChanged = append(task.IfChanged, value)
+		case "if_command_fails":
+			if value == "" {
+				err = fmt.Errorf("command is required")
+			}
+			task.IfCommandFails = value
+		default:
+			err = fmt.Errorf("unknown setting")
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", key, err))
+		}
+	}
+	task.Body = strings.TrimSpace(strings.Join(lines[i:], "\n"))
+	if len(problems) > 0 {
+		return task, true, fmt.Errorf("task %q ignored: %s", name, strings.Join(problems, "; "))
+	}
+	return task, true, nil
+}
+
+// parseEvery parses a duration such as "90m", "6h" or "2d".
+func parseEvery(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
+
+// parseHours parses a window such as "08:00-22:00" or "22-6".
+func parseHours(s string) (hoursWindow, error) {
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return hoursWindow{}, fmt.Errorf("expected a range like 08:00-22:00, got %q", s)
+	}
+	start, err := parseClock(strings.TrimSpace(from))
+	if err != nil {
+		return hoursWindow{}, err
+	}
+	end, err := parseClock(strings.TrimSpace(to))
+	if err != nil {
+		return hoursWindow{}, err
+	}
+	return hoursWindow{start: start, end: end}, nil
+}
+
+func parseClock(s string) (int, error) {
+	h, m, hasMinutes := strings.Cut(s, ":")
+	hour, err := strconv.Atoi(h)
+	if err != nil || hour < 0 || hour > 24 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	minute := 0
+	if hasMinutes {
+		minute, err = strconv.Atoi(m)
+		if err != nil || minute < 0 || minute > 59 || len(m) != 2 {
+			return 0, fmt.Errorf("invalid time %q", s)
+		}
+	}
+	if hour == 24 && minute != 0 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return hour*60 + minute, nil
+}
+
+// dueTask is a task selected for this heartbeat, with what made it due.
+type dueTask struct {
+	Task
+	fingerprints  map[string]string
+	changed       []string
+	commandOutput string
+}
+
+// evaluate reports whether task is due at now. Cheap checks run first, so
+// the command only runs when everything else allows the task. The first time
+// a path is seen its fingerprint is recorded as a baseline.
+func (hs *HeartbeatService) evaluate(task Task, now time.Time) (*dueTask, error) {
+	st, _ := hs.state.GetHeartbeatTask(task.Name)
+	if task.Every > 0 && !st.LastRun.IsZero() && now.Sub(st.LastRun) < task.Every {
+		return nil, nil
+	}
+	if task.Hours != nil && !task.Hours.contains(now) {
+		return nil, nil
+	}
+
+	due := &dueTask{Task: task, fingerprints: make(map[string]string)}
+	if len(task.IfChanged) > 0 {
+		baseline := false
+		for _, path := range task.IfChanged {
+			fp, err := hs.fingerprint(path)
+			if err != nil {
+				return nil, fmt.Errorf("if_changed %s: %w", path, err)
+			}
+			due.fingerprints[path] = fp
+			prev, seen := st.Fingerprints[path]
+			switch {
+			case !seen:
+				baseline = true
+			case prev != fp:
+				due.changed = append(due.changed, path)
+			}
+		}
+		if baseline {
+			if st.Fingerprints == nil {
+				st.Fingerprints = make(map[string]string)
+			}
+			for path, fp := range due.fingerprints {
+				if _, seen := st.Fingerprints[path]; !seen {
+					st.Fingerprints[path] = fp
+				}
+			}
+			if err := hs.state.SetHeartbeatTask(task.Name, st); err != nil {
+				return nil, err
+			}
+		}
+		if len(due.changed) == 0 {
+			return nil, nil
+		}
+	}
+
+	if task.IfCommandFails != "" {
+		failed, output, err := hs.runCheck(task.IfCommandFails)
+		if err != nil {
+			return nil, fmt.Errorf("if_command_fails: %w", err)
+		}
+		if !failed {
+			return nil, nil
+		}
+		due.commandOutput = output
+	}
+	return due, nil
+}
+
+// runCheck runs a precondition command in the workspace with the exec
+// tool's safety guard. It reports whether the command exited non-zero, with
+// the end of its output and the exit status. Anything else that stops the
+// command (blocked, timed out, failed to start) is an error in the check.
+func (hs *HeartbeatService) runCheck(command string) (failed bool, output string, err error) {
+	if err := hs.exec.CheckCommand(command); err != nil {
+		return false, "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), checkCommandTimeout)
+	defer cancel()
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Dir = hs.workspace
+	// Don't wait on pipes held open by background children after a timeout.
+	cmd.WaitDelay = time.Second
+
+	out, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return false, "", fmt.Errorf("timed out after %v", checkCommandTimeout)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if len(out) > maxCheckOutput {
+			out = append([]byte("... (truncated)\n"), out[len(out)-maxCheckOutput:]...)
+		}
+		return true, fmt.Sprintf("%s\n(%v)", bytes.TrimSpace(out), exitErr), nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+	return false, "", nil
+}
+
+// fingerprint summarizes the contents of a workspace file or directory.
+func (hs *HeartbeatService) fingerprint(path string) (string, error) {
+	full := path
+	if !filepath.IsAbs(full) {
+		full = filepath.Join(hs.workspace, path)
+	}
+	info, err := os.Stat(full)
+	if os.IsNotExist(err) {
+		return "missing", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	if !info.IsDir() {
+		f, err := os.Open(full)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		if _, err := io.Copy(h, f); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+
+	entries := 0
+	err = filepath.WalkDir(full, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entries++; entries > maxFingerprintEntries {
+			return filepath.SkipAll
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(full, p)
+		fmt.Fprintf(h, "%s\x00%d\x00%d\n", rel, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// markRan records a successful run of the given tasks.
+func (hs *HeartbeatService) markRan(tasks []*dueTask, now time.Time) {
+	for _, task := range tasks {
+		st, _ := hs.state.GetHeartbeatTask(task.Name)
+		st.LastRun = now
+		for path, fp := range task.fingerprints {
+			if st.Fingerprints == nil {
+				st.Fingerprints = make(map[string]string)
+			}
+			st.Fingerprints[path] = fp
+		}
+		if err := hs.state.SetHeartbeatTask(task.Name, st); err != nil {
+			hs.logErrorf("Failed to save state of task %q: %v", task.Name, err)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package heartbeat
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/state"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+const structuredHeartbeat = `# Heartbeat
+
+Keep answers short.
+
+## Morning summary
+every: 1d
+hours: 07:00-09:00
+
+Summarize today's calendar.
+
+## Notes review
+- if_changed: notes
+
+Review what changed in my notes.
+
+## Checklist
+
+- Water the plants
+
+` + "```" + `
+## Not a task
+every: 1h
+` + "```" + `
+
+## Broken
+every: soon
+`
+
+func TestParseHeartbeat(t *testing.T) {
+	text, tasks, warnings := parseHeartbeat(structuredHeartbeat)
+
+	if len(tasks) != 2 {
+		t.Fatalf("Expected 2 tasks, got %d: %+v", len(tasks), tasks)
+	}
+	morning := tasks[0]
+	if morning.Name != "Morning summary" || morning.Every != 24*time.Hour ||
+		morning.Hours == nil || *morning.Hours != (hoursWindow{start: 7 * 60, end: 9 * 60}) ||
+		morning.Body != "Summarize today's calendar." {
+		t.Errorf("Unexpected morning task: %+v", morning)
+	}
+	if notes := tasks[1]; len(notes.IfChanged) != 1 || notes.IfChanged[0] != "notes" {
+		t.Errorf("Unexpected notes task: %+v", notes)
+	}
+
+	for _, want := range []string{"Keep answers short.", "## Checklist", "- Water the plants", "## Not a task"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected free-form text to contain %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "Broken") {
+		t.Error("Invalid task should not be part of the free-form text")
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], `"Broken"`) {
+		t.Errorf("Expected a warning for the broken task, got %v", warnings)
+	}
+}
+
+func TestHoursWindow(t *testing.T) {
+	day := func(h, m int) time.Time { return time.Date(2026, 3, 1, h, m, 0, 0, time.Local) }
+
+	w, err := parseHours("08:00-22:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !w.contains(day(8, 0)) || !w.contains(day(22, 29)) || w.contains(day(22, 30)) || w.contains(day(7, 59)) {
+		t.Errorf("Unexpected window behaviour for %+v", w)
+	}
+
+	overnight, err := parseHours("22-6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !overnight.contains(day(23, 0)) || !overnight.contains(day(5, 59)) || overnight.contains(day(12, 0)) {
+		t.Errorf("Unexpected overnight window behaviour for %+v", overnight)
+	}
+
+	for _, bad := range []string{"8", "25-26", "08:5-09:00", "a-b"} {
+		if _, err := parseHours(bad); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+	for _, bad := range []string{"0m", "-1h", "xd", "soon"} {
+		if _, err := parseEvery(bad); err == nil {
+			t.Errorf("Expected error for every %q", bad)
+		}
+	}
+}
+
+func newTaskTestService(t *testing.T, heartbeat string) (*HeartbeatService, *time.Time, *[]string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "HEARTBEAT.md"), []byte(heartbeat), 0o644)
+
+	hs := NewHeartbeatService(tmpDir, 30, true)
+	hs.stopChan = make(chan struct{}) // Enable for testing
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.Local)
+	hs.now = func() time.Time { return now }
+
+	var prompts []string
+	hs.SetHandler(func(prompt, channel, chatID string) *tools.ToolResult {
+		prompts = append(prompts, prompt)
+		return tools.SilentResult("HEARTBEAT_OK")
+	})
+	return hs, &now, &prompts
+}
+
+func TestExecuteHeartbeat_DueTasksOnly(t *testing.T) {
+	hs, now, prompts := newTaskTestService(t, structuredHeartbeat)
+	os.MkdirAll(filepath.Join(hs.workspace, "notes"), 0o755)
+
+	hs.executeHeartbeat()
+	if len(*prompts) != 1 {
+		t.Fatalf("Expected 1 handler call, got %d", len(*prompts))
+	}
+	prompt := (*prompts)[0]
+	if !strings.Contains(prompt, "### Morning summary") || !strings.Contains(prompt, "Summarize today's calendar.") {
+		t.Errorf("Expected the morning task in the prompt:\n%s", prompt)
+	}
+	// The notes directory has only been seen for the first time.
+	if strings.Contains(prompt, "Notes review") {
+		t.Errorf("Notes task should not be due before anything changed:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "Keep answers short.") {
+		t.Errorf("Expected free-form instructions in the prompt:\n%s", prompt)
+	}
+
+	// Nothing is due an hour later: the morning task ran and notes are unchanged.
+	*now = now.Add(time.Hour)
+	hs.executeHeartbeat()
+	if len(*prompts) != 1 {
+		t.Fatalf("Expected no handler call when nothing is due, got %d calls", len(*prompts))
+	}
+
+	os.WriteFile(filepath.Join(hs.workspace, "notes", "todo.md"), []byte("buy milk"), 0o644)
+	hs.executeHeartbeat()
+	if len(*prompts) != 2 {
+		t.Fatalf("Expected a handler call after notes changed, got %d calls", len(*prompts))
+	}
+	prompt = (*prompts)[1]
+	if !strings.Contains(prompt, "### Notes review") || !strings.Contains(prompt, "Changed since the last run: notes") ||
+		strings.Contains(prompt, "Morning summary") {
+		t.Errorf("Expected only the notes task in the prompt:\n%s", prompt)
+	}
+
+	hs.executeHeartbeat()
+	if len(*prompts) != 2 {
+		t.Errorf("Notes task should not run again without further changes")
+	}
+
+	// State survives a restart.
+	st, ok := state.NewManager(hs.workspace).GetHeartbeatTask("Morning summary")
+	if !ok || st.LastRun.IsZero() {
+		t.Errorf("Expected persisted last run, got %+v", st)
+	}
+}
+
+func TestExecuteHeartbeat_SharedState(t *testing.T) {
+	hs, _, prompts := newTaskTestService(t, structuredHeartbeat)
+	shared := state.NewManager(hs.workspace)
+	hs.SetStateManager(shared)
+
+	// The agent loop records a channel after the heartbeat service started.
+	if err := shared.SetLastChannel("telegram:42"); err != nil {
+		t.Fatal(err)
+	}
+	hs.executeHeartbeat()
+	if len(*prompts) != 1 {
+		t.Fatalf("Expected 1 handler call, got %d", len(*prompts))
+	}
+
+	reloaded := state.NewManager(hs.workspace)
+	if got := reloaded.GetLastChannel(); got != "telegram:42" {
+		t.Errorf("last channel after a task save = %q, want telegram:42", got)
+	}
+	if _, ok := reloaded.GetHeartbeatTask("Morning summary"); !ok {
+		t.Error("task state was not saved")
+	}
+}
+
+func TestExecuteHeartbeat_CommandPrecondition(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	hs, now, prompts := newTaskTestService(t, `## Service check
+if_command_fails: test -f healthy
+
+Investigate why the service is unhealthy.
+`)
+
+	hs.executeHeartbeat()
+	if len(*prompts) != 1 || !strings.Contains((*prompts)[0], "Check command `test -f healthy` failed") {
+		t.Fatalf("Expected the task to run when the check fails, got %q", *prompts)
+	}
+
+	os.WriteFile(filepath.Join(hs.workspace, "healthy"), nil, 0o644)
+	*now = now.Add(30 * time.Minute)
+	hs.executeHeartbeat()
+	if len(*prompts) != 1 {
+		t.Errorf("Expected no handler call when the check passes")
+	}
+}
+
+func TestRunCheck(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	hs := NewHeartbeatService(t.TempDir(), 30, true)
+
+	// Output beyond what the exec tool would show must not hide the exit.
+	failed, output, err := hs.runCheck("yes noise | head -c 20000; echo disk full; exit 3")
+	if err != nil || !failed {
+		t.Fatalf("runCheck(noisy failure) = %v, %v", failed, err)
+	}
+	if !strings.HasSuffix(output, "disk full\n(exit status 3)") || len(output) > maxCheckOutput+100 {
+		t.Errorf("expected the truncated end of the output, got %d chars ending %q", len(output), output[len(output)-20:])
+	}
+
+	if failed, _, err := hs.runCheck("true"); err != nil || failed {
+		t.Errorf("runCheck(true) = %v, %v", failed, err)
+	}
+	if _, _, err := hs.runCheck("rm -rf /"); err == nil {
+		t.Error("expected the safety guard to block the check")
+	}
+}
+
+func TestExecuteHeartbeat_FailedRunIsRetried(t *testing.T) {
+	hs, now, _ := newTaskTestService(t, "## Report\nevery: 6h\n\nReport status.\n")
+	calls := 0
+	hs.SetHandler(func(prompt, channel, chatID string) *tools.ToolResult {
+		calls++
+		if calls == 1 {
+			return tools.ErrorResult("provider unavailable")
+		}
+		return tools.SilentResult("done")
+	})
+
+	hs.executeHeartbeat()
+	*now = now.Add(30 * time.Minute)
+	hs.executeHeartbeat()
+	*now = now.Add(30 * time.Minute)
+	hs.executeHeartbeat()
+	if calls != 2 {
+		t.Errorf("Expected a retry after the failed run and then nothing due, got %d calls", calls)
+	}
+}
@@ -21,10 +21,14 @@ type (
 	Message                = protocoltypes.Message
 	ToolDefinition         = protocoltypes.ToolDefinition
 	ToolFunctionDefinition = protocoltypes.ToolFunctionDefinition
+	ThinkingBlock          = protocoltypes.ThinkingBlock
 )
 
 const defaultBaseURL = "https://api.anthropic.com"
 
+// minThinkingBudget is the smallest budget_tokens the API accepts.
+const minThinkingBudget = 1024
+
 type Provider struct {
 	client      *anthropic.Client
 	tokenSource func() (string, error)
@@ -90,7 +94,11 @@ func (p *Provider) Chat(
 		return nil, fmt.Errorf("claude API call: %w", err)
 	}
 
-	return parseResponse(resp), nil
+	out := parseResponse(resp)
+	if rf := protocoltypes.ResponseFormatFromOptions(options); rf != nil {
+		extractStructuredOutput(out, responseToolName(rf))
+	}
+	return out, nil
 }
 
 func (p *Provider) GetDefaultModel() string {
@@ -140,6 +148,11 @@ func buildParams(
 		case "assistant":
 			if len(msg.ToolCalls) > 0 {
 				var blocks []anthropic.ContentBlockParamUnion
+				// With extended thinking, the signed thinking blocks must be
+				// replayed as they were, ahead of the tool_use blocks they produced.
+				for _, tb := range msg.ThinkingBlocks {
+					blocks = append(blocks, anthropic.NewThinkingBlock(tb.Signature, tb.Thinking))
+				}
 				if msg.Content != "" {
 					blocks = append(blocks, anthropic.NewTextBlock(msg.Content))
 				}
@@ -174,10 +187,38 @@ func buildParams(
 		params.System = system
 	}
 
-	if temp, ok := options["temperature"].(float64); ok {
+	rf := protocoltypes.ResponseFormatFromOptions(options)
+
+	if _, budget := protocoltypes.ReasoningFromOptions(options); budget > 0 && rf == nil {
+		if budget < minThinkingBudget {
+			budget = minThinkingBudget
+		}
+		// budget_tokens counts against max_tokens, so keep room for the answer.
+		if params.MaxTokens <= int64(budget) {
+			params.MaxTokens = int64(budget) + maxTokens
+		}
+		params.Thinking = anthropic.ThinkingConfigParamOfEnabled(int64(budget))
+	} else if temp, ok := options["temperature"].(float64); ok {
+		// Temperature is not supported together with extended thinking.
+		// Forced tool use is not supported with thinking either, so a
+		// structured-output request runs without it.
 		params.Temperature = anthropic.Float(temp)
 	}
 
+	if rf != nil {
+		// Claude has no JSON-schema response mode; force a call to a tool whose
+		// input schema is the requested schema and read the answer from its input.
+		tools = append(tools, ToolDefinition{
+			Type: "function",
+			Function: protocoltypes.ToolFunctionDefinition{
+				Name:        responseToolName(rf),
+				Description: "Respond with the final answer.",
+				Parameters:  rf.Schema,
+			},
+		})
+		params.ToolChoice = anthropic.ToolChoiceParamOfTool(responseToolName(rf))
+	}
+
 	if len(tools) > 0 {
 		params.Tools = translateTools(tools)
 	}
@@ -185,6 +226,32 @@ func buildParams(
 	return params, nil
 }
 
+func responseToolName(rf *protocoltypes.ResponseFormat) string {
+	if rf.Name != "" {
+		return rf.Name
+	}
+	return "respond"
+}
+
+// extractStructuredOutput moves the forced response tool's input into Content.
+func extractStructuredOutput(resp *LLMResponse, toolName string) {
+	for i, tc := range resp.ToolCalls {
+		if tc.Name != toolName {
+			continue
+		}
+		data, err := json.Marshal(tc.Arguments)
+		if err != nil {
+			return
+		}
+		resp.Content = string(data)
+		resp.ToolCalls = append(resp.ToolCalls[:i], resp.ToolCalls[i+1:]...)
+		if len(resp.ToolCalls) == 0 {
+			resp.FinishReason = "stop"
+		}
+		return
+	}
+}
+
 func translateTools(tools []ToolDefinition) []anthropic.ToolUnionParam {
 	result := make([]anthropic.ToolUnionParam, 0, len(tools))
 	for _, t := range tools {
@@ -212,7 +279,8 @@ func translateTools(tools []ToolDefinition) []anthropic.ToolUnionParam {
 }
 
 func parseResponse(resp *anthropic.Message) *LLMResponse {
-	var content string
+	var content, reasoning string
+	var thinking []ThinkingBlock
 	var toolCalls []ToolCall
 
 	for _, block := range resp.Content {
@@ -220,6 +288,10 @@ func parseResponse(resp *anthropic.Message) *LLMResponse {
 		case "text":
 			tb := block.AsText()
 			content += tb.Text
+		case "thinking":
+			tb := block.AsThinking()
+			reasoning += tb.Thinking
+			thinking = append(thinking, ThinkingBlock{Thinking: tb.Thinking, Signature: tb.Signature})
 		case "tool_use":
 			tu := block.AsToolUse()
 			var args map[string]any
@@ -246,9 +318,11 @@ func parseResponse(resp *anthropic.Message) *LLMResponse {
 	}
 
 	return &LLMResponse{
-		Content:      content,
-		ToolCalls:    toolCalls,
-		FinishReason: finishReason,
+		Content:          content,
+		ReasoningContent: reasoning,
+		ThinkingBlocks:   thinking,
+		ToolCalls:        toolCalls,
+		FinishReason:     finishReason,
 		Usage: &UsageInfo{
 			PromptTokens:     int(resp.Usage.InputTokens),
 			CompletionTokens: int(resp.Usage.OutputTokens),
@@ -4,11 +4,14 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"sync/atomic"
 	"testing"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	anthropicoption "github.com/anthropics/anthropic-sdk-go/option"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
 )
 
 func TestBuildParams_BasicMessage(t *testing.T) {
@@ -77,6 +80,47 @@ func TestBuildParams_ToolCallMessage(t *testing.T) {
 	}
 }
 
+func TestBuildParams_Thinking(t *testing.T) {
+	messages := []Message{
+		{Role: "user", Content: "What's the weather?"},
+		{
+			Role:             "assistant",
+			ReasoningContent: "I should call the weather tool. Then answer.",
+			ThinkingBlocks: []ThinkingBlock{
+				{Thinking: "I should call the weather tool.", Signature: "sig-1"},
+				{Thinking: " Then answer.", Signature: "sig-2"},
+			},
+			ToolCalls: []ToolCall{
+				{ID: "call_1", Name: "get_weather", Arguments: map[string]any{"city": "SF"}},
+			},
+		},
+		{Role: "tool", Content: `{"temp": 72}`, ToolCallID: "call_1"},
+	}
+	params, err := buildParams(messages, nil, "claude-sonnet-4.6", map[string]any{
+		"max_tokens":       2048,
+		"temperature":      0.7,
+		"reasoning_effort": "medium",
+	})
+	if err != nil {
+		t.Fatalf("buildParams() error: %v", err)
+	}
+	if params.Thinking.OfEnabled == nil || params.Thinking.OfEnabled.BudgetTokens != 4096 {
+		t.Fatalf("Thinking = %+v, want enabled with 4096 budget", params.Thinking)
+	}
+	if params.MaxTokens <= 4096 {
+		t.Errorf("MaxTokens = %d, want room above the thinking budget", params.MaxTokens)
+	}
+	if params.Temperature.Valid() {
+		t.Error("temperature must not be sent with extended thinking")
+	}
+	blocks := params.Messages[1].Content
+	if len(blocks) != 3 || blocks[0].OfThinking == nil || blocks[1].OfThinking == nil ||
+		blocks[0].OfThinking.Signature != "sig-1" || blocks[1].OfThinking.Signature != "sig-2" ||
+		blocks[1].OfThinking.Thinking != " Then answer." {
+		t.Errorf("expected each signed thinking block before tool_use, got %+v", blocks)
+	}
+}
+
 func TestBuildParams_WithTools(t *testing.T) {
 	tools := []ToolDefinition{
 		{
@@ -123,6 +167,29 @@ func TestParseResponse_TextOnly(t *testing.T) {
 	}
 }
 
+func TestParseResponse_ThinkingBlocks(t *testing.T) {
+	var resp anthropic.Message
+	err := json.Unmarshal([]byte(`{
+		"content": [
+			{"type": "thinking", "thinking": "First.", "signature": "sig-1"},
+			{"type": "thinking", "thinking": " Second.", "signature": "sig-2"},
+			{"type": "tool_use", "id": "call_1", "name": "get_weather", "input": {"city": "SF"}}
+		],
+		"stop_reason": "tool_use"
+	}`), &resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := parseResponse(&resp)
+	if result.ReasoningContent != "First. Second." {
+		t.Errorf("ReasoningContent = %q", result.ReasoningContent)
+	}
+	want := []ThinkingBlock{{Thinking: "First.", Signature: "sig-1"}, {Thinking: " Second.", Signature: "sig-2"}}
+	if !reflect.DeepEqual(result.ThinkingBlocks, want) {
+		t.Errorf("ThinkingBlocks = %+v, want %+v", result.ThinkingBlocks, want)
+	}
+}
+
 func TestParseResponse_StopReasons(t *testing.T) {
 	tests := []struct {
 		stopReason anthropic.StopReason
@@ -193,6 +260,55 @@ func TestProvider_ChatRoundTrip(t *testing.T) {
 	}
 }
 
+func TestProvider_ChatStructuredOutput(t *testing.T) {
+	var reqBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&reqBody)
+		resp := map[string]any{
+			"id":          "msg_test",
+			"type":        "message",
+			"role":        "assistant",
+			"model":       reqBody["model"],
+			"stop_reason": "tool_use",
+			"content": []map[string]any{
+				{"type": "tool_use", "id": "toolu_1", "name": "contact", "input": map[string]any{"name": "Ada"}},
+			},
+			"usage": map[string]any{"input_tokens": 10, "output_tokens": 5},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	provider := NewProviderWithClient(createAnthropicTestClient(server.URL, "test-token"))
+	resp, err := provider.Chat(t.Context(), []Message{{Role: "user", Content: "Ada"}}, nil, "claude-sonnet-4.6",
+		map[string]any{
+			"reasoning_effort": "high",
+			"response_format": &protocoltypes.ResponseFormat{
+				Name: "contact",
+				Schema: map[string]any{
+					"type":       "object",
+					"properties": map[string]any{"name": map[string]any{"type": "string"}},
+					"required":   []any{"name"},
+				},
+			},
+		})
+	if err != nil {
+		t.Fatalf("Chat() error: %v", err)
+	}
+
+	choice, _ := reqBody["tool_choice"].(map[string]any)
+	if choice["type"] != "tool" || choice["name"] != "contact" {
+		t.Errorf("tool_choice = %v, want forced contact tool", reqBody["tool_choice"])
+	}
+	if _, ok := reqBody["thinking"]; ok {
+		t.Error("thinking must be disabled when forcing a tool")
+	}
+	if resp.Content != `{"name":"Ada"}` || len(resp.ToolCalls) != 0 || resp.FinishReason != "stop" {
+		t.Errorf("unexpected response: content=%q toolCalls=%v finish=%q", resp.Content, resp.ToolCalls, resp.FinishReason)
+	}
+}
+
 func TestProvider_GetDefaultModel(t *testing.T) {
 	p := NewProvider("test-token")
 	if got := p.GetDefaultModel(); got != "claude-sonnet-4.6" {
@@ -0,0 +1,331 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// CassetteVersion is the current cassette file format version.
+const CassetteVersion = 1
+
+// Cassette holds recorded LLM interactions, for replaying conversations
+// offline in tests and development.
+type Cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded Chat call.
+type Interaction struct {
+	Hash       string       `json:"hash"`
+	RecordedAt time.Time    `json:"recorded_at"`
+	Request    RecordedCall `json:"request"`
+	Response   *LLMResponse `json:"response,omitempty"`
+	Error      string       `json:"error,omitempty"`
+}
+
+// RecordedCall is the normalized form of a request, which is what replays
+// are matched on.
+type RecordedCall struct {
+	Model    string            `json:"model"`
+	Tools    []string          `json:"tools,omitempty"`
+	Messages []RecordedMessage `json:"messages"`
+}
+
+// RecordedMessage is a request message without the parts that change from
+// run to run.
+type RecordedMessage struct {
+	Role      string             `json:"role"`
+	Content   string             `json:"content,omitempty"`
+	ToolCalls []RecordedToolCall `json:"tool_calls,omitempty"`
+}
+
+// RecordedToolCall is a tool call in a request message.
+type RecordedToolCall struct {
+	Name      string         `json:"name"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+}
+
+// normalizeRequest reduces a request to what identifies it across runs.
+// System messages are left out since they embed the current time and the
+// workspace path, as are tool call IDs, options and tool schemas.
+func normalizeRequest(messages []Message, tools []ToolDefinition, model string) RecordedCall {
+	call := RecordedCall{Model: model}
+	for _, t := range tools {
+		call.Tools = append(call.Tools, t.Function.Name)
+	}
+	sort.Strings(call.Tools)
+
+	for _, m := range messages {
+		if m.Role == "system" {
+			continue
+		}
+		rm := RecordedMessage{Role: m.Role, Content: strings.TrimSpace(m.Content)}
+		for _, tc := range m.ToolCalls {
+			tc = NormalizeToolCall(copyToolCall(tc))
+			rm.ToolCalls = append(rm.ToolCalls, RecordedToolCall{Name: tc.Name, Arguments: tc.Arguments})
+		}
+		call.Messages = append(call.Messages, rm)
+	}
+	return call
+}
+
+// Hash returns the key replays are matched on.
+func (c RecordedCall) Hash() string {
+	// encoding/json sorts map keys, so equal requests hash equally.
+	data, _ := json.Marshal(c)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadCassette reads a cassette file.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("cassette %s: %w", path, err)
+	}
+	if c.Version > CassetteVersion {
+		return nil, fmt.Errorf("cassette %s has version %d, newer than supported %d", path, c.Version, CassetteVersion)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette atomically.
+func (c *Cassette) Save(path string) error {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// RecordingProvider passes calls through to another provider and records
+// each request and response to a cassette file. The cassette is replaced,
+// not appended to, when a process starts recording to it.
+type RecordingProvider struct {
+	inner    LLMProvider
+	recorder *cassetteRecorder
+}
+
+// NewRecordingProvider records inner's interactions to the cassette at path.
+// Providers recording to the same path share the cassette, since one
+// record/ model can be built several times (the main provider, model
+// overrides, fallbacks).
+func NewRecordingProvider(inner LLMProvider, path string) *RecordingProvider {
+	return &RecordingProvider{inner: inner, recorder: recorderFor(path)}
+}
+
+func (p *RecordingProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	resp, err := p.inner.Chat(ctx, messages, tools, model, options)
+	if ctx.Err() != nil {
+		// A canceled call says nothing about the model; don't record it.
+		return resp, err
+	}
+
+	call := normalizeRequest(messages, tools, model)
+	interaction := Interaction{
+		Hash:       call.Hash(),
+		RecordedAt: time.Now().UTC(),
+		Request:    call,
+		Response:   storableResponse(resp),
+	}
+	if err != nil {
+		interaction.Error = err.Error()
+	}
+	p.recorder.add(interaction)
+	return resp, err
+}
+
+// cassetteRecorder is the cassette being recorded to one file.
+type cassetteRecorder struct {
+	path string
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+var (
+	recordersMu sync.Mutex
+	recorders   = make(map[string]*cassetteRecorder)
+)
+
+// recorderFor returns the recorder for the cassette at path, starting a new
+// cassette on first use.
+func recorderFor(path string) *cassetteRecorder {
+	key := filepath.Clean(path)
+	if abs, err := filepath.Abs(key); err == nil {
+		key = abs
+	}
+
+	recordersMu.Lock()
+	defer recordersMu.Unlock()
+	r, ok := recorders[key]
+	if !ok {
+		r = &cassetteRecorder{
+			path:     path,
+			cassette: Cassette{Version: CassetteVersion, Interactions: []Interaction{}},
+		}
+		recorders[key] = r
+	}
+	return r
+}
+
+func (r *cassetteRecorder) add(interaction Interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	if err := r.cassette.Save(r.path); err != nil {
+		logger.WarnCF("provider", "Failed to save cassette",
+			map[string]any{"path": r.path, "error": err.Error()})
+	}
+}
+
+func (p *RecordingProvider) GetDefaultModel() string {
+	return p.inner.GetDefaultModel()
+}
+
+// ReplayProvider serves recorded responses without any network access.
+// Requests are matched by their normalized hash; identical requests get
+// the recorded responses in order, and the last one once they run out.
+type ReplayProvider struct {
+	path string
+
+	mu     sync.Mutex
+	byHash map[string][]Interaction
+	served map[string]int
+}
+
+// NewReplayProvider loads the cassette at path.
+func NewReplayProvider(path string) (*ReplayProvider, error) {
+	c, err := LoadCassette(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading cassette for replay: %w", err)
+	}
+	return NewReplayProviderFromCassette(c, path), nil
+}
+
+// NewReplayProviderFromCassette replays an in-memory cassette; name is
+// used in error messages.
+func NewReplayProviderFromCassette(c *Cassette, name string) *ReplayProvider {
+	p := &ReplayProvider{
+		path:   name,
+		byHash: make(map[string][]Interaction),
+		served: make(map[string]int),
+	}
+	for _, in := range c.Interactions {
+		p.byHash[in.Hash] = append(p.byHash[in.Hash], in)
+	}
+	return p
+}
+
+func (p *ReplayProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	hash := normalizeRequest(messages, tools, model).Hash()
+
+	p.mu.Lock()
+	recorded := p.byHash[hash]
+	n := p.served[hash]
+	p.served[hash] = n + 1
+	p.mu.Unlock()
+
+	if len(recorded) == 0 {
+		return nil, fmt.Errorf("replay: no recorded response for this request (hash %s) in cassette %s; re-record it",
+			hash[:12], p.path)
+	}
+	in := recorded[min(n, len(recorded)-1)]
+	if in.Error != "" {
+		return nil, errors.New(in.Error)
+	}
+	if in.Response == nil {
+		return nil, fmt.Errorf("replay: recorded interaction %s has no response", hash[:12])
+	}
+	return storableResponse(in.Response), nil
+}
+
+func (p *ReplayProvider) GetDefaultModel() string {
+	return ""
+}
+
+// storableResponse copies a response with both forms of each tool call
+// filled in, since only Function survives JSON encoding.
+func storableResponse(resp *LLMResponse) *LLMResponse {
+	if resp == nil {
+		return nil
+	}
+	out := *resp
+	out.ToolCalls = make([]ToolCall, len(resp.ToolCalls))
+	for i, tc := range resp.ToolCalls {
+		out.ToolCalls[i] = NormalizeToolCall(copyToolCall(tc))
+	}
+	if len(out.ToolCalls) == 0 {
+		out.ToolCalls = nil
+	}
+	return &out
+}
+
+func copyToolCall(tc ToolCall) ToolCall {
+	if tc.Function != nil {
+		f := *tc.Function
+		tc.Function = &f
+	}
+	return tc
+}
+
+// cassettePath returns where a record/ or replay/ model keeps its cassette.
+func cassettePath(cfg *config.ModelConfig) string {
+	if cfg.Cassette != "" {
+		return cfg.Cassette
+	}
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, cfg.ModelName)
+	if name == "" {
+		name = "default"
+	}
+	return filepath.Join(cfg.Workspace, "cassettes", name+".json")
+}
@@ -0,0 +1,237 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package providers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// scriptProvider asks for a read_file call on the first user message and
+// answers once it sees the tool result.
+type scriptProvider struct {
+	calls int
+	fail  bool
+}
+
+func (p *scriptProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	p.calls++
+	if p.fail {
+		return nil, errors.New("rate limited")
+	}
+	last := messages[len(messages)-1]
+	if last.Role == "tool" {
+		return &LLMResponse{Content: "It says: " + last.Content, FinishReason: "stop"}, nil
+	}
+	return &LLMResponse{
+		ToolCalls: []ToolCall{{
+			ID:        "call_live_1",
+			Name:      "read_file",
+			Arguments: map[string]any{"path": "notes.txt"},
+		}},
+		FinishReason: "tool_calls",
+		Usage:        &UsageInfo{PromptTokens: 5, CompletionTokens: 1, TotalTokens: 6},
+	}, nil
+}
+
+func (p *scriptProvider) GetDefaultModel() string { return "script" }
+
+func conversation(system, toolCallID string) ([]Message, []Message) {
+	first := []Message{
+		{Role: "system", Content: system},
+		{Role: "user", Content: "What is in notes.txt?"},
+	}
+	second := append(append([]Message(nil), first...),
+		Message{Role: "assistant", ToolCalls: []ToolCall{{
+			ID: toolCallID, Type: "function",
+			Function: &FunctionCall{Name: "read_file", Arguments: `{"path":"notes.txt"}`},
+		}}},
+		Message{Role: "tool", ToolCallID: toolCallID, Content: "buy milk"},
+	)
+	return first, second
+}
+
+var testTools = []ToolDefinition{
+	{Type: "function", Function: ToolFunctionDefinition{Name: "read_file"}},
+	{Type: "function", Function: ToolFunctionDefinition{Name: "exec"}},
+}
+
+func TestRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassettes", "test.json")
+	ctx := context.Background()
+
+	inner := &scriptProvider{}
+	rec := NewRecordingProvider(inner, path)
+	first, second := conversation("Current time: 10:00", "call_live_1")
+	resp1, err := rec.Chat(ctx, first, testTools, "gpt-test", nil)
+	if err != nil {
+		t.Fatalf("record first: %v", err)
+	}
+	resp2, err := rec.Chat(ctx, second, testTools, "gpt-test", nil)
+	if err != nil {
+		t.Fatalf("record second: %v", err)
+	}
+	if resp1.ToolCalls[0].Function != nil {
+		t.Error("recording must not change the response handed to the caller")
+	}
+
+	replay, err := NewReplayProvider(path)
+	if err != nil {
+		t.Fatalf("NewReplayProvider: %v", err)
+	}
+
+	// A different system prompt, tool call ID and tool order still match.
+	first, second = conversation("Current time: 11:30", "call_other")
+	tools := []ToolDefinition{testTools[1], testTools[0]}
+	got1, err := replay.Chat(ctx, first, tools, "gpt-test", map[string]any{"temperature": 0.2})
+	if err != nil {
+		t.Fatalf("replay first: %v", err)
+	}
+	if len(got1.ToolCalls) != 1 || got1.ToolCalls[0].Name != "read_file" ||
+		got1.ToolCalls[0].Arguments["path"] != "notes.txt" || got1.ToolCalls[0].ID != "call_live_1" {
+		t.Errorf("replayed tool call = %+v", got1.ToolCalls)
+	}
+	if got1.Usage == nil || got1.Usage.TotalTokens != resp1.Usage.TotalTokens {
+		t.Errorf("replayed usage = %+v", got1.Usage)
+	}
+	got2, err := replay.Chat(ctx, second, tools, "gpt-test", nil)
+	if err != nil || got2.Content != resp2.Content {
+		t.Errorf("replay second = %+v, %v; want %q", got2, err, resp2.Content)
+	}
+
+	// Repeating a request serves the last recording again.
+	if again, err := replay.Chat(ctx, second, tools, "gpt-test", nil); err != nil || again.Content != resp2.Content {
+		t.Errorf("repeat replay = %+v, %v", again, err)
+	}
+
+	// Anything else is not in the cassette.
+	other := []Message{{Role: "user", Content: "Something else"}}
+	if _, err := replay.Chat(ctx, other, tools, "gpt-test", nil); err == nil ||
+		!strings.Contains(err.Error(), "no recorded response") {
+		t.Errorf("unrecorded request err = %v", err)
+	}
+	if _, err := replay.Chat(ctx, first, tools, "other-model", nil); err == nil {
+		t.Error("expected a different model not to match")
+	}
+	if inner.calls != 2 {
+		t.Errorf("inner provider called %d times, want 2", inner.calls)
+	}
+}
+
+func TestRecordingProvider_SharedCassette(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "shared.json")
+	ctx := context.Background()
+
+	// The same record/ model built twice, e.g. for the main provider and a
+	// model override, and once more with an equivalent path.
+	main := NewRecordingProvider(&scriptProvider{}, path)
+	override := NewRecordingProvider(&scriptProvider{}, filepath.Join(dir, ".", "shared.json"))
+	first, second := conversation("Current time: 10:00", "call_1")
+	if _, err := main.Chat(ctx, first, testTools, "gpt-test", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := override.Chat(ctx, first, testTools, "gpt-fast", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := main.Chat(ctx, second, testTools, "gpt-test", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Interactions) != 3 {
+		t.Fatalf("cassette has %d interactions, want all 3", len(c.Interactions))
+	}
+	if c.Interactions[1].Request.Model != "gpt-fast" {
+		t.Errorf("interactions out of order: %+v", c.Interactions[1].Request)
+	}
+}
+
+func TestRecordReplay_Errors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.json")
+	msgs := []Message{{Role: "user", Content: "hi"}}
+
+	rec := NewRecordingProvider(&scriptProvider{fail: true}, path)
+	if _, err := rec.Chat(context.Background(), msgs, nil, "m", nil); err == nil {
+		t.Fatal("expected the inner error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec.Chat(ctx, []Message{{Role: "user", Content: "canceled"}}, nil, "m", nil)
+
+	c, err := LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Interactions) != 1 {
+		t.Fatalf("got %d interactions, want 1 (canceled calls are not recorded)", len(c.Interactions))
+	}
+
+	replay := NewReplayProviderFromCassette(c, "errors")
+	if _, err := replay.Chat(context.Background(), msgs, nil, "m", nil); err == nil || err.Error() != "rate limited" {
+		t.Errorf("replayed error = %v, want rate limited", err)
+	}
+}
+
+func TestLoadCassette_NewerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "future.json")
+	os.WriteFile(path, []byte(`{"version": 99, "interactions": []}`), 0o644)
+	if _, err := LoadCassette(path); err == nil {
+		t.Error("expected error for newer cassette version")
+	}
+}
+
+func TestCreateProviderFromConfig_RecordReplay(t *testing.T) {
+	workspace := t.TempDir()
+
+	cfg := &config.ModelConfig{
+		ModelName: "gpt/test",
+		Model:     "record/openai/gpt-4o",
+		APIKey:    "sk-test",
+		Workspace: workspace,
+	}
+	provider, modelID, err := CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if _, ok := provider.(*RecordingProvider); !ok || modelID != "gpt-4o" {
+		t.Errorf("record = %T, %q", provider, modelID)
+	}
+	if got, want := cassettePath(cfg), filepath.Join(workspace, "cassettes", "gpt_test.json"); got != want {
+		t.Errorf("cassettePath = %q, want %q", got, want)
+	}
+
+	cfg.Model = "replay/openai/gpt-4o"
+	if _, _, err := CreateProviderFromConfig(cfg); err == nil {
+		t.Error("replay without a cassette should fail")
+	}
+
+	cfg.Cassette = filepath.Join(workspace, "custom.json")
+	(&Cassette{Version: CassetteVersion}).Save(cfg.Cassette)
+	provider, modelID, err = CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if _, ok := provider.(*ReplayProvider); !ok || modelID != "gpt-4o" {
+		t.Errorf("replay = %T, %q", provider, modelID)
+	}
+}
@@ -2,6 +2,7 @@ package providers
 
 import (
 	"context"
+	"errors"
 	"regexp"
 	"strings"
 )
@@ -94,6 +95,12 @@ var (
 	}
 )
 
+// failoverReasoner is implemented by provider errors that know their own
+// FailoverReason value ("auth", "rate_limit", ...).
+type failoverReasoner interface {
+	FailoverReason() string
+}
+
 // ClassifyError classifies an error into a FailoverError with reason.
 // Returns nil if the error is not classifiable (unknown errors should not trigger fallback).
 func ClassifyError(err error, provider, model string) *FailoverError {
@@ -116,6 +123,21 @@ func ClassifyError(err error, provider, model string) *FailoverError {
 		}
 	}
 
+	// Provider-native classification (e.g. Gemini's canonical status/reason),
+	// which is more precise than matching on the HTTP status alone.
+	var reasoner failoverReasoner
+	if errors.As(err, &reasoner) {
+		if reason := FailoverReason(reasoner.FailoverReason()); reason != "" {
+			return &FailoverError{
+				Reason:   reason,
+				Provider: provider,
+				Model:    model,
+				Status:   extractHTTPStatus(strings.ToLower(err.Error())),
+				Wrapped:  err,
+			}
+		}
+	}
+
 	msg := strings.ToLower(err.Error())
 
 	// Image dimension/size errors: non-retriable, non-fallback.
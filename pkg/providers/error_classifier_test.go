@@ -240,6 +240,31 @@ func TestClassifyError_UnknownError(t *testing.T) {
 	}
 }
 
+type reasonedError struct{ reason string }
+
+func (e *reasonedError) Error() string          { return "API error: status: 400 bad key" }
+func (e *reasonedError) FailoverReason() string { return e.reason }
+
+func TestClassifyError_ProviderReason(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", &reasonedError{reason: "auth"})
+	result := ClassifyError(err, "gemini", "gemini-2.5-flash")
+	if result == nil {
+		t.Fatal("expected non-nil")
+	}
+	if result.Reason != FailoverAuth {
+		t.Errorf("reason = %q, want auth (provider reason beats 400 status)", result.Reason)
+	}
+	if result.Status != 400 {
+		t.Errorf("status = %d, want 400", result.Status)
+	}
+
+	// An empty provider reason falls back to status classification.
+	result = ClassifyError(&reasonedError{}, "gemini", "gemini-2.5-flash")
+	if result == nil || result.Reason != FailoverFormat {
+		t.Errorf("expected format fallback, got %+v", result)
+	}
+}
+
 func TestClassifyError_ProviderModelPropagation(t *testing.T) {
 	err := errors.New("rate limit exceeded")
 	result := ClassifyError(err, "my-provider", "my-model")
@@ -53,7 +53,8 @@ func ExtractProtocol(model string) (protocol, modelID string) {
 
 // CreateProviderFromConfig creates a provider based on the ModelConfig.
 // It uses the protocol prefix in the Model field to determine which provider to create.
-// Supported protocols: openai, anthropic, antigravity, claude-cli, codex-cli, github-copilot
+// Supported protocols: openai, anthropic, gemini, ollama, antigravity, claude-cli, codex-cli, github-copilot,
+// and record/replay wrappers
 // Returns the provider, the model ID (without protocol prefix), and any error.
 func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, error) {
 	if cfg == nil {
@@ -86,8 +87,8 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 		}
 		return NewHTTPProviderWithMaxTokensField(cfg.APIKey, apiBase, cfg.Proxy, cfg.MaxTokensField), modelID, nil
 
-	case "openrouter", "groq", "zhipu", "gemini", "nvidia",
-		"ollama", "moonshot", "shengsuanyun", "deepseek", "cerebras",
+	case "openrouter", "groq", "zhipu", "nvidia",
+		"moonshot", "shengsuanyun", "deepseek", "cerebras",
 		"volcengine", "vllm", "qwen", "mistral":
 		// All other OpenAI-compatible HTTP providers
 		if cfg.APIKey == "" && cfg.APIBase == "" {
@@ -99,6 +100,17 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 		}
 		return NewHTTPProviderWithMaxTokensField(cfg.APIKey, apiBase, cfg.Proxy, cfg.MaxTokensField), modelID, nil
 
+	case "ollama":
+		// Native Ollama API (/api/chat); an OpenAI-style ".../v1" api_base is accepted too.
+		return NewOllamaProvider(cfg.APIKey, cfg.APIBase, cfg.Proxy, cfg.KeepAlive), modelID, nil
+
+	case "gemini":
+		// Native Generative Language API; an OpenAI-compat ".../openai" api_base is accepted too.
+		if cfg.APIKey == "" {
+			return nil, "", fmt.Errorf("api_key is required for gemini protocol (model: %s)", cfg.Model)
+		}
+		return NewGeminiProvider(cfg.APIKey, cfg.APIBase, cfg.Proxy, cfg.SafetySettings), modelID, nil
+
 	case "anthropic":
 		if cfg.AuthMethod == "oauth" || cfg.AuthMethod == "token" {
 			// Use OAuth credentials from auth store
@@ -150,6 +162,26 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 		}
 		return provider, modelID, nil
 
+	case "record":
+		// Wrap the provider named by the rest of the model, e.g.
+		// "record/openai/gpt-4o", and record its interactions.
+		inner := *cfg
+		inner.Model = modelID
+		provider, innerModelID, err := CreateProviderFromConfig(&inner)
+		if err != nil {
+			return nil, "", err
+		}
+		return NewRecordingProvider(provider, cassettePath(cfg)), innerModelID, nil
+
+	case "replay":
+		// Serve the interactions recorded by "record/" for the same model.
+		provider, err := NewReplayProvider(cassettePath(cfg))
+		if err != nil {
+			return nil, "", err
+		}
+		_, innerModelID := ExtractProtocol(modelID)
+		return provider, innerModelID, nil
+
 	default:
 		return nil, "", fmt.Errorf("unknown protocol %q in model %q", protocol, cfg.Model)
 	}
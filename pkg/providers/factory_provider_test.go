@@ -107,7 +107,6 @@ func TestCreateProviderFromConfig_DefaultAPIBase(t *testing.T) {
 		{"qwen", "qwen"},
 		{"vllm", "vllm"},
 		{"deepseek", "deepseek"},
-		{"ollama", "ollama"},
 	}
 
 	for _, tt := range tests {
@@ -150,6 +149,59 @@ func TestCreateProviderFromConfig_Anthropic(t *testing.T) {
 	}
 }
 
+func TestCreateProviderFromConfig_Ollama(t *testing.T) {
+	cfg := &config.ModelConfig{
+		ModelName: "test-ollama",
+		Model:     "ollama/qwen3:4b",
+		APIBase:   "http://192.168.1.20:11434/v1",
+		KeepAlive: "10m",
+	}
+
+	provider, modelID, err := CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	op, ok := provider.(*OllamaProvider)
+	if !ok {
+		t.Fatalf("expected *OllamaProvider, got %T", provider)
+	}
+	if got := op.delegate.BaseURL(); got != "http://192.168.1.20:11434" {
+		t.Errorf("BaseURL() = %q, want /v1 suffix trimmed", got)
+	}
+	if modelID != "qwen3:4b" {
+		t.Errorf("modelID = %q, want %q", modelID, "qwen3:4b")
+	}
+}
+
+func TestCreateProviderFromConfig_Gemini(t *testing.T) {
+	cfg := &config.ModelConfig{
+		ModelName: "test-gemini",
+		Model:     "gemini/gemini-2.5-flash",
+		APIKey:    "test-key",
+		APIBase:   "https://generativelanguage.googleapis.com/v1beta/openai/",
+	}
+
+	provider, modelID, err := CreateProviderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	gp, ok := provider.(*GeminiProvider)
+	if !ok {
+		t.Fatalf("expected *GeminiProvider, got %T", provider)
+	}
+	if got := gp.delegate.BaseURL(); got != "https://generativelanguage.googleapis.com/v1beta" {
+		t.Errorf("BaseURL() = %q, want /openai suffix trimmed", got)
+	}
+	if modelID != "gemini-2.5-flash" {
+		t.Errorf("modelID = %q, want %q", modelID, "gemini-2.5-flash")
+	}
+
+	cfg.APIKey = ""
+	if _, _, err := CreateProviderFromConfig(cfg); err == nil {
+		t.Fatal("CreateProviderFromConfig() expected error for missing API key")
+	}
+}
+
 func TestCreateProviderFromConfig_Antigravity(t *testing.T) {
 	cfg := &config.ModelConfig{
 		ModelName: "test-antigravity",
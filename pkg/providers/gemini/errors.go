@@ -0,0 +1,87 @@
+package gemini
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// APIError is a non-200 response from the Generative Language API.
+type APIError struct {
+	StatusCode int    // HTTP status code
+	Status     string // Canonical status, e.g. "RESOURCE_EXHAUSTED"
+	Reason     string // ErrorInfo reason, e.g. "API_KEY_INVALID"
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	detail := e.Status
+	if e.Reason != "" {
+		detail += "/" + e.Reason
+	}
+	return fmt.Sprintf("gemini API error (status: %d %s): %s", e.StatusCode, detail, e.Message)
+}
+
+// FailoverReason maps the error onto the fallback chain's reason vocabulary
+// (see providers.FailoverReason). Google reports some auth and billing
+// failures as 400 INVALID_ARGUMENT / FAILED_PRECONDITION, which a status-only
+// classification would treat as malformed requests.
+func (e *APIError) FailoverReason() string {
+	switch e.Reason {
+	case "API_KEY_INVALID", "API_KEY_SERVICE_BLOCKED", "ACCESS_TOKEN_EXPIRED":
+		return "auth"
+	case "BILLING_DISABLED":
+		return "billing"
+	case "RATE_LIMIT_EXCEEDED":
+		return "rate_limit"
+	}
+
+	switch e.Status {
+	case "UNAUTHENTICATED", "PERMISSION_DENIED":
+		return "auth"
+	case "RESOURCE_EXHAUSTED":
+		return "rate_limit"
+	case "UNAVAILABLE":
+		return "overloaded"
+	case "DEADLINE_EXCEEDED", "INTERNAL":
+		return "timeout"
+	case "FAILED_PRECONDITION":
+		if strings.Contains(strings.ToLower(e.Message), "billing") {
+			return "billing"
+		}
+		return "format"
+	case "INVALID_ARGUMENT":
+		return "format"
+	}
+	return ""
+}
+
+func parseAPIError(statusCode int, body []byte) error {
+	var payload struct {
+		Error struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+			Status  string `json:"status"`
+			Details []struct {
+				Type   string `json:"@type"`
+				Reason string `json:"reason"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+
+	apiErr := &APIError{StatusCode: statusCode}
+	if err := json.Unmarshal(body, &payload); err != nil || payload.Error.Message == "" {
+		apiErr.Message = strings.TrimSpace(string(body))
+		return apiErr
+	}
+
+	apiErr.Status = payload.Error.Status
+	apiErr.Message = payload.Error.Message
+	for _, d := range payload.Error.Details {
+		if d.Reason != "" {
+			apiErr.Reason = d.Reason
+			break
+		}
+	}
+	return apiErr
+}
@@ -0,0 +1,605 @@
+// Package gemini implements a provider for the public Google Generative
+// Language API (generateContent), authenticated with an API key.
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+type (
+	ToolCall       = protocoltypes.ToolCall
+	FunctionCall   = protocoltypes.FunctionCall
+	ExtraContent   = protocoltypes.ExtraContent
+	GoogleExtra    = protocoltypes.GoogleExtra
+	LLMResponse    = protocoltypes.LLMResponse
+	UsageInfo      = protocoltypes.UsageInfo
+	Message        = protocoltypes.Message
+	ToolDefinition = protocoltypes.ToolDefinition
+)
+
+const (
+	DefaultBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+	defaultModel   = "gemini-2.5-flash"
+)
+
+// maxInlineBytes caps the size of a single inline image. The API rejects
+// requests whose total inline payload exceeds 20MB.
+const maxInlineBytes = 20 << 20
+
+type Provider struct {
+	baseURL        string
+	apiKey         string
+	safetySettings []safetySetting
+	httpClient     *http.Client
+}
+
+// NewProvider creates a Gemini provider. safety maps harm categories to block
+// thresholds, e.g. {"HARM_CATEGORY_HARASSMENT": "BLOCK_ONLY_HIGH"}.
+func NewProvider(apiKey, apiBase, proxy string, safety map[string]string) *Provider {
+	client := &http.Client{
+		Timeout: 120 * time.Second,
+	}
+
+	if proxy != "" {
+		parsed, err := url.Parse(proxy)
+		if err == nil {
+			client.Transport = &http.Transport{
+				Proxy: http.ProxyURL(parsed),
+			}
+		} else {
+			log.Printf("gemini: invalid proxy URL %q: %v", proxy, err)
+		}
+	}
+
+	return &Provider{
+		baseURL:        NormalizeBaseURL(apiBase),
+		apiKey:         apiKey,
+		safetySettings: buildSafetySettings(safety),
+		httpClient:     client,
+	}
+}
+
+// NormalizeBaseURL trims trailing slashes and the OpenAI-compatible "/openai"
+// suffix, so configs written for the openai_compat shim keep working.
+func NormalizeBaseURL(apiBase string) string {
+	base := strings.TrimRight(strings.TrimSpace(apiBase), "/")
+	base = strings.TrimSuffix(base, "/openai")
+	if base == "" {
+		return DefaultBaseURL
+	}
+	return base
+}
+
+func (p *Provider) BaseURL() string {
+	return p.baseURL
+}
+
+func (p *Provider) GetDefaultModel() string {
+	return defaultModel
+}
+
+// --- Wire types ---
+
+type request struct {
+	Contents          []content         `json:"contents"`
+	SystemInstruction *content          `json:"systemInstruction,omitempty"`
+	Tools             []tool            `json:"tools,omitempty"`
+	SafetySettings    []safetySetting   `json:"safetySettings,omitempty"`
+	GenerationConfig  *generationConfig `json:"generationConfig,omitempty"`
+}
+
+type content struct {
+	Role  string `json:"role,omitempty"`
+	Parts []part `json:"parts"`
+}
+
+type part struct {
+	Text             string            `json:"text,omitempty"`
+	Thought          bool              `json:"thought,omitempty"`
+	ThoughtSignature string            `json:"thoughtSignature,omitempty"`
+	InlineData       *inlineData       `json:"inlineData,omitempty"`
+	FunctionCall     *functionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *functionResponse `json:"functionResponse,omitempty"`
+}
+
+type inlineData struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"`
+}
+
+type functionCall struct {
+	ID   string         `json:"id,omitempty"`
+	Name string         `json:"name"`
+	Args map[string]any `json:"args"`
+}
+
+type functionResponse struct {
+	ID       string         `json:"id,omitempty"`
+	Name     string         `json:"name"`
+	Response map[string]any `json:"response"`
+}
+
+type tool struct {
+	FunctionDeclarations []functionDeclaration `json:"functionDeclarations"`
+}
+
+type functionDeclaration struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters,omitempty"`
+}
+
+type safetySetting struct {
+	Category  string `json:"category"`
+	Threshold string `json:"threshold"`
+}
+
+type generationConfig struct {
+	MaxOutputTokens  int             `json:"maxOutputTokens,omitempty"`
+	Temperature      *float64        `json:"temperature,omitempty"`
+	ThinkingConfig   *thinkingConfig `json:"thinkingConfig,omitempty"`
+	ResponseMimeType string          `json:"responseMimeType,omitempty"`
+	ResponseSchema   map[string]any  `json:"responseSchema,omitempty"`
+}
+
+type thinkingConfig struct {
+	IncludeThoughts bool   `json:"includeThoughts,omitempty"`
+	ThinkingBudget  int    `json:"thinkingBudget,omitempty"`
+	ThinkingLevel   string `json:"thinkingLevel,omitempty"`
+}
+
+type response struct {
+	Candidates []struct {
+		Content      content `json:"content"`
+		FinishReason string  `json:"finishReason"`
+	} `json:"candidates"`
+	PromptFeedback *struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+	UsageMetadata *struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+		ThoughtsTokenCount   int `json:"thoughtsTokenCount"`
+		TotalTokenCount      int `json:"totalTokenCount"`
+	} `json:"usageMetadata"`
+}
+
+// --- Chat ---
+
+func (p *Provider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	if p.apiKey == "" {
+		return nil, fmt.Errorf("gemini: no api key found")
+	}
+
+	model = normalizeModel(model)
+	if model == "" {
+		model = defaultModel
+	}
+
+	req := buildRequest(messages, tools, model, options)
+	req.SafetySettings = p.safetySettings
+
+	jsonData, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/models/%s:generateContent", p.baseURL, url.PathEscape(model))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-goog-api-key", p.apiKey)
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseAPIError(resp.StatusCode, body)
+	}
+
+	return parseResponse(body)
+}
+
+func normalizeModel(model string) string {
+	model = strings.TrimSpace(model)
+	model = strings.TrimPrefix(model, "gemini/")
+	model = strings.TrimPrefix(model, "google/")
+	return strings.TrimPrefix(model, "models/")
+}
+
+// buildRequest maps internal messages to generateContent contents.
+// System messages become the system instruction; tool results are sent as
+// functionResponse parts in a user turn, with consecutive results merged so
+// parallel calls are answered in a single turn as the API expects.
+func buildRequest(messages []Message, tools []ToolDefinition, model string, options map[string]any) request {
+	var req request
+	var systemParts []part
+	toolNames := make(map[string]string)
+
+	appendContent := func(role string, parts ...part) {
+		if n := len(req.Contents); n > 0 && req.Contents[n-1].Role == role && role == "user" &&
+			isFunctionResponse(req.Contents[n-1]) && parts[0].FunctionResponse != nil {
+			req.Contents[n-1].Parts = append(req.Contents[n-1].Parts, parts...)
+			return
+		}
+		req.Contents = append(req.Contents, content{Role: role, Parts: parts})
+	}
+
+	for _, msg := range messages {
+		switch msg.Role {
+		case "system":
+			if len(msg.SystemParts) > 0 {
+				for _, block := range msg.SystemParts {
+					if block.Text != "" {
+						systemParts = append(systemParts, part{Text: block.Text})
+					}
+				}
+			} else if msg.Content != "" {
+				systemParts = append(systemParts, part{Text: msg.Content})
+			}
+
+		case "user":
+			if msg.ToolCallID != "" {
+				appendContent("user", toolResultPart(msg, toolNames))
+				continue
+			}
+			var parts []part
+			if msg.Content != "" {
+				parts = append(parts, part{Text: msg.Content})
+			}
+			parts = append(parts, loadImages(msg.Media)...)
+			if len(parts) > 0 {
+				appendContent("user", parts...)
+			}
+
+		case "assistant":
+			var parts []part
+			if msg.Content != "" {
+				parts = append(parts, part{Text: msg.Content})
+			}
+			for _, tc := range msg.ToolCalls {
+				name, args, signature := toolCallFields(tc)
+				if name == "" {
+					continue
+				}
+				toolNames[tc.ID] = name
+				parts = append(parts, part{
+					ThoughtSignature: signature,
+					FunctionCall:     &functionCall{Name: name, Args: args},
+				})
+			}
+			if len(parts) > 0 {
+				appendContent("model", parts...)
+			}
+
+		case "tool":
+			appendContent("user", toolResultPart(msg, toolNames))
+		}
+	}
+
+	if len(systemParts) > 0 {
+		req.SystemInstruction = &content{Parts: systemParts}
+	}
+
+	var decls []functionDeclaration
+	for _, t := range tools {
+		if t.Type != "function" {
+			continue
+		}
+		decls = append(decls, functionDeclaration{
+			Name:        t.Function.Name,
+			Description: t.Function.Description,
+			Parameters:  sanitizeSchema(t.Function.Parameters),
+		})
+	}
+	if len(decls) > 0 {
+		req.Tools = []tool{{FunctionDeclarations: decls}}
+	}
+
+	genCfg := &generationConfig{}
+	if maxTokens, ok := asInt(options["max_tokens"]); ok && maxTokens > 0 {
+		genCfg.MaxOutputTokens = maxTokens
+	}
+	if temperature, ok := options["temperature"].(float64); ok {
+		genCfg.Temperature = &temperature
+	}
+	if effort, budget := protocoltypes.ReasoningFromOptions(options); effort != "" || budget > 0 {
+		genCfg.ThinkingConfig = &thinkingConfig{IncludeThoughts: true}
+		// Gemini 3 replaces the token budget with a coarse thinking level.
+		if strings.HasPrefix(model, "gemini-3") && effort != "" {
+			genCfg.ThinkingConfig.ThinkingLevel = effort
+		} else {
+			genCfg.ThinkingConfig.ThinkingBudget = budget
+		}
+	}
+	if rf := protocoltypes.ResponseFormatFromOptions(options); rf != nil {
+		genCfg.ResponseMimeType = "application/json"
+		genCfg.ResponseSchema = sanitizeSchema(rf.Schema)
+	}
+	if genCfg.MaxOutputTokens > 0 || genCfg.Temperature != nil || genCfg.ThinkingConfig != nil ||
+		genCfg.ResponseSchema != nil {
+		req.GenerationConfig = genCfg
+	}
+
+	return req
+}
+
+func isFunctionResponse(c content) bool {
+	return len(c.Parts) > 0 && c.Parts[0].FunctionResponse != nil
+}
+
+func toolResultPart(msg Message, toolNames map[string]string) part {
+	name := toolNames[msg.ToolCallID]
+	if name == "" {
+		name = msg.ToolCallID
+	}
+	return part{
+		FunctionResponse: &functionResponse{
+			Name:     name,
+			Response: map[string]any{"result": msg.Content},
+		},
+	}
+}
+
+// toolCallFields extracts name, arguments and thought signature from a stored
+// tool call, whichever of its representations carries them.
+func toolCallFields(tc ToolCall) (string, map[string]any, string) {
+	name := tc.Name
+	args := tc.Arguments
+	signature := tc.ThoughtSignature
+
+	if tc.Function != nil {
+		if name == "" {
+			name = tc.Function.Name
+		}
+		if signature == "" {
+			signature = tc.Function.ThoughtSignature
+		}
+		if args == nil && tc.Function.Arguments != "" {
+			if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
+				args = map[string]any{"raw": tc.Function.Arguments}
+			}
+		}
+	}
+	if signature == "" && tc.ExtraContent != nil && tc.ExtraContent.Google != nil {
+		signature = tc.ExtraContent.Google.ThoughtSignature
+	}
+	if args == nil {
+		args = map[string]any{}
+	}
+	return name, args, signature
+}
+
+func parseResponse(body []byte) (*LLMResponse, error) {
+	var apiResponse response
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if len(apiResponse.Candidates) == 0 {
+		if apiResponse.PromptFeedback != nil && apiResponse.PromptFeedback.BlockReason != "" {
+			return nil, fmt.Errorf("gemini: prompt blocked (%s)", apiResponse.PromptFeedback.BlockReason)
+		}
+		return nil, fmt.Errorf("gemini: response contained no candidates")
+	}
+
+	candidate := apiResponse.Candidates[0]
+	var text, reasoning strings.Builder
+	var toolCalls []ToolCall
+
+	for i, pt := range candidate.Content.Parts {
+		switch {
+		case pt.FunctionCall != nil:
+			args := pt.FunctionCall.Args
+			if args == nil {
+				args = map[string]any{}
+			}
+			argsJSON, _ := json.Marshal(args)
+			id := pt.FunctionCall.ID
+			if id == "" {
+				id = fmt.Sprintf("call_%s_%d", pt.FunctionCall.Name, i)
+			}
+			tc := ToolCall{
+				ID:        id,
+				Type:      "function",
+				Name:      pt.FunctionCall.Name,
+				Arguments: args,
+				Function: &FunctionCall{
+					Name:             pt.FunctionCall.Name,
+					Arguments:        string(argsJSON),
+					ThoughtSignature: pt.ThoughtSignature,
+				},
+				ThoughtSignature: pt.ThoughtSignature,
+			}
+			if pt.ThoughtSignature != "" {
+				tc.ExtraContent = &ExtraContent{
+					Google: &GoogleExtra{ThoughtSignature: pt.ThoughtSignature},
+				}
+			}
+			toolCalls = append(toolCalls, tc)
+		case pt.Thought:
+			reasoning.WriteString(pt.Text)
+		default:
+			text.WriteString(pt.Text)
+		}
+	}
+
+	finishReason := "stop"
+	switch {
+	case len(toolCalls) > 0:
+		finishReason = "tool_calls"
+	case candidate.FinishReason == "MAX_TOKENS":
+		finishReason = "length"
+	case isBlockedFinish(candidate.FinishReason):
+		finishReason = "content_filter"
+	}
+
+	llmResp := &LLMResponse{
+		Content:          text.String(),
+		ReasoningContent: reasoning.String(),
+		ToolCalls:        toolCalls,
+		FinishReason:     finishReason,
+	}
+	if u := apiResponse.UsageMetadata; u != nil {
+		llmResp.Usage = &UsageInfo{
+			PromptTokens:     u.PromptTokenCount,
+			CompletionTokens: u.CandidatesTokenCount + u.ThoughtsTokenCount,
+			TotalTokens:      u.TotalTokenCount,
+		}
+	}
+	return llmResp, nil
+}
+
+func isBlockedFinish(reason string) bool {
+	switch reason {
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII", "IMAGE_SAFETY":
+		return true
+	}
+	return false
+}
+
+func buildSafetySettings(safety map[string]string) []safetySetting {
+	if len(safety) == 0 {
+		return nil
+	}
+	settings := make([]safetySetting, 0, len(safety))
+	for category, threshold := range safety {
+		settings = append(settings, safetySetting{
+			Category:  strings.ToUpper(strings.TrimSpace(category)),
+			Threshold: strings.ToUpper(strings.TrimSpace(threshold)),
+		})
+	}
+	// Keep request bodies deterministic.
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Category < settings[j].Category })
+	return settings
+}
+
+// loadImages reads image files and returns them as inline data parts.
+// Non-image media (audio, documents) and unreadable files are skipped.
+func loadImages(paths []string) []part {
+	var parts []part
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("gemini: failed to read media %q: %v", path, err)
+			continue
+		}
+		if len(data) > maxInlineBytes {
+			log.Printf("gemini: skipping media %q: %d bytes exceeds limit", path, len(data))
+			continue
+		}
+		mimeType := http.DetectContentType(data)
+		if !strings.HasPrefix(mimeType, "image/") {
+			continue
+		}
+		parts = append(parts, part{
+			InlineData: &inlineData{
+				MimeType: mimeType,
+				Data:     base64.StdEncoding.EncodeToString(data),
+			},
+		})
+	}
+	return parts
+}
+
+// unsupportedSchemaKeywords lists JSON Schema keywords the Gemini function
+// declaration schema rejects.
+var unsupportedSchemaKeywords = map[string]bool{
+	"patternProperties":    true,
+	"additionalProperties": true,
+	"$schema":              true,
+	"$id":                  true,
+	"$ref":                 true,
+	"$defs":                true,
+	"definitions":          true,
+	"examples":             true,
+	"const":                true,
+}
+
+func sanitizeSchema(schema map[string]any) map[string]any {
+	if schema == nil {
+		return nil
+	}
+	result := make(map[string]any, len(schema))
+	for k, v := range schema {
+		if unsupportedSchemaKeywords[k] {
+			continue
+		}
+		switch val := v.(type) {
+		case map[string]any:
+			if k == "properties" {
+				// Keys here are argument names, not keywords.
+				props := make(map[string]any, len(val))
+				for name, prop := range val {
+					if m, ok := prop.(map[string]any); ok {
+						prop = sanitizeSchema(m)
+					}
+					props[name] = prop
+				}
+				result[k] = props
+				continue
+			}
+			result[k] = sanitizeSchema(val)
+		case []any:
+			items := make([]any, len(val))
+			for i, item := range val {
+				if m, ok := item.(map[string]any); ok {
+					items[i] = sanitizeSchema(m)
+				} else {
+					items[i] = item
+				}
+			}
+			result[k] = items
+		default:
+			result[k] = v
+		}
+	}
+	if _, hasProps := result["properties"]; hasProps {
+		if _, hasType := result["type"]; !hasType {
+			result["type"] = "object"
+		}
+	}
+	return result
+}
+
+func asInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
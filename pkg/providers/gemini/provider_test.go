@@ -0,0 +1,306 @@
+package gemini
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+// 1x1 transparent PNG.
+const pngBase64 = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAQAAAC1HAwCAAAAC0lEQVR42mNkYAAAAAYAAjCB0C8AAAAASUVORK5CYII="
+
+func TestNormalizeBaseURL(t *testing.T) {
+	tests := map[string]string{
+		"": DefaultBaseURL,
+		"https://generativelanguage.googleapis.com/v1beta/openai/": DefaultBaseURL,
+		"https://generativelanguage.googleapis.com/v1beta/":        DefaultBaseURL,
+		"https://proxy.example.com/gemini/v1beta":                  "https://proxy.example.com/gemini/v1beta",
+	}
+	for in, want := range tests {
+		if got := NormalizeBaseURL(in); got != want {
+			t.Errorf("NormalizeBaseURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestProviderChat_SendsNativeRequest(t *testing.T) {
+	var requestBody map[string]any
+	var gotPath, gotKey string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotKey = r.Header.Get("x-goog-api-key")
+		if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"candidates": []map[string]any{{
+				"content": map[string]any{
+					"role": "model",
+					"parts": []map[string]any{
+						{"text": "checking the weather", "thought": true},
+						{
+							"functionCall":     map[string]any{"name": "get_weather", "args": map[string]any{"city": "Shenzhen"}},
+							"thoughtSignature": "sig-new",
+						},
+					},
+				},
+				"finishReason": "STOP",
+			}},
+			"usageMetadata": map[string]any{
+				"promptTokenCount":     20,
+				"candidatesTokenCount": 5,
+				"thoughtsTokenCount":   3,
+				"totalTokenCount":      28,
+			},
+		})
+	}))
+	defer server.Close()
+
+	imgPath := filepath.Join(t.TempDir(), "photo.png")
+	png, _ := base64.StdEncoding.DecodeString(pngBase64)
+	if err := os.WriteFile(imgPath, png, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewProvider("test-key", server.URL+"/openai", "", map[string]string{
+		"harm_category_harassment": "block_only_high",
+	})
+	resp, err := p.Chat(
+		t.Context(),
+		[]Message{
+			{Role: "system", Content: "sys"},
+			{Role: "user", Content: "what is this?", Media: []string{imgPath}},
+			{Role: "assistant", ToolCalls: []ToolCall{
+				{
+					ID: "call_a", Name: "read_file", Arguments: map[string]any{"path": "a.txt"},
+					ExtraContent: &ExtraContent{Google: &GoogleExtra{ThoughtSignature: "sig-old"}},
+				},
+				{ID: "call_b", Name: "list_dir", Arguments: map[string]any{"path": "."}},
+			}},
+			{Role: "tool", Content: "hello", ToolCallID: "call_a"},
+			{Role: "tool", Content: "a.txt", ToolCallID: "call_b"},
+		},
+		[]ToolDefinition{{
+			Type: "function",
+			Function: protocoltypes.ToolFunctionDefinition{
+				Name: "get_weather",
+				Parameters: map[string]any{
+					"type":                 "object",
+					"additionalProperties": false,
+					"properties":           map[string]any{"city": map[string]any{"type": "string"}},
+				},
+			},
+		}},
+		"gemini/gemini-2.5-flash",
+		map[string]any{"max_tokens": 256, "temperature": 0.0},
+	)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if gotPath != "/models/gemini-2.5-flash:generateContent" {
+		t.Errorf("path = %q", gotPath)
+	}
+	if gotKey != "test-key" {
+		t.Errorf("x-goog-api-key = %q", gotKey)
+	}
+
+	sys, _ := requestBody["systemInstruction"].(map[string]any)
+	if parts, _ := sys["parts"].([]any); len(parts) != 1 {
+		t.Errorf("unexpected systemInstruction: %v", requestBody["systemInstruction"])
+	}
+	safety, _ := requestBody["safetySettings"].([]any)
+	if len(safety) != 1 || safety[0].(map[string]any)["threshold"] != "BLOCK_ONLY_HIGH" {
+		t.Errorf("unexpected safetySettings: %v", requestBody["safetySettings"])
+	}
+	genCfg, _ := requestBody["generationConfig"].(map[string]any)
+	if genCfg["maxOutputTokens"] != float64(256) || genCfg["temperature"] != 0.0 {
+		t.Errorf("unexpected generationConfig: %v", genCfg)
+	}
+	tools, _ := requestBody["tools"].([]any)
+	decls, _ := tools[0].(map[string]any)["functionDeclarations"].([]any)
+	params := decls[0].(map[string]any)["parameters"].(map[string]any)
+	if _, ok := params["additionalProperties"]; ok {
+		t.Error("additionalProperties should be stripped from tool schema")
+	}
+
+	contents, _ := requestBody["contents"].([]any)
+	if len(contents) != 3 {
+		t.Fatalf("expected 3 contents (user, model, merged tool results), got %d", len(contents))
+	}
+	userParts := contents[0].(map[string]any)["parts"].([]any)
+	if len(userParts) != 2 {
+		t.Fatalf("expected text + image parts, got %v", userParts)
+	}
+	inline, _ := userParts[1].(map[string]any)["inlineData"].(map[string]any)
+	if inline["mimeType"] != "image/png" || inline["data"] != pngBase64 {
+		t.Errorf("unexpected inlineData: %v", inline)
+	}
+	modelParts := contents[1].(map[string]any)["parts"].([]any)
+	if modelParts[0].(map[string]any)["thoughtSignature"] != "sig-old" {
+		t.Errorf("thought signature not round-tripped: %v", modelParts[0])
+	}
+	toolParts := contents[2].(map[string]any)["parts"].([]any)
+	if len(toolParts) != 2 {
+		t.Fatalf("expected 2 functionResponse parts, got %v", toolParts)
+	}
+	fr := toolParts[1].(map[string]any)["functionResponse"].(map[string]any)
+	if fr["name"] != "list_dir" {
+		t.Errorf("functionResponse name = %v, want list_dir", fr["name"])
+	}
+
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "get_weather" || resp.ToolCalls[0].ID == "" {
+		t.Fatalf("unexpected tool calls: %+v", resp.ToolCalls)
+	}
+	tc := resp.ToolCalls[0]
+	if tc.ExtraContent == nil || tc.ExtraContent.Google.ThoughtSignature != "sig-new" ||
+		tc.Function.ThoughtSignature != "sig-new" {
+		t.Errorf("thought signature not captured: %+v", tc)
+	}
+	if resp.FinishReason != "tool_calls" {
+		t.Errorf("FinishReason = %q, want tool_calls", resp.FinishReason)
+	}
+	if resp.ReasoningContent != "checking the weather" || resp.Content != "" {
+		t.Errorf("Content = %q, ReasoningContent = %q", resp.Content, resp.ReasoningContent)
+	}
+	if resp.Usage == nil || resp.Usage.CompletionTokens != 8 || resp.Usage.TotalTokens != 28 {
+		t.Errorf("unexpected usage: %+v", resp.Usage)
+	}
+}
+
+func TestProviderChat_SafetyBlocked(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"candidates":[{"content":{"parts":[]},"finishReason":"SAFETY"}]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("k", server.URL, "", nil)
+	resp, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "gemini-2.5-flash", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.FinishReason != "content_filter" {
+		t.Errorf("FinishReason = %q, want content_filter", resp.FinishReason)
+	}
+}
+
+func TestProviderChat_APIErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		reason string
+	}{
+		{
+			name:   "invalid key reported as 400",
+			status: http.StatusBadRequest,
+			body: `{"error":{"code":400,"message":"API key not valid.","status":"INVALID_ARGUMENT",
+				"details":[{"@type":"type.googleapis.com/google.rpc.ErrorInfo","reason":"API_KEY_INVALID"}]}}`,
+			reason: "auth",
+		},
+		{
+			name:   "quota",
+			status: http.StatusTooManyRequests,
+			body:   `{"error":{"code":429,"message":"Quota exceeded","status":"RESOURCE_EXHAUSTED"}}`,
+			reason: "rate_limit",
+		},
+		{
+			name:   "bad request",
+			status: http.StatusBadRequest,
+			body:   `{"error":{"code":400,"message":"Invalid JSON payload","status":"INVALID_ARGUMENT"}}`,
+			reason: "format",
+		},
+		{
+			name:   "overloaded",
+			status: http.StatusServiceUnavailable,
+			body:   `{"error":{"code":503,"message":"The model is overloaded.","status":"UNAVAILABLE"}}`,
+			reason: "overloaded",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			p := NewProvider("k", server.URL, "", nil)
+			_, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "gemini-2.5-flash", nil)
+			apiErr, ok := err.(*APIError)
+			if !ok {
+				t.Fatalf("expected *APIError, got %T: %v", err, err)
+			}
+			if apiErr.StatusCode != tt.status {
+				t.Errorf("StatusCode = %d, want %d", apiErr.StatusCode, tt.status)
+			}
+			if got := apiErr.FailoverReason(); got != tt.reason {
+				t.Errorf("FailoverReason() = %q, want %q", got, tt.reason)
+			}
+		})
+	}
+}
+
+func TestBuildRequest_ThinkingConfig(t *testing.T) {
+	msgs := []Message{{Role: "user", Content: "hi"}}
+
+	req := buildRequest(msgs, nil, "gemini-2.5-flash", map[string]any{"reasoning_effort": "low"})
+	if tc := req.GenerationConfig.ThinkingConfig; tc == nil || tc.ThinkingBudget != 1024 || !tc.IncludeThoughts {
+		t.Errorf("gemini-2.5 thinkingConfig = %+v, want budget 1024", tc)
+	}
+
+	req = buildRequest(msgs, nil, "gemini-3-pro-preview", map[string]any{"reasoning_effort": "high"})
+	if tc := req.GenerationConfig.ThinkingConfig; tc == nil || tc.ThinkingLevel != "high" || tc.ThinkingBudget != 0 {
+		t.Errorf("gemini-3 thinkingConfig = %+v, want level high", tc)
+	}
+
+	req = buildRequest(msgs, nil, "gemini-2.5-flash", nil)
+	if req.GenerationConfig != nil {
+		t.Errorf("expected no generationConfig, got %+v", req.GenerationConfig)
+	}
+}
+
+func TestBuildRequest_ResponseSchema(t *testing.T) {
+	req := buildRequest([]Message{{Role: "user", Content: "hi"}}, nil, "gemini-2.5-flash", map[string]any{
+		"response_format": protocoltypes.ResponseFormat{Schema: map[string]any{
+			"type":                 "object",
+			"additionalProperties": false,
+			"properties":           map[string]any{"name": map[string]any{"type": "string"}},
+		}},
+	})
+	cfg := req.GenerationConfig
+	if cfg == nil || cfg.ResponseMimeType != "application/json" || cfg.ResponseSchema == nil {
+		t.Fatalf("generationConfig = %+v, want JSON response schema", cfg)
+	}
+	if _, ok := cfg.ResponseSchema["additionalProperties"]; ok {
+		t.Error("additionalProperties should be stripped from response schema")
+	}
+}
+
+func TestSanitizeSchema_KeepsPropertyNames(t *testing.T) {
+	got := sanitizeSchema(map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"examples": map[string]any{"type": "array", "examples": []any{"a"}},
+			"const":    map[string]any{"type": "string"},
+		},
+		"required": []any{"examples", "const"},
+	})
+	props := got["properties"].(map[string]any)
+	for _, name := range []string{"examples", "const"} {
+		if _, ok := props[name]; !ok {
+			t.Errorf("property %q was dropped", name)
+		}
+	}
+	if _, ok := props["examples"].(map[string]any)["examples"]; ok {
+		t.Error("examples keyword inside a property schema should be stripped")
+	}
+}
@@ -0,0 +1,28 @@
+package providers
+
+import (
+	"context"
+
+	geminiprovider "github.com/sipeed/picoclaw/pkg/providers/gemini"
+)
+
+// GeminiProvider talks to the public Google Generative Language API with an API key.
+type GeminiProvider struct {
+	delegate *geminiprovider.Provider
+}
+
+func NewGeminiProvider(apiKey, apiBase, proxy string, safety map[string]string) *GeminiProvider {
+	return &GeminiProvider{
+		delegate: geminiprovider.NewProvider(apiKey, apiBase, proxy, safety),
+	}
+}
+
+func (p *GeminiProvider) Chat(
+	ctx context.Context, messages []Message, tools []ToolDefinition, model string, options map[string]any,
+) (*LLMResponse, error) {
+	return p.delegate.Chat(ctx, messages, tools, model, options)
+}
+
+func (p *GeminiProvider) GetDefaultModel() string {
+	return p.delegate.GetDefaultModel()
+}
@@ -0,0 +1,528 @@
+// Package ollama implements a provider for the native Ollama HTTP API
+// (/api/chat, /api/show, /api/tags, /api/pull).
+package ollama
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+type (
+	ToolCall       = protocoltypes.ToolCall
+	FunctionCall   = protocoltypes.FunctionCall
+	LLMResponse    = protocoltypes.LLMResponse
+	UsageInfo      = protocoltypes.UsageInfo
+	Message        = protocoltypes.Message
+	ToolDefinition = protocoltypes.ToolDefinition
+)
+
+const DefaultBaseURL = "http://localhost:11434"
+
+// maxImageBytes caps the size of a single inline image sent to Ollama.
+const maxImageBytes = 20 << 20
+
+type Provider struct {
+	baseURL    string
+	apiKey     string
+	keepAlive  string
+	httpClient *http.Client
+}
+
+func NewProvider(apiKey, apiBase, proxy, keepAlive string) *Provider {
+	client := &http.Client{
+		// Local models on small boards can be slow to load and generate.
+		Timeout: 300 * time.Second,
+	}
+
+	if proxy != "" {
+		parsed, err := url.Parse(proxy)
+		if err == nil {
+			client.Transport = &http.Transport{
+				Proxy: http.ProxyURL(parsed),
+			}
+		} else {
+			log.Printf("ollama: invalid proxy URL %q: %v", proxy, err)
+		}
+	}
+
+	return &Provider{
+		baseURL:    NormalizeBaseURL(apiBase),
+		apiKey:     apiKey,
+		keepAlive:  keepAlive,
+		httpClient: client,
+	}
+}
+
+// NormalizeBaseURL trims trailing slashes and the OpenAI-compatible "/v1" suffix,
+// so configs written for the openai_compat path keep working.
+func NormalizeBaseURL(apiBase string) string {
+	base := strings.TrimRight(strings.TrimSpace(apiBase), "/")
+	base = strings.TrimSuffix(base, "/v1")
+	if base == "" {
+		return DefaultBaseURL
+	}
+	return base
+}
+
+func (p *Provider) BaseURL() string {
+	return p.baseURL
+}
+
+func (p *Provider) GetDefaultModel() string {
+	return ""
+}
+
+type chatMessage struct {
+	Role      string         `json:"role"`
+	Content   string         `json:"content"`
+	Thinking  string         `json:"thinking,omitempty"`
+	Images    []string       `json:"images,omitempty"`
+	ToolCalls []chatToolCall `json:"tool_calls,omitempty"`
+	ToolName  string         `json:"tool_name,omitempty"`
+}
+
+type chatToolCall struct {
+	Function struct {
+		Name      string         `json:"name"`
+		Arguments map[string]any `json:"arguments"`
+	} `json:"function"`
+}
+
+type chatResponse struct {
+	Message         chatMessage `json:"message"`
+	Done            bool        `json:"done"`
+	DoneReason      string      `json:"done_reason"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+	EvalCount       int         `json:"eval_count"`
+	Error           string      `json:"error"`
+}
+
+func (p *Provider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	requestBody := map[string]any{
+		"model":    normalizeModel(model),
+		"messages": convertMessages(messages),
+		"stream":   false,
+	}
+
+	if len(tools) > 0 {
+		requestBody["tools"] = tools
+	}
+
+	if p.keepAlive != "" {
+		requestBody["keep_alive"] = p.keepAlive
+	}
+
+	if effort, budget := protocoltypes.ReasoningFromOptions(options); effort != "" || budget > 0 {
+		// gpt-oss takes a level; other thinking models only accept a boolean.
+		if strings.Contains(normalizeModel(model), "gpt-oss") && effort != "" {
+			requestBody["think"] = effort
+		} else {
+			requestBody["think"] = true
+		}
+	}
+
+	// Ollama constrains generation to a JSON schema passed as "format".
+	if rf := protocoltypes.ResponseFormatFromOptions(options); rf != nil {
+		requestBody["format"] = rf.Schema
+	}
+
+	modelOptions := map[string]any{}
+	if maxTokens, ok := asInt(options["max_tokens"]); ok {
+		modelOptions["num_predict"] = maxTokens
+	}
+	if temperature, ok := options["temperature"].(float64); ok {
+		modelOptions["temperature"] = temperature
+	}
+	if len(modelOptions) > 0 {
+		requestBody["options"] = modelOptions
+	}
+
+	body, err := p.post(ctx, "/api/chat", requestBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseResponse(body)
+}
+
+func (p *Provider) post(ctx context.Context, path string, payload any) ([]byte, error) {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+path, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	p.setHeaders(req)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API request failed:\n  Status: %d\n  Body:   %s", resp.StatusCode, string(body))
+	}
+	return body, nil
+}
+
+func (p *Provider) setHeaders(req *http.Request) {
+	req.Header.Set("Content-Type", "application/json")
+	// Ollama itself ignores auth, but reverse proxies in front of it often require a token.
+	if p.apiKey != "" && p.apiKey != "ollama" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+}
+
+func parseResponse(body []byte) (*LLMResponse, error) {
+	var apiResponse chatResponse
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if apiResponse.Error != "" {
+		return nil, fmt.Errorf("ollama error: %s", apiResponse.Error)
+	}
+
+	// Ollama does not return tool call IDs; synthesize stable per-response IDs
+	// so tool results can be matched back to their calls.
+	toolCalls := make([]ToolCall, 0, len(apiResponse.Message.ToolCalls))
+	for i, tc := range apiResponse.Message.ToolCalls {
+		args := tc.Function.Arguments
+		if args == nil {
+			args = map[string]any{}
+		}
+		toolCalls = append(toolCalls, ToolCall{
+			ID:        "call_" + strconv.Itoa(i),
+			Name:      tc.Function.Name,
+			Arguments: args,
+		})
+	}
+
+	finishReason := "stop"
+	switch {
+	case len(toolCalls) > 0:
+		finishReason = "tool_calls"
+	case apiResponse.DoneReason == "length":
+		finishReason = "length"
+	}
+
+	return &LLMResponse{
+		Content:          apiResponse.Message.Content,
+		ReasoningContent: apiResponse.Message.Thinking,
+		ToolCalls:        toolCalls,
+		FinishReason:     finishReason,
+		Usage: &UsageInfo{
+			PromptTokens:     apiResponse.PromptEvalCount,
+			CompletionTokens: apiResponse.EvalCount,
+			TotalTokens:      apiResponse.PromptEvalCount + apiResponse.EvalCount,
+		},
+	}, nil
+}
+
+// convertMessages maps internal messages to the Ollama wire format.
+// Tool results carry the tool name (looked up from the preceding assistant
+// tool calls) because Ollama has no tool call IDs.
+func convertMessages(messages []Message) []chatMessage {
+	toolNames := make(map[string]string)
+	out := make([]chatMessage, 0, len(messages))
+
+	for _, m := range messages {
+		cm := chatMessage{
+			Role:     m.Role,
+			Content:  m.Content,
+			Thinking: m.ReasoningContent,
+		}
+
+		for _, tc := range m.ToolCalls {
+			name, args := toolCallNameAndArgs(tc)
+			toolNames[tc.ID] = name
+			var call chatToolCall
+			call.Function.Name = name
+			call.Function.Arguments = args
+			cm.ToolCalls = append(cm.ToolCalls, call)
+		}
+
+		if m.Role == "tool" {
+			cm.ToolName = toolNames[m.ToolCallID]
+		}
+
+		if len(m.Media) > 0 {
+			cm.Images = loadImages(m.Media)
+		}
+
+		out = append(out, cm)
+	}
+	return out
+}
+
+func toolCallNameAndArgs(tc ToolCall) (string, map[string]any) {
+	name := tc.Name
+	args := tc.Arguments
+	if tc.Function != nil {
+		if name == "" {
+			name = tc.Function.Name
+		}
+		if args == nil && tc.Function.Arguments != "" {
+			if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
+				args = map[string]any{"raw": tc.Function.Arguments}
+			}
+		}
+	}
+	if args == nil {
+		args = map[string]any{}
+	}
+	return name, args
+}
+
+// loadImages reads image files and returns them base64-encoded.
+// Non-image media (audio, documents) and unreadable files are skipped.
+func loadImages(paths []string) []string {
+	var images []string
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("ollama: failed to read media %q: %v", path, err)
+			continue
+		}
+		if len(data) > maxImageBytes {
+			log.Printf("ollama: skipping media %q: %d bytes exceeds limit", path, len(data))
+			continue
+		}
+		if !strings.HasPrefix(http.DetectContentType(data), "image/") {
+			continue
+		}
+		images = append(images, base64.StdEncoding.EncodeToString(data))
+	}
+	return images
+}
+
+// ModelInfo describes a model as reported by /api/show.
+type ModelInfo struct {
+	Family        string
+	ParameterSize string
+	Quantization  string
+	ContextLength int
+}
+
+// Show queries /api/show for model details, including the context length.
+func (p *Provider) Show(ctx context.Context, model string) (*ModelInfo, error) {
+	body, err := p.post(ctx, "/api/show", map[string]any{"model": normalizeModel(model)})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Parameters string         `json:"parameters"`
+		ModelInfo  map[string]any `json:"model_info"`
+		Details    struct {
+			Family            string `json:"family"`
+			ParameterSize     string `json:"parameter_size"`
+			QuantizationLevel string `json:"quantization_level"`
+		} `json:"details"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	info := &ModelInfo{
+		Family:        resp.Details.Family,
+		ParameterSize: resp.Details.ParameterSize,
+		Quantization:  resp.Details.QuantizationLevel,
+	}
+
+	// An explicit num_ctx in the Modelfile wins over the architecture maximum,
+	// since that is what the server will actually allocate.
+	for _, line := range strings.Split(resp.Parameters, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "num_ctx" {
+			if n, err := strconv.Atoi(fields[1]); err == nil {
+				info.ContextLength = n
+				return info, nil
+			}
+		}
+	}
+	for key, v := range resp.ModelInfo {
+		if strings.HasSuffix(key, ".context_length") {
+			if n, ok := asInt(v); ok {
+				info.ContextLength = n
+				break
+			}
+		}
+	}
+	return info, nil
+}
+
+// ContextWindow returns the model's context length as reported by /api/show.
+func (p *Provider) ContextWindow(ctx context.Context, model string) (int, error) {
+	info, err := p.Show(ctx, model)
+	if err != nil {
+		return 0, err
+	}
+	if info.ContextLength <= 0 {
+		return 0, fmt.Errorf("ollama: context length not reported for %q", model)
+	}
+	return info.ContextLength, nil
+}
+
+// LocalModel is an entry returned by /api/tags.
+type LocalModel struct {
+	Name          string    `json:"name"`
+	Size          int64     `json:"size"`
+	ModifiedAt    time.Time `json:"modified_at"`
+	ParameterSize string    `json:"-"`
+	Quantization  string    `json:"-"`
+}
+
+// ListLocal returns the models available on the Ollama server.
+func (p *Provider) ListLocal(ctx context.Context) ([]LocalModel, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.baseURL+"/api/tags", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	p.setHeaders(req)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API request failed:\n  Status: %d\n  Body:   %s", resp.StatusCode, string(body))
+	}
+
+	var tags struct {
+		Models []struct {
+			Name       string    `json:"name"`
+			Size       int64     `json:"size"`
+			ModifiedAt time.Time `json:"modified_at"`
+			Details    struct {
+				ParameterSize     string `json:"parameter_size"`
+				QuantizationLevel string `json:"quantization_level"`
+			} `json:"details"`
+		} `json:"models"`
+	}
+	if err := json.Unmarshal(body, &tags); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	models := make([]LocalModel, 0, len(tags.Models))
+	for _, m := range tags.Models {
+		models = append(models, LocalModel{
+			Name:          m.Name,
+			Size:          m.Size,
+			ModifiedAt:    m.ModifiedAt,
+			ParameterSize: m.Details.ParameterSize,
+			Quantization:  m.Details.QuantizationLevel,
+		})
+	}
+	return models, nil
+}
+
+// PullProgress is one status update streamed by /api/pull.
+type PullProgress struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Pull downloads a model, reporting streamed progress to the callback.
+// Pulls can take a long time, so the request is bound only by ctx.
+func (p *Provider) Pull(ctx context.Context, model string, progress func(PullProgress)) error {
+	jsonData, err := json.Marshal(map[string]any{"model": normalizeModel(model), "stream": true})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/api/pull", bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	p.setHeaders(req)
+
+	client := &http.Client{Transport: p.httpClient.Transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("API request failed:\n  Status: %d\n  Body:   %s", resp.StatusCode, string(body))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var update PullProgress
+		if err := json.Unmarshal(line, &update); err != nil {
+			continue
+		}
+		if update.Error != "" {
+			return fmt.Errorf("ollama pull failed: %s", update.Error)
+		}
+		if progress != nil {
+			progress(update)
+		}
+	}
+	return scanner.Err()
+}
+
+// normalizeModel strips a leftover "ollama/" prefix from the model name.
+func normalizeModel(model string) string {
+	return strings.TrimPrefix(model, "ollama/")
+}
+
+func asInt(v any) (int, bool) {
+	switch val := v.(type) {
+	case int:
+		return val, true
+	case int64:
+		return int(val), true
+	case float64:
+		return int(val), true
+	case float32:
+		return int(val), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,238 @@
+package ollama
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+// 1x1 transparent PNG.
+const pngBase64 = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAQAAAC1HAwCAAAAC0lEQVR42mNkYAAAAAYAAjCB0C8AAAAASUVORK5CYII="
+
+func TestNormalizeBaseURL(t *testing.T) {
+	tests := map[string]string{
+		"":                           DefaultBaseURL,
+		"http://localhost:11434/v1":  "http://localhost:11434",
+		"http://pi.lan:11434/":       "http://pi.lan:11434",
+		"http://pi.lan:11434/v1/":    "http://pi.lan:11434",
+		"  http://10.0.0.2:11434  ":  "http://10.0.0.2:11434",
+		"https://ollama.example.com": "https://ollama.example.com",
+	}
+	for in, want := range tests {
+		if got := NormalizeBaseURL(in); got != want {
+			t.Errorf("NormalizeBaseURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestProviderChat_SendsNativeRequest(t *testing.T) {
+	var requestBody map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"message": map[string]any{
+				"role":     "assistant",
+				"content":  "",
+				"thinking": "need the weather",
+				"tool_calls": []map[string]any{
+					{"function": map[string]any{"name": "get_weather", "arguments": map[string]any{"city": "Shenzhen"}}},
+				},
+			},
+			"done":              true,
+			"done_reason":       "stop",
+			"prompt_eval_count": 12,
+			"eval_count":        5,
+		})
+	}))
+	defer server.Close()
+
+	imgPath := filepath.Join(t.TempDir(), "photo.png")
+	png, _ := base64.StdEncoding.DecodeString(pngBase64)
+	if err := os.WriteFile(imgPath, png, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewProvider("", server.URL+"/v1", "", "30m")
+	resp, err := p.Chat(
+		t.Context(),
+		[]Message{
+			{Role: "system", Content: "sys"},
+			{Role: "user", Content: "what is this?", Media: []string{imgPath}},
+			{Role: "assistant", ToolCalls: []ToolCall{{
+				ID: "call_0", Name: "read_file", Arguments: map[string]any{"path": "a.txt"},
+			}}},
+			{Role: "tool", Content: "hello", ToolCallID: "call_0"},
+		},
+		[]ToolDefinition{{
+			Type:     "function",
+			Function: protocoltypes.ToolFunctionDefinition{Name: "get_weather", Parameters: map[string]any{"type": "object"}},
+		}},
+		"ollama/qwen3:4b",
+		map[string]any{
+			"max_tokens":      256,
+			"temperature":     0.2,
+			"response_format": &protocoltypes.ResponseFormat{Schema: map[string]any{"type": "object"}},
+		},
+	)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if requestBody["model"] != "qwen3:4b" {
+		t.Errorf("model = %v, want prefix stripped", requestBody["model"])
+	}
+	if requestBody["stream"] != false {
+		t.Errorf("stream = %v, want false", requestBody["stream"])
+	}
+	if requestBody["keep_alive"] != "30m" {
+		t.Errorf("keep_alive = %v, want 30m", requestBody["keep_alive"])
+	}
+	opts, _ := requestBody["options"].(map[string]any)
+	if opts["num_predict"] != float64(256) || opts["temperature"] != 0.2 {
+		t.Errorf("unexpected options: %v", opts)
+	}
+	if format, _ := requestBody["format"].(map[string]any); format["type"] != "object" {
+		t.Errorf("format = %v, want the response schema", requestBody["format"])
+	}
+	if tools, _ := requestBody["tools"].([]any); len(tools) != 1 {
+		t.Errorf("expected 1 tool, got %v", requestBody["tools"])
+	}
+
+	msgs, _ := requestBody["messages"].([]any)
+	if len(msgs) != 4 {
+		t.Fatalf("expected 4 messages, got %d", len(msgs))
+	}
+	user := msgs[1].(map[string]any)
+	if images, _ := user["images"].([]any); len(images) != 1 || images[0] != pngBase64 {
+		t.Errorf("expected inline base64 image, got %v", user["images"])
+	}
+	tool := msgs[3].(map[string]any)
+	if tool["tool_name"] != "read_file" {
+		t.Errorf("tool_name = %v, want read_file", tool["tool_name"])
+	}
+
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "get_weather" || resp.ToolCalls[0].ID == "" {
+		t.Fatalf("unexpected tool calls: %+v", resp.ToolCalls)
+	}
+	if resp.ToolCalls[0].Arguments["city"] != "Shenzhen" {
+		t.Errorf("unexpected arguments: %v", resp.ToolCalls[0].Arguments)
+	}
+	if resp.FinishReason != "tool_calls" {
+		t.Errorf("FinishReason = %q, want tool_calls", resp.FinishReason)
+	}
+	if resp.ReasoningContent != "need the weather" {
+		t.Errorf("ReasoningContent = %q", resp.ReasoningContent)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 17 {
+		t.Errorf("unexpected usage: %+v", resp.Usage)
+	}
+}
+
+func TestProviderChat_HTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"model 'nope' not found"}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	p := NewProvider("", server.URL, "", "")
+	_, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "nope", nil)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestProviderContextWindow(t *testing.T) {
+	tests := []struct {
+		name string
+		body map[string]any
+		want int
+	}{
+		{
+			name: "model info",
+			body: map[string]any{"model_info": map[string]any{"qwen3.context_length": 40960}},
+			want: 40960,
+		},
+		{
+			name: "num_ctx parameter wins",
+			body: map[string]any{
+				"parameters": "temperature 0.6\nnum_ctx 8192",
+				"model_info": map[string]any{"llama.context_length": 131072},
+			},
+			want: 8192,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/show" {
+					http.Error(w, "not found", http.StatusNotFound)
+					return
+				}
+				json.NewEncoder(w).Encode(tt.body)
+			}))
+			defer server.Close()
+
+			p := NewProvider("", server.URL, "", "")
+			got, err := p.ContextWindow(t.Context(), "m")
+			if err != nil {
+				t.Fatalf("ContextWindow() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ContextWindow() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProviderListLocalAndPull(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			json.NewEncoder(w).Encode(map[string]any{
+				"models": []map[string]any{{
+					"name":    "qwen3:4b",
+					"size":    2500000000,
+					"details": map[string]any{"parameter_size": "4B", "quantization_level": "Q4_K_M"},
+				}},
+			})
+		case "/api/pull":
+			w.Write([]byte(`{"status":"pulling manifest"}` + "\n"))
+			w.Write([]byte(`{"status":"downloading","digest":"sha256:x","total":100,"completed":50}` + "\n"))
+			w.Write([]byte(`{"status":"success"}` + "\n"))
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p := NewProvider("", server.URL, "", "")
+	models, err := p.ListLocal(t.Context())
+	if err != nil {
+		t.Fatalf("ListLocal() error = %v", err)
+	}
+	if len(models) != 1 || models[0].Name != "qwen3:4b" || models[0].Quantization != "Q4_K_M" {
+		t.Errorf("unexpected models: %+v", models)
+	}
+
+	var statuses []string
+	if err := p.Pull(t.Context(), "qwen3:4b", func(u PullProgress) { statuses = append(statuses, u.Status) }); err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+	if len(statuses) != 3 || statuses[2] != "success" {
+		t.Errorf("unexpected progress: %v", statuses)
+	}
+}
@@ -0,0 +1,33 @@
+package providers
+
+import (
+	"context"
+
+	ollamaprovider "github.com/sipeed/picoclaw/pkg/providers/ollama"
+)
+
+// OllamaProvider talks to a local or LAN Ollama server through its native API.
+type OllamaProvider struct {
+	delegate *ollamaprovider.Provider
+}
+
+func NewOllamaProvider(apiKey, apiBase, proxy, keepAlive string) *OllamaProvider {
+	return &OllamaProvider{
+		delegate: ollamaprovider.NewProvider(apiKey, apiBase, proxy, keepAlive),
+	}
+}
+
+func (p *OllamaProvider) Chat(
+	ctx context.Context, messages []Message, tools []ToolDefinition, model string, options map[string]any,
+) (*LLMResponse, error) {
+	return p.delegate.Chat(ctx, messages, tools, model, options)
+}
+
+func (p *OllamaProvider) GetDefaultModel() string {
+	return p.delegate.GetDefaultModel()
+}
+
+// ContextWindow reports the model's context length from /api/show.
+func (p *OllamaProvider) ContextWindow(ctx context.Context, model string) (int, error) {
+	return p.delegate.ContextWindow(ctx, model)
+}
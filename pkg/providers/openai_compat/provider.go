@@ -101,7 +101,7 @@ func (p *Provider) Chat(
 		requestBody[fieldName] = maxTokens
 	}
 
-	if temperature, ok := asFloat(options["temperature"]); ok {
+	if temperature, ok := asFloat(options["temperature"]); ok && !thinkingDisallowsTemperature(p.apiBase, options) {
 		lowerModel := strings.ToLower(model)
 		// Kimi k2 models only support temperature=1.
 		if strings.Contains(lowerModel, "kimi") && strings.Contains(lowerModel, "k2") {
@@ -111,6 +111,9 @@ func (p *Provider) Chat(
 		}
 	}
 
+	applyReasoningOptions(requestBody, p.apiBase, model, options)
+	applyResponseFormat(requestBody, p.apiBase, options)
+
 	// Prompt caching: pass a stable cache key so OpenAI can bucket requests
 	// with the same key and reuse prefix KV cache across calls.
 	// The key is typically the agent ID — stable per agent, shared across requests.
@@ -152,6 +155,70 @@ func (p *Provider) Chat(
 	return parseResponse(body)
 }
 
+// applyReasoningOptions translates the generic reasoning options into the
+// request field each OpenAI-compatible backend understands.
+func applyReasoningOptions(requestBody map[string]any, apiBase, model string, options map[string]any) {
+	effort, budget := protocoltypes.ReasoningFromOptions(options)
+	if effort == "" && budget <= 0 {
+		return
+	}
+
+	lowerBase := strings.ToLower(apiBase)
+	switch {
+	case strings.Contains(lowerBase, "anthropic.com"):
+		// Anthropic's OpenAI SDK compatibility layer accepts the native thinking block.
+		requestBody["thinking"] = map[string]any{"type": "enabled", "budget_tokens": max(budget, 1024)}
+	case strings.Contains(lowerBase, "dashscope"):
+		// Qwen3 hybrid thinking models.
+		requestBody["enable_thinking"] = true
+		if budget > 0 {
+			requestBody["thinking_budget"] = budget
+		}
+	case strings.Contains(lowerBase, "deepseek.com"):
+		// deepseek-reasoner always thinks; hybrid models opt in per request.
+		if !strings.Contains(strings.ToLower(model), "reasoner") {
+			requestBody["thinking"] = map[string]any{"type": "enabled"}
+		}
+	default:
+		if effort != "" {
+			requestBody["reasoning_effort"] = effort
+		}
+	}
+}
+
+// applyResponseFormat requests schema-constrained JSON output. DeepSeek only
+// supports plain JSON mode, so the schema itself is left to the prompt there.
+func applyResponseFormat(requestBody map[string]any, apiBase string, options map[string]any) {
+	rf := protocoltypes.ResponseFormatFromOptions(options)
+	if rf == nil {
+		return
+	}
+
+	if strings.Contains(strings.ToLower(apiBase), "deepseek.com") {
+		requestBody["response_format"] = map[string]any{"type": "json_object"}
+		return
+	}
+
+	name := rf.Name
+	if name == "" {
+		name = "response"
+	}
+	requestBody["response_format"] = map[string]any{
+		"type": "json_schema",
+		"json_schema": map[string]any{
+			"name":   name,
+			"schema": rf.Schema,
+		},
+	}
+}
+
+// thinkingDisallowsTemperature reports whether the backend rejects a custom
+// temperature while extended thinking is on.
+func thinkingDisallowsTemperature(apiBase string, options map[string]any) bool {
+	_, budget := protocoltypes.ReasoningFromOptions(options)
+	return budget > 0 && strings.Contains(strings.ToLower(apiBase), "anthropic.com")
+}
+
 func parseResponse(body []byte) (*LLMResponse, error) {
 	var apiResponse struct {
 		Choices []struct {
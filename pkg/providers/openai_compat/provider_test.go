@@ -6,6 +6,8 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
 )
 
 func TestProviderChat_UsesMaxCompletionTokensForGLM(t *testing.T) {
@@ -364,3 +366,93 @@ func TestNormalizeModel_UsesAPIBase(t *testing.T) {
 		t.Fatalf("normalizeModel(openrouter) = %q, want %q", got, "openrouter/auto")
 	}
 }
+
+func TestApplyReasoningOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		apiBase string
+		model   string
+		options map[string]any
+		want    map[string]any
+	}{
+		{
+			name:    "openai effort",
+			apiBase: "https://api.openai.com/v1",
+			model:   "o4-mini",
+			options: map[string]any{"reasoning_effort": "high"},
+			want:    map[string]any{"reasoning_effort": "high"},
+		},
+		{
+			name:    "openai budget maps to effort",
+			apiBase: "https://api.openai.com/v1",
+			model:   "gpt-5",
+			options: map[string]any{"thinking_budget": 2000},
+			want:    map[string]any{"reasoning_effort": "low"},
+		},
+		{
+			name:    "qwen thinking budget",
+			apiBase: "https://dashscope.aliyuncs.com/compatible-mode/v1",
+			model:   "qwen-plus",
+			options: map[string]any{"thinking_budget": 8000},
+			want:    map[string]any{"enable_thinking": true, "thinking_budget": 8000},
+		},
+		{
+			name:    "deepseek hybrid model",
+			apiBase: "https://api.deepseek.com/v1",
+			model:   "deepseek-chat",
+			options: map[string]any{"reasoning_effort": "medium"},
+			want:    map[string]any{"thinking": map[string]any{"type": "enabled"}},
+		},
+		{
+			name:    "deepseek reasoner needs nothing",
+			apiBase: "https://api.deepseek.com/v1",
+			model:   "deepseek-reasoner",
+			options: map[string]any{"reasoning_effort": "medium"},
+			want:    map[string]any{},
+		},
+		{
+			name:    "not configured",
+			apiBase: "https://api.openai.com/v1",
+			model:   "gpt-4o",
+			options: map[string]any{"max_tokens": 100},
+			want:    map[string]any{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := map[string]any{}
+			applyReasoningOptions(got, tt.apiBase, tt.model, tt.options)
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(tt.want)
+			if string(gotJSON) != string(wantJSON) {
+				t.Errorf("applyReasoningOptions() = %s, want %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
+
+func TestApplyResponseFormat(t *testing.T) {
+	schema := map[string]any{"type": "object"}
+	options := map[string]any{"response_format": &protocoltypes.ResponseFormat{Name: "contact", Schema: schema}}
+
+	got := map[string]any{}
+	applyResponseFormat(got, "https://api.openai.com/v1", options)
+	gotJSON, _ := json.Marshal(got)
+	want := `{"response_format":{"json_schema":{"name":"contact","schema":{"type":"object"}},"type":"json_schema"}}`
+	if string(gotJSON) != want {
+		t.Errorf("applyResponseFormat() = %s, want %s", gotJSON, want)
+	}
+
+	got = map[string]any{}
+	applyResponseFormat(got, "https://api.deepseek.com/v1", options)
+	gotJSON, _ = json.Marshal(got)
+	if string(gotJSON) != `{"response_format":{"type":"json_object"}}` {
+		t.Errorf("deepseek applyResponseFormat() = %s", gotJSON)
+	}
+
+	got = map[string]any{}
+	applyResponseFormat(got, "https://api.openai.com/v1", nil)
+	if len(got) != 0 {
+		t.Errorf("expected no response_format, got %v", got)
+	}
+}
@@ -0,0 +1,41 @@
+package protocoltypes
+
+import "strings"
+
+// Thinking budgets used when only a reasoning effort is configured and the
+// provider takes a token budget (Anthropic, Gemini 2.5, Qwen).
+var effortBudgets = map[string]int{
+	"low":    1024,
+	"medium": 4096,
+	"high":   16384,
+}
+
+// ReasoningFromOptions reads the "reasoning_effort" and "thinking_budget" chat
+// options and fills in whichever is missing from the other, so each provider
+// can use the control it natively understands. Both are zero when reasoning
+// is not configured.
+func ReasoningFromOptions(options map[string]any) (effort string, budget int) {
+	effort, _ = options["reasoning_effort"].(string)
+	effort = strings.ToLower(strings.TrimSpace(effort))
+	switch v := options["thinking_budget"].(type) {
+	case int:
+		budget = v
+	case float64:
+		budget = int(v)
+	}
+
+	switch {
+	case budget <= 0 && effort != "":
+		budget = effortBudgets[effort]
+	case budget > 0 && effort == "":
+		switch {
+		case budget <= effortBudgets["low"]*2:
+			effort = "low"
+		case budget <= effortBudgets["medium"]*2:
+			effort = "medium"
+		default:
+			effort = "high"
+		}
+	}
+	return effort, budget
+}
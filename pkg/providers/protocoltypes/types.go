@@ -25,11 +25,22 @@ type FunctionCall struct {
 }
 
 type LLMResponse struct {
-	Content          string     `json:"content"`
-	ReasoningContent string     `json:"reasoning_content,omitempty"`
-	ToolCalls        []ToolCall `json:"tool_calls,omitempty"`
-	FinishReason     string     `json:"finish_reason"`
-	Usage            *UsageInfo `json:"usage,omitempty"`
+	Content          string `json:"content"`
+	ReasoningContent string `json:"reasoning_content,omitempty"`
+	// ThinkingBlocks are the signed blocks ReasoningContent was joined from,
+	// for providers that require thinking to be replayed verbatim during
+	// tool-use turns (Anthropic).
+	ThinkingBlocks []ThinkingBlock `json:"thinking_blocks,omitempty"`
+	ToolCalls      []ToolCall      `json:"tool_calls,omitempty"`
+	FinishReason   string          `json:"finish_reason"`
+	Usage          *UsageInfo      `json:"usage,omitempty"`
+}
+
+// ThinkingBlock is one block of extended thinking with the signature that
+// authenticates it.
+type ThinkingBlock struct {
+	Thinking  string `json:"thinking"`
+	Signature string `json:"signature"`
 }
 
 type UsageInfo struct {
@@ -54,12 +65,14 @@ type ContentBlock struct {
 }
 
 type Message struct {
-	Role             string         `json:"role"`
-	Content          string         `json:"content"`
-	ReasoningContent string         `json:"reasoning_content,omitempty"`
-	SystemParts      []ContentBlock `json:"system_parts,omitempty"` // structured system blocks for cache-aware adapters
-	ToolCalls        []ToolCall     `json:"tool_calls,omitempty"`
-	ToolCallID       string         `json:"tool_call_id,omitempty"`
+	Role             string          `json:"role"`
+	Content          string          `json:"content"`
+	ReasoningContent string          `json:"reasoning_content,omitempty"`
+	ThinkingBlocks   []ThinkingBlock `json:"thinking_blocks,omitempty"`
+	SystemParts      []ContentBlock  `json:"system_parts,omitempty"` // structured system blocks for cache-aware adapters
+	Media            []string        `json:"media,omitempty"`        // local file paths of attached media (images) for vision-capable adapters
+	ToolCalls        []ToolCall      `json:"tool_calls,omitempty"`
+	ToolCallID       string          `json:"tool_call_id,omitempty"`
 }
 
 type ToolDefinition struct {
@@ -72,3 +85,26 @@ type ToolFunctionDefinition struct {
 	Description string         `json:"description"`
 	Parameters  map[string]any `json:"parameters"`
 }
+
+// ResponseFormat asks the model for a JSON reply conforming to Schema.
+// It is passed to Chat as options["response_format"]; providers map it to
+// their native structured-output feature and ignore it otherwise.
+type ResponseFormat struct {
+	Name   string         `json:"name"` // identifier, [a-zA-Z0-9_-]
+	Schema map[string]any `json:"schema"`
+}
+
+// ResponseFormatFromOptions returns the requested response format, if any.
+func ResponseFormatFromOptions(options map[string]any) *ResponseFormat {
+	switch rf := options["response_format"].(type) {
+	case *ResponseFormat:
+		if rf != nil && rf.Schema != nil {
+			return rf
+		}
+	case ResponseFormat:
+		if rf.Schema != nil {
+			return &rf
+		}
+	}
+	return nil
+}
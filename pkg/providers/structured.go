@@ -0,0 +1,178 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// DefaultStructuredAttempts is how many times ChatStructured asks the model
+// before giving up on a schema-conforming reply.
+const DefaultStructuredAttempts = 3
+
+// ChatStructured asks the model for a JSON reply conforming to format.Schema
+// and returns the validated JSON.
+//
+// The format is passed to the provider as options["response_format"], which
+// providers with native support map to their structured-output feature
+// (OpenAI response_format, Claude tool forcing, Gemini responseSchema, Ollama
+// format). The schema is also stated in the prompt so that providers without
+// native support still know what to produce. Every reply is validated
+// locally; an invalid reply is sent back to the model with the validation
+// error, up to maxAttempts times in total.
+//
+// Claude tool inputs and OpenAI json_schema formats must be objects, so a
+// schema for an array or scalar is requested as {"result": <value>} and the
+// value is unwrapped before it is returned.
+func ChatStructured(
+	ctx context.Context,
+	provider LLMProvider,
+	messages []Message,
+	model string,
+	options map[string]any,
+	format ResponseFormat,
+	maxAttempts int,
+) (json.RawMessage, error) {
+	if format.Schema == nil {
+		return nil, fmt.Errorf("structured output: schema is required")
+	}
+	wrapped := !isObjectSchema(format.Schema)
+	if wrapped {
+		format.Schema = wrapSchema(format.Schema)
+	}
+	resolved, err := resolveSchema(format.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("structured output: invalid schema: %w", err)
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultStructuredAttempts
+	}
+
+	schemaJSON, _ := json.Marshal(format.Schema)
+	instruction := "Respond only with a JSON value that conforms to this JSON schema, " +
+		"without any other text:\n" + string(schemaJSON)
+
+	opts := make(map[string]any, len(options)+1)
+	for k, v := range options {
+		opts[k] = v
+	}
+	opts["response_format"] = &format
+
+	history := make([]Message, 0, len(messages)+1+2*maxAttempts)
+	history = append(history, messages...)
+	history = append(history, Message{Role: "user", Content: instruction})
+
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		resp, err := provider.Chat(ctx, history, nil, model, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := validateStructured(resp.Content, resolved)
+		if err == nil {
+			if wrapped {
+				var reply struct {
+					Result json.RawMessage `json:"result"`
+				}
+				if err := json.Unmarshal(data, &reply); err != nil {
+					return nil, fmt.Errorf("structured output: %w", err)
+				}
+				return reply.Result, nil
+			}
+			return data, nil
+		}
+		lastErr = err
+
+		history = append(history,
+			Message{Role: "assistant", Content: resp.Content},
+			Message{
+				Role: "user",
+				Content: fmt.Sprintf("That reply is not valid: %v\n"+
+					"Respond again with only the corrected JSON.", err),
+			},
+		)
+	}
+
+	return nil, fmt.Errorf("structured output: no valid reply after %d attempts: %w", maxAttempts, lastErr)
+}
+
+// isObjectSchema reports whether schema only accepts JSON objects.
+func isObjectSchema(schema map[string]any) bool {
+	if t, ok := schema["type"]; ok {
+		return t == "object"
+	}
+	_, hasProps := schema["properties"]
+	return hasProps
+}
+
+// wrapSchema turns schema into the schema of an object holding the value
+// under "result". Definitions move to the top so references still resolve.
+func wrapSchema(schema map[string]any) map[string]any {
+	inner := make(map[string]any, len(schema))
+	wrapper := map[string]any{
+		"type":                 "object",
+		"required":             []any{"result"},
+		"additionalProperties": false,
+	}
+	for k, v := range schema {
+		switch k {
+		case "$defs", "definitions", "$schema":
+			wrapper[k] = v
+		default:
+			inner[k] = v
+		}
+	}
+	wrapper["properties"] = map[string]any{"result": inner}
+	return wrapper
+}
+
+func resolveSchema(schema map[string]any) (*jsonschema.Resolved, error) {
+	raw, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	var s jsonschema.Schema
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, err
+	}
+	return s.Resolve(nil)
+}
+
+func validateStructured(content string, resolved *jsonschema.Resolved) (json.RawMessage, error) {
+	raw := extractJSON(content)
+	if raw == nil {
+		return nil, fmt.Errorf("reply does not contain JSON")
+	}
+	var instance any
+	if err := json.Unmarshal(raw, &instance); err != nil {
+		return nil, err
+	}
+	if err := resolved.Validate(instance); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// extractJSON returns the first JSON object or array in text, tolerating
+// Markdown code fences and surrounding prose.
+func extractJSON(text string) json.RawMessage {
+	text = strings.TrimSpace(text)
+	if json.Valid([]byte(text)) {
+		return json.RawMessage(text)
+	}
+
+	for i := 0; i < len(text); i++ {
+		if text[i] != '{' && text[i] != '[' {
+			continue
+		}
+		var raw json.RawMessage
+		if err := json.NewDecoder(strings.NewReader(text[i:])).Decode(&raw); err == nil {
+			return raw
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package providers
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// scriptedProvider replies with the given contents in order and records
+// the requests it received.
+type scriptedProvider struct {
+	replies  []string
+	requests [][]Message
+	options  []map[string]any
+}
+
+func (p *scriptedProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	p.requests = append(p.requests, messages)
+	p.options = append(p.options, options)
+	reply := p.replies[0]
+	if len(p.replies) > 1 {
+		p.replies = p.replies[1:]
+	}
+	return &LLMResponse{Content: reply, FinishReason: "stop"}, nil
+}
+
+func (p *scriptedProvider) GetDefaultModel() string { return "scripted" }
+
+var contactSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"name": map[string]any{"type": "string"},
+		"age":  map[string]any{"type": "integer"},
+	},
+	"required": []any{"name", "age"},
+}
+
+func TestChatStructured_ValidFirstReply(t *testing.T) {
+	p := &scriptedProvider{replies: []string{"Here you go:\n```json\n{\"name\": \"Ada\", \"age\": 36}\n```"}}
+
+	got, err := ChatStructured(context.Background(), p, []Message{{Role: "user", Content: "Ada, 36"}},
+		"m", map[string]any{"temperature": 0.0}, ResponseFormat{Name: "contact", Schema: contactSchema}, 3)
+	if err != nil {
+		t.Fatalf("ChatStructured() error: %v", err)
+	}
+	if string(got) != `{"name": "Ada", "age": 36}` {
+		t.Errorf("result = %s", got)
+	}
+	if len(p.requests) != 1 {
+		t.Errorf("expected 1 request, got %d", len(p.requests))
+	}
+	rf, ok := p.options[0]["response_format"].(*ResponseFormat)
+	if !ok || rf.Name != "contact" {
+		t.Errorf("response_format option = %v", p.options[0]["response_format"])
+	}
+	if p.options[0]["temperature"] != 0.0 {
+		t.Error("caller options should be passed through")
+	}
+	last := p.requests[0][len(p.requests[0])-1]
+	if !strings.Contains(last.Content, `"required":["name","age"]`) {
+		t.Errorf("schema not stated in prompt: %q", last.Content)
+	}
+}
+
+func TestChatStructured_RetriesInvalidReply(t *testing.T) {
+	p := &scriptedProvider{replies: []string{`{"name": "Ada"}`, `{"name": "Ada", "age": 36}`}}
+
+	got, err := ChatStructured(context.Background(), p, []Message{{Role: "user", Content: "Ada, 36"}},
+		"m", nil, ResponseFormat{Schema: contactSchema}, 3)
+	if err != nil {
+		t.Fatalf("ChatStructured() error: %v", err)
+	}
+	if string(got) != `{"name": "Ada", "age": 36}` {
+		t.Errorf("result = %s", got)
+	}
+	if len(p.requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(p.requests))
+	}
+	retry := p.requests[1]
+	if retry[len(retry)-2].Role != "assistant" || !strings.Contains(retry[len(retry)-1].Content, "age") {
+		t.Errorf("retry should echo the reply and the validation error, got %+v", retry[len(retry)-2:])
+	}
+}
+
+func TestChatStructured_GivesUp(t *testing.T) {
+	p := &scriptedProvider{replies: []string{"I can't do that."}}
+
+	_, err := ChatStructured(context.Background(), p, []Message{{Role: "user", Content: "x"}},
+		"m", nil, ResponseFormat{Schema: contactSchema}, 2)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if len(p.requests) != 2 {
+		t.Errorf("expected 2 attempts, got %d", len(p.requests))
+	}
+}
+
+func TestChatStructured_WrapsNonObjectSchema(t *testing.T) {
+	p := &scriptedProvider{replies: []string{`{"result": ["a", "b"]}`}}
+	schema := map[string]any{"type": "array", "items": map[string]any{"type": "string"}}
+
+	got, err := ChatStructured(context.Background(), p, []Message{{Role: "user", Content: "list"}},
+		"m", nil, ResponseFormat{Name: "tags", Schema: schema}, 1)
+	if err != nil {
+		t.Fatalf("ChatStructured() error: %v", err)
+	}
+	if string(got) != `["a", "b"]` {
+		t.Errorf("result = %s, want the unwrapped array", got)
+	}
+	rf := p.options[0]["response_format"].(*ResponseFormat)
+	if rf.Schema["type"] != "object" {
+		t.Errorf("schema sent to the provider = %v, want an object", rf.Schema)
+	}
+	if schema["type"] != "array" {
+		t.Error("the caller's schema was modified")
+	}
+}
+
+func TestExtractJSON(t *testing.T) {
+	tests := map[string]string{
+		`{"a": 1}`:                           `{"a": 1}`,
+		"```json\n[1, 2]\n```":               `[1, 2]`,
+		`Sure! {"a": "}"} hope that helps {`: `{"a": "}"}`,
+	}
+	for in, want := range tests {
+		if got := extractJSON(in); string(got) != want {
+			t.Errorf("extractJSON(%q) = %s, want %s", in, got, want)
+		}
+	}
+	if got := extractJSON("no json here"); got != nil {
+		t.Errorf("extractJSON() = %s, want nil", got)
+	}
+}
@@ -19,6 +19,8 @@ type (
 	GoogleExtra            = protocoltypes.GoogleExtra
 	ContentBlock           = protocoltypes.ContentBlock
 	CacheControl           = protocoltypes.CacheControl
+	ResponseFormat         = protocoltypes.ResponseFormat
+	ThinkingBlock          = protocoltypes.ThinkingBlock
 )
 
 type LLMProvider interface {
@@ -37,6 +39,12 @@ type StatefulProvider interface {
 	Close()
 }
 
+// ContextWindowProvider is implemented by providers that can report a model's
+// context length at runtime (e.g. Ollama via /api/show).
+type ContextWindowProvider interface {
+	ContextWindow(ctx context.Context, model string) (int, error)
+}
+
 // FailoverReason classifies why an LLM request failed for fallback decisions.
 type FailoverReason string
 
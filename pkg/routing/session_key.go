@@ -144,6 +144,12 @@ func normalizeChannel(channel string) string {
 	return c
 }
 
+// LinkedIdentity returns the identity_links name that peerID on channel
+// belongs to, or "" if it is not linked.
+func LinkedIdentity(identityLinks map[string][]string, channel, peerID string) string {
+	return resolveLinkedPeerID(identityLinks, channel, peerID)
+}
+
 func resolveLinkedPeerID(identityLinks map[string][]string, channel, peerID string) string {
 	if len(identityLinks) == 0 {
 		return ""
@@ -0,0 +1,216 @@
+package session
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// A chat's routed session key is its base session. Forks and archived
+// conversations live next to it as "<base>#<name>" branches, and the base
+// session records which of them is active.
+const branchSeparator = "#"
+
+// MainBranch is the display name of the base session.
+const MainBranch = "main"
+
+var branchNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// BranchKey returns the key of the named branch of base. The main branch
+// is base itself.
+func BranchKey(base, name string) string {
+	if name == "" || name == MainBranch {
+		return base
+	}
+	return base + branchSeparator + name
+}
+
+// BaseKey returns the base session of a branch key.
+func BaseKey(key string) string {
+	base, _, _ := strings.Cut(key, branchSeparator)
+	return base
+}
+
+// BranchName returns the branch name of key, MainBranch for a base session.
+func BranchName(key string) string {
+	if _, name, ok := strings.Cut(key, branchSeparator); ok {
+		return name
+	}
+	return MainBranch
+}
+
+// ValidateBranchName checks a user-supplied branch name.
+func ValidateBranchName(name string) error {
+	if !branchNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid session name %q: use up to 64 letters, digits, '.', '_' or '-'", name)
+	}
+	return nil
+}
+
+// BranchInfo describes one session of a chat.
+type BranchInfo struct {
+	Key      string
+	Name     string
+	Messages int
+	Updated  time.Time
+	Active   bool
+}
+
+// Active returns the key of the branch currently in use for base.
+func (sm *SessionManager) Active(base string) string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if session, ok := sm.sessions[base]; ok && session.Active != "" {
+		return session.Active
+	}
+	return base
+}
+
+// SetActive makes key the branch in use for its base session.
+func (sm *SessionManager) SetActive(key string) {
+	base := BaseKey(key)
+	active := key
+	if active == base {
+		active = ""
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	session, ok := sm.sessions[base]
+	if !ok {
+		session = &Session{
+			Key:      base,
+			Messages: []providers.Message{},
+			Created:  time.Now(),
+			Updated:  time.Now(),
+		}
+		sm.sessions[base] = session
+	}
+	session.Active = active
+}
+
+// Branches lists the sessions of base, the main session first and the
+// rest by name.
+func (sm *SessionManager) Branches(base string) []BranchInfo {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	active := base
+	if session, ok := sm.sessions[base]; ok && session.Active != "" {
+		active = session.Active
+	}
+
+	var branches []BranchInfo
+	for key, session := range sm.sessions {
+		if key != base && !strings.HasPrefix(key, base+branchSeparator) {
+			continue
+		}
+		branches = append(branches, BranchInfo{
+			Key:      key,
+			Name:     BranchName(key),
+			Messages: len(session.Messages),
+			Updated:  session.Updated,
+			Active:   key == active,
+		})
+	}
+	sort.Slice(branches, func(i, j int) bool {
+		if (branches[i].Key == base) != (branches[j].Key == base) {
+			return branches[i].Key == base
+		}
+		return branches[i].Name < branches[j].Name
+	})
+	return branches
+}
+
+// Exists reports whether a session with key has been created.
+func (sm *SessionManager) Exists(key string) bool {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	_, ok := sm.sessions[key]
+	return ok
+}
+
+// Fork copies the history and summary of src into a new session dst.
+func (sm *SessionManager) Fork(src, dst string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if _, exists := sm.sessions[dst]; exists {
+		return fmt.Errorf("session %q already exists", BranchName(dst))
+	}
+
+	fork := &Session{
+		Key:      dst,
+		Messages: []providers.Message{},
+		Created:  time.Now(),
+		Updated:  time.Now(),
+	}
+	if source, ok := sm.sessions[src]; ok {
+		fork.Messages = append(fork.Messages, source.Messages...)
+		fork.Summary = source.Summary
+	}
+	sm.sessions[dst] = fork
+	return nil
+}
+
+// Archive moves the history of key into a new timestamped branch and
+// leaves key empty. It returns the archive's key, or "" when there was
+// nothing to archive.
+func (sm *SessionManager) Archive(key string) string {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	session, ok := sm.sessions[key]
+	if !ok || (len(session.Messages) == 0 && session.Summary == "") {
+		return ""
+	}
+
+	name := "archive-" + time.Now().Format("20060102-150405")
+	archiveKey := BranchKey(BaseKey(key), name)
+	for i := 2; sm.sessions[archiveKey] != nil; i++ {
+		archiveKey = BranchKey(BaseKey(key), fmt.Sprintf("%s-%d", name, i))
+	}
+
+	sm.sessions[archiveKey] = &Session{
+		Key:      archiveKey,
+		Messages: session.Messages,
+		Summary:  session.Summary,
+		Created:  session.Created,
+		Updated:  session.Updated,
+	}
+	session.Messages = []providers.Message{}
+	session.Summary = ""
+	session.Created = time.Now()
+	session.Updated = time.Now()
+	return archiveKey
+}
+
+// PopTurn removes the last exchange from a session: the last user message
+// and every assistant and tool message after it. Cutting at a user message
+// keeps tool calls paired with their results. It returns the removed user
+// message, and false if there was no exchange to remove.
+func (sm *SessionManager) PopTurn(key string) (providers.Message, bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	session, ok := sm.sessions[key]
+	if !ok {
+		return providers.Message{}, false
+	}
+	for i := len(session.Messages) - 1; i >= 0; i-- {
+		if session.Messages[i].Role == "user" {
+			user := session.Messages[i]
+			session.Messages = session.Messages[:i:i]
+			session.Updated = time.Now()
+			return user, true
+		}
+	}
+	return providers.Message{}, false
+}
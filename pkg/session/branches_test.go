@@ -0,0 +1,148 @@
+package session
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func addTurn(sm *SessionManager, key, user string, extra ...providers.Message) {
+	sm.AddMessage(key, "user", user)
+	for _, m := range extra {
+		sm.AddFullMessage(key, m)
+	}
+	sm.AddMessage(key, "assistant", "re: "+user)
+}
+
+func TestBranchKeys(t *testing.T) {
+	base := "agent:main:telegram:direct:42"
+	key := BranchKey(base, "idea")
+	if key != base+"#idea" || BaseKey(key) != base || BranchName(key) != "idea" {
+		t.Errorf("BranchKey/BaseKey/BranchName round trip failed for %q", key)
+	}
+	if BranchKey(base, MainBranch) != base || BranchName(base) != MainBranch {
+		t.Error("the main branch is the base key")
+	}
+	for _, name := range []string{"", "../x", "a/b", "has space", "#x"} {
+		if ValidateBranchName(name) == nil {
+			t.Errorf("ValidateBranchName(%q) should fail", name)
+		}
+	}
+	if err := ValidateBranchName("plan-b.2"); err != nil {
+		t.Errorf("ValidateBranchName: %v", err)
+	}
+}
+
+func TestPopTurn(t *testing.T) {
+	sm := NewSessionManager("")
+	key := "s"
+	addTurn(sm, key, "first")
+	addTurn(sm, key, "second",
+		providers.Message{Role: "assistant", ToolCalls: []providers.ToolCall{{ID: "1", Name: "exec"}}},
+		providers.Message{Role: "tool", ToolCallID: "1", Content: "ok"},
+	)
+
+	user, ok := sm.PopTurn(key)
+	if !ok || user.Content != "second" {
+		t.Fatalf("PopTurn = %q, %v; want second", user.Content, ok)
+	}
+	history := sm.GetHistory(key)
+	if len(history) != 2 || history[1].Content != "re: first" {
+		t.Errorf("history after pop = %+v", history)
+	}
+
+	sm.PopTurn(key)
+	if _, ok := sm.PopTurn(key); ok {
+		t.Error("PopTurn on an empty session should report false")
+	}
+}
+
+func TestForkArchiveAndSwitch(t *testing.T) {
+	dir := t.TempDir()
+	sm := NewSessionManager(dir)
+	base := "agent:main:main"
+	addTurn(sm, base, "hello")
+	sm.SetSummary(base, "greeted")
+
+	fork := BranchKey(base, "idea")
+	if err := sm.Fork(base, fork); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.Fork(base, fork); err == nil {
+		t.Error("forking onto an existing session should fail")
+	}
+	addTurn(sm, fork, "only in fork")
+	if len(sm.GetHistory(base)) != 2 || len(sm.GetHistory(fork)) != 4 || sm.GetSummary(fork) != "greeted" {
+		t.Error("a fork should copy history and summary without sharing it")
+	}
+
+	sm.SetActive(fork)
+	if sm.Active(base) != fork {
+		t.Errorf("Active = %q, want %q", sm.Active(base), fork)
+	}
+
+	archived := sm.Archive(fork)
+	if archived == "" || BaseKey(archived) != base || len(sm.GetHistory(archived)) != 4 {
+		t.Fatalf("Archive = %q", archived)
+	}
+	if len(sm.GetHistory(fork)) != 0 || sm.GetSummary(fork) != "" {
+		t.Error("the archived session should be empty")
+	}
+	if sm.Archive(fork) != "" {
+		t.Error("archiving an empty session should be a no-op")
+	}
+
+	for _, key := range []string{base, fork, archived} {
+		if err := sm.Save(key); err != nil {
+			t.Fatalf("Save(%q): %v", key, err)
+		}
+	}
+
+	reloaded := NewSessionManager(dir)
+	if reloaded.Active(base) != fork {
+		t.Error("the active branch should survive a reload")
+	}
+	var names []string
+	for _, b := range reloaded.Branches(base) {
+		names = append(names, b.Name)
+		if b.Active != (b.Key == fork) {
+			t.Errorf("%s: Active = %v", b.Name, b.Active)
+		}
+	}
+	if want := []string{MainBranch, BranchName(archived), "idea"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Branches = %v, want %v", names, want)
+	}
+
+	reloaded.SetActive(base)
+	if reloaded.Active(base) != base {
+		t.Error("switching back to the main branch failed")
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	dir := t.TempDir()
+	sm := NewSessionManager(dir)
+	key := "agent:main:main"
+
+	temp := 0.2
+	sm.SetOverrides(key, Overrides{Model: "fast", Temperature: &temp})
+	if err := sm.Save(key); err != nil {
+		t.Fatal(err)
+	}
+
+	got := NewSessionManager(dir).GetOverrides(key)
+	if got.Model != "fast" || got.Temperature == nil || *got.Temperature != 0.2 {
+		t.Errorf("reloaded overrides = %+v", got)
+	}
+
+	merged := Overrides{Persona: "p.md"}.Merge(got)
+	if merged.Model != "fast" || merged.Persona != "p.md" || *merged.Temperature != 0.2 {
+		t.Errorf("Merge = %+v", merged)
+	}
+
+	sm.SetOverrides(key, Overrides{})
+	if !sm.GetOverrides(key).IsZero() {
+		t.Error("clearing overrides failed")
+	}
+}
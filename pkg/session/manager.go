@@ -17,6 +17,12 @@ type Session struct {
 	Summary  string              `json:"summary,omitempty"`
 	Created  time.Time           `json:"created"`
 	Updated  time.Time           `json:"updated"`
+
+	// Active is the branch of this chat currently in use (see SetActive);
+	// empty means this session itself.
+	Active string `json:"active,omitempty"`
+	// Overrides are the chat's settings (see SetOverrides).
+	Overrides *Overrides `json:"overrides,omitempty"`
 }
 
 type SessionManager struct {
@@ -180,9 +186,14 @@ func (sm *SessionManager) Save(key string) error {
 	snapshot := Session{
 		Key:     stored.Key,
 		Summary: stored.Summary,
+		Active:  stored.Active,
 		Created: stored.Created,
 		Updated: stored.Updated,
 	}
+	if stored.Overrides != nil {
+		overrides := *stored.Overrides
+		snapshot.Overrides = &overrides
+	}
 	if len(stored.Messages) > 0 {
 		snapshot.Messages = make([]providers.Message, len(stored.Messages))
 		copy(snapshot.Messages, stored.Messages)
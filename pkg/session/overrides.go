@@ -0,0 +1,70 @@
+package session
+
+import (
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// Overrides are per-chat settings that take precedence over the agent's
+// configuration. Empty fields keep the configured value.
+type Overrides struct {
+	Model       string   `json:"model,omitempty"`       // model_list model_name
+	Agent       string   `json:"agent,omitempty"`       // agent ID the chat is bound to
+	Temperature *float64 `json:"temperature,omitempty"` // sampling temperature
+	Persona     string   `json:"persona,omitempty"`     // persona file, relative to the agent workspace
+}
+
+// IsZero reports whether no setting is overridden.
+func (o Overrides) IsZero() bool {
+	return o.Model == "" && o.Agent == "" && o.Temperature == nil && o.Persona == ""
+}
+
+// Merge returns o with the empty fields taken from defaults.
+func (o Overrides) Merge(defaults Overrides) Overrides {
+	if o.Model == "" {
+		o.Model = defaults.Model
+	}
+	if o.Agent == "" {
+		o.Agent = defaults.Agent
+	}
+	if o.Temperature == nil {
+		o.Temperature = defaults.Temperature
+	}
+	if o.Persona == "" {
+		o.Persona = defaults.Persona
+	}
+	return o
+}
+
+// GetOverrides returns the settings stored with a session.
+func (sm *SessionManager) GetOverrides(key string) Overrides {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if session, ok := sm.sessions[key]; ok && session.Overrides != nil {
+		return *session.Overrides
+	}
+	return Overrides{}
+}
+
+// SetOverrides stores settings with a session, creating it if needed.
+func (sm *SessionManager) SetOverrides(key string, o Overrides) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	session, ok := sm.sessions[key]
+	if !ok {
+		session = &Session{
+			Key:      key,
+			Messages: []providers.Message{},
+			Created:  time.Now(),
+		}
+		sm.sessions[key] = session
+	}
+	session.Overrides = nil
+	if !o.IsZero() {
+		session.Overrides = &o
+	}
+	session.Updated = time.Now()
+}
@@ -29,6 +29,13 @@ type ClawHubRegistry struct {
 	maxZipSize      int
 	maxResponseSize int
 	client          *http.Client
+	trust           *TrustStore // optional signature verification
+}
+
+// SetTrustStore enables signature checks on downloaded skills, using the
+// store's policy for this registry.
+func (c *ClawHubRegistry) SetTrustStore(ts *TrustStore) {
+	c.trust = ts
 }
 
 // NewClawHubRegistry creates a new ClawHub registry client from config.
@@ -258,6 +265,7 @@ func (c *ClawHubRegistry) DownloadAndInstall(
 		q.Set("version", installVersion)
 	}
 	u.RawQuery = q.Encode()
+	result.ResolvedURL = u.String()
 
 	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
@@ -278,6 +286,15 @@ func (c *ClawHubRegistry) DownloadAndInstall(
 		return nil, err
 	}
 
+	// Step 5: Verify the publisher signature, per the trust policy.
+	if c.trust != nil {
+		trust, err := c.trust.Check(c.Name(), targetDir)
+		if err != nil {
+			return nil, err
+		}
+		result.Trust = trust
+	}
+
 	return result, nil
 }
 
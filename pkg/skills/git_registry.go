@@ -0,0 +1,391 @@
+package skills
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const defaultGitRefreshInterval = 5 * time.Minute
+
+// GitRegistryConfig configures a registry backed by a Git repository.
+type GitRegistryConfig struct {
+	Name            string
+	URL             string // anything git can clone: https://, ssh://, git@host:repo or a local path
+	Ref             string // branch or tag to index, "" = the remote's default branch
+	CacheDir        string // local mirror, "" = <user cache dir>/picoclaw/skill-registries/<name>
+	RefreshInterval int    // seconds between fetches, 0 = default (5m)
+}
+
+// GitRegistry serves skills from a Git repository. Every directory that
+// contains a SKILL.md is a skill, with the directory name as its slug (or
+// the registry name for a SKILL.md at the repository root). Tags and
+// branches are versions. With a configured ref, the latest version is that
+// ref: a tag at its commit, or the commit itself. Otherwise it is the highest
+// tag on the default branch, or its commit when there are no tags.
+//
+// The repository is mirrored locally with the git command, so private
+// repositories work with the user's usual SSH keys and credential helpers.
+type GitRegistry struct {
+	name     string
+	url      string
+	ref      string
+	cacheDir string
+	refresh  time.Duration
+	trust    *TrustStore
+
+	mu        sync.Mutex
+	lastFetch time.Time
+}
+
+// gitSkill is one skill found in the repository.
+type gitSkill struct {
+	Slug        string
+	Dir         string // slash-separated path in the repository, "" for the root
+	Name        string
+	Description string
+}
+
+// NewGitRegistry creates a Git registry from config. The repository is not
+// contacted until the first search or install.
+func NewGitRegistry(cfg GitRegistryConfig) (*GitRegistry, error) {
+	if err := utils.ValidateSkillIdentifier(cfg.Name); err != nil {
+		return nil, fmt.Errorf("invalid registry name %q: %w", cfg.Name, err)
+	}
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("git registry %q: url is required", cfg.Name)
+	}
+
+	cacheDir := cfg.CacheDir
+	if cacheDir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("git registry %q: %w", cfg.Name, err)
+		}
+		cacheDir = filepath.Join(base, "picoclaw", "skill-registries", cfg.Name)
+	}
+
+	refresh := defaultGitRefreshInterval
+	if cfg.RefreshInterval > 0 {
+		refresh = time.Duration(cfg.RefreshInterval) * time.Second
+	}
+
+	return &GitRegistry{
+		name:     cfg.Name,
+		url:      cfg.URL,
+		ref:      cfg.Ref,
+		cacheDir: cacheDir,
+		refresh:  refresh,
+	}, nil
+}
+
+func (g *GitRegistry) Name() string {
+	return g.name
+}
+
+// SetTrustStore enables signature checks on installed skills, using the
+// store's policy for this registry.
+func (g *GitRegistry) SetTrustStore(ts *TrustStore) {
+	g.trust = ts
+}
+
+func (g *GitRegistry) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	if err := g.update(ctx); err != nil {
+		return nil, err
+	}
+	commit, err := g.resolve(ctx, g.defaultRef())
+	if err != nil {
+		return nil, err
+	}
+	found, err := g.index(ctx, commit)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := g.latestVersion(ctx, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []SearchResult
+	for _, s := range found {
+		score := matchScore(query, s.Slug+" "+s.Name, s.Description)
+		if score == 0 {
+			continue
+		}
+		results = append(results, SearchResult{
+			Score:        score,
+			Slug:         s.Slug,
+			DisplayName:  s.Name,
+			Summary:      s.Description,
+			Version:      latest,
+			RegistryName: g.name,
+		})
+	}
+	sortByScoreDesc(results)
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func (g *GitRegistry) GetSkillMeta(ctx context.Context, slug string) (*SkillMeta, error) {
+	if err := utils.ValidateSkillIdentifier(slug); err != nil {
+		return nil, fmt.Errorf("invalid slug %q: error: %s", slug, err.Error())
+	}
+	if err := g.update(ctx); err != nil {
+		return nil, err
+	}
+	commit, err := g.resolve(ctx, g.defaultRef())
+	if err != nil {
+		return nil, err
+	}
+	skill, err := g.find(ctx, commit, slug)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := g.latestVersion(ctx, commit)
+	if err != nil {
+		return nil, err
+	}
+	return &SkillMeta{
+		Slug:          skill.Slug,
+		DisplayName:   skill.Name,
+		Summary:       skill.Description,
+		LatestVersion: latest,
+		RegistryName:  g.name,
+	}, nil
+}
+
+// DownloadAndInstall installs the skill at version, which may be a tag, a
+// branch or a commit. An empty version or "latest" installs the latest
+// version.
+func (g *GitRegistry) DownloadAndInstall(
+	ctx context.Context,
+	slug, version, targetDir string,
+) (*InstallResult, error) {
+	if err := utils.ValidateSkillIdentifier(slug); err != nil {
+		return nil, fmt.Errorf("invalid slug %q: error: %s", slug, err.Error())
+	}
+	if err := g.update(ctx); err != nil {
+		return nil, err
+	}
+
+	if version == "" || version == "latest" {
+		commit, err := g.resolve(ctx, g.defaultRef())
+		if err != nil {
+			return nil, err
+		}
+		if version, err = g.latestVersion(ctx, commit); err != nil {
+			return nil, err
+		}
+	}
+	commit, err := g.resolve(ctx, version)
+	if err != nil {
+		return nil, fmt.Errorf("version %q not found in %s: %w", version, g.name, err)
+	}
+	skill, err := g.find(ctx, commit, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	// Export the skill's subtree as a zip and reuse the safe extractor.
+	tree := commit
+	if skill.Dir != "" {
+		tree += ":" + skill.Dir
+	}
+	tmp, err := os.CreateTemp("", "picoclaw-skill-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+	if _, err := g.git(ctx, "archive", "--format=zip", "--output="+tmp.Name(), tree); err != nil {
+		return nil, err
+	}
+	if err := utils.ExtractZipFile(tmp.Name(), targetDir); err != nil {
+		return nil, err
+	}
+
+	result := &InstallResult{
+		Version:     version,
+		ResolvedURL: redactURL(g.url) + "#" + commit,
+		Summary:     skill.Description,
+	}
+	if g.trust != nil {
+		trust, err := g.trust.Check(g.name, targetDir)
+		if err != nil {
+			return nil, err
+		}
+		result.Trust = trust
+	}
+	return result, nil
+}
+
+func (g *GitRegistry) defaultRef() string {
+	if g.ref != "" {
+		return g.ref
+	}
+	return "HEAD"
+}
+
+// update clones the mirror on first use and fetches it when it is older
+// than the refresh interval. A failed fetch falls back to the cached copy.
+func (g *GitRegistry) update(ctx context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, err := os.Stat(filepath.Join(g.cacheDir, "HEAD")); err != nil {
+		if err := os.MkdirAll(filepath.Dir(g.cacheDir), 0o755); err != nil {
+			return err
+		}
+		if _, err := runGit(ctx, "", "clone", "--mirror", "--quiet", "--", g.url, g.cacheDir); err != nil {
+			os.RemoveAll(g.cacheDir)
+			return fmt.Errorf("failed to clone %s: %w", redactURL(g.url), err)
+		}
+		g.lastFetch = time.Now()
+		return nil
+	}
+
+	if time.Since(g.lastFetch) < g.refresh {
+		return nil
+	}
+	if _, err := g.git(ctx, "fetch", "--prune", "--quiet", "origin"); err != nil {
+		slog.Warn("git registry fetch failed, using cached copy", "registry", g.name, "error", err)
+		return nil
+	}
+	g.lastFetch = time.Now()
+	return nil
+}
+
+// resolve returns the commit a tag, branch or commit refers to.
+func (g *GitRegistry) resolve(ctx context.Context, ref string) (string, error) {
+	if strings.HasPrefix(ref, "-") {
+		return "", fmt.Errorf("invalid ref %q", ref)
+	}
+	out, err := g.git(ctx, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("unknown ref %q", ref)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// latestVersion returns the latest version of the indexed commit: the
+// highest tag at commit when a ref is configured, so installs match what
+// Search indexed, and otherwise the highest tag reachable from it. Without
+// such a tag it is commit.
+func (g *GitRegistry) latestVersion(ctx context.Context, commit string) (string, error) {
+	filter := "--merged"
+	if g.ref != "" {
+		filter = "--points-at"
+	}
+	out, err := g.git(ctx, "tag", "--list", "--sort=-v:refname", filter, commit)
+	if err != nil {
+		return "", err
+	}
+	if tags := strings.Fields(string(out)); len(tags) > 0 {
+		return tags[0], nil
+	}
+	return commit, nil
+}
+
+// index lists the skills in commit, sorted by path.
+func (g *GitRegistry) index(ctx context.Context, commit string) ([]gitSkill, error) {
+	out, err := g.git(ctx, "ls-tree", "-r", "--name-only", commit)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, p := range strings.Split(string(out), "\n") {
+		if path.Base(p) == "SKILL.md" {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	var parser SkillsLoader
+	seen := make(map[string]bool)
+	skills := make([]gitSkill, 0, len(paths))
+	for _, p := range paths {
+		dir := path.Dir(p)
+		slug := path.Base(dir)
+		if dir == "." {
+			dir, slug = "", g.name
+		}
+		if seen[slug] || utils.ValidateSkillIdentifier(slug) != nil {
+			continue
+		}
+		content, err := g.git(ctx, "show", commit+":"+p)
+		if err != nil {
+			return nil, err
+		}
+		meta := parser.parseSkillMetadata(string(content), slug)
+		name := meta.Name
+		if name == "" {
+			name = slug
+		}
+		seen[slug] = true
+		skills = append(skills, gitSkill{Slug: slug, Dir: dir, Name: name, Description: meta.Description})
+	}
+	return skills, nil
+}
+
+func (g *GitRegistry) find(ctx context.Context, commit, slug string) (gitSkill, error) {
+	found, err := g.index(ctx, commit)
+	if err != nil {
+		return gitSkill{}, err
+	}
+	for _, s := range found {
+		if s.Slug == slug {
+			return s, nil
+		}
+	}
+	return gitSkill{}, fmt.Errorf("skill %q not found in %s", slug, g.name)
+}
+
+// redactURL drops credentials embedded in an https URL before it is
+// recorded in the lockfile.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil || u.Scheme == "" {
+		return raw
+	}
+	u.User = nil
+	return u.String()
+}
+
+func (g *GitRegistry) git(ctx context.Context, args ...string) ([]byte, error) {
+	return runGit(ctx, g.cacheDir, args...)
+}
+
+// runGit runs git non-interactively, in gitDir if set.
+func runGit(ctx context.Context, gitDir string, args ...string) ([]byte, error) {
+	sub := args[0]
+	if gitDir != "" {
+		args = append([]string{"--git-dir=" + gitDir}, args...)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", sub, msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", sub, err)
+	}
+	return out, nil
+}
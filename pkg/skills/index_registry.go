@@ -0,0 +1,327 @@
+package skills
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	indexFormatVersion = 1
+	indexCacheTTL      = 5 * time.Minute
+)
+
+// IndexRegistryConfig configures a registry served as a static JSON index.
+type IndexRegistryConfig struct {
+	Name            string
+	URL             string // URL of the index file
+	AuthToken       string // optional bearer token, sent only to the index host
+	Timeout         int    // seconds, 0 = default (30s)
+	MaxZipSize      int    // bytes, 0 = default (50MB)
+	MaxResponseSize int    // bytes, 0 = default (2MB)
+}
+
+// IndexRegistry serves skills from a static index file, so a registry can
+// be hosted on any web server or object store:
+//
+//	{
+//	  "version": 1,
+//	  "skills": [{
+//	    "slug": "weather",
+//	    "display_name": "Weather",
+//	    "summary": "Weather forecasts",
+//	    "latest_version": "1.1.0",
+//	    "versions": {
+//	      "1.1.0": {"url": "weather/1.1.0.zip", "sha256": "<hex>"}
+//	    }
+//	  }]
+//	}
+//
+// Version URLs are zip archives of the skill directory, resolved relative
+// to the index URL. When sha256 is set the download must match it.
+type IndexRegistry struct {
+	name            string
+	indexURL        *url.URL
+	authToken       string
+	maxZipSize      int
+	maxResponseSize int
+	client          *http.Client
+	trust           *TrustStore
+
+	mu        sync.Mutex
+	cached    *skillIndex
+	fetchedAt time.Time
+}
+
+type skillIndex struct {
+	Version int               `json:"version"`
+	Skills  []skillIndexEntry `json:"skills"`
+}
+
+type skillIndexEntry struct {
+	Slug             string                       `json:"slug"`
+	DisplayName      string                       `json:"display_name"`
+	Summary          string                       `json:"summary"`
+	LatestVersion    string                       `json:"latest_version"`
+	Versions         map[string]skillIndexVersion `json:"versions"`
+	IsMalwareBlocked bool                         `json:"is_malware_blocked,omitempty"`
+	IsSuspicious     bool                         `json:"is_suspicious,omitempty"`
+}
+
+type skillIndexVersion struct {
+	URL    string `json:"url"`
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// NewIndexRegistry creates a static-index registry from config.
+func NewIndexRegistry(cfg IndexRegistryConfig) (*IndexRegistry, error) {
+	if err := utils.ValidateSkillIdentifier(cfg.Name); err != nil {
+		return nil, fmt.Errorf("invalid registry name %q: %w", cfg.Name, err)
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("index registry %q: url must be an http(s) URL", cfg.Name)
+	}
+
+	timeout := defaultClawHubTimeout
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	maxZip := defaultMaxZipSize
+	if cfg.MaxZipSize > 0 {
+		maxZip = cfg.MaxZipSize
+	}
+	maxResp := defaultMaxResponseSize
+	if cfg.MaxResponseSize > 0 {
+		maxResp = cfg.MaxResponseSize
+	}
+
+	return &IndexRegistry{
+		name:            cfg.Name,
+		indexURL:        u,
+		authToken:       cfg.AuthToken,
+		maxZipSize:      maxZip,
+		maxResponseSize: maxResp,
+		client:          &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (r *IndexRegistry) Name() string {
+	return r.name
+}
+
+// SetTrustStore enables signature checks on downloaded skills, using the
+// store's policy for this registry.
+func (r *IndexRegistry) SetTrustStore(ts *TrustStore) {
+	r.trust = ts
+}
+
+func (r *IndexRegistry) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	index, err := r.index(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []SearchResult
+	for _, s := range index.Skills {
+		if s.IsMalwareBlocked {
+			continue
+		}
+		name := s.DisplayName
+		if name == "" {
+			name = s.Slug
+		}
+		score := matchScore(query, s.Slug+" "+name, s.Summary)
+		if score == 0 {
+			continue
+		}
+		results = append(results, SearchResult{
+			Score:        score,
+			Slug:         s.Slug,
+			DisplayName:  name,
+			Summary:      s.Summary,
+			Version:      s.LatestVersion,
+			RegistryName: r.name,
+		})
+	}
+	sortByScoreDesc(results)
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func (r *IndexRegistry) GetSkillMeta(ctx context.Context, slug string) (*SkillMeta, error) {
+	entry, err := r.find(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	return &SkillMeta{
+		Slug:             entry.Slug,
+		DisplayName:      entry.DisplayName,
+		Summary:          entry.Summary,
+		LatestVersion:    entry.LatestVersion,
+		IsMalwareBlocked: entry.IsMalwareBlocked,
+		IsSuspicious:     entry.IsSuspicious,
+		RegistryName:     r.name,
+	}, nil
+}
+
+func (r *IndexRegistry) DownloadAndInstall(
+	ctx context.Context,
+	slug, version, targetDir string,
+) (*InstallResult, error) {
+	entry, err := r.find(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &InstallResult{
+		IsMalwareBlocked: entry.IsMalwareBlocked,
+		IsSuspicious:     entry.IsSuspicious,
+		Summary:          entry.Summary,
+	}
+	if entry.IsMalwareBlocked {
+		return result, nil
+	}
+
+	if version == "" || version == "latest" {
+		version = entry.LatestVersion
+	}
+	v, ok := entry.Versions[version]
+	if !ok {
+		return nil, fmt.Errorf("version %q of %q not found in %s", version, slug, r.name)
+	}
+	result.Version = version
+
+	u, err := r.indexURL.Parse(v.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("invalid download URL %q", v.URL)
+	}
+	result.ResolvedURL = u.String()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	r.authorize(req)
+
+	tmpPath, err := utils.DownloadToFile(ctx, r.client, req, int64(r.maxZipSize))
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+	defer os.Remove(tmpPath)
+
+	if v.SHA256 != "" {
+		if err := checkSHA256(tmpPath, v.SHA256); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := utils.ExtractZipFile(tmpPath, targetDir); err != nil {
+		return nil, err
+	}
+
+	if r.trust != nil {
+		trust, err := r.trust.Check(r.name, targetDir)
+		if err != nil {
+			return nil, err
+		}
+		result.Trust = trust
+	}
+	return result, nil
+}
+
+func (r *IndexRegistry) find(ctx context.Context, slug string) (skillIndexEntry, error) {
+	if err := utils.ValidateSkillIdentifier(slug); err != nil {
+		return skillIndexEntry{}, fmt.Errorf("invalid slug %q: error: %s", slug, err.Error())
+	}
+	index, err := r.index(ctx)
+	if err != nil {
+		return skillIndexEntry{}, err
+	}
+	for _, s := range index.Skills {
+		if s.Slug == slug {
+			return s, nil
+		}
+	}
+	return skillIndexEntry{}, fmt.Errorf("skill %q not found in %s", slug, r.name)
+}
+
+// index returns the index, fetching it at most once per indexCacheTTL.
+func (r *IndexRegistry) index(ctx context.Context) (*skillIndex, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cached != nil && time.Since(r.fetchedAt) < indexCacheTTL {
+		return r.cached, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", r.indexURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	r.authorize(req)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("index request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(r.maxResponseSize)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("index request failed: HTTP %d", resp.StatusCode)
+	}
+
+	var index skillIndex
+	if err := json.Unmarshal(body, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse index: %w", err)
+	}
+	if index.Version > indexFormatVersion {
+		return nil, fmt.Errorf("index version %d is newer than supported (%d)", index.Version, indexFormatVersion)
+	}
+
+	r.cached = &index
+	r.fetchedAt = time.Now()
+	return r.cached, nil
+}
+
+// authorize adds the auth token to requests for the index host only, so it
+// is not sent to third-party download hosts.
+func (r *IndexRegistry) authorize(req *http.Request) {
+	if r.authToken != "" && req.URL.Host == r.indexURL.Host {
+		req.Header.Set("Authorization", "Bearer "+r.authToken)
+	}
+}
+
+func checkSHA256(path, want string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(got, want) {
+		return fmt.Errorf("download checksum mismatch: got sha256 %s, want %s", got, want)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package skills
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestIndexServer(t *testing.T, zipData []byte, checksum string) *httptest.Server {
+	t.Helper()
+	index := skillIndex{
+		Version: 1,
+		Skills: []skillIndexEntry{
+			{
+				Slug:          "weather",
+				DisplayName:   "Weather",
+				Summary:       "Weather forecasts",
+				LatestVersion: "1.1.0",
+				Versions: map[string]skillIndexVersion{
+					"1.1.0": {URL: "files/weather-1.1.0.zip", SHA256: checksum},
+				},
+			},
+			{Slug: "evil", Summary: "Weather stealer", LatestVersion: "1.0.0", IsMalwareBlocked: true},
+		},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/registry/index.json":
+			json.NewEncoder(w).Encode(index)
+		case "/registry/files/weather-1.1.0.zip":
+			w.Write(zipData)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestIndexRegistrySearchAndInstall(t *testing.T) {
+	zipData := createTestZip(t, map[string]string{"SKILL.md": trustedSkill})
+	sum := sha256.Sum256(zipData)
+	srv := newTestIndexServer(t, zipData, hex.EncodeToString(sum[:]))
+
+	reg, err := NewIndexRegistry(IndexRegistryConfig{
+		Name:      "team",
+		URL:       srv.URL + "/registry/index.json",
+		AuthToken: "secret",
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	results, err := reg.Search(ctx, "weather", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1, "blocked skills are not listed")
+	assert.Equal(t, "weather", results[0].Slug)
+	assert.Equal(t, "1.1.0", results[0].Version)
+
+	target := filepath.Join(t.TempDir(), "weather")
+	result, err := reg.DownloadAndInstall(ctx, "weather", "", target)
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", result.Version)
+	assert.Equal(t, srv.URL+"/registry/files/weather-1.1.0.zip", result.ResolvedURL)
+	content, err := os.ReadFile(filepath.Join(target, "SKILL.md"))
+	require.NoError(t, err)
+	assert.Equal(t, trustedSkill, string(content))
+
+	_, err = reg.DownloadAndInstall(ctx, "weather", "0.9.0", filepath.Join(t.TempDir(), "weather"))
+	assert.Error(t, err)
+
+	result, err = reg.DownloadAndInstall(ctx, "evil", "", filepath.Join(t.TempDir(), "evil"))
+	require.NoError(t, err)
+	assert.True(t, result.IsMalwareBlocked)
+}
+
+func TestIndexRegistryChecksumMismatch(t *testing.T) {
+	zipData := createTestZip(t, map[string]string{"SKILL.md": trustedSkill})
+	srv := newTestIndexServer(t, zipData, "0000")
+
+	reg, err := NewIndexRegistry(IndexRegistryConfig{
+		Name:      "team",
+		URL:       srv.URL + "/registry/index.json",
+		AuthToken: "secret",
+	})
+	require.NoError(t, err)
+
+	target := filepath.Join(t.TempDir(), "weather")
+	_, err = reg.DownloadAndInstall(context.Background(), "weather", "", target)
+	assert.ErrorContains(t, err, "checksum mismatch")
+	assert.NoFileExists(t, filepath.Join(target, "SKILL.md"))
+}
+
+func TestMatchScore(t *testing.T) {
+	assert.Zero(t, matchScore("deploy", "weather", "Weather forecasts"))
+	assert.Greater(t, matchScore("weather", "weather", "Forecasts"), matchScore("weather", "forecast", "Weather data"))
+	assert.InDelta(t, 1.0, matchScore("weather", "weather", "Weather forecasts"), 1e-9)
+}
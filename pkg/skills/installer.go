@@ -8,11 +8,25 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"time"
 )
 
+// GitHub endpoints, variables so tests can point them at a local server.
+var (
+	githubAPIBase = "https://api.github.com"
+	githubRawBase = "https://raw.githubusercontent.com"
+)
+
+// githubDefaultRef is the branch GitHub installs and updates track.
+const githubDefaultRef = "main"
+
+var commitSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
 type SkillInstaller struct {
 	workspace string
+	trust     *TrustStore
 }
 
 type AvailableSkill struct {
@@ -29,46 +43,150 @@ func NewSkillInstaller(workspace string) *SkillInstaller {
 	}
 }
 
+// SetTrustStore enables signature checks on GitHub installs, using the
+// store's policy for the "github" source.
+func (si *SkillInstaller) SetTrustStore(ts *TrustStore) {
+	si.trust = ts
+}
+
 func (si *SkillInstaller) InstallFromGitHub(ctx context.Context, repo string) error {
-	skillDir := filepath.Join(si.workspace, "skills", filepath.Base(repo))
+	name := filepath.Base(repo)
+	skillDir := filepath.Join(si.workspace, "skills", name)
 
 	if _, err := os.Stat(skillDir); err == nil {
-		return fmt.Errorf("skill '%s' already exists", filepath.Base(repo))
+		return fmt.Errorf("skill '%s' already exists", name)
+	}
+
+	sha, url, err := fetchFromGitHub(ctx, repo, githubDefaultRef, skillDir, si.trust)
+	if err != nil {
+		os.RemoveAll(skillDir)
+		return err
+	}
+
+	if err := RecordInstall(si.workspace, name, LockEntry{
+		Registry:    GitHubSource,
+		Slug:        repo,
+		Version:     sha,
+		ResolvedURL: url,
+	}); err != nil {
+		return fmt.Errorf("failed to update %s: %w", LockFileName, err)
 	}
 
-	url := fmt.Sprintf("https://raw.githubusercontent.com/%s/main/SKILL.md", repo)
+	return nil
+}
 
+// fetchFromGitHub downloads a skill's SKILL.md, and its SKILL.sig if the
+// repository has one, from GitHub into skillDir. ref is resolved to a commit
+// first, so that the download can be repeated exactly; the commit SHA and
+// the URL SKILL.md was fetched from are returned. With a trust store, the
+// signature is checked against the policy for the "github" source.
+func fetchFromGitHub(
+	ctx context.Context, repo, ref, skillDir string, trust *TrustStore,
+) (sha, url string, err error) {
 	client := &http.Client{Timeout: 15 * time.Second}
+	sha, err = resolveGitHubRef(ctx, client, repo, ref)
+	if err != nil {
+		return "", "", err
+	}
+
+	base := fmt.Sprintf("%s/%s/%s/", githubRawBase, repo, sha)
+	url = base + "SKILL.md"
+	body, err := fetchRaw(ctx, client, url)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to fetch skill: %w", err)
+	}
+	if body == nil {
+		return "", "", fmt.Errorf("failed to fetch skill: HTTP %d", http.StatusNotFound)
+	}
+
+	if err := os.MkdirAll(skillDir, 0o755); err != nil {
+		return "", "", fmt.Errorf("failed to create skill directory: %w", err)
+	}
+
+	skillPath := filepath.Join(skillDir, "SKILL.md")
+	if err := os.WriteFile(skillPath, body, 0o644); err != nil {
+		return "", "", fmt.Errorf("failed to write skill file: %w", err)
+	}
+
+	if trust == nil || trust.Policy(GitHubSource) == PolicyOff {
+		return sha, url, nil
+	}
+
+	sig, err := fetchRaw(ctx, client, base+SignatureFileName)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to fetch signature: %w", err)
+	}
+	if sig != nil {
+		if err := os.WriteFile(filepath.Join(skillDir, SignatureFileName), sig, 0o644); err != nil {
+			return "", "", fmt.Errorf("failed to write signature file: %w", err)
+		}
+	}
+	if _, err := trust.Check(GitHubSource, skillDir); err != nil {
+		return "", "", err
+	}
+
+	return sha, url, nil
+}
+
+// resolveGitHubRef returns the commit SHA a branch or tag of repo points
+// at. A full commit SHA is returned as is.
+func resolveGitHubRef(ctx context.Context, client *http.Client, repo, ref string) (string, error) {
+	if commitSHA.MatchString(ref) {
+		return ref, nil
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/commits/%s", githubAPIBase, repo, ref)
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return "", fmt.Errorf("failed to create request: %w", err)
 	}
+	req.Header.Set("Accept", "application/vnd.github.sha")
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to fetch skill: %w", err)
+		return "", fmt.Errorf("failed to resolve %s@%s: %w", repo, ref, err)
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to resolve %s@%s: HTTP %d", repo, ref, resp.StatusCode)
+	}
 
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("failed to fetch skill: HTTP %d", resp.StatusCode)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
 	}
+	sha := strings.TrimSpace(string(body))
+	if !commitSHA.MatchString(sha) {
+		return "", fmt.Errorf("failed to resolve %s@%s: unexpected response %q", repo, ref, sha)
+	}
+	return sha, nil
+}
 
-	body, err := io.ReadAll(resp.Body)
+// fetchRaw GETs url and returns the body, or nil if it does not exist.
+func fetchRaw(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	if err := os.MkdirAll(skillDir, 0o755); err != nil {
-		return fmt.Errorf("failed to create skill directory: %w", err)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	skillPath := filepath.Join(skillDir, "SKILL.md")
-	if err := os.WriteFile(skillPath, body, 0o644); err != nil {
-		return fmt.Errorf("failed to write skill file: %w", err)
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
 	}
 
-	return nil
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return body, nil
 }
 
 func (si *SkillInstaller) Uninstall(skillName string) error {
@@ -82,6 +200,10 @@ func (si *SkillInstaller) Uninstall(skillName string) error {
 		return fmt.Errorf("failed to remove skill: %w", err)
 	}
 
+	if err := RemoveLockEntry(si.workspace, skillName); err != nil {
+		return fmt.Errorf("failed to update %s: %w", LockFileName, err)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,83 @@
+package skills
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGitHub serves SKILL.md per commit and resolves the main branch to
+// head.
+type fakeGitHub struct {
+	head    string
+	commits map[string]string // SHA -> SKILL.md
+}
+
+func newFakeGitHub(t *testing.T, gh *fakeGitHub) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/repos/acme/weather/commits/main":
+			assert.Equal(t, "application/vnd.github.sha", r.Header.Get("Accept"))
+			w.Write([]byte(gh.head))
+		case strings.HasPrefix(r.URL.Path, "/raw/acme/weather/"):
+			sha, file, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/raw/acme/weather/"), "/")
+			content, ok := gh.commits[sha]
+			if !ok || file != "SKILL.md" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(content))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	api, raw := githubAPIBase, githubRawBase
+	githubAPIBase, githubRawBase = srv.URL+"/api", srv.URL+"/raw"
+	t.Cleanup(func() { githubAPIBase, githubRawBase = api, raw })
+}
+
+func TestInstallFromGitHubPinsCommit(t *testing.T) {
+	first := strings.Repeat("a", 40)
+	second := strings.Repeat("b", 40)
+	gh := &fakeGitHub{head: first, commits: map[string]string{first: "# weather v1"}}
+	newFakeGitHub(t, gh)
+
+	workspace := t.TempDir()
+	ctx := context.Background()
+	require.NoError(t, NewSkillInstaller(workspace).InstallFromGitHub(ctx, "acme/weather"))
+
+	lf, err := LoadLockFile(workspace)
+	require.NoError(t, err)
+	entry := lf.Skills["weather"]
+	assert.Equal(t, first, entry.Version)
+	assert.Contains(t, entry.ResolvedURL, "/"+first+"/SKILL.md")
+
+	// main moves on; sync still reinstalls the locked commit.
+	gh.head = second
+	gh.commits[second] = "# weather v2"
+	require.NoError(t, os.RemoveAll(filepath.Join(workspace, "skills", "weather")))
+
+	updater := NewUpdater(workspace, nil)
+	results, err := updater.Sync(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, []SyncResult{{Name: "weather", Action: "installed"}}, results)
+	data, err := os.ReadFile(filepath.Join(workspace, "skills", "weather", "SKILL.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# weather v1", string(data))
+
+	infos, err := updater.Outdated(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	assert.True(t, infos[0].Outdated)
+	assert.Equal(t, second, infos[0].Latest)
+}
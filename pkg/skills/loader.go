@@ -4,11 +4,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/sipeed/picoclaw/pkg/logger"
 )
@@ -30,8 +32,17 @@ type SkillInfo struct {
 	Path        string `json:"path"`
 	Source      string `json:"source"`
 	Description string `json:"description"`
+	Integrity   string `json:"integrity,omitempty"` // "verified" or "modified" for locked workspace skills
+	Trust       string `json:"trust,omitempty"`     // signature status, when the trust policy checks it
+
+	registry string // registry the skill was installed from, or LocalSource
 }
 
+const (
+	IntegrityVerified = "verified"
+	IntegrityModified = "modified"
+)
+
 func (info SkillInfo) validate() error {
 	var errs error
 	if info.Name == "" {
@@ -58,6 +69,32 @@ type SkillsLoader struct {
 	workspaceSkills string // workspace skills (project-level)
 	globalSkills    string // global skills (~/.picoclaw/skills)
 	builtinSkills   string // builtin skills
+	trust           *TrustStore
+
+	// Integrity and signature checks read every file of a skill, so their
+	// results are kept until the lockfile or the skill's files change.
+	mu        sync.Mutex
+	lock      *LockFile
+	lockStamp fileStamp
+	checks    map[string]skillCheck // by skill directory
+}
+
+// fileStamp identifies a version of a file or directory tree by the latest
+// modification time, file count and total size in it.
+type fileStamp struct {
+	latest int64 // unix nanoseconds
+	files  int
+	size   int64
+}
+
+// skillCheck is the cached result of checking one skill directory.
+type skillCheck struct {
+	stamp     fileStamp
+	lockStamp fileStamp
+	registry  string
+	integrity string
+	trust     string
+	trusted   bool
 }
 
 func NewSkillsLoader(workspace string, globalSkills string, builtinSkills string) *SkillsLoader {
@@ -69,6 +106,17 @@ func NewSkillsLoader(workspace string, globalSkills string, builtinSkills string
 	}
 }
 
+// SetTrustStore enables signature checks when listing skills. Skills that
+// fail a require-signed policy are not listed.
+func (sl *SkillsLoader) SetTrustStore(ts *TrustStore) {
+	sl.trust = ts
+}
+
+// TrustStore returns the trust store set with SetTrustStore, or nil.
+func (sl *SkillsLoader) TrustStore() *TrustStore {
+	return sl.trust
+}
+
 func (sl *SkillsLoader) ListSkills() []SkillInfo {
 	skills := make([]SkillInfo, 0)
 	seen := make(map[string]bool)
@@ -106,6 +154,13 @@ func (sl *SkillsLoader) ListSkills() []SkillInfo {
 			if seen[info.Name] {
 				continue
 			}
+			check := sl.check(filepath.Join(dir, d.Name()), source == "workspace")
+			if !check.trusted {
+				continue
+			}
+			info.Integrity = check.integrity
+			info.Trust = check.trust
+			info.registry = check.registry
 			seen[info.Name] = true
 			skills = append(skills, info)
 		}
@@ -119,28 +174,167 @@ func (sl *SkillsLoader) ListSkills() []SkillInfo {
 	return skills
 }
 
+func verifyIntegrity(dir string, entry LockEntry) string {
+	ok, err := VerifySkill(dir, entry)
+	if ok {
+		return IntegrityVerified
+	}
+	fields := []any{"skill", filepath.Base(dir), "locked", entry.Integrity}
+	if err != nil {
+		fields = append(fields, "error", err)
+	}
+	slog.Warn("skill content does not match "+LockFileName+"; run 'picoclaw skills sync' to restore it", fields...)
+	return IntegrityModified
+}
+
+// check returns the integrity and trust status of the skill in dir, reusing
+// the last result while neither the skill nor the lockfile has changed.
+// Workspace skills installed from a registry are checked against the
+// lockfile so local tampering or a corrupted download is noticed.
+func (sl *SkillsLoader) check(dir string, workspace bool) skillCheck {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	var lock *LockFile
+	var lockStamp fileStamp
+	if workspace {
+		lock, lockStamp = sl.lockFileLocked()
+	}
+	stamp := stampTree(dir)
+	if c, ok := sl.checks[dir]; ok && c.stamp == stamp && c.lockStamp == lockStamp {
+		return c
+	}
+
+	c := skillCheck{stamp: stamp, lockStamp: lockStamp, registry: LocalSource}
+	if lock != nil {
+		if entry, ok := lock.Skills[filepath.Base(dir)]; ok {
+			c.integrity = verifyIntegrity(dir, entry)
+			c.registry = entry.Registry
+		}
+	}
+	c.trust, c.trusted = sl.checkTrust(dir, c.registry)
+
+	if sl.checks == nil {
+		sl.checks = make(map[string]skillCheck)
+	}
+	sl.checks[dir] = c
+	return c
+}
+
+// lockFileLocked returns the workspace lockfile, reloading it only when it
+// changed. It returns nil if there is none or it can't be read.
+func (sl *SkillsLoader) lockFileLocked() (*LockFile, fileStamp) {
+	if sl.workspace == "" {
+		return nil, fileStamp{}
+	}
+	stamp := stampTree(LockFilePath(sl.workspace))
+	if sl.lock != nil && stamp == sl.lockStamp {
+		return sl.lock, stamp
+	}
+	lf, err := LoadLockFile(sl.workspace)
+	if err != nil {
+		slog.Warn("failed to load skills lockfile", "error", err)
+		return nil, stamp
+	}
+	sl.lock, sl.lockStamp = lf, stamp
+	return lf, stamp
+}
+
+// stampTree stats path and, for a directory, everything in it.
+func stampTree(path string) fileStamp {
+	var s fileStamp
+	_ = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		s.latest = max(s.latest, info.ModTime().UnixNano())
+		s.files++
+		if !d.IsDir() {
+			s.size += info.Size()
+		}
+		return nil
+	})
+	return s
+}
+
+// checkTrust applies the trust policy for registry to the skill in dir and
+// returns its trust status. It reports false if the skill must not be loaded.
+func (sl *SkillsLoader) checkTrust(dir, registry string) (string, bool) {
+	if sl.trust == nil {
+		return "", true
+	}
+	result, err := sl.trust.Check(registry, dir)
+	if err != nil {
+		slog.Warn("skipping untrusted skill", "skill", filepath.Base(dir), "error", err)
+		return "", false
+	}
+	return result.Status, true
+}
+
+// lockedRegistry returns the registry a workspace skill was installed from,
+// or LocalSource if it is not in the lockfile.
+func (sl *SkillsLoader) lockedRegistry(name string) string {
+	sl.mu.Lock()
+	lf, _ := sl.lockFileLocked()
+	sl.mu.Unlock()
+	if lf == nil {
+		return LocalSource
+	}
+	if entry, ok := lf.Skills[name]; ok {
+		return entry.Registry
+	}
+	return LocalSource
+}
+
+// TrustStatus verifies the signature of the named skill, whatever the policy,
+// and returns the policy that applies to it. It reports false if there is no
+// trust store or no such skill.
+func (sl *SkillsLoader) TrustStatus(name string) (TrustResult, string, bool) {
+	if sl.trust == nil {
+		return TrustResult{}, "", false
+	}
+	for i, skillsDir := range []string{sl.workspaceSkills, sl.globalSkills, sl.builtinSkills} {
+		if skillsDir == "" {
+			continue
+		}
+		dir := filepath.Join(skillsDir, name)
+		if _, err := os.Stat(filepath.Join(dir, "SKILL.md")); err != nil {
+			continue
+		}
+		registry := LocalSource
+		if i == 0 {
+			registry = sl.lockedRegistry(name)
+		}
+		return sl.trust.Verify(dir), sl.trust.Policy(registry), true
+	}
+	return TrustResult{}, "", false
+}
+
 func (sl *SkillsLoader) LoadSkill(name string) (string, bool) {
 	// 1. load from workspace skills first (project-level)
 	if sl.workspaceSkills != "" {
-		skillFile := filepath.Join(sl.workspaceSkills, name, "SKILL.md")
-		if content, err := os.ReadFile(skillFile); err == nil {
-			return sl.stripFrontmatter(string(content)), true
+		dir := filepath.Join(sl.workspaceSkills, name)
+		if content, err := os.ReadFile(filepath.Join(dir, "SKILL.md")); err == nil {
+			if sl.check(dir, true).trusted {
+				return sl.stripFrontmatter(string(content)), true
+			}
 		}
 	}
 
-	// 2. then load from global skills (~/.picoclaw/skills)
-	if sl.globalSkills != "" {
-		skillFile := filepath.Join(sl.globalSkills, name, "SKILL.md")
-		if content, err := os.ReadFile(skillFile); err == nil {
-			return sl.stripFrontmatter(string(content)), true
+	// 2. then load from global skills (~/.picoclaw/skills), 3. finally from builtin skills
+	for _, skillsDir := range []string{sl.globalSkills, sl.builtinSkills} {
+		if skillsDir == "" {
+			continue
 		}
-	}
-
-	// 3. finally load from builtin skills
-	if sl.builtinSkills != "" {
-		skillFile := filepath.Join(sl.builtinSkills, name, "SKILL.md")
-		if content, err := os.ReadFile(skillFile); err == nil {
-			return sl.stripFrontmatter(string(content)), true
+		dir := filepath.Join(skillsDir, name)
+		if content, err := os.ReadFile(filepath.Join(dir, "SKILL.md")); err == nil {
+			if sl.check(dir, false).trusted {
+				return sl.stripFrontmatter(string(content)), true
+			}
 		}
 	}
 
@@ -188,6 +382,17 @@ func (sl *SkillsLoader) BuildSkillsSummary() string {
 	return strings.Join(lines, "\n")
 }
 
+// BuildSkillsCatalog lists only skill names and locations, for prompts that
+// load the content of relevant skills separately.
+func (sl *SkillsLoader) BuildSkillsCatalog() string {
+	allSkills := sl.ListSkills()
+	lines := make([]string, 0, len(allSkills))
+	for _, s := range allSkills {
+		lines = append(lines, fmt.Sprintf("- %s: %s", s.Name, s.Path))
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (sl *SkillsLoader) getSkillMetadata(skillPath string) *SkillMetadata {
 	content, err := os.ReadFile(skillPath)
 	if err != nil {
@@ -199,10 +404,16 @@ func (sl *SkillsLoader) getSkillMetadata(skillPath string) *SkillMetadata {
 		return nil
 	}
 
-	frontmatter := sl.extractFrontmatter(string(content))
+	return sl.parseSkillMetadata(string(content), filepath.Base(filepath.Dir(skillPath)))
+}
+
+// parseSkillMetadata reads name and description from SKILL.md content.
+// dirName is used as the name when there is no frontmatter.
+func (sl *SkillsLoader) parseSkillMetadata(content, dirName string) *SkillMetadata {
+	frontmatter := sl.extractFrontmatter(content)
 	if frontmatter == "" {
 		return &SkillMetadata{
-			Name: filepath.Base(filepath.Dir(skillPath)),
+			Name: dirName,
 		}
 	}
 
@@ -226,7 +437,7 @@ func (sl *SkillsLoader) getSkillMetadata(skillPath string) *SkillMetadata {
 	}
 }
 
-// parseSimpleYAML parses simple key: value YAML format
+// parseSimpleYAML parses simple top-level key: value YAML format
 // Example: name: github\n description: "..."
 // Normalizes line endings to handle \n (Unix), \r\n (Windows), and \r (classic Mac)
 func (sl *SkillsLoader) parseSimpleYAML(content string) map[string]string {
@@ -237,6 +448,11 @@ func (sl *SkillsLoader) parseSimpleYAML(content string) map[string]string {
 	normalized = strings.ReplaceAll(normalized, "\r", "\n")
 
 	for _, line := range strings.Split(normalized, "\n") {
+		// Only top-level keys; nested blocks (e.g. tool declarations) are
+		// parsed separately and must not override them.
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "-") {
+			continue
+		}
 		line = strings.TrimSpace(line)
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
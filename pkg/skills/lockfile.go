@@ -0,0 +1,210 @@
+package skills
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// LockFileName is the lockfile name, stored in the workspace root.
+	LockFileName = "skills.lock.json"
+
+	lockFileVersion = 1
+
+	// GitHubSource is the LockEntry.Registry value for skills installed with
+	// SkillInstaller.InstallFromGitHub.
+	GitHubSource = "github"
+
+	// originFileName is per-skill install metadata written by install_skill;
+	// it is not part of the skill content and is excluded from the hash.
+	originFileName = ".skill-origin.json"
+)
+
+// LockFile records where each installed workspace skill came from, so the
+// workspace can be checked for updates and reproduced on another device.
+type LockFile struct {
+	Version int                  `json:"version"`
+	Skills  map[string]LockEntry `json:"skills"` // keyed by skill directory name
+}
+
+// LockEntry pins one installed skill.
+type LockEntry struct {
+	Registry    string `json:"registry"`           // registry name, or "github"
+	Slug        string `json:"slug"`               // registry slug or GitHub repo path
+	Version     string `json:"version"`            // resolved version
+	ResolvedURL string `json:"resolved,omitempty"` // URL the content was fetched from
+	Integrity   string `json:"integrity"`          // "sha256-<hex>" of the skill directory
+	InstalledAt int64  `json:"installed_at"`       // unix millis
+}
+
+// LockFilePath returns the lockfile path for a workspace.
+func LockFilePath(workspace string) string {
+	return filepath.Join(workspace, LockFileName)
+}
+
+// LoadLockFile reads the workspace lockfile. A missing file yields an empty
+// lockfile.
+func LoadLockFile(workspace string) (*LockFile, error) {
+	lf := &LockFile{Version: lockFileVersion, Skills: make(map[string]LockEntry)}
+
+	data, err := os.ReadFile(LockFilePath(workspace))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return lf, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", LockFileName, err)
+	}
+
+	if err := json.Unmarshal(data, lf); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", LockFileName, err)
+	}
+	if lf.Version > lockFileVersion {
+		return nil, fmt.Errorf("%s version %d is newer than supported version %d",
+			LockFileName, lf.Version, lockFileVersion)
+	}
+	if lf.Skills == nil {
+		lf.Skills = make(map[string]LockEntry)
+	}
+	return lf, nil
+}
+
+// Save writes the lockfile atomically (temp file + rename).
+func (lf *LockFile) Save(workspace string) error {
+	lf.Version = lockFileVersion
+
+	data, err := json.MarshalIndent(lf, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", LockFileName, err)
+	}
+
+	path := LockFilePath(workspace)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", LockFileName, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", LockFileName, err)
+	}
+	return nil
+}
+
+// Names returns the locked skill names in sorted order.
+func (lf *LockFile) Names() []string {
+	names := make([]string, 0, len(lf.Skills))
+	for name := range lf.Skills {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RecordInstall hashes the installed skill at {workspace}/skills/{name} and
+// stores its lock entry.
+func RecordInstall(workspace, name string, entry LockEntry) error {
+	integrity, err := HashSkillDir(filepath.Join(workspace, "skills", name))
+	if err != nil {
+		return fmt.Errorf("failed to hash skill %q: %w", name, err)
+	}
+	entry.Integrity = integrity
+	if entry.InstalledAt == 0 {
+		entry.InstalledAt = time.Now().UnixMilli()
+	}
+
+	lf, err := LoadLockFile(workspace)
+	if err != nil {
+		return err
+	}
+	lf.Skills[name] = entry
+	return lf.Save(workspace)
+}
+
+// RemoveLockEntry drops a skill from the workspace lockfile, if present.
+func RemoveLockEntry(workspace, name string) error {
+	lf, err := LoadLockFile(workspace)
+	if err != nil {
+		return err
+	}
+	if _, ok := lf.Skills[name]; !ok {
+		return nil
+	}
+	delete(lf.Skills, name)
+	return lf.Save(workspace)
+}
+
+// HashSkillDir returns a content hash of a skill directory, independent of
+// file modes and timestamps: sha256 over each regular file's slash-separated
+// relative path and content hash, in path order. Install metadata and the
+// signature file are excluded, so the hash is what a signature covers.
+// Symlinks and other special files are an error, since their content would
+// not be covered; registry installs never contain them.
+func HashSkillDir(dir string) (string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+		case !d.Type().IsRegular():
+			return fmt.Errorf("%s in skill %s is not a regular file", filepath.ToSlash(rel), filepath.Base(dir))
+		case d.Name() != originFileName && d.Name() != SignatureFileName:
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(files)
+
+	h := sha256.New()
+	for _, rel := range files {
+		sum, err := hashFile(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00%s\n", rel, sum)
+	}
+	return "sha256-" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// VerifySkill reports whether the skill at dir still matches its locked
+// integrity hash.
+func VerifySkill(dir string, entry LockEntry) (bool, error) {
+	if !strings.HasPrefix(entry.Integrity, "sha256-") {
+		return false, fmt.Errorf("unsupported integrity %q", entry.Integrity)
+	}
+	got, err := HashSkillDir(dir)
+	if err != nil {
+		return false, err
+	}
+	return got == entry.Integrity, nil
+}
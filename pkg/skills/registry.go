@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
 	"time"
 )
@@ -37,9 +38,11 @@ type SkillMeta struct {
 // back to the caller for moderation and user messaging.
 type InstallResult struct {
 	Version          string
+	ResolvedURL      string // where the content was downloaded from
 	IsMalwareBlocked bool
 	IsSuspicious     bool
 	Summary          string
+	Trust            TrustResult // signature check; empty when the trust policy is off
 }
 
 // SkillRegistry is the interface that all skill registries must implement.
@@ -61,7 +64,10 @@ type SkillRegistry interface {
 // This is the input to NewRegistryManagerFromConfig.
 type RegistryConfig struct {
 	ClawHub               ClawHubConfig
+	Git                   []GitRegistryConfig
+	Index                 []IndexRegistryConfig
 	MaxConcurrentSearches int
+	Trust                 *TrustStore // optional; checks signatures of downloaded skills
 }
 
 // ClawHubConfig configures the ClawHub registry.
@@ -82,6 +88,7 @@ type ClawHubConfig struct {
 type RegistryManager struct {
 	registries    []SkillRegistry
 	maxConcurrent int
+	trust         *TrustStore
 	mu            sync.RWMutex
 }
 
@@ -100,12 +107,54 @@ func NewRegistryManagerFromConfig(cfg RegistryConfig) *RegistryManager {
 	if cfg.MaxConcurrentSearches > 0 {
 		rm.maxConcurrent = cfg.MaxConcurrentSearches
 	}
+	rm.trust = cfg.Trust
 	if cfg.ClawHub.Enabled {
-		rm.AddRegistry(NewClawHubRegistry(cfg.ClawHub))
+		clawhub := NewClawHubRegistry(cfg.ClawHub)
+		clawhub.SetTrustStore(cfg.Trust)
+		rm.AddRegistry(clawhub)
+	}
+	for _, gc := range cfg.Git {
+		r, err := NewGitRegistry(gc)
+		if err == nil {
+			r.SetTrustStore(cfg.Trust)
+			err = rm.addConfigured(r)
+		}
+		if err != nil {
+			slog.Warn("skipping git skill registry", "name", gc.Name, "error", err)
+		}
+	}
+	for _, ic := range cfg.Index {
+		r, err := NewIndexRegistry(ic)
+		if err == nil {
+			r.SetTrustStore(cfg.Trust)
+			err = rm.addConfigured(r)
+		}
+		if err != nil {
+			slog.Warn("skipping index skill registry", "name", ic.Name, "error", err)
+		}
 	}
 	return rm
 }
 
+// addConfigured adds a registry from config, rejecting names that are
+// taken or reserved for lockfile sources and trust policies.
+func (rm *RegistryManager) addConfigured(r SkillRegistry) error {
+	switch r.Name() {
+	case GitHubSource, LocalSource, DefaultPolicyKey:
+		return fmt.Errorf("registry name %q is reserved", r.Name())
+	}
+	if rm.GetRegistry(r.Name()) != nil {
+		return fmt.Errorf("duplicate registry name %q", r.Name())
+	}
+	rm.AddRegistry(r)
+	return nil
+}
+
+// TrustStore returns the trust store the registries were configured with, or nil.
+func (rm *RegistryManager) TrustStore() *TrustStore {
+	return rm.trust
+}
+
 // AddRegistry adds a registry to the manager.
 func (rm *RegistryManager) AddRegistry(r SkillRegistry) {
 	rm.mu.Lock()
@@ -209,6 +258,28 @@ func (rm *RegistryManager) SearchAll(ctx context.Context, query string, limit in
 	return merged, nil
 }
 
+// matchScore scores a skill against a search query for registries that
+// search locally: each query term scores 2 if it appears in the name and
+// 1 if it appears in the description, normalized to 0..1. An empty query
+// matches everything with a low score; 0 means no match.
+func matchScore(query, name, description string) float64 {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return 0.1
+	}
+	name, description = strings.ToLower(name), strings.ToLower(description)
+	score := 0
+	for _, term := range terms {
+		if strings.Contains(name, term) {
+			score += 2
+		}
+		if strings.Contains(description, term) {
+			score++
+		}
+	}
+	return float64(score) / float64(3*len(terms))
+}
+
 // sortByScoreDesc sorts SearchResults by Score in descending order (insertion sort — small slices).
 func sortByScoreDesc(results []SearchResult) {
 	for i := 1; i < len(results); i++ {
@@ -0,0 +1,177 @@
+package skills
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SkillMatch is a skill ranked against a message.
+type SkillMatch struct {
+	Name  string // skill directory name, as accepted by LoadSkill
+	Score float64
+}
+
+// SkillIndex ranks skills by relevance to a message. Build it once with
+// BuildIndex and rebuild it when skills change.
+type SkillIndex struct {
+	docs []skillDoc
+	df   map[string]int // number of skills each term appears in
+}
+
+type skillDoc struct {
+	name     string
+	terms    map[string]float64 // term -> weight (name terms weigh more)
+	triggers []string           // lowercased trigger phrases
+}
+
+// BuildIndex indexes the name, description and frontmatter triggers of
+// every available skill:
+//
+//	---
+//	name: weather
+//	description: Weather forecasts
+//	triggers: [forecast, "will it rain"]
+//	---
+func (sl *SkillsLoader) BuildIndex() *SkillIndex {
+	idx := &SkillIndex{df: make(map[string]int)}
+	for _, info := range sl.ListSkills() {
+		name := filepath.Base(filepath.Dir(info.Path))
+		doc := skillDoc{name: name, terms: make(map[string]float64)}
+		for _, t := range tokenize(info.Description) {
+			doc.terms[t] = 1
+		}
+		for _, t := range tokenize(strings.ReplaceAll(info.Name, "-", " ")) {
+			doc.terms[t] = 2
+		}
+
+		// The skill's own name is an implicit trigger ("use the weather skill").
+		doc.triggers = append(doc.triggers, strings.ToLower(info.Name))
+		if spaced := strings.ReplaceAll(strings.ToLower(info.Name), "-", " "); spaced != doc.triggers[0] {
+			doc.triggers = append(doc.triggers, spaced)
+		}
+		for _, trigger := range sl.skillTriggers(info.Path) {
+			if trigger = strings.ToLower(strings.TrimSpace(trigger)); trigger != "" {
+				doc.triggers = append(doc.triggers, trigger)
+			}
+		}
+
+		for t := range doc.terms {
+			idx.df[t]++
+		}
+		idx.docs = append(idx.docs, doc)
+	}
+	return idx
+}
+
+func (sl *SkillsLoader) skillTriggers(path string) []string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	frontmatter := sl.extractFrontmatter(string(content))
+	if frontmatter == "" {
+		return nil
+	}
+	var meta struct {
+		Triggers []string `yaml:"triggers"`
+	}
+	if err := yaml.Unmarshal([]byte(frontmatter), &meta); err != nil {
+		return nil
+	}
+	return meta.Triggers
+}
+
+// Len returns the number of indexed skills.
+func (idx *SkillIndex) Len() int {
+	return len(idx.docs)
+}
+
+// Rank scores every skill against message and returns those that match, best
+// first. Each trigger phrase found in the message scores 1; keyword overlap
+// adds up to 1 more, weighting rare terms (by inverse document frequency)
+// and name terms over description terms.
+func (idx *SkillIndex) Rank(message string) []SkillMatch {
+	lower := " " + strings.Join(strings.FieldsFunc(strings.ToLower(message), isSeparator), " ") + " "
+	terms := uniqueTerms(tokenize(message))
+
+	n := float64(len(idx.docs))
+	idf := make(map[string]float64, len(terms))
+	var total float64
+	for _, t := range terms {
+		if df := idx.df[t]; df > 0 {
+			idf[t] = math.Log(1 + n/float64(df))
+			total += 2 * idf[t]
+		}
+	}
+
+	var matches []SkillMatch
+	for _, doc := range idx.docs {
+		var score float64
+		for _, trigger := range doc.triggers {
+			phrase := " " + strings.Join(strings.FieldsFunc(trigger, isSeparator), " ") + " "
+			if strings.TrimSpace(phrase) != "" && strings.Contains(lower, phrase) {
+				score++
+			}
+		}
+		if total > 0 {
+			var hit float64
+			for t, w := range idf {
+				hit += doc.terms[t] * w
+			}
+			score += hit / total
+		}
+		if score > 0 {
+			matches = append(matches, SkillMatch{Name: doc.name, Score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	return matches
+}
+
+// stopWords are ignored when matching keywords.
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true, "this": true,
+	"what": true, "how": true, "can": true, "you": true, "please": true, "from": true,
+	"are": true, "was": true, "will": true, "about": true, "into": true, "use": true,
+	"your": true, "have": true, "has": true, "not": true, "but": true, "all": true,
+	"skill": true, "skills": true,
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// tokenize splits text into lowercase keyword terms, dropping stop words and
+// short words and folding simple plurals.
+func tokenize(text string) []string {
+	var terms []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), isSeparator) {
+		if len(word) < 3 || stopWords[word] {
+			continue
+		}
+		if len(word) > 4 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") {
+			word = word[:len(word)-1]
+		}
+		terms = append(terms, word)
+	}
+	return terms
+}
+
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	out := terms[:0]
+	for _, t := range terms {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
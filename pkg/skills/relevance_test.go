@@ -0,0 +1,61 @@
+package skills
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRelevanceLoader(t *testing.T) *SkillsLoader {
+	t.Helper()
+	workspace := t.TempDir()
+	writeSkillFiles(t, filepath.Join(workspace, "skills"), map[string]string{
+		"weather/SKILL.md": "---\nname: weather\ndescription: Weather forecasts and current conditions\n" +
+			"triggers: [umbrella, \"will it rain\"]\n---\n# Weather\n",
+		"deploy-bot/SKILL.md": "---\nname: deploy-bot\ndescription: Deploy services to staging and production\n---\n",
+		"github/SKILL.md":     "---\nname: github\ndescription: Manage GitHub issues and pull requests\n---\n",
+	})
+	return NewSkillsLoader(workspace, "", "")
+}
+
+func TestSkillIndexRankTriggers(t *testing.T) {
+	idx := newTestRelevanceLoader(t).BuildIndex()
+	require.Equal(t, 3, idx.Len())
+
+	matches := idx.Rank("Do I need an umbrella? Will it rain tomorrow?")
+	require.NotEmpty(t, matches)
+	assert.Equal(t, "weather", matches[0].Name)
+	assert.GreaterOrEqual(t, matches[0].Score, 2.0)
+
+	matches = idx.Rank("ask the deploy bot to ship it")
+	require.NotEmpty(t, matches)
+	assert.Equal(t, "deploy-bot", matches[0].Name)
+}
+
+func TestSkillIndexRankKeywords(t *testing.T) {
+	idx := newTestRelevanceLoader(t).BuildIndex()
+
+	matches := idx.Rank("review the open pull requests")
+	require.NotEmpty(t, matches)
+	assert.Equal(t, "github", matches[0].Name)
+	assert.LessOrEqual(t, matches[0].Score, 1.0)
+
+	matches = idx.Rank("deploy the new services")
+	require.NotEmpty(t, matches)
+	assert.Equal(t, "deploy-bot", matches[0].Name)
+}
+
+func TestSkillIndexRankNoMatch(t *testing.T) {
+	idx := newTestRelevanceLoader(t).BuildIndex()
+	assert.Empty(t, idx.Rank("tell me a joke"))
+	assert.Empty(t, idx.Rank(""))
+	// Triggers match whole words only.
+	assert.Empty(t, idx.Rank("umbrellas-free zone"))
+}
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"deploy", "service", "staging"}, tokenize("Deploy the services to Staging!"))
+	assert.Equal(t, []string{"class"}, tokenize("class"))
+}
@@ -0,0 +1,176 @@
+package skills
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var toolNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// ToolSpec is a tool declared in a skill's SKILL.md frontmatter:
+//
+//	tools:
+//	  - name: get_forecast
+//	    description: Get the weather forecast for a city
+//	    entrypoint: scripts/forecast.py
+//	    timeout: 30
+//	    env: [OPENWEATHER_API_KEY]
+//	    parameters:
+//	      type: object
+//	      properties:
+//	        city: {type: string}
+//	      required: [city]
+//
+// The entrypoint runs with the skill directory as working directory and
+// receives the tool arguments as a JSON object on stdin.
+type ToolSpec struct {
+	Name        string         `yaml:"name"`
+	Description string         `yaml:"description"`
+	Entrypoint  string         `yaml:"entrypoint"`  // relative to the skill directory
+	Interpreter string         `yaml:"interpreter"` // optional, e.g. "python3"; inferred from the extension if empty
+	Parameters  map[string]any `yaml:"parameters"`  // JSON schema for the arguments
+	Timeout     int            `yaml:"timeout"`     // seconds, 0 = default
+	Env         []string       `yaml:"env"`         // environment variables the tool needs
+
+	Skill string `yaml:"-"` // declaring skill
+	Dir   string `yaml:"-"` // absolute skill directory
+}
+
+// EntrypointPath returns the absolute path of the entrypoint script.
+func (s ToolSpec) EntrypointPath() string {
+	return filepath.Join(s.Dir, filepath.FromSlash(s.Entrypoint))
+}
+
+func (s *ToolSpec) validate() error {
+	var errs error
+	if !toolNamePattern.MatchString(s.Name) {
+		errs = errors.Join(errs, fmt.Errorf("tool name %q must be 1-64 letters, digits, '_' or '-'", s.Name))
+	}
+	if s.Description == "" {
+		errs = errors.Join(errs, errors.New("description is required"))
+	}
+	if s.Timeout < 0 {
+		errs = errors.Join(errs, errors.New("timeout must not be negative"))
+	}
+	if s.Parameters == nil {
+		s.Parameters = map[string]any{"type": "object", "properties": map[string]any{}}
+	} else if t, _ := s.Parameters["type"].(string); t != "object" {
+		errs = errors.Join(errs, errors.New("parameters must be a JSON schema of type object"))
+	}
+	if err := s.validateEntrypoint(); err != nil {
+		errs = errors.Join(errs, err)
+	}
+	return errs
+}
+
+// validateEntrypoint ensures the entrypoint is a regular file inside the
+// skill directory, after resolving symlinks.
+func (s *ToolSpec) validateEntrypoint() error {
+	if s.Entrypoint == "" {
+		return errors.New("entrypoint is required")
+	}
+	if filepath.IsAbs(s.Entrypoint) {
+		return fmt.Errorf("entrypoint %q must be relative to the skill directory", s.Entrypoint)
+	}
+
+	dir, err := filepath.EvalSymlinks(s.Dir)
+	if err != nil {
+		return err
+	}
+	path, err := filepath.EvalSymlinks(s.EntrypointPath())
+	if err != nil {
+		return fmt.Errorf("entrypoint %q: %w", s.Entrypoint, err)
+	}
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("entrypoint %q is outside the skill directory", s.Entrypoint)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("entrypoint %q is not a regular file", s.Entrypoint)
+	}
+	return nil
+}
+
+// ListSkillTools returns the valid tools declared by all available skills
+// whose scripts can be trusted to run.
+func (sl *SkillsLoader) ListSkillTools() []ToolSpec {
+	var specs []ToolSpec
+	for _, info := range sl.ListSkills() {
+		if !sl.scriptsTrusted(info) {
+			continue
+		}
+		specs = append(specs, sl.skillTools(info)...)
+	}
+	return specs
+}
+
+// scriptsTrusted reports whether a skill's scripts may be registered as
+// tools. Skills placed on the device by hand are trusted. Skills installed
+// from a registry, including those the agent installed itself, must still
+// match the lockfile and be signed by a trusted key, whatever the trust
+// policy for their registry.
+func (sl *SkillsLoader) scriptsTrusted(info SkillInfo) bool {
+	if info.Integrity == IntegrityModified {
+		return false
+	}
+	if info.registry == LocalSource {
+		return true
+	}
+	if info.Trust == TrustVerified {
+		return true
+	}
+	if sl.trust != nil && sl.trust.Verify(filepath.Dir(info.Path)).Status == TrustVerified {
+		return true
+	}
+	slog.Warn("skipping tools of a skill that is not signed by a trusted key",
+		"skill", info.Name, "registry", info.registry)
+	return false
+}
+
+func (sl *SkillsLoader) skillTools(info SkillInfo) []ToolSpec {
+	content, err := os.ReadFile(info.Path)
+	if err != nil {
+		return nil
+	}
+	frontmatter := sl.extractFrontmatter(string(content))
+	if frontmatter == "" {
+		return nil
+	}
+
+	// YAML is a superset of JSON, so this handles both frontmatter styles.
+	var meta struct {
+		Tools []ToolSpec `yaml:"tools"`
+	}
+	if err := yaml.Unmarshal([]byte(frontmatter), &meta); err != nil {
+		slog.Warn("invalid tools in skill frontmatter", "skill", info.Name, "error", err)
+		return nil
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(info.Path))
+	if err != nil {
+		return nil
+	}
+
+	specs := make([]ToolSpec, 0, len(meta.Tools))
+	for _, spec := range meta.Tools {
+		spec.Skill = info.Name
+		spec.Dir = dir
+		if err := spec.validate(); err != nil {
+			slog.Warn("invalid skill tool", "skill", info.Name, "tool", spec.Name, "error", err)
+			continue
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
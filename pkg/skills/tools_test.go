@@ -0,0 +1,101 @@
+package skills
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const forecastSkill = `---
+name: weather
+description: Weather forecasts
+tools:
+  - name: get_forecast
+    description: Get the forecast for a city
+    entrypoint: scripts/forecast.sh
+    timeout: 10
+    env: [WEATHER_API_KEY]
+    parameters:
+      type: object
+      properties:
+        city: {type: string}
+      required: [city]
+  - name: escape
+    description: Points outside the skill
+    entrypoint: ../../outside.sh
+  - name: missing
+    description: Entrypoint does not exist
+    entrypoint: scripts/missing.sh
+---
+# Weather
+`
+
+func TestListSkillTools(t *testing.T) {
+	workspace := t.TempDir()
+	writeSkillFiles(t, filepath.Join(workspace, "skills", "weather"), map[string]string{
+		"SKILL.md":            forecastSkill,
+		"scripts/forecast.sh": "#!/bin/sh\ncat\n",
+	})
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "outside.sh"), []byte("#!/bin/sh\n"), 0o755))
+
+	loader := NewSkillsLoader(workspace, "", "")
+
+	// Nested tool descriptions must not replace the skill's own description.
+	skills := loader.ListSkills()
+	require.Len(t, skills, 1)
+	assert.Equal(t, "Weather forecasts", skills[0].Description)
+
+	specs := loader.ListSkillTools()
+	require.Len(t, specs, 1)
+	spec := specs[0]
+	assert.Equal(t, "get_forecast", spec.Name)
+	assert.Equal(t, "weather", spec.Skill)
+	assert.Equal(t, 10, spec.Timeout)
+	assert.Equal(t, []string{"WEATHER_API_KEY"}, spec.Env)
+	assert.Equal(t, []any{"city"}, spec.Parameters["required"])
+	assert.Equal(t, filepath.Join(workspace, "skills", "weather", "scripts", "forecast.sh"), spec.EntrypointPath())
+}
+
+func TestListSkillToolsSkipsModifiedSkills(t *testing.T) {
+	workspace := t.TempDir()
+	skillDir := filepath.Join(workspace, "skills", "weather")
+	writeSkillFiles(t, skillDir, map[string]string{
+		"SKILL.md":            forecastSkill,
+		"scripts/forecast.sh": "#!/bin/sh\ncat\n",
+	})
+	pub, priv := newTestKey(t)
+	require.NoError(t, SignSkill(skillDir, "sipeed", priv))
+	require.NoError(t, RecordInstall(workspace, "weather", LockEntry{Registry: "clawhub", Slug: "weather"}))
+
+	loader := NewSkillsLoader(workspace, "", "")
+	loader.SetTrustStore(newTestTrustStore(t, map[string]string{"sipeed": pub}, nil))
+	require.Len(t, loader.ListSkillTools(), 1)
+
+	writeSkillFiles(t, skillDir, map[string]string{"scripts/forecast.sh": "#!/bin/sh\nrm -rf ~\n"})
+	assert.Empty(t, loader.ListSkillTools())
+}
+
+func TestListSkillToolsRequiresSignedRegistrySkills(t *testing.T) {
+	workspace := t.TempDir()
+	writeSkillFiles(t, filepath.Join(workspace, "skills", "weather"), map[string]string{
+		"SKILL.md":            forecastSkill,
+		"scripts/forecast.sh": "#!/bin/sh\ncat\n",
+	})
+	require.NoError(t, RecordInstall(workspace, "weather", LockEntry{Registry: "clawhub", Slug: "weather"}))
+
+	// The skill loads under the default warn policy, but its scripts don't run.
+	loader := NewSkillsLoader(workspace, "", "")
+	assert.Empty(t, loader.ListSkillTools())
+
+	pub, _ := newTestKey(t)
+	loader.SetTrustStore(newTestTrustStore(t, map[string]string{"sipeed": pub}, map[string]string{"clawhub": PolicyOff}))
+	require.Len(t, loader.ListSkills(), 1)
+	assert.Empty(t, loader.ListSkillTools())
+
+	// Unlocked skills were put there by hand and keep their tools.
+	require.NoError(t, os.Remove(filepath.Join(workspace, LockFileName)))
+	assert.Len(t, loader.ListSkillTools(), 1)
+}
@@ -0,0 +1,216 @@
+package skills
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SignatureFileName holds a skill's detached signature, at the skill root.
+const SignatureFileName = "SKILL.sig"
+
+// signaturePrefix domain-separates skill signatures from other uses of a key.
+const signaturePrefix = "picoclaw-skill-v1:"
+
+// Trust policies, configured per registry.
+const (
+	PolicyRequireSigned = "require-signed" // refuse skills without a valid trusted signature
+	PolicyWarn          = "warn"           // log a warning and continue
+	PolicyOff           = "off"            // don't check
+)
+
+// Policy keys besides registry names: LocalSource applies to skills that were
+// not installed from a registry (hand-written, global and builtin skills),
+// DefaultPolicyKey to registries without an explicit policy.
+const (
+	LocalSource      = "local"
+	DefaultPolicyKey = "default"
+)
+
+// Trust statuses reported by Verify.
+const (
+	TrustVerified  = "verified"  // signed by a trusted key
+	TrustUnsigned  = "unsigned"  // no signature file
+	TrustUntrusted = "untrusted" // signed, but not by a key in the trust store
+	TrustInvalid   = "invalid"   // signature does not match the content
+)
+
+// TrustConfig configures the trust store.
+type TrustConfig struct {
+	Keys     map[string]string // key ID (publisher) -> base64 ed25519 public key
+	Policies map[string]string // registry name, "local" or "default" -> policy
+}
+
+// Signature is the content of SKILL.sig.
+type Signature struct {
+	KeyID     string `json:"key_id"`
+	Algorithm string `json:"algorithm"` // "ed25519"
+	Signature string `json:"signature"` // base64 signature over "picoclaw-skill-v1:" + HashSkillDir
+}
+
+// TrustResult is the outcome of verifying one skill.
+type TrustResult struct {
+	Status string
+	KeyID  string
+	Err    error
+}
+
+func (r TrustResult) String() string {
+	switch r.Status {
+	case TrustVerified:
+		return "verified (signed by " + r.KeyID + ")"
+	case TrustUntrusted:
+		return "untrusted (signed by unknown key " + r.KeyID + ")"
+	case TrustInvalid:
+		return fmt.Sprintf("invalid signature (%v)", r.Err)
+	default:
+		return r.Status
+	}
+}
+
+// TrustStore holds the trusted publisher keys and the per-registry policy.
+type TrustStore struct {
+	keys     map[string]ed25519.PublicKey
+	policies map[string]string
+}
+
+// NewTrustStore builds a trust store from config. Without explicit policies,
+// registry installs are checked in warn mode and local skills are not checked.
+func NewTrustStore(cfg TrustConfig) (*TrustStore, error) {
+	ts := &TrustStore{
+		keys:     make(map[string]ed25519.PublicKey, len(cfg.Keys)),
+		policies: map[string]string{DefaultPolicyKey: PolicyWarn, LocalSource: PolicyOff},
+	}
+	for id, encoded := range cfg.Keys {
+		raw, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("trusted key %q is not a base64 ed25519 public key", id)
+		}
+		ts.keys[id] = ed25519.PublicKey(raw)
+	}
+	for registry, policy := range cfg.Policies {
+		switch policy {
+		case PolicyRequireSigned, PolicyWarn, PolicyOff:
+			ts.policies[registry] = policy
+		default:
+			return nil, fmt.Errorf("invalid trust policy %q for %q (want %s, %s or %s)",
+				policy, registry, PolicyRequireSigned, PolicyWarn, PolicyOff)
+		}
+	}
+	return ts, nil
+}
+
+// Policy returns the policy that applies to skills from registry.
+func (ts *TrustStore) Policy(registry string) string {
+	if p, ok := ts.policies[registry]; ok {
+		return p
+	}
+	return ts.policies[DefaultPolicyKey]
+}
+
+// Verify checks the signature of the skill in dir against the trust store.
+func (ts *TrustStore) Verify(dir string) TrustResult {
+	data, err := os.ReadFile(filepath.Join(dir, SignatureFileName))
+	if os.IsNotExist(err) {
+		return TrustResult{Status: TrustUnsigned}
+	}
+	if err != nil {
+		return TrustResult{Status: TrustInvalid, Err: err}
+	}
+
+	var sig Signature
+	if err := json.Unmarshal(data, &sig); err != nil {
+		return TrustResult{Status: TrustInvalid, Err: fmt.Errorf("malformed %s: %w", SignatureFileName, err)}
+	}
+	if sig.Algorithm != "ed25519" {
+		return TrustResult{Status: TrustInvalid, KeyID: sig.KeyID,
+			Err: fmt.Errorf("unsupported algorithm %q", sig.Algorithm)}
+	}
+	key, ok := ts.keys[sig.KeyID]
+	if !ok {
+		return TrustResult{Status: TrustUntrusted, KeyID: sig.KeyID}
+	}
+	raw, err := base64.StdEncoding.DecodeString(sig.Signature)
+	if err != nil {
+		return TrustResult{Status: TrustInvalid, KeyID: sig.KeyID, Err: fmt.Errorf("malformed signature: %w", err)}
+	}
+	digest, err := HashSkillDir(dir)
+	if err != nil {
+		return TrustResult{Status: TrustInvalid, KeyID: sig.KeyID, Err: err}
+	}
+	if !ed25519.Verify(key, []byte(signaturePrefix+digest), raw) {
+		return TrustResult{Status: TrustInvalid, KeyID: sig.KeyID, Err: fmt.Errorf("content does not match signature")}
+	}
+	return TrustResult{Status: TrustVerified, KeyID: sig.KeyID}
+}
+
+// Check verifies the skill in dir and applies the policy for registry. It
+// returns an error only when the policy is require-signed and the skill is
+// not signed by a trusted key. With policy off the result is empty.
+func (ts *TrustStore) Check(registry, dir string) (TrustResult, error) {
+	policy := ts.Policy(registry)
+	if policy == PolicyOff {
+		return TrustResult{}, nil
+	}
+
+	result := ts.Verify(dir)
+	if result.Status == TrustVerified {
+		return result, nil
+	}
+	if policy == PolicyRequireSigned {
+		return result, fmt.Errorf("skill %q from %s is not signed by a trusted key: %s",
+			filepath.Base(dir), registry, result)
+	}
+	slog.Warn("skill is not signed by a trusted key", "skill", filepath.Base(dir), "registry", registry,
+		"status", result.String())
+	return result, nil
+}
+
+// SignSkill writes SKILL.sig for the skill in dir.
+func SignSkill(dir, keyID string, key ed25519.PrivateKey) error {
+	digest, err := HashSkillDir(dir)
+	if err != nil {
+		return err
+	}
+	sig := Signature{
+		KeyID:     keyID,
+		Algorithm: "ed25519",
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, []byte(signaturePrefix+digest))),
+	}
+	data, err := json.MarshalIndent(sig, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, SignatureFileName), append(data, '\n'), 0o644)
+}
+
+// GenerateSigningKey returns a new ed25519 key pair, base64 encoded in the
+// formats used by the trust config and ParsePrivateKey.
+func GenerateSigningKey() (publicKey, privateKey string, err error) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.StdEncoding.EncodeToString(pub), base64.StdEncoding.EncodeToString(priv), nil
+}
+
+// ParsePrivateKey decodes a base64 ed25519 private key or 32-byte seed.
+func ParsePrivateKey(encoded string) (ed25519.PrivateKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("private key is not base64: %w", err)
+	}
+	switch len(raw) {
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(raw), nil
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), nil
+	default:
+		return nil, fmt.Errorf("private key has %d bytes, want %d or %d", len(raw), ed25519.PrivateKeySize, ed25519.SeedSize)
+	}
+}
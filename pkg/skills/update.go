@@ -0,0 +1,439 @@
+package skills
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Updater checks, updates and restores workspace skills recorded in the
+// lockfile.
+type Updater struct {
+	workspace  string
+	registries *RegistryManager
+}
+
+// NewUpdater creates an Updater for workspace. registries resolves the
+// registry names recorded in the lockfile.
+func NewUpdater(workspace string, registries *RegistryManager) *Updater {
+	return &Updater{workspace: workspace, registries: registries}
+}
+
+func (u *Updater) trustStore() *TrustStore {
+	if u.registries == nil {
+		return nil
+	}
+	return u.registries.TrustStore()
+}
+
+// OutdatedInfo describes the update state of one locked skill.
+type OutdatedInfo struct {
+	Name     string
+	Current  LockEntry
+	Latest   string // latest version, or the current version if unknown
+	Outdated bool
+}
+
+// Outdated compares each locked skill with its source. Skills without
+// version information (GitHub installs) are downloaded and compared by
+// content hash. An empty names list checks every locked skill.
+func (u *Updater) Outdated(ctx context.Context, names []string) ([]OutdatedInfo, error) {
+	lf, err := LoadLockFile(u.workspace)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		names = lf.Names()
+	}
+
+	infos := make([]OutdatedInfo, 0, len(names))
+	for _, name := range names {
+		entry, ok := lf.Skills[name]
+		if !ok {
+			return nil, fmt.Errorf("skill %q is not in %s", name, LockFileName)
+		}
+
+		info := OutdatedInfo{Name: name, Current: entry, Latest: entry.Version}
+		latest, err := u.latestVersion(ctx, entry)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if latest != "" {
+			info.Latest = latest
+			info.Outdated = latest != entry.Version
+		} else {
+			staged, err := u.Stage(ctx, name, "")
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			info.Outdated = staged.Changed()
+			staged.Discard()
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func (u *Updater) latestVersion(ctx context.Context, entry LockEntry) (string, error) {
+	if entry.Registry == GitHubSource {
+		client := &http.Client{Timeout: 15 * time.Second}
+		return resolveGitHubRef(ctx, client, entry.Slug, githubDefaultRef)
+	}
+	registry, err := u.registry(entry.Registry)
+	if err != nil {
+		return "", err
+	}
+	meta, err := registry.GetSkillMeta(ctx, entry.Slug)
+	if err != nil {
+		return "", err
+	}
+	return meta.LatestVersion, nil
+}
+
+func (u *Updater) registry(name string) (SkillRegistry, error) {
+	if u.registries != nil {
+		if r := u.registries.GetRegistry(name); r != nil {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("registry %q not found or not enabled", name)
+}
+
+// StagedUpdate is a downloaded skill version waiting to replace the
+// installed copy. Call Apply to install it or Discard to throw it away.
+type StagedUpdate struct {
+	Name    string
+	Current LockEntry
+	Next    LockEntry
+	Changes []FileChange
+
+	stageDir  string // temp dir holding the new content
+	workspace string
+}
+
+// Changed reports whether the staged content differs from the locked one.
+func (s *StagedUpdate) Changed() bool {
+	return s.Next.Integrity != s.Current.Integrity
+}
+
+// Stage downloads version (latest if empty) of a locked skill next to the
+// workspace and diffs it against the installed copy.
+func (u *Updater) Stage(ctx context.Context, name, version string) (*StagedUpdate, error) {
+	lf, err := LoadLockFile(u.workspace)
+	if err != nil {
+		return nil, err
+	}
+	entry, ok := lf.Skills[name]
+	if !ok {
+		return nil, fmt.Errorf("skill %q is not in %s", name, LockFileName)
+	}
+
+	next, stageDir, err := u.fetch(ctx, name, entry, version)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := DiffDirs(filepath.Join(u.workspace, "skills", name), filepath.Join(stageDir, name))
+	if err != nil {
+		os.RemoveAll(stageDir)
+		return nil, err
+	}
+
+	return &StagedUpdate{
+		Name:      name,
+		Current:   entry,
+		Next:      next,
+		Changes:   changes,
+		stageDir:  stageDir,
+		workspace: u.workspace,
+	}, nil
+}
+
+// fetch downloads the skill described by entry into a new staging directory
+// and returns the lock entry describing what was downloaded.
+func (u *Updater) fetch(ctx context.Context, name string, entry LockEntry, version string) (LockEntry, string, error) {
+	stageDir, err := os.MkdirTemp(u.workspace, ".skill-stage-*")
+	if err != nil {
+		return LockEntry{}, "", fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	target := filepath.Join(stageDir, name)
+
+	next := LockEntry{Registry: entry.Registry, Slug: entry.Slug}
+	if entry.Registry == GitHubSource {
+		ref := version
+		if ref == "" {
+			ref = githubDefaultRef
+		}
+		next.Version, next.ResolvedURL, err = fetchFromGitHub(ctx, entry.Slug, ref, target, u.trustStore())
+	} else {
+		var registry SkillRegistry
+		registry, err = u.registry(entry.Registry)
+		if err == nil {
+			var result *InstallResult
+			result, err = registry.DownloadAndInstall(ctx, entry.Slug, version, target)
+			if err == nil && result.IsMalwareBlocked {
+				err = fmt.Errorf("skill %q is flagged as malicious", entry.Slug)
+			}
+			if err == nil {
+				next.Version = result.Version
+				next.ResolvedURL = result.ResolvedURL
+			}
+		}
+	}
+	if err == nil {
+		next.Integrity, err = HashSkillDir(target)
+	}
+	if err != nil {
+		os.RemoveAll(stageDir)
+		return LockEntry{}, "", err
+	}
+	return next, stageDir, nil
+}
+
+// Apply replaces the installed skill with the staged copy and updates the
+// lockfile.
+func (s *StagedUpdate) Apply() error {
+	defer s.Discard()
+
+	skillsDir := filepath.Join(s.workspace, "skills")
+	if err := os.MkdirAll(skillsDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create skills directory: %w", err)
+	}
+	if err := replaceDir(filepath.Join(s.stageDir, s.Name), filepath.Join(skillsDir, s.Name)); err != nil {
+		return err
+	}
+
+	lf, err := LoadLockFile(s.workspace)
+	if err != nil {
+		return err
+	}
+	s.Next.InstalledAt = time.Now().UnixMilli()
+	lf.Skills[s.Name] = s.Next
+	return lf.Save(s.workspace)
+}
+
+// Discard removes the staged download.
+func (s *StagedUpdate) Discard() {
+	if s.stageDir != "" {
+		os.RemoveAll(s.stageDir)
+	}
+}
+
+// replaceDir moves src to dst, keeping the old dst until the move succeeded.
+func replaceDir(src, dst string) error {
+	backup := dst + ".old"
+	os.RemoveAll(backup)
+
+	hadOld := false
+	if _, err := os.Stat(dst); err == nil {
+		if err := os.Rename(dst, backup); err != nil {
+			return fmt.Errorf("failed to move old skill aside: %w", err)
+		}
+		hadOld = true
+	}
+	if err := os.Rename(src, dst); err != nil {
+		if hadOld {
+			os.Rename(backup, dst)
+		}
+		return fmt.Errorf("failed to install skill: %w", err)
+	}
+	if hadOld {
+		os.RemoveAll(backup)
+	}
+	return nil
+}
+
+// SyncResult reports what Sync did for one skill.
+type SyncResult struct {
+	Name   string
+	Action string // "ok", "installed", "removed"
+	Err    error
+}
+
+// Sync makes the workspace skills match the lockfile: missing or modified
+// skills are reinstalled at their locked version and must match the locked
+// integrity hash. With prune, workspace skills not in the lockfile are removed.
+func (u *Updater) Sync(ctx context.Context, prune bool) ([]SyncResult, error) {
+	lf, err := LoadLockFile(u.workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	skillsDir := filepath.Join(u.workspace, "skills")
+	var results []SyncResult
+	for _, name := range lf.Names() {
+		entry := lf.Skills[name]
+		dir := filepath.Join(skillsDir, name)
+
+		if ok, _ := VerifySkill(dir, entry); ok {
+			results = append(results, SyncResult{Name: name, Action: "ok"})
+			continue
+		}
+
+		results = append(results, SyncResult{Name: name, Action: "installed", Err: u.syncOne(ctx, name, entry)})
+	}
+
+	if prune {
+		dirs, _ := os.ReadDir(skillsDir)
+		for _, d := range dirs {
+			if !d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+				continue
+			}
+			if _, ok := lf.Skills[d.Name()]; ok {
+				continue
+			}
+			results = append(results, SyncResult{
+				Name:   d.Name(),
+				Action: "removed",
+				Err:    os.RemoveAll(filepath.Join(skillsDir, d.Name())),
+			})
+		}
+	}
+
+	return results, nil
+}
+
+func (u *Updater) syncOne(ctx context.Context, name string, entry LockEntry) error {
+	next, stageDir, err := u.fetch(ctx, name, entry, entry.Version)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stageDir)
+
+	if next.Integrity != entry.Integrity {
+		return fmt.Errorf("integrity mismatch for %s@%s: locked %s, downloaded %s",
+			entry.Slug, entry.Version, entry.Integrity, next.Integrity)
+	}
+	if err := os.MkdirAll(filepath.Join(u.workspace, "skills"), 0o755); err != nil {
+		return fmt.Errorf("failed to create skills directory: %w", err)
+	}
+	return replaceDir(filepath.Join(stageDir, name), filepath.Join(u.workspace, "skills", name))
+}
+
+// FileChange is one file-level difference between two skill versions.
+type FileChange struct {
+	Path string
+	Kind string // "added", "removed", "modified"
+	Diff string // line diff for modified text files
+}
+
+// DiffDirs compares two skill directories file by file.
+func DiffDirs(oldDir, newDir string) ([]FileChange, error) {
+	oldFiles, err := listFiles(oldDir)
+	if err != nil {
+		return nil, err
+	}
+	newFiles, err := listFiles(newDir)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]bool)
+	for p := range oldFiles {
+		paths[p] = true
+	}
+	for p := range newFiles {
+		paths[p] = true
+	}
+	sorted := make([]string, 0, len(paths))
+	for p := range paths {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+
+	var changes []FileChange
+	for _, p := range sorted {
+		oldData, inOld := oldFiles[p]
+		newData, inNew := newFiles[p]
+		switch {
+		case !inOld:
+			changes = append(changes, FileChange{Path: p, Kind: "added"})
+		case !inNew:
+			changes = append(changes, FileChange{Path: p, Kind: "removed"})
+		case oldData != newData:
+			changes = append(changes, FileChange{Path: p, Kind: "modified", Diff: lineDiff(oldData, newData)})
+		}
+	}
+	return changes, nil
+}
+
+// listFiles reads every regular file under dir, keyed by slash path. A
+// missing dir is treated as empty.
+func listFiles(dir string) (map[string]string, error) {
+	files := make(map[string]string)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return files, nil
+	}
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() || d.Name() == originFileName {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	return files, err
+}
+
+const maxDiffLines = 2000
+
+// lineDiff returns the changed lines between two texts as "-"/"+" lines,
+// based on their longest common subsequence. Binary or very large files are
+// only reported as changed.
+func lineDiff(oldText, newText string) string {
+	if strings.ContainsRune(oldText, 0) || strings.ContainsRune(newText, 0) {
+		return "binary file changed"
+	}
+	a := strings.Split(strings.TrimSuffix(oldText, "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(newText, "\n"), "\n")
+	if len(a) > maxDiffLines || len(b) > maxDiffLines {
+		return fmt.Sprintf("%d lines -> %d lines", len(a), len(b))
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var sb strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			sb.WriteString("- " + a[i] + "\n")
+			i++
+		default:
+			sb.WriteString("+ " + b[j] + "\n")
+			j++
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,138 @@
+package skills
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// versionedRegistry serves fixed file sets per version.
+type versionedRegistry struct {
+	latest   string
+	versions map[string]map[string]string
+}
+
+func (r *versionedRegistry) Name() string { return "test" }
+
+func (r *versionedRegistry) Search(context.Context, string, int) ([]SearchResult, error) {
+	return nil, nil
+}
+
+func (r *versionedRegistry) GetSkillMeta(_ context.Context, slug string) (*SkillMeta, error) {
+	return &SkillMeta{Slug: slug, LatestVersion: r.latest}, nil
+}
+
+func (r *versionedRegistry) DownloadAndInstall(_ context.Context, slug, version, targetDir string) (*InstallResult, error) {
+	if version == "" {
+		version = r.latest
+	}
+	files, ok := r.versions[version]
+	if !ok {
+		return nil, fmt.Errorf("version %s not found", version)
+	}
+	for name, content := range files {
+		path := filepath.Join(targetDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return nil, err
+		}
+	}
+	return &InstallResult{Version: version, ResolvedURL: "https://test/" + slug + "@" + version}, nil
+}
+
+func setupLockedSkill(t *testing.T) (string, *versionedRegistry, *Updater) {
+	t.Helper()
+	reg := &versionedRegistry{
+		latest: "1.0.0",
+		versions: map[string]map[string]string{
+			"1.0.0": {"SKILL.md": "# Weather\nuse wttr.in\n"},
+			"1.1.0": {"SKILL.md": "# Weather\nuse open-meteo\n", "forecast.sh": "curl open-meteo"},
+		},
+	}
+	rm := NewRegistryManager()
+	rm.AddRegistry(reg)
+
+	workspace := t.TempDir()
+	_, err := reg.DownloadAndInstall(context.Background(), "weather", "1.0.0", filepath.Join(workspace, "skills", "weather"))
+	require.NoError(t, err)
+	require.NoError(t, RecordInstall(workspace, "weather", LockEntry{Registry: "test", Slug: "weather", Version: "1.0.0"}))
+
+	return workspace, reg, NewUpdater(workspace, rm)
+}
+
+func TestUpdaterOutdatedAndUpdate(t *testing.T) {
+	workspace, reg, updater := setupLockedSkill(t)
+	ctx := context.Background()
+
+	infos, err := updater.Outdated(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	assert.False(t, infos[0].Outdated)
+
+	reg.latest = "1.1.0"
+	infos, err = updater.Outdated(ctx, nil)
+	require.NoError(t, err)
+	assert.True(t, infos[0].Outdated)
+	assert.Equal(t, "1.1.0", infos[0].Latest)
+
+	staged, err := updater.Stage(ctx, "weather", "")
+	require.NoError(t, err)
+	assert.True(t, staged.Changed())
+	require.Len(t, staged.Changes, 2)
+	assert.Equal(t, FileChange{
+		Path: "SKILL.md",
+		Kind: "modified",
+		Diff: "- use wttr.in\n+ use open-meteo\n",
+	}, staged.Changes[0])
+	assert.Equal(t, FileChange{Path: "forecast.sh", Kind: "added"}, staged.Changes[1])
+
+	require.NoError(t, staged.Apply())
+
+	lf, err := LoadLockFile(workspace)
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", lf.Skills["weather"].Version)
+	assert.FileExists(t, filepath.Join(workspace, "skills", "weather", "forecast.sh"))
+
+	// No staging directories are left behind.
+	leftovers, _ := filepath.Glob(filepath.Join(workspace, ".skill-stage-*"))
+	assert.Empty(t, leftovers)
+}
+
+func TestUpdaterSync(t *testing.T) {
+	workspace, reg, updater := setupLockedSkill(t)
+	ctx := context.Background()
+
+	// Unlocked skill, and the locked one deleted.
+	writeSkillFiles(t, filepath.Join(workspace, "skills", "stray"), map[string]string{"SKILL.md": "# stray"})
+	require.NoError(t, os.RemoveAll(filepath.Join(workspace, "skills", "weather")))
+
+	// The registry has moved on; sync must still install the locked version.
+	reg.latest = "1.1.0"
+	results, err := updater.Sync(ctx, true)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, SyncResult{Name: "weather", Action: "installed"}, results[0])
+	assert.Equal(t, SyncResult{Name: "stray", Action: "removed"}, results[1])
+	assert.NoFileExists(t, filepath.Join(workspace, "skills", "weather", "forecast.sh"))
+	assert.NoDirExists(t, filepath.Join(workspace, "skills", "stray"))
+
+	results, err = updater.Sync(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, []SyncResult{{Name: "weather", Action: "ok"}}, results)
+
+	// Upstream republished the locked version with different content.
+	require.NoError(t, os.RemoveAll(filepath.Join(workspace, "skills", "weather")))
+	reg.versions["1.0.0"] = map[string]string{"SKILL.md": "# tampered"}
+	results, err = updater.Sync(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.ErrorContains(t, results[0].Err, "integrity mismatch")
+	assert.NoDirExists(t, filepath.Join(workspace, "skills", "weather"))
+}
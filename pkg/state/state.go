@@ -21,6 +21,20 @@ type State struct {
 
 	// Timestamp is the last time this state was updated
 	Timestamp time.Time `json:"timestamp"`
+
+	// HeartbeatTasks records when each structured heartbeat task last ran,
+	// keyed by task name.
+	HeartbeatTasks map[string]HeartbeatTaskState `json:"heartbeat_tasks,omitempty"`
+}
+
+// HeartbeatTaskState is the persisted state of a structured heartbeat task.
+type HeartbeatTaskState struct {
+	// LastRun is when the task was last sent to the agent
+	LastRun time.Time `json:"last_run,omitempty"`
+
+	// Fingerprints are the contents of watched paths as of the last run,
+	// keyed by path
+	Fingerprints map[string]string `json:"fingerprints,omitempty"`
 }
 
 // Manager manages persistent state with atomic saves.
@@ -114,6 +128,67 @@ func (sm *Manager) GetLastChatID() string {
 	return sm.state.LastChatID
 }
 
+// GetHeartbeatTask returns the state of the named heartbeat task.
+func (sm *Manager) GetHeartbeatTask(name string) (HeartbeatTaskState, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	st, ok := sm.state.HeartbeatTasks[name]
+	if st.Fingerprints != nil {
+		fingerprints := make(map[string]string, len(st.Fingerprints))
+		for path, fp := range st.Fingerprints {
+			fingerprints[path] = fp
+		}
+		st.Fingerprints = fingerprints
+	}
+	return st, ok
+}
+
+// SetHeartbeatTask atomically updates the state of the named heartbeat task
+// and saves the state.
+func (sm *Manager) SetHeartbeatTask(name string, st HeartbeatTaskState) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.state.HeartbeatTasks == nil {
+		sm.state.HeartbeatTasks = make(map[string]HeartbeatTaskState)
+	}
+	sm.state.HeartbeatTasks[name] = st
+	sm.state.Timestamp = time.Now()
+
+	if err := sm.saveAtomic(); err != nil {
+		return fmt.Errorf("failed to save state atomically: %w", err)
+	}
+
+	return nil
+}
+
+// PruneHeartbeatTasks drops the state of heartbeat tasks not in names.
+func (sm *Manager) PruneHeartbeatTasks(names []string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	keep := make(map[string]bool, len(names))
+	for _, name := range names {
+		keep[name] = true
+	}
+	changed := false
+	for name := range sm.state.HeartbeatTasks {
+		if !keep[name] {
+			delete(sm.state.HeartbeatTasks, name)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	if err := sm.saveAtomic(); err != nil {
+		return fmt.Errorf("failed to save state atomically: %w", err)
+	}
+
+	return nil
+}
+
 // GetTimestamp returns the timestamp of the last state update.
 func (sm *Manager) GetTimestamp() time.Time {
 	sm.mu.RLock()
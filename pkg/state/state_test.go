@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestAtomicSave(t *testing.T) {
@@ -214,3 +215,39 @@ func TestNewManager_EmptyWorkspace(t *testing.T) {
 		t.Error("Expected zero timestamp for new state")
 	}
 }
+
+func TestHeartbeatTaskState(t *testing.T) {
+	tmpDir := t.TempDir()
+	sm := NewManager(tmpDir)
+
+	if _, ok := sm.GetHeartbeatTask("disk"); ok {
+		t.Fatal("Expected no state for unknown task")
+	}
+
+	lastRun := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	err := sm.SetHeartbeatTask("disk", HeartbeatTaskState{
+		LastRun:      lastRun,
+		Fingerprints: map[string]string{"notes.md": "abc"},
+	})
+	if err != nil {
+		t.Fatalf("SetHeartbeatTask failed: %v", err)
+	}
+	sm.SetHeartbeatTask("backup", HeartbeatTaskState{LastRun: lastRun})
+
+	// Returned state is a copy.
+	st, _ := sm.GetHeartbeatTask("disk")
+	st.Fingerprints["notes.md"] = "changed"
+
+	sm2 := NewManager(tmpDir)
+	st, ok := sm2.GetHeartbeatTask("disk")
+	if !ok || !st.LastRun.Equal(lastRun) || st.Fingerprints["notes.md"] != "abc" {
+		t.Errorf("Expected persisted task state, got %+v (ok=%v)", st, ok)
+	}
+
+	if err := sm2.PruneHeartbeatTasks([]string{"disk"}); err != nil {
+		t.Fatalf("PruneHeartbeatTasks failed: %v", err)
+	}
+	if _, ok := NewManager(tmpDir).GetHeartbeatTask("backup"); ok {
+		t.Error("Expected pruned task state to be removed")
+	}
+}
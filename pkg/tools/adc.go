@@ -0,0 +1,219 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+const maxADCSamples = 100
+
+var (
+	iioDevicePattern  = regexp.MustCompile(`^iio:device\d+$`)
+	iioChannelPattern = regexp.MustCompile(`^[a-z]+\d*$`)
+)
+
+// iioUnits are the units of scaled IIO readings, per channel type.
+var iioUnits = map[string]string{
+	"voltage":          "mV",
+	"current":          "mA",
+	"temp":             "m°C",
+	"humidityrelative": "m%RH",
+	"pressure":         "kPa",
+	"illuminance":      "lux",
+}
+
+// ADCTool reads analog inputs through the Linux IIO subsystem
+// (/sys/bus/iio/devices). Only channels in the configured allowlist can be
+// read.
+type ADCTool struct {
+	channels []config.ADCToolChannel
+	root     string
+}
+
+func NewADCTool(cfg config.ADCToolConfig) *ADCTool {
+	return &ADCTool{channels: cfg.Channels, root: "/sys/bus/iio/devices"}
+}
+
+func (t *ADCTool) Name() string {
+	return "adc"
+}
+
+func (t *ADCTool) Description() string {
+	return "Read allowed analog inputs (ADC voltages, temperature and other IIO sensors). Actions: list (allowed channels), read (raw and scaled value, optionally averaged over several samples). Linux only."
+}
+
+func (t *ADCTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"list", "read"},
+				"description": "Action to perform: list (show allowed channels), read (read a channel)",
+			},
+			"name": map[string]any{
+				"type":        "string",
+				"description": "Configured channel name. Alternative to device + channel.",
+			},
+			"device": map[string]any{
+				"type":        "string",
+				"description": "IIO device (e.g. \"iio:device0\").",
+			},
+			"channel": map[string]any{
+				"type":        "string",
+				"description": "IIO channel (e.g. \"voltage0\" for in_voltage0_raw).",
+			},
+			"samples": map[string]any{
+				"type":        "integer",
+				"description": "Number of samples to average (1-100). Default: 1.",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *ADCTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, ok := args["action"].(string)
+	if !ok {
+		return ErrorResult("action is required")
+	}
+
+	switch action {
+	case "list":
+		result, _ := json.MarshalIndent(t.channels, "", "  ")
+		return SilentResult(fmt.Sprintf("Allowed ADC channels:\n%s", string(result)))
+	case "read":
+		return t.read(ctx, args)
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action: %s (valid: list, read)", action))
+	}
+}
+
+func (t *ADCTool) read(ctx context.Context, args map[string]any) *ToolResult {
+	ch, errResult := t.resolveChannel(args)
+	if errResult != nil {
+		return errResult
+	}
+
+	samples := 1
+	if s, ok := args["samples"].(float64); ok {
+		samples = int(s)
+	}
+	if samples < 1 || samples > maxADCSamples {
+		return ErrorResult(fmt.Sprintf("samples must be between 1 and %d", maxADCSamples))
+	}
+
+	dir := filepath.Join(t.root, ch.Device)
+	prefix := "in_" + ch.Channel
+	typ := strings.TrimRight(ch.Channel, "0123456789")
+
+	var sum float64
+	for i := 0; i < samples; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return ErrorResult("read cancelled")
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+		raw, err := readSysfsAttr(dir, prefix+"_raw")
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("failed to read %s %s: %v", ch.Device, ch.Channel, err))
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("unexpected raw value %q from %s %s", raw, ch.Device, ch.Channel))
+		}
+		sum += v
+	}
+	raw := sum / float64(samples)
+
+	// Scale and offset are per channel or shared by all channels of a type.
+	scale, hasScale := iioAttr(dir, prefix+"_scale", "in_"+typ+"_scale")
+	offset, _ := iioAttr(dir, prefix+"_offset", "in_"+typ+"_offset")
+
+	fields := map[string]any{
+		"device":  ch.Device,
+		"channel": ch.Channel,
+		"raw":     raw,
+		"samples": samples,
+	}
+	if ch.Name != "" {
+		fields["name"] = ch.Name
+	}
+	if deviceName, err := readSysfsAttr(dir, "name"); err == nil {
+		fields["device_name"] = deviceName
+	}
+	if hasScale {
+		value := (raw + offset) * scale
+		fields["scale"] = scale
+		fields["offset"] = offset
+		fields["value"] = math.Round(value*1000) / 1000
+		if unit, ok := iioUnits[typ]; ok {
+			fields["unit"] = unit
+		}
+	}
+	result, _ := json.MarshalIndent(fields, "", "  ")
+	return SilentResult(string(result))
+}
+
+// iioAttr reads the first of names that exists as a number.
+func iioAttr(dir string, names ...string) (float64, bool) {
+	for _, name := range names {
+		value, err := readSysfsAttr(dir, name)
+		if err != nil {
+			continue
+		}
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f, true
+		}
+	}
+	return 0, false
+}
+
+func (t *ADCTool) resolveChannel(args map[string]any) (config.ADCToolChannel, *ToolResult) {
+	var found *config.ADCToolChannel
+	if name, ok := args["name"].(string); ok && name != "" {
+		for i := range t.channels {
+			if t.channels[i].Name == name {
+				found = &t.channels[i]
+				break
+			}
+		}
+		if found == nil {
+			return config.ADCToolChannel{}, ErrorResult(fmt.Sprintf("no allowed ADC channel named %q", name))
+		}
+	} else {
+		device, _ := args["device"].(string)
+		channel, _ := args["channel"].(string)
+		if device == "" || channel == "" {
+			return config.ADCToolChannel{}, ErrorResult("name, or device and channel, is required")
+		}
+		for i := range t.channels {
+			if t.channels[i].Device == device && t.channels[i].Channel == channel {
+				found = &t.channels[i]
+				break
+			}
+		}
+		if found == nil {
+			return config.ADCToolChannel{}, ErrorResult(fmt.Sprintf(
+				"%s %s is not in the allowed ADC channels (tools.hardware.adc.channels)", device, channel))
+		}
+	}
+
+	// Config values end up in file paths.
+	if !iioDevicePattern.MatchString(found.Device) || !iioChannelPattern.MatchString(found.Channel) {
+		return config.ADCToolChannel{}, ErrorResult(
+			fmt.Sprintf("invalid ADC channel config: device %q, channel %q", found.Device, found.Channel))
+	}
+	return *found, nil
+}
@@ -0,0 +1,89 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func newFakeIIOTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"iio:device0/name":             "ads1015",
+		"iio:device0/in_voltage0_raw":  "1000",
+		"iio:device0/in_voltage_scale": "0.5",
+		"iio:device1/in_temp_raw":      "300",
+		"iio:device1/in_temp_offset":   "-100",
+		"iio:device1/in_temp_scale":    "125",
+		"iio:device2/in_voltage1_raw":  "42",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte(content+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func newTestADCTool(t *testing.T) *ADCTool {
+	tool := NewADCTool(config.ADCToolConfig{Channels: []config.ADCToolChannel{
+		{Name: "battery", Device: "iio:device0", Channel: "voltage0"},
+		{Name: "board-temp", Device: "iio:device1", Channel: "temp"},
+		{Name: "unscaled", Device: "iio:device2", Channel: "voltage1"},
+		{Name: "bad", Device: "../../etc", Channel: "voltage0"},
+	}})
+	tool.root = newFakeIIOTree(t)
+	return tool
+}
+
+func TestADCTool_Read(t *testing.T) {
+	tool := newTestADCTool(t)
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{"action": "read", "name": "battery", "samples": float64(3)})
+	if result.IsError {
+		t.Fatalf("read = %+v", result)
+	}
+	for _, want := range []string{`"value": 500`, `"unit": "mV"`, `"device_name": "ads1015"`, `"samples": 3`} {
+		if !strings.Contains(result.ForLLM, want) {
+			t.Errorf("result missing %s:\n%s", want, result.ForLLM)
+		}
+	}
+
+	// (raw + offset) * scale
+	result = tool.Execute(ctx, map[string]any{"action": "read", "device": "iio:device1", "channel": "temp"})
+	if result.IsError || !strings.Contains(result.ForLLM, `"value": 25000`) {
+		t.Errorf("temp read = %+v", result)
+	}
+
+	// Without a scale only the raw value is reported.
+	result = tool.Execute(ctx, map[string]any{"action": "read", "name": "unscaled"})
+	if result.IsError || strings.Contains(result.ForLLM, `"value"`) || !strings.Contains(result.ForLLM, `"raw": 42`) {
+		t.Errorf("unscaled read = %+v", result)
+	}
+}
+
+func TestADCTool_Allowlist(t *testing.T) {
+	tool := newTestADCTool(t)
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{"action": "read", "device": "iio:device2", "channel": "voltage0"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "not in the allowed ADC channels") {
+		t.Errorf("expected allowlist error, got %+v", result)
+	}
+	result = tool.Execute(ctx, map[string]any{"action": "read", "name": "bad"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "invalid ADC channel config") {
+		t.Errorf("expected config error, got %+v", result)
+	}
+	result = tool.Execute(ctx, map[string]any{"action": "read", "name": "battery", "samples": float64(500)})
+	if !result.IsError {
+		t.Error("expected samples error")
+	}
+}
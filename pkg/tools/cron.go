@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/sipeed/picoclaw/pkg/access"
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/cron"
@@ -26,6 +27,7 @@ type CronTool struct {
 	executor    JobExecutor
 	msgBus      *bus.MessageBus
 	execTool    *ExecTool
+	access      *access.Controller
 	channel     string
 	chatID      string
 	mu          sync.RWMutex
@@ -64,7 +66,7 @@ func (t *CronTool) Parameters() map[string]any {
 		"properties": map[string]any{
 			"action": map[string]any{
 				"type":        "string",
-				"enum":        []string{"add", "list", "remove", "enable", "disable"},
+				"enum":        []string{"add", "list", "remove", "enable", "disable", "history"},
 				"description": "Action to perform. Use 'add' when user wants to schedule a reminder or task.",
 			},
 			"message": map[string]any{
@@ -87,9 +89,47 @@ func (t *CronTool) Parameters() map[string]any {
 				"type":        "string",
 				"description": "Cron expression for complex recurring schedules (e.g., '0 9 * * *' for daily at 9am). Use this for complex recurring schedules.",
 			},
+			"tz": map[string]any{
+				"type":        "string",
+				"description": "Optional IANA time zone for cron_expr, e.g. 'Asia/Shanghai'. Defaults to the host's local time zone. Use the user's time zone when they give wall-clock times.",
+			},
+			"misfire": map[string]any{
+				"type":        "string",
+				"enum":        []string{cron.MisfireSkip, cron.MisfireRunOnce, cron.MisfireRunAll},
+				"description": "Optional: what to do with recurring runs missed while the service was down. 'skip' (default) drops them, 'run_once' runs once on startup, 'run_all' replays each missed run up to misfire_max_runs.",
+			},
+			"misfire_max_runs": map[string]any{
+				"type":        "integer",
+				"description": "Optional: cap on replayed runs for misfire='run_all' (default 10).",
+			},
+			"misfire_grace_seconds": map[string]any{
+				"type":        "integer",
+				"description": "Optional: a missed run this many seconds old or newer still runs once on startup, whatever the misfire policy.",
+			},
+			"retries": map[string]any{
+				"type":        "integer",
+				"description": "Optional: how many times to retry a failed run (default 0).",
+			},
+			"retry_backoff_seconds": map[string]any{
+				"type":        "integer",
+				"description": "Optional: wait before the first retry, doubled for each further retry (default 60).",
+			},
+			"notify_on_failure": map[string]any{
+				"type":        "boolean",
+				"description": "Optional: report to this chat when a run fails and no retries are left.",
+			},
+			"concurrency": map[string]any{
+				"type":        "string",
+				"enum":        []string{cron.ConcurrencyForbid, cron.ConcurrencyAllow, cron.ConcurrencyReplace},
+				"description": "Optional: what to do when the job comes due while its previous run is still going. 'forbid' (default) skips the new run, 'allow' runs both, 'replace' cancels the old run.",
+			},
+			"max_runtime_seconds": map[string]any{
+				"type":        "integer",
+				"description": "Optional: cancel a run that takes longer than this and record it as failed.",
+			},
 			"job_id": map[string]any{
 				"type":        "string",
-				"description": "Job ID (for remove/enable/disable)",
+				"description": "Job ID (for remove/enable/disable/history)",
 			},
 			"deliver": map[string]any{
 				"type":        "boolean",
@@ -100,6 +140,12 @@ func (t *CronTool) Parameters() map[string]any {
 	}
 }
 
+// SetAccess sets the roles that jobs created from chats run under. Without
+// it, every job runs with full access.
+func (t *CronTool) SetAccess(c *access.Controller) {
+	t.access = c
+}
+
 // SetContext sets the current session context for job creation
 func (t *CronTool) SetContext(channel, chatID string) {
 	t.mu.Lock()
@@ -117,7 +163,7 @@ func (t *CronTool) Execute(ctx context.Context, args map[string]any) *ToolResult
 
 	switch action {
 	case "add":
-		return t.addJob(args)
+		return t.addJob(ctx, args)
 	case "list":
 		return t.listJobs()
 	case "remove":
@@ -126,12 +172,14 @@ func (t *CronTool) Execute(ctx context.Context, args map[string]any) *ToolResult
 		return t.enableJob(args, true)
 	case "disable":
 		return t.enableJob(args, false)
+	case "history":
+		return t.jobHistory(args)
 	default:
 		return ErrorResult(fmt.Sprintf("unknown action: %s", action))
 	}
 }
 
-func (t *CronTool) addJob(args map[string]any) *ToolResult {
+func (t *CronTool) addJob(ctx context.Context, args map[string]any) *ToolResult {
 	t.mu.RLock()
 	channel := t.channel
 	chatID := t.chatID
@@ -175,6 +223,18 @@ func (t *CronTool) addJob(args map[string]any) *ToolResult {
 		return ErrorResult("one of at_seconds, every_seconds, or cron_expr is required")
 	}
 
+	schedule.TZ, _ = args["tz"].(string)
+	policy, _ := args["misfire"].(string)
+	maxRuns, _ := args["misfire_max_runs"].(float64)
+	graceSeconds, _ := args["misfire_grace_seconds"].(float64)
+	if policy != "" || maxRuns != 0 || graceSeconds != 0 {
+		schedule.Misfire = &cron.CronMisfire{
+			Policy:  policy,
+			MaxRuns: int(maxRuns),
+			GraceMS: int64(graceSeconds) * 1000,
+		}
+	}
+
 	// Read deliver parameter, default to true
 	deliver := true
 	if d, ok := args["deliver"].(bool); ok {
@@ -190,10 +250,21 @@ func (t *CronTool) addJob(args map[string]any) *ToolResult {
 		deliver = false
 	}
 
+	// Jobs created by a sender with a role run under that role, so the
+	// cron tool can't be used to get around it.
+	var createdBy *cron.CronSender
+	if p, ok := GuardFrom(ctx).(*access.Principal); ok && p != nil {
+		if command != "" && !p.Permits("exec") {
+			return ErrorResult(fmt.Sprintf("permission denied: the %s role may not schedule commands", p.Role))
+		}
+		createdBy = &cron.CronSender{Channel: p.Channel, SenderID: p.SenderID}
+	}
+
 	// Truncate message for job name (max 30 chars)
 	messagePreview := utils.Truncate(message, 30)
 
-	job, err := t.cronService.AddJob(
+	job, err := t.cronService.AddJobAs(
+		createdBy,
 		messagePreview,
 		schedule,
 		message,
@@ -205,10 +276,27 @@ func (t *CronTool) addJob(args map[string]any) *ToolResult {
 		return ErrorResult(fmt.Sprintf("Error adding job: %v", err))
 	}
 
-	if command != "" {
+	retries, _ := args["retries"].(float64)
+	backoffSeconds, _ := args["retry_backoff_seconds"].(float64)
+	notify, _ := args["notify_on_failure"].(bool)
+	concurrency, _ := args["concurrency"].(string)
+	maxRuntime, _ := args["max_runtime_seconds"].(float64)
+
+	if command != "" || retries > 0 || notify || concurrency != "" || maxRuntime > 0 {
 		job.Payload.Command = command
-		// Need to save the updated payload
-		t.cronService.UpdateJob(job)
+		job.Concurrency = concurrency
+		job.MaxRuntimeMS = int64(maxRuntime) * 1000
+		if retries > 0 {
+			job.Retry = &cron.CronRetry{MaxRetries: int(retries), BackoffMS: int64(backoffSeconds) * 1000}
+		}
+		if notify {
+			job.Notify = &cron.CronNotify{Channel: channel, To: chatID}
+		}
+		// Need to save the updated job
+		if err := t.cronService.UpdateJob(job); err != nil {
+			t.cronService.RemoveJob(job.ID)
+			return ErrorResult(fmt.Sprintf("Error adding job: %v", err))
+		}
 	}
 
 	return SilentResult(fmt.Sprintf("Cron job added: %s (id: %s)", job.Name, job.ID))
@@ -221,6 +309,11 @@ func (t *CronTool) listJobs() *ToolResult {
 		return SilentResult("No scheduled jobs")
 	}
 
+	running := make(map[string]cron.RunningJob)
+	for _, r := range t.cronService.Running() {
+		running[r.JobID] = r
+	}
+
 	result := "Scheduled jobs:\n"
 	for _, j := range jobs {
 		var scheduleInfo string
@@ -228,11 +321,24 @@ func (t *CronTool) listJobs() *ToolResult {
 			scheduleInfo = fmt.Sprintf("every %ds", *j.Schedule.EveryMS/1000)
 		} else if j.Schedule.Kind == "cron" {
 			scheduleInfo = j.Schedule.Expr
+			if j.Schedule.TZ != "" {
+				scheduleInfo += " (" + j.Schedule.TZ + ")"
+			}
 		} else if j.Schedule.Kind == "at" {
 			scheduleInfo = "one-time"
 		} else {
 			scheduleInfo = "unknown"
 		}
+		if j.State.LastStatus != "" {
+			scheduleInfo += ", last run: " + j.State.LastStatus
+		}
+		if r, ok := running[j.ID]; ok {
+			if r.Queued {
+				scheduleInfo += ", waiting for a worker"
+			} else {
+				scheduleInfo += ", running since " + time.UnixMilli(r.StartedAtMS).Format(time.Kitchen)
+			}
+		}
 		result += fmt.Sprintf("- %s (id: %s, %s)\n", j.Name, j.ID, scheduleInfo)
 	}
 
@@ -269,8 +375,50 @@ func (t *CronTool) enableJob(args map[string]any, enable bool) *ToolResult {
 	return SilentResult(fmt.Sprintf("Cron job '%s' %s", job.Name, status))
 }
 
-// ExecuteJob executes a cron job through the agent
-func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
+func (t *CronTool) jobHistory(args map[string]any) *ToolResult {
+	jobID, ok := args["job_id"].(string)
+	if !ok || jobID == "" {
+		return ErrorResult("job_id is required for history")
+	}
+
+	runs, found := t.cronService.History(jobID)
+	if !found {
+		return ErrorResult(fmt.Sprintf("Job %s not found", jobID))
+	}
+	if len(runs) == 0 {
+		return SilentResult(fmt.Sprintf("Job %s has not run yet", jobID))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Runs of job %s (oldest first):\n", jobID)
+	for _, r := range runs {
+		fmt.Fprintf(&sb, "- %s %s in %dms (attempt %d)",
+			time.UnixMilli(r.StartedAtMS).Format(time.RFC3339), r.Status, r.DurationMS, r.Attempt)
+		if r.Error != "" {
+			fmt.Fprintf(&sb, ": %s", r.Error)
+		}
+		sb.WriteString("\n")
+	}
+	return SilentResult(sb.String())
+}
+
+// NotifyFailure reports a job whose retries are exhausted to its notify
+// target.
+func (t *CronTool) NotifyFailure(job *cron.CronJob, run cron.CronRun) {
+	if job.Notify == nil {
+		return
+	}
+	t.msgBus.PublishOutbound(bus.OutboundMessage{
+		Channel: job.Notify.Channel,
+		ChatID:  job.Notify.To,
+		Content: fmt.Sprintf("⚠️ Scheduled job '%s' (%s) failed after %d attempt(s): %s",
+			job.Name, job.ID, run.Attempt, run.Error),
+	})
+}
+
+// ExecuteJob executes a cron job through the agent. The returned output
+// and error are recorded in the job's run history.
+func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) (string, error) {
 	// Get channel/chatID from job payload
 	channel := job.Payload.Channel
 	chatID := job.Payload.To
@@ -283,15 +431,35 @@ func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
 		chatID = "direct"
 	}
 
+	// Run as the job's creator, whose role is resolved again for every run.
+	// Jobs set up by the device owner have no creator and full access.
+	var principal *access.Principal
+	if job.CreatedBy != nil {
+		principal = t.access.Resolve(job.CreatedBy.Channel, job.CreatedBy.SenderID)
+		ctx = access.WithSender(ctx, job.CreatedBy.Channel, job.CreatedBy.SenderID)
+	} else {
+		ctx = access.Trusted(ctx)
+	}
+
 	// Execute command if present
 	if job.Payload.Command != "" {
+		if err := principal.AllowTool("exec"); err != nil {
+			t.msgBus.PublishOutbound(bus.OutboundMessage{
+				Channel: channel,
+				ChatID:  chatID,
+				Content: fmt.Sprintf("Scheduled command not run: %v", err),
+			})
+			return "", fmt.Errorf("permission denied: %w", err)
+		}
+
 		args := map[string]any{
 			"command": job.Payload.Command,
 		}
 
 		result := t.execTool.Execute(ctx, args)
 		if shouldSuppressScheduledCommandErrorForFeishu(channel, result) {
-			return "ok"
+			// Don't post the error to the chat, but keep it in the history.
+			return "", fmt.Errorf("command failed: %s", result.ForLLM)
 		}
 		var output string
 		if result.IsError {
@@ -302,7 +470,10 @@ func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
 			ChatID:  chatID,
 			Content: output,
 		})
-		return "ok"
+		if result.IsError {
+			return "", fmt.Errorf("command failed: %s", result.ForLLM)
+		}
+		return result.ForLLM, nil
 	}
 
 	// If deliver=true, send message directly without agent processing
@@ -312,27 +483,18 @@ func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
 			ChatID:  chatID,
 			Content: job.Payload.Message,
 		})
-		return "ok"
+		return job.Payload.Message, nil
 	}
 
-	// For deliver=false, process through agent (for complex tasks)
-	sessionKey := fmt.Sprintf("cron-%s", job.ID)
-
-	// Call agent with job's message
-	response, err := t.executor.ProcessDirectWithChannel(
+	// For deliver=false, process through agent (for complex tasks).
+	// The response is sent via MessageBus by AgentLoop.
+	return t.executor.ProcessDirectWithChannel(
 		ctx,
 		job.Payload.Message,
-		sessionKey,
+		t.cronService.SessionKey(job),
 		channel,
 		chatID,
 	)
-	if err != nil {
-		return fmt.Sprintf("Error: %v", err)
-	}
-
-	// Response is automatically sent via MessageBus by AgentLoop
-	_ = response // Will be sent by AgentLoop
-	return "ok"
 }
 
 func shouldSuppressScheduledCommandErrorForFeishu(channel string, result *ToolResult) bool {
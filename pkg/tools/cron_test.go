@@ -1,6 +1,18 @@
 package tools
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/access"
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/cron"
+)
 
 func TestShouldSuppressScheduledCommandErrorForFeishu(t *testing.T) {
 	tests := []struct {
@@ -62,3 +74,106 @@ func TestShouldSuppressScheduledCommandErrorForFeishu(t *testing.T) {
 		})
 	}
 }
+
+type failingExecutor struct{ err error }
+
+func (e failingExecutor) ProcessDirectWithChannel(
+	_ context.Context, _, _, _, _ string,
+) (string, error) {
+	return "", e.err
+}
+
+func TestCronToolExecuteJobReportsFailures(t *testing.T) {
+	workspace := t.TempDir()
+	msgBus := bus.NewMessageBus()
+	cs := cron.NewCronService(filepath.Join(workspace, "cron", "jobs.json"), nil)
+	tool := NewCronTool(cs, failingExecutor{err: errors.New("llm down")}, msgBus, workspace, true, 0, config.DefaultConfig())
+
+	job := &cron.CronJob{ID: "j1", Name: "report", Payload: cron.CronPayload{Message: "hi", Channel: "telegram", To: "42"}}
+	if _, err := tool.ExecuteJob(context.Background(), job); err == nil || !strings.Contains(err.Error(), "llm down") {
+		t.Fatalf("ExecuteJob error = %v, want the executor error", err)
+	}
+
+	job.Notify = &cron.CronNotify{Channel: "telegram", To: "42"}
+	tool.NotifyFailure(job, cron.CronRun{Attempt: 3, Error: "llm down"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	msg, ok := msgBus.SubscribeOutbound(ctx)
+	if !ok {
+		t.Fatal("no failure notification published")
+	}
+	if msg.Channel != "telegram" || msg.ChatID != "42" || !strings.Contains(msg.Content, "failed after 3 attempt(s): llm down") {
+		t.Errorf("notification = %+v", msg)
+	}
+}
+
+// ctxExecutor records the context each agent turn runs with.
+type ctxExecutor struct{ ctx context.Context }
+
+func (e *ctxExecutor) ProcessDirectWithChannel(
+	ctx context.Context, _, _, _, _ string,
+) (string, error) {
+	e.ctx = ctx
+	return "ok", nil
+}
+
+func TestCronToolRunsJobsAsCreator(t *testing.T) {
+	workspace := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Access = &config.AccessConfig{
+		DefaultRole: "guest",
+		Roles:       map[string]config.RolePolicy{"guest": {Tools: []string{"cron"}}},
+	}
+	controller := access.NewController(cfg)
+	executor := &ctxExecutor{}
+	cs := cron.NewCronService(filepath.Join(workspace, "cron", "jobs.json"), nil)
+	tool := NewCronTool(cs, executor, bus.NewMessageBus(), workspace, true, 0, cfg)
+	tool.SetAccess(controller)
+	tool.SetContext("telegram", "7")
+	ctx := WithGuard(context.Background(), controller.Resolve("telegram", "7"))
+
+	result := tool.Execute(ctx, map[string]any{
+		"action": "add", "message": "disk", "command": "df -h", "every_seconds": float64(60),
+	})
+	if !result.IsError || !strings.Contains(result.ForLLM, "permission denied") {
+		t.Fatalf("guest command job = %+v, want permission denied", result)
+	}
+
+	result = tool.Execute(ctx, map[string]any{
+		"action": "add", "message": "check the disk", "deliver": false, "every_seconds": float64(60),
+	})
+	if result.IsError {
+		t.Fatalf("guest agent job: %s", result.ForLLM)
+	}
+	jobs := cs.ListJobs(true)
+	if len(jobs) != 1 || jobs[0].CreatedBy == nil ||
+		jobs[0].CreatedBy.Channel != "telegram" || jobs[0].CreatedBy.SenderID != "7" {
+		t.Fatalf("jobs = %+v, want one job created by telegram:7", jobs)
+	}
+
+	job := jobs[0]
+	if _, err := tool.ExecuteJob(context.Background(), &job); err != nil {
+		t.Fatal(err)
+	}
+	if channel, sender, ok := access.SenderFrom(executor.ctx); !ok || channel != "telegram" || sender != "7" {
+		t.Errorf("agent turn ran as %s:%s (%v), want telegram:7", channel, sender, ok)
+	}
+
+	// A command job whose creator lost exec is refused when it runs.
+	job.Payload.Command = "df -h"
+	if _, err := tool.ExecuteJob(context.Background(), &job); err == nil ||
+		!strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("command job for guest = %v, want permission denied", err)
+	}
+
+	// Jobs without a creator were set up by the device owner.
+	job.CreatedBy = nil
+	job.Payload.Command = ""
+	if _, err := tool.ExecuteJob(context.Background(), &job); err != nil {
+		t.Fatal(err)
+	}
+	if !access.IsTrusted(executor.ctx) {
+		t.Error("job without a creator should run trusted")
+	}
+}
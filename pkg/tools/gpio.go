@@ -0,0 +1,321 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/devices/gpio"
+)
+
+const (
+	defaultGPIOWaitTimeout = 10 * time.Second
+	maxGPIOWaitTimeout     = 60 * time.Second
+)
+
+// gpioLine is the part of *gpio.Line the tool uses, so tests can fake it.
+type gpioLine interface {
+	Value() (int, error)
+	SetValue(value int) error
+	ReadEvent(timeout time.Duration) (gpio.Event, error)
+	Close() error
+}
+
+// GPIOTool reads, drives and waits on GPIO lines through the GPIO character
+// device. Only lines in the configured allowlist can be used, and only those
+// marked as outputs can be written.
+type GPIOTool struct {
+	lines   []config.GPIOToolLine
+	request func(chip string, offset uint32, cfg gpio.LineConfig) (gpioLine, error)
+
+	// Lines driven by write stay claimed so they keep their value.
+	mu      sync.Mutex
+	outputs map[string]gpioLine
+}
+
+func NewGPIOTool(cfg config.GPIOToolConfig) *GPIOTool {
+	return &GPIOTool{
+		lines: cfg.Lines,
+		request: func(chip string, offset uint32, cfg gpio.LineConfig) (gpioLine, error) {
+			return gpio.RequestLine(chip, offset, cfg)
+		},
+		outputs: make(map[string]gpioLine),
+	}
+}
+
+func (t *GPIOTool) Name() string {
+	return "gpio"
+}
+
+func (t *GPIOTool) Description() string {
+	return "Read, write and wait for edges on allowed GPIO lines (relays, buttons, sensors). Actions: list (allowed lines), read (line value), write (set an output line), wait (block until an edge or timeout). Linux only."
+}
+
+func (t *GPIOTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"list", "read", "write", "wait"},
+				"description": "Action to perform: list (show allowed lines), read (get a line's value), write (set an output line's value), wait (wait for an edge on an input line)",
+			},
+			"name": map[string]any{
+				"type":        "string",
+				"description": "Configured line name. Alternative to chip + line.",
+			},
+			"chip": map[string]any{
+				"type":        "string",
+				"description": "GPIO chip (e.g. \"gpiochip0\"). Default: gpiochip0.",
+			},
+			"line": map[string]any{
+				"type":        "integer",
+				"description": "Line offset on the chip.",
+			},
+			"value": map[string]any{
+				"type":        "integer",
+				"enum":        []int{0, 1},
+				"description": "Value to write (0 or 1). Required for write.",
+			},
+			"edge": map[string]any{
+				"type":        "string",
+				"enum":        []string{gpio.EdgeRising, gpio.EdgeFalling, gpio.EdgeBoth},
+				"description": "Edge to wait for. Default: both.",
+			},
+			"timeout_ms": map[string]any{
+				"type":        "integer",
+				"description": "How long to wait for an edge (max 60000). Default: 10000.",
+			},
+			"confirm": map[string]any{
+				"type":        "boolean",
+				"description": "Must be true for write operations. Safety guard to prevent accidental writes.",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *GPIOTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, ok := args["action"].(string)
+	if !ok {
+		return ErrorResult("action is required")
+	}
+
+	switch action {
+	case "list":
+		return t.list()
+	case "read":
+		return t.read(args)
+	case "write":
+		return t.write(args)
+	case "wait":
+		return t.wait(ctx, args)
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action: %s (valid: list, read, write, wait)", action))
+	}
+}
+
+// Close releases the lines held by write.
+func (t *GPIOTool) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, l := range t.outputs {
+		l.Close()
+		delete(t.outputs, key)
+	}
+	return nil
+}
+
+func (t *GPIOTool) list() *ToolResult {
+	type lineInfo struct {
+		Name   string `json:"name,omitempty"`
+		Chip   string `json:"chip"`
+		Line   uint32 `json:"line"`
+		Output bool   `json:"output"`
+	}
+	lines := make([]lineInfo, 0, len(t.lines))
+	for _, l := range t.lines {
+		lines = append(lines, lineInfo{Name: l.Name, Chip: gpioChip(l), Line: l.Line, Output: l.Output})
+	}
+	result, _ := json.MarshalIndent(lines, "", "  ")
+	return SilentResult(fmt.Sprintf("Allowed GPIO lines:\n%s", string(result)))
+}
+
+func (t *GPIOTool) read(args map[string]any) *ToolResult {
+	line, errResult := t.resolveLine(args)
+	if errResult != nil {
+		return errResult
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	l, held := t.outputs[gpioKey(line)]
+	if !held {
+		var err error
+		l, err = t.request(gpioChip(line), line.Line, gpio.LineConfig{ActiveLow: line.ActiveLow})
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("failed to request %s: %v", gpioKey(line), err))
+		}
+		defer l.Close()
+	}
+
+	value, err := l.Value()
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to read %s: %v", gpioKey(line), err))
+	}
+	return SilentResult(gpioJSON(line, map[string]any{"value": value, "output": held}))
+}
+
+func (t *GPIOTool) write(args map[string]any) *ToolResult {
+	confirm, _ := args["confirm"].(bool)
+	if !confirm {
+		return ErrorResult(
+			"write operations require confirm: true. Please confirm with the user before driving GPIO lines, as they may switch relays, motors or other hardware.",
+		)
+	}
+
+	line, errResult := t.resolveLine(args)
+	if errResult != nil {
+		return errResult
+	}
+	if !line.Output {
+		return ErrorResult(fmt.Sprintf("%s is not configured as an output", gpioKey(line)))
+	}
+	v, ok := args["value"].(float64)
+	if !ok || (v != 0 && v != 1) {
+		return ErrorResult("value is required for write (0 or 1)")
+	}
+	value := int(v)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := gpioKey(line)
+	if l, held := t.outputs[key]; held {
+		if err := l.SetValue(value); err != nil {
+			return ErrorResult(fmt.Sprintf("failed to write %s: %v", key, err))
+		}
+	} else {
+		l, err := t.request(gpioChip(line), line.Line, gpio.LineConfig{
+			Output:    true,
+			Value:     value,
+			ActiveLow: line.ActiveLow,
+		})
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("failed to request %s as output: %v", key, err))
+		}
+		t.outputs[key] = l
+	}
+	return SilentResult(gpioJSON(line, map[string]any{"value": value, "output": true}))
+}
+
+func (t *GPIOTool) wait(ctx context.Context, args map[string]any) *ToolResult {
+	line, errResult := t.resolveLine(args)
+	if errResult != nil {
+		return errResult
+	}
+
+	edge := gpio.EdgeBoth
+	if e, ok := args["edge"].(string); ok && e != "" {
+		edge = e
+	}
+	if !gpio.ValidEdge(edge) {
+		return ErrorResult("edge must be rising, falling or both")
+	}
+	timeout := defaultGPIOWaitTimeout
+	if ms, ok := args["timeout_ms"].(float64); ok && ms > 0 {
+		timeout = time.Duration(ms) * time.Millisecond
+	}
+	if timeout > maxGPIOWaitTimeout {
+		return ErrorResult("timeout_ms must be at most 60000")
+	}
+
+	t.mu.Lock()
+	_, held := t.outputs[gpioKey(line)]
+	t.mu.Unlock()
+	if held {
+		return ErrorResult(fmt.Sprintf("%s is being driven as an output", gpioKey(line)))
+	}
+
+	l, err := t.request(gpioChip(line), line.Line, gpio.LineConfig{Edge: edge, ActiveLow: line.ActiveLow})
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to request %s: %v", gpioKey(line), err))
+	}
+	defer l.Close()
+
+	// Release the line if the turn is cancelled, which ends the wait.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			l.Close()
+		case <-done:
+		}
+	}()
+
+	ev, err := l.ReadEvent(timeout)
+	switch {
+	case errors.Is(err, os.ErrDeadlineExceeded):
+		return SilentResult(gpioJSON(line, map[string]any{"edge": nil, "timed_out": true}))
+	case ctx.Err() != nil:
+		return ErrorResult(fmt.Sprintf("wait on %s cancelled", gpioKey(line)))
+	case err != nil:
+		return ErrorResult(fmt.Sprintf("failed to wait on %s: %v", gpioKey(line), err))
+	}
+	return SilentResult(gpioJSON(line, map[string]any{"edge": ev.Edge, "timestamp_ns": ev.TimestampNs}))
+}
+
+// resolveLine finds the allowlisted line named by args.
+func (t *GPIOTool) resolveLine(args map[string]any) (config.GPIOToolLine, *ToolResult) {
+	if name, ok := args["name"].(string); ok && name != "" {
+		for _, l := range t.lines {
+			if l.Name == name {
+				return l, nil
+			}
+		}
+		return config.GPIOToolLine{}, ErrorResult(fmt.Sprintf("no allowed GPIO line named %q", name))
+	}
+
+	lineF, ok := args["line"].(float64)
+	if !ok || lineF < 0 {
+		return config.GPIOToolLine{}, ErrorResult("name or line is required")
+	}
+	chip, _ := args["chip"].(string)
+	want := config.GPIOToolLine{Chip: chip, Line: uint32(lineF)}
+	for _, l := range t.lines {
+		if gpioKey(l) == gpioKey(want) {
+			return l, nil
+		}
+	}
+	return config.GPIOToolLine{}, ErrorResult(
+		fmt.Sprintf("%s is not in the allowed GPIO lines (tools.hardware.gpio.lines)", gpioKey(want)),
+	)
+}
+
+func gpioChip(l config.GPIOToolLine) string {
+	if l.Chip == "" {
+		return "gpiochip0"
+	}
+	return l.Chip
+}
+
+func gpioKey(l config.GPIOToolLine) string {
+	return fmt.Sprintf("%s:%d", gpioChip(l), l.Line)
+}
+
+func gpioJSON(line config.GPIOToolLine, fields map[string]any) string {
+	fields["chip"] = gpioChip(line)
+	fields["line"] = line.Line
+	if line.Name != "" {
+		fields["name"] = line.Name
+	}
+	result, _ := json.MarshalIndent(fields, "", "  ")
+	return string(result)
+}
@@ -0,0 +1,145 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/devices/gpio"
+)
+
+type fakeGPIOLine struct {
+	cfg    gpio.LineConfig
+	value  int
+	event  *gpio.Event
+	closed bool
+}
+
+func (l *fakeGPIOLine) Value() (int, error)      { return l.value, nil }
+func (l *fakeGPIOLine) SetValue(value int) error { l.value = value; return nil }
+func (l *fakeGPIOLine) Close() error             { l.closed = true; return nil }
+
+func (l *fakeGPIOLine) ReadEvent(timeout time.Duration) (gpio.Event, error) {
+	if l.event == nil {
+		return gpio.Event{}, os.ErrDeadlineExceeded
+	}
+	return *l.event, nil
+}
+
+func newTestGPIOTool(t *testing.T) (*GPIOTool, map[string]*fakeGPIOLine) {
+	t.Helper()
+	tool := NewGPIOTool(config.GPIOToolConfig{Lines: []config.GPIOToolLine{
+		{Name: "button", Line: 5},
+		{Name: "relay", Chip: "gpiochip1", Line: 17, Output: true},
+	}})
+	requested := map[string]*fakeGPIOLine{}
+	tool.request = func(chip string, offset uint32, cfg gpio.LineConfig) (gpioLine, error) {
+		l := &fakeGPIOLine{cfg: cfg, value: 1, event: &gpio.Event{Edge: gpio.EdgeFalling, TimestampNs: 99}}
+		requested[chip] = l
+		return l, nil
+	}
+	return tool, requested
+}
+
+func TestGPIOTool_Allowlist(t *testing.T) {
+	tool, _ := newTestGPIOTool(t)
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{"action": "read", "line": float64(6)})
+	if !result.IsError || !strings.Contains(result.ForLLM, "not in the allowed GPIO lines") {
+		t.Errorf("expected allowlist error, got %+v", result)
+	}
+	result = tool.Execute(ctx, map[string]any{"action": "read", "line": float64(17)})
+	if !result.IsError {
+		t.Error("line 17 is only allowed on gpiochip1")
+	}
+	result = tool.Execute(ctx, map[string]any{"action": "read", "name": "missing"})
+	if !result.IsError {
+		t.Error("expected error for unknown name")
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "list"})
+	if result.IsError || !strings.Contains(result.ForLLM, "relay") {
+		t.Errorf("list = %+v", result)
+	}
+}
+
+func TestGPIOTool_ReadWrite(t *testing.T) {
+	tool, requested := newTestGPIOTool(t)
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{"action": "read", "line": float64(5)})
+	if result.IsError || !strings.Contains(result.ForLLM, `"value": 1`) {
+		t.Fatalf("read = %+v", result)
+	}
+	if !requested["gpiochip0"].closed {
+		t.Error("input line should be released after reading")
+	}
+
+	// Writes need confirmation and an output line.
+	result = tool.Execute(ctx, map[string]any{"action": "write", "name": "relay", "value": float64(0)})
+	if !result.IsError || !strings.Contains(result.ForLLM, "confirm") {
+		t.Errorf("expected confirm error, got %+v", result)
+	}
+	result = tool.Execute(ctx, map[string]any{"action": "write", "name": "button", "value": float64(1), "confirm": true})
+	if !result.IsError || !strings.Contains(result.ForLLM, "not configured as an output") {
+		t.Errorf("expected output error, got %+v", result)
+	}
+	result = tool.Execute(ctx, map[string]any{"action": "write", "name": "relay", "value": float64(2), "confirm": true})
+	if !result.IsError {
+		t.Error("expected error for value 2")
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "write", "name": "relay", "value": float64(0), "confirm": true})
+	if result.IsError {
+		t.Fatalf("write = %+v", result)
+	}
+	relay := requested["gpiochip1"]
+	if !relay.cfg.Output || relay.cfg.Value != 0 || relay.closed {
+		t.Errorf("relay should be held as an output with value 0: %+v", relay)
+	}
+
+	// Later writes and reads use the held line.
+	tool.Execute(ctx, map[string]any{"action": "write", "name": "relay", "value": float64(1), "confirm": true})
+	result = tool.Execute(ctx, map[string]any{"action": "read", "name": "relay"})
+	if relay.value != 1 || !strings.Contains(result.ForLLM, `"output": true`) {
+		t.Errorf("read of held line = %+v", result)
+	}
+	if requested["gpiochip1"] != relay {
+		t.Error("held line should not be requested again")
+	}
+
+	tool.Close()
+	if !relay.closed {
+		t.Error("Close should release held lines")
+	}
+}
+
+func TestGPIOTool_Wait(t *testing.T) {
+	tool, requested := newTestGPIOTool(t)
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{"action": "wait", "name": "button", "edge": "falling"})
+	if result.IsError || !strings.Contains(result.ForLLM, `"edge": "falling"`) {
+		t.Fatalf("wait = %+v", result)
+	}
+	if requested["gpiochip0"].cfg.Edge != gpio.EdgeFalling {
+		t.Errorf("edge config = %q", requested["gpiochip0"].cfg.Edge)
+	}
+
+	tool.request = func(chip string, offset uint32, cfg gpio.LineConfig) (gpioLine, error) {
+		return &fakeGPIOLine{}, nil
+	}
+	result = tool.Execute(ctx, map[string]any{"action": "wait", "name": "button", "timeout_ms": float64(10)})
+	if result.IsError || !strings.Contains(result.ForLLM, `"timed_out": true`) {
+		t.Errorf("expected timeout result, got %+v", result)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "wait", "name": "button", "timeout_ms": float64(120000)})
+	if !result.IsError {
+		t.Error("expected error for timeout above the maximum")
+	}
+}
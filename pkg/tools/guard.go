@@ -0,0 +1,44 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// Guard decides which tools the sender of the current turn may use.
+// ExecuteWithContext consults the guard attached to its context.
+type Guard interface {
+	// Permits reports whether the tool may be offered to the model at all.
+	Permits(name string) bool
+	// AllowTool is called before each execution and may also enforce quotas.
+	AllowTool(name string) error
+}
+
+type guardKey struct{}
+
+// WithGuard returns a context whose tool executions are checked by g.
+func WithGuard(ctx context.Context, g Guard) context.Context {
+	return context.WithValue(ctx, guardKey{}, g)
+}
+
+// GuardFrom returns the guard attached to ctx, or nil.
+func GuardFrom(ctx context.Context) Guard {
+	g, _ := ctx.Value(guardKey{}).(Guard)
+	return g
+}
+
+// FilterDefs drops the definitions of tools the guard in ctx does not permit.
+func FilterDefs(ctx context.Context, defs []providers.ToolDefinition) []providers.ToolDefinition {
+	guard := GuardFrom(ctx)
+	if guard == nil {
+		return defs
+	}
+	filtered := make([]providers.ToolDefinition, 0, len(defs))
+	for _, def := range defs {
+		if guard.Permits(def.Function.Name) {
+			filtered = append(filtered, def)
+		}
+	}
+	return filtered
+}
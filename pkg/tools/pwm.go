@@ -0,0 +1,314 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// PWMTool configures PWM outputs through sysfs (/sys/class/pwm). Only
+// channels in the configured allowlist can be used, within their configured
+// period and duty-cycle bounds.
+type PWMTool struct {
+	channels []config.PWMToolChannel
+	root     string
+}
+
+func NewPWMTool(cfg config.PWMToolConfig) *PWMTool {
+	return &PWMTool{channels: cfg.Channels, root: "/sys/class/pwm"}
+}
+
+func (t *PWMTool) Name() string {
+	return "pwm"
+}
+
+func (t *PWMTool) Description() string {
+	return "Control allowed PWM outputs (motors, servos, LEDs, buzzers). Actions: list (allowed channels), status (current period, duty cycle and state), set (period and duty cycle, enables the output), disable. Linux only."
+}
+
+func (t *PWMTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"list", "status", "set", "disable"},
+				"description": "Action to perform: list (show allowed channels), status (read a channel's settings), set (configure and enable a channel), disable (turn a channel off)",
+			},
+			"name": map[string]any{
+				"type":        "string",
+				"description": "Configured channel name. Alternative to chip + channel.",
+			},
+			"chip": map[string]any{
+				"type":        "integer",
+				"description": "PWM chip number (N in /sys/class/pwm/pwmchipN).",
+			},
+			"channel": map[string]any{
+				"type":        "integer",
+				"description": "Channel number on the chip.",
+			},
+			"period_ns": map[string]any{
+				"type":        "integer",
+				"description": "Period in nanoseconds (e.g. 20000000 for a 50 Hz servo signal). Defaults to the current period.",
+			},
+			"frequency_hz": map[string]any{
+				"type":        "number",
+				"description": "Frequency in Hz. Alternative to period_ns.",
+			},
+			"duty_ns": map[string]any{
+				"type":        "integer",
+				"description": "Duty cycle (active time) in nanoseconds.",
+			},
+			"duty_percent": map[string]any{
+				"type":        "number",
+				"description": "Duty cycle as a percentage of the period (0-100). Alternative to duty_ns.",
+			},
+			"confirm": map[string]any{
+				"type":        "boolean",
+				"description": "Must be true for set and disable. Safety guard to prevent accidental changes to outputs.",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *PWMTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, ok := args["action"].(string)
+	if !ok {
+		return ErrorResult("action is required")
+	}
+
+	switch action {
+	case "list":
+		return t.list()
+	case "status":
+		return t.status(args)
+	case "set":
+		return t.set(args)
+	case "disable":
+		return t.disable(args)
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action: %s (valid: list, status, set, disable)", action))
+	}
+}
+
+func (t *PWMTool) list() *ToolResult {
+	result, _ := json.MarshalIndent(t.channels, "", "  ")
+	return SilentResult(fmt.Sprintf("Allowed PWM channels:\n%s", string(result)))
+}
+
+func (t *PWMTool) status(args map[string]any) *ToolResult {
+	ch, errResult := t.resolveChannel(args)
+	if errResult != nil {
+		return errResult
+	}
+	dir := t.channelDir(ch)
+	if _, err := os.Stat(dir); err != nil {
+		return SilentResult(pwmJSON(ch, map[string]any{"exported": false}))
+	}
+
+	fields := map[string]any{"exported": true}
+	for _, attr := range []string{"period", "duty_cycle", "enable", "polarity"} {
+		value, err := readSysfsAttr(dir, attr)
+		if err != nil {
+			continue
+		}
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			fields[attr] = n
+		} else {
+			fields[attr] = value
+		}
+	}
+	return SilentResult(pwmJSON(ch, fields))
+}
+
+func (t *PWMTool) set(args map[string]any) *ToolResult {
+	confirm, _ := args["confirm"].(bool)
+	if !confirm {
+		return ErrorResult(
+			"set operations require confirm: true. Please confirm with the user before changing PWM outputs, as they may drive motors or other hardware.",
+		)
+	}
+	ch, errResult := t.resolveChannel(args)
+	if errResult != nil {
+		return errResult
+	}
+	if err := t.export(ch); err != nil {
+		return ErrorResult(err.Error())
+	}
+	dir := t.channelDir(ch)
+
+	curPeriod, _ := readSysfsInt(dir, "period")
+	curDuty, _ := readSysfsInt(dir, "duty_cycle")
+
+	period := curPeriod
+	if p, ok := args["period_ns"].(float64); ok {
+		period = int64(p)
+	} else if f, ok := args["frequency_hz"].(float64); ok {
+		if f <= 0 {
+			return ErrorResult("frequency_hz must be positive")
+		}
+		period = int64(float64(time.Second) / f)
+	}
+	if period <= 0 {
+		return ErrorResult("period_ns or frequency_hz is required (the channel has no period set)")
+	}
+	if ch.MinPeriodNs > 0 && period < ch.MinPeriodNs {
+		return ErrorResult(fmt.Sprintf("period %d ns is below the allowed minimum of %d ns", period, ch.MinPeriodNs))
+	}
+	if ch.MaxPeriodNs > 0 && period > ch.MaxPeriodNs {
+		return ErrorResult(fmt.Sprintf("period %d ns is above the allowed maximum of %d ns", period, ch.MaxPeriodNs))
+	}
+
+	var duty int64
+	if d, ok := args["duty_ns"].(float64); ok {
+		duty = int64(d)
+	} else if pct, ok := args["duty_percent"].(float64); ok {
+		if pct < 0 || pct > 100 {
+			return ErrorResult("duty_percent must be between 0 and 100")
+		}
+		duty = int64(float64(period) * pct / 100)
+	} else {
+		return ErrorResult("duty_ns or duty_percent is required")
+	}
+	if duty < 0 || duty > period {
+		return ErrorResult(fmt.Sprintf("duty cycle must be between 0 and the period (%d ns)", period))
+	}
+	if ch.MaxDutyPercent > 0 && float64(duty) > float64(period)*ch.MaxDutyPercent/100 {
+		return ErrorResult(fmt.Sprintf("duty cycle is above the allowed maximum of %g%%", ch.MaxDutyPercent))
+	}
+
+	// The kernel rejects a duty cycle longer than the period at every step,
+	// so shrink the duty cycle first when shortening the period.
+	steps := [][2]string{{"period", strconv.FormatInt(period, 10)}, {"duty_cycle", strconv.FormatInt(duty, 10)}}
+	if period < curDuty {
+		steps[0], steps[1] = steps[1], steps[0]
+	}
+	steps = append(steps, [2]string{"enable", "1"})
+	for _, s := range steps {
+		if err := writeSysfsAttr(dir, s[0], s[1]); err != nil {
+			return ErrorResult(fmt.Sprintf("failed to set %s on %s: %v", s[0], dir, err))
+		}
+	}
+
+	return SilentResult(pwmJSON(ch, map[string]any{
+		"period":       period,
+		"duty_cycle":   duty,
+		"duty_percent": float64(duty) * 100 / float64(period),
+		"enable":       1,
+	}))
+}
+
+func (t *PWMTool) disable(args map[string]any) *ToolResult {
+	confirm, _ := args["confirm"].(bool)
+	if !confirm {
+		return ErrorResult("disable requires confirm: true. Please confirm with the user before changing PWM outputs.")
+	}
+	ch, errResult := t.resolveChannel(args)
+	if errResult != nil {
+		return errResult
+	}
+	dir := t.channelDir(ch)
+	if _, err := os.Stat(dir); err != nil {
+		return SilentResult(pwmJSON(ch, map[string]any{"enable": 0, "exported": false}))
+	}
+	if err := writeSysfsAttr(dir, "enable", "0"); err != nil {
+		return ErrorResult(fmt.Sprintf("failed to disable %s: %v", dir, err))
+	}
+	return SilentResult(pwmJSON(ch, map[string]any{"enable": 0}))
+}
+
+// export makes the channel's sysfs directory available, waiting briefly for
+// udev to create it after writing to the chip's export file.
+func (t *PWMTool) export(ch config.PWMToolChannel) error {
+	dir := t.channelDir(ch)
+	if _, err := os.Stat(dir); err == nil {
+		return nil
+	}
+	chipDir := filepath.Join(t.root, fmt.Sprintf("pwmchip%d", ch.Chip))
+	if npwm, err := readSysfsInt(chipDir, "npwm"); err == nil && int64(ch.Channel) >= npwm {
+		return fmt.Errorf("pwmchip%d has only %d channels", ch.Chip, npwm)
+	}
+	if err := writeSysfsAttr(chipDir, "export", strconv.Itoa(ch.Channel)); err != nil {
+		return fmt.Errorf("failed to export PWM channel %d on pwmchip%d: %v", ch.Channel, ch.Chip, err)
+	}
+	for i := 0; i < 20; i++ {
+		if _, err := os.Stat(filepath.Join(dir, "enable")); err == nil {
+			return nil
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return fmt.Errorf("PWM channel %s did not appear after export", dir)
+}
+
+func (t *PWMTool) channelDir(ch config.PWMToolChannel) string {
+	return filepath.Join(t.root, fmt.Sprintf("pwmchip%d", ch.Chip), fmt.Sprintf("pwm%d", ch.Channel))
+}
+
+func (t *PWMTool) resolveChannel(args map[string]any) (config.PWMToolChannel, *ToolResult) {
+	if name, ok := args["name"].(string); ok && name != "" {
+		for _, ch := range t.channels {
+			if ch.Name == name {
+				return ch, nil
+			}
+		}
+		return config.PWMToolChannel{}, ErrorResult(fmt.Sprintf("no allowed PWM channel named %q", name))
+	}
+
+	chip, ok1 := args["chip"].(float64)
+	channel, ok2 := args["channel"].(float64)
+	if !ok1 || !ok2 {
+		return config.PWMToolChannel{}, ErrorResult("name, or chip and channel, is required")
+	}
+	for _, ch := range t.channels {
+		if ch.Chip == int(chip) && ch.Channel == int(channel) {
+			return ch, nil
+		}
+	}
+	return config.PWMToolChannel{}, ErrorResult(fmt.Sprintf(
+		"pwmchip%d channel %d is not in the allowed PWM channels (tools.hardware.pwm.channels)", int(chip), int(channel)))
+}
+
+func pwmJSON(ch config.PWMToolChannel, fields map[string]any) string {
+	fields["chip"] = ch.Chip
+	fields["channel"] = ch.Channel
+	if ch.Name != "" {
+		fields["name"] = ch.Name
+	}
+	result, _ := json.MarshalIndent(fields, "", "  ")
+	return string(result)
+}
+
+func readSysfsAttr(dir, name string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func readSysfsInt(dir, name string) (int64, error) {
+	value, err := readSysfsAttr(dir, name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+func writeSysfsAttr(dir, name, value string) error {
+	// sysfs attributes exist already; never create files.
+	f, err := os.OpenFile(filepath.Join(dir, name), os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(value)
+	return err
+}
@@ -0,0 +1,133 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// newFakePWMTree creates a sysfs-like /sys/class/pwm with pwmchip0, whose
+// channel 0 is already exported.
+func newFakePWMTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"pwmchip0/npwm":            "2",
+		"pwmchip0/export":          "",
+		"pwmchip0/pwm0/period":     "1000000",
+		"pwmchip0/pwm0/duty_cycle": "500000",
+		"pwmchip0/pwm0/enable":     "0",
+		"pwmchip0/pwm0/polarity":   "normal",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte(content+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func readFakeAttr(t *testing.T, root, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(root, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func newTestPWMTool(t *testing.T) (*PWMTool, string) {
+	root := newFakePWMTree(t)
+	tool := NewPWMTool(config.PWMToolConfig{Channels: []config.PWMToolChannel{
+		{Name: "fan", Chip: 0, Channel: 0, MaxDutyPercent: 80},
+		{Name: "servo", Chip: 0, Channel: 1, MinPeriodNs: 1000000, MaxPeriodNs: 25000000},
+	}})
+	tool.root = root
+	return tool, root
+}
+
+func TestPWMTool_Status(t *testing.T) {
+	tool, _ := newTestPWMTool(t)
+	result := tool.Execute(context.Background(), map[string]any{"action": "status", "name": "fan"})
+	if result.IsError {
+		t.Fatalf("status = %+v", result)
+	}
+	for _, want := range []string{`"period": 1000000`, `"duty_cycle": 500000`, `"enable": 0`, `"polarity": "normal"`} {
+		if !strings.Contains(result.ForLLM, want) {
+			t.Errorf("status missing %s:\n%s", want, result.ForLLM)
+		}
+	}
+
+	result = tool.Execute(context.Background(), map[string]any{"action": "status", "chip": float64(1), "channel": float64(0)})
+	if !result.IsError || !strings.Contains(result.ForLLM, "not in the allowed PWM channels") {
+		t.Errorf("expected allowlist error, got %+v", result)
+	}
+}
+
+func TestPWMTool_Set(t *testing.T) {
+	tool, root := newTestPWMTool(t)
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{"action": "set", "name": "fan", "duty_percent": float64(50)})
+	if !result.IsError || !strings.Contains(result.ForLLM, "confirm") {
+		t.Errorf("expected confirm error, got %+v", result)
+	}
+	result = tool.Execute(ctx, map[string]any{"action": "set", "name": "fan", "duty_percent": float64(90), "confirm": true})
+	if !result.IsError || !strings.Contains(result.ForLLM, "maximum of 80%") {
+		t.Errorf("expected duty limit error, got %+v", result)
+	}
+
+	// Shortening the period below the current duty cycle.
+	result = tool.Execute(ctx, map[string]any{
+		"action": "set", "name": "fan", "period_ns": float64(400000), "duty_percent": float64(25), "confirm": true,
+	})
+	if result.IsError {
+		t.Fatalf("set = %+v", result)
+	}
+	if got := readFakeAttr(t, root, "pwmchip0/pwm0/period"); got != "400000" {
+		t.Errorf("period = %s", got)
+	}
+	if got := readFakeAttr(t, root, "pwmchip0/pwm0/duty_cycle"); got != "100000" {
+		t.Errorf("duty_cycle = %s", got)
+	}
+	if got := readFakeAttr(t, root, "pwmchip0/pwm0/enable"); got != "1" {
+		t.Errorf("enable = %s", got)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "disable", "name": "fan", "confirm": true})
+	if result.IsError || readFakeAttr(t, root, "pwmchip0/pwm0/enable") != "0" {
+		t.Errorf("disable = %+v", result)
+	}
+}
+
+func TestPWMTool_SetPeriodBounds(t *testing.T) {
+	tool, root := newTestPWMTool(t)
+	// Simulate the kernel creating the channel directory on export.
+	for _, name := range []string{"period", "duty_cycle", "enable"} {
+		path := filepath.Join(root, "pwmchip0/pwm1", name)
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		os.WriteFile(path, []byte("0\n"), 0o644)
+	}
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"action": "set", "name": "servo", "frequency_hz": float64(10), "duty_ns": float64(1500000), "confirm": true,
+	})
+	if !result.IsError || !strings.Contains(result.ForLLM, "above the allowed maximum") {
+		t.Errorf("expected period bound error, got %+v", result)
+	}
+	result = tool.Execute(context.Background(), map[string]any{
+		"action": "set", "name": "servo", "frequency_hz": float64(50), "duty_ns": float64(1500000), "confirm": true,
+	})
+	if result.IsError {
+		t.Fatalf("set = %+v", result)
+	}
+	if got := readFakeAttr(t, root, "pwmchip0/pwm1/period"); got != "20000000" {
+		t.Errorf("period = %s", got)
+	}
+}
@@ -29,6 +29,13 @@ func (r *ToolRegistry) Register(tool Tool) {
 	r.tools[tool.Name()] = tool
 }
 
+// Unregister removes a tool; it is a no-op if the tool is not registered.
+func (r *ToolRegistry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.tools, name)
+}
+
 func (r *ToolRegistry) Get(name string) (Tool, bool) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -67,6 +74,17 @@ func (r *ToolRegistry) ExecuteWithContext(
 		return ErrorResult(fmt.Sprintf("tool %q not found", name)).WithError(fmt.Errorf("tool not found"))
 	}
 
+	if guard := GuardFrom(ctx); guard != nil {
+		if err := guard.AllowTool(name); err != nil {
+			logger.WarnCF("tool", "Tool execution denied",
+				map[string]any{
+					"tool":   name,
+					"reason": err.Error(),
+				})
+			return ErrorResult(fmt.Sprintf("permission denied: %v", err)).WithError(err)
+		}
+	}
+
 	// If tool implements ContextualTool, set context
 	if contextualTool, ok := tool.(ContextualTool); ok && channel != "" && chatID != "" {
 		contextualTool.SetContext(channel, chatID)
@@ -2,6 +2,7 @@ package tools
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"sync"
 	"testing"
@@ -90,6 +91,22 @@ func TestToolRegistry_Get_NotFound(t *testing.T) {
 	}
 }
 
+func TestToolRegistry_Unregister(t *testing.T) {
+	r := NewToolRegistry()
+	r.Register(newMockTool("keep", "kept"))
+	r.Register(newMockTool("drop", "dropped"))
+
+	r.Unregister("drop")
+	r.Unregister("nonexistent")
+
+	if _, ok := r.Get("drop"); ok {
+		t.Error("expected unregistered tool to be gone")
+	}
+	if r.Count() != 1 {
+		t.Errorf("expected count 1 after unregister, got %d", r.Count())
+	}
+}
+
 func TestToolRegistry_RegisterOverwrite(t *testing.T) {
 	r := NewToolRegistry()
 	r.Register(newMockTool("dup", "first"))
@@ -348,3 +365,37 @@ func TestToolRegistry_ConcurrentAccess(t *testing.T) {
 		t.Error("expected tools to be registered after concurrent access")
 	}
 }
+
+type denyGuard struct{ denied string }
+
+func (g denyGuard) Permits(name string) bool { return name != g.denied }
+
+func (g denyGuard) AllowTool(name string) error {
+	if name == g.denied {
+		return fmt.Errorf("%s is not allowed", name)
+	}
+	return nil
+}
+
+func TestToolRegistry_ExecuteWithContext_Guard(t *testing.T) {
+	r := NewToolRegistry()
+	r.Register(newMockTool("exec", "runs commands"))
+	r.Register(newMockTool("read_file", "reads files"))
+	ctx := WithGuard(context.Background(), denyGuard{denied: "exec"})
+
+	result := r.ExecuteWithContext(ctx, "exec", nil, "", "", nil)
+	if !result.IsError || !strings.Contains(result.ForLLM, "permission denied") {
+		t.Errorf("expected permission denied, got %q", result.ForLLM)
+	}
+	if result.Err == nil {
+		t.Error("expected Err to be set via WithError")
+	}
+	if result := r.ExecuteWithContext(ctx, "read_file", nil, "", "", nil); result.IsError {
+		t.Errorf("allowed tool failed: %s", result.ForLLM)
+	}
+
+	defs := FilterDefs(ctx, r.ToProviderDefs())
+	if len(defs) != 1 || defs[0].Function.Name != "read_file" {
+		t.Errorf("FilterDefs = %v, want only read_file", defs)
+	}
+}
@@ -0,0 +1,437 @@
+package tools
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/devices/serial"
+)
+
+const (
+	defaultSerialReadTimeout = 2 * time.Second
+	maxSerialReadTimeout     = 30 * time.Second
+	defaultSerialMaxBytes    = 4096
+	maxSerialMaxBytes        = 65536
+
+	// Without a pattern, a read ends once the device goes quiet for this long.
+	serialIdleGap = 100 * time.Millisecond
+)
+
+// serialSession is an open port. Bytes read past a pattern match are kept in
+// pending for the next read.
+type serialSession struct {
+	mu      sync.Mutex
+	port    *serial.Port
+	pending []byte
+}
+
+// SerialTool talks to UARTs and USB serial adapters. Only ports in the
+// configured allowlist can be opened; ports stay open between calls so no
+// received data is lost.
+type SerialTool struct {
+	ports []config.SerialToolPort
+
+	mu       sync.Mutex
+	sessions map[string]*serialSession
+}
+
+func NewSerialTool(cfg config.SerialToolConfig) *SerialTool {
+	return &SerialTool{
+		ports:    cfg.Ports,
+		sessions: make(map[string]*serialSession),
+	}
+}
+
+func (t *SerialTool) Name() string {
+	return "serial"
+}
+
+func (t *SerialTool) Description() string {
+	return "Talk to allowed serial ports (UART, USB serial adapters, microcontrollers, modems). Actions: list (allowed ports), open (set baud rate, data bits, parity, stop bits), write (send text or hex bytes), read (wait for data, optionally until a regex matches), close. Ports open automatically with their configured settings. Linux only."
+}
+
+func (t *SerialTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"list", "open", "write", "read", "close"},
+				"description": "Action to perform: list (show allowed ports), open (open or reconfigure a port), write (send data), read (receive data), close (release a port)",
+			},
+			"name": map[string]any{
+				"type":        "string",
+				"description": "Configured port name. Alternative to device.",
+			},
+			"device": map[string]any{
+				"type":        "string",
+				"description": "Serial device path (e.g. \"/dev/ttyUSB0\").",
+			},
+			"baud": map[string]any{
+				"type":        "integer",
+				"description": "Baud rate for open (e.g. 9600, 115200). Default: the configured rate, or 115200.",
+			},
+			"data_bits": map[string]any{
+				"type":        "integer",
+				"enum":        []int{5, 6, 7, 8},
+				"description": "Data bits for open. Default: 8.",
+			},
+			"parity": map[string]any{
+				"type":        "string",
+				"enum":        []string{serial.ParityNone, serial.ParityEven, serial.ParityOdd},
+				"description": "Parity for open. Default: none.",
+			},
+			"stop_bits": map[string]any{
+				"type":        "integer",
+				"enum":        []int{1, 2},
+				"description": "Stop bits for open. Default: 1.",
+			},
+			"data": map[string]any{
+				"type":        "string",
+				"description": "Data to write. Include line endings (e.g. \"AT\\r\\n\") if the device expects them.",
+			},
+			"encoding": map[string]any{
+				"type":        "string",
+				"enum":        []string{"text", "hex"},
+				"description": "How data is written and returned: text (default) or hex (e.g. \"01 03 00 00\").",
+			},
+			"until": map[string]any{
+				"type":        "string",
+				"description": "Regular expression to read until (e.g. \"OK\\r\\n\"). Without it, read returns once data stops arriving.",
+			},
+			"timeout_ms": map[string]any{
+				"type":        "integer",
+				"description": "How long read waits (max 30000). Default: 2000.",
+			},
+			"max_bytes": map[string]any{
+				"type":        "integer",
+				"description": "Maximum bytes to read (max 65536). Default: 4096.",
+			},
+			"confirm": map[string]any{
+				"type":        "boolean",
+				"description": "Must be true for write operations. Safety guard to prevent accidental writes.",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *SerialTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	action, ok := args["action"].(string)
+	if !ok {
+		return ErrorResult("action is required")
+	}
+
+	switch action {
+	case "list":
+		return t.list()
+	case "open":
+		return t.open(args)
+	case "write":
+		return t.write(args)
+	case "read":
+		return t.read(ctx, args)
+	case "close":
+		return t.closePort(args)
+	default:
+		return ErrorResult(fmt.Sprintf("unknown action: %s (valid: list, open, write, read, close)", action))
+	}
+}
+
+// Close closes all open ports.
+func (t *SerialTool) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for device, s := range t.sessions {
+		s.port.Close()
+		delete(t.sessions, device)
+	}
+	return nil
+}
+
+func (t *SerialTool) list() *ToolResult {
+	type portInfo struct {
+		config.SerialToolPort
+		Open bool `json:"open"`
+	}
+	t.mu.Lock()
+	ports := make([]portInfo, 0, len(t.ports))
+	for _, p := range t.ports {
+		_, open := t.sessions[p.Device]
+		ports = append(ports, portInfo{SerialToolPort: p, Open: open})
+	}
+	t.mu.Unlock()
+	result, _ := json.MarshalIndent(ports, "", "  ")
+	return SilentResult(fmt.Sprintf("Allowed serial ports:\n%s", string(result)))
+}
+
+func (t *SerialTool) open(args map[string]any) *ToolResult {
+	port, errResult := t.resolvePort(args)
+	if errResult != nil {
+		return errResult
+	}
+
+	cfg := serialPortConfig(port)
+	if v, ok := args["baud"].(float64); ok {
+		cfg.Baud = int(v)
+	}
+	if v, ok := args["data_bits"].(float64); ok {
+		cfg.DataBits = int(v)
+	}
+	if v, ok := args["parity"].(string); ok && v != "" {
+		cfg.Parity = v
+	}
+	if v, ok := args["stop_bits"].(float64); ok {
+		cfg.StopBits = int(v)
+	}
+	if err := cfg.Validate(); err != nil {
+		return ErrorResult(err.Error())
+	}
+
+	t.mu.Lock()
+	s, ok := t.sessions[port.Device]
+	t.mu.Unlock()
+	if ok {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if err := s.port.Configure(cfg); err != nil {
+			return ErrorResult(err.Error())
+		}
+	} else if _, err := t.session(port, cfg); err != nil {
+		return ErrorResult(err.Error())
+	}
+	return SilentResult(serialJSON(port, map[string]any{"open": true, "settings": cfg.WithDefaults().String()}))
+}
+
+func (t *SerialTool) write(args map[string]any) *ToolResult {
+	confirm, _ := args["confirm"].(bool)
+	if !confirm {
+		return ErrorResult(
+			"write operations require confirm: true. Please confirm with the user before writing to serial devices, as they may control hardware.",
+		)
+	}
+	port, errResult := t.resolvePort(args)
+	if errResult != nil {
+		return errResult
+	}
+
+	text, ok := args["data"].(string)
+	if !ok || text == "" {
+		return ErrorResult("data is required for write")
+	}
+	data := []byte(text)
+	if encoding, _ := args["encoding"].(string); encoding == "hex" {
+		var err error
+		data, err = hex.DecodeString(strings.Join(strings.Fields(text), ""))
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("invalid hex data: %v", err))
+		}
+	}
+
+	s, err := t.session(port, serialPortConfig(port))
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n, err := s.port.Write(data)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to write to %s: %v", port.Device, err))
+	}
+	return SilentResult(serialJSON(port, map[string]any{"bytes_written": n}))
+}
+
+func (t *SerialTool) read(ctx context.Context, args map[string]any) *ToolResult {
+	port, errResult := t.resolvePort(args)
+	if errResult != nil {
+		return errResult
+	}
+
+	timeout := defaultSerialReadTimeout
+	if ms, ok := args["timeout_ms"].(float64); ok && ms > 0 {
+		timeout = time.Duration(ms) * time.Millisecond
+	}
+	if timeout > maxSerialReadTimeout {
+		return ErrorResult("timeout_ms must be at most 30000")
+	}
+	maxBytes := defaultSerialMaxBytes
+	if n, ok := args["max_bytes"].(float64); ok {
+		maxBytes = int(n)
+	}
+	if maxBytes < 1 || maxBytes > maxSerialMaxBytes {
+		return ErrorResult(fmt.Sprintf("max_bytes must be between 1 and %d", maxSerialMaxBytes))
+	}
+	var until *regexp.Regexp
+	if pattern, ok := args["until"].(string); ok && pattern != "" {
+		var err error
+		if until, err = regexp.Compile(pattern); err != nil {
+			return ErrorResult(fmt.Sprintf("invalid until pattern: %v", err))
+		}
+	}
+
+	s, err := t.session(port, serialPortConfig(port))
+	if err != nil {
+		return ErrorResult(err.Error())
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deadline := time.Now().Add(timeout)
+	buf := s.pending
+	s.pending = nil
+	matched, timedOut := false, false
+	chunk := make([]byte, 1024)
+	for {
+		if until != nil {
+			if loc := until.FindIndex(buf); loc != nil {
+				matched = true
+				s.pending = append(s.pending, buf[loc[1]:]...)
+				buf = buf[:loc[1]]
+				break
+			}
+		}
+		if len(buf) >= maxBytes {
+			s.pending = append(s.pending, buf[maxBytes:]...)
+			buf = buf[:maxBytes]
+			break
+		}
+		if ctx.Err() != nil {
+			s.pending = buf
+			return ErrorResult(fmt.Sprintf("read from %s cancelled", port.Device))
+		}
+
+		// Poll in short steps so cancellation is noticed, and without a
+		// pattern stop once the device goes quiet.
+		readDeadline := time.Now().Add(serialIdleGap)
+		if readDeadline.After(deadline) {
+			readDeadline = deadline
+		}
+		s.port.SetReadDeadline(readDeadline)
+		n, err := s.port.Read(chunk)
+		buf = append(buf, chunk[:n]...)
+		if err != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+			return ErrorResult(fmt.Sprintf("failed to read from %s: %v", port.Device, err))
+		}
+		if n == 0 {
+			if !time.Now().Before(deadline) {
+				timedOut = true
+				break
+			}
+			if until == nil && len(buf) > 0 {
+				break
+			}
+		}
+	}
+
+	fields := map[string]any{"bytes": len(buf)}
+	if until != nil {
+		fields["matched"] = matched
+	}
+	if timedOut {
+		fields["timed_out"] = true
+	}
+	if encoding, _ := args["encoding"].(string); encoding == "hex" || !utf8.Valid(buf) {
+		fields["hex"] = hex.EncodeToString(buf)
+	} else {
+		fields["data"] = string(buf)
+	}
+	return SilentResult(serialJSON(port, fields))
+}
+
+func (t *SerialTool) closePort(args map[string]any) *ToolResult {
+	port, errResult := t.resolvePort(args)
+	if errResult != nil {
+		return errResult
+	}
+	t.mu.Lock()
+	s, ok := t.sessions[port.Device]
+	delete(t.sessions, port.Device)
+	t.mu.Unlock()
+	if ok {
+		s.mu.Lock()
+		s.port.Close()
+		s.mu.Unlock()
+	}
+	return SilentResult(serialJSON(port, map[string]any{"open": false}))
+}
+
+// session returns the open session for port, opening it with cfg if needed.
+func (t *SerialTool) session(port config.SerialToolPort, cfg serial.Config) (*serialSession, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if s, ok := t.sessions[port.Device]; ok {
+		return s, nil
+	}
+	p, err := serial.Open(port.Device, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", port.Device, err)
+	}
+	s := &serialSession{port: p}
+	t.sessions[port.Device] = s
+	return s, nil
+}
+
+// resolvePort finds the allowlisted port named by args.
+func (t *SerialTool) resolvePort(args map[string]any) (config.SerialToolPort, *ToolResult) {
+	var found *config.SerialToolPort
+	if name, ok := args["name"].(string); ok && name != "" {
+		for i := range t.ports {
+			if t.ports[i].Name == name {
+				found = &t.ports[i]
+				break
+			}
+		}
+		if found == nil {
+			return config.SerialToolPort{}, ErrorResult(fmt.Sprintf("no allowed serial port named %q", name))
+		}
+	} else {
+		device, _ := args["device"].(string)
+		if device == "" {
+			return config.SerialToolPort{}, ErrorResult("name or device is required")
+		}
+		for i := range t.ports {
+			if t.ports[i].Device == device {
+				found = &t.ports[i]
+				break
+			}
+		}
+		if found == nil {
+			return config.SerialToolPort{}, ErrorResult(
+				fmt.Sprintf("%s is not in the allowed serial ports (tools.hardware.serial.ports)", device))
+		}
+	}
+
+	if !strings.HasPrefix(filepath.Clean(found.Device), "/dev/") {
+		return config.SerialToolPort{}, ErrorResult(fmt.Sprintf("invalid serial port config: %q is not a device", found.Device))
+	}
+	return *found, nil
+}
+
+func serialPortConfig(port config.SerialToolPort) serial.Config {
+	return serial.Config{
+		Baud:     port.Baud,
+		DataBits: port.DataBits,
+		Parity:   port.Parity,
+		StopBits: port.StopBits,
+	}
+}
+
+func serialJSON(port config.SerialToolPort, fields map[string]any) string {
+	fields["device"] = port.Device
+	if port.Name != "" {
+		fields["name"] = port.Name
+	}
+	result, _ := json.MarshalIndent(fields, "", "  ")
+	return string(result)
+}
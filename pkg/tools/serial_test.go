@@ -0,0 +1,116 @@
+//go:build linux
+
+package tools
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/devices/serial"
+)
+
+func newTestSerialTool(t *testing.T) (*SerialTool, *os.File) {
+	t.Helper()
+	master, path, err := serial.OpenPTY()
+	if err != nil {
+		t.Skipf("pseudo-terminals not available: %v", err)
+	}
+	tool := NewSerialTool(config.SerialToolConfig{Ports: []config.SerialToolPort{
+		{Name: "mcu", Device: path, Baud: 9600},
+		{Name: "bad", Device: "/etc/passwd"},
+	}})
+	t.Cleanup(func() {
+		tool.Close()
+		master.Close()
+	})
+	return tool, master
+}
+
+func TestSerialTool_Allowlist(t *testing.T) {
+	tool, _ := newTestSerialTool(t)
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{"action": "read", "device": "/dev/ttyS9"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "not in the allowed serial ports") {
+		t.Errorf("expected allowlist error, got %+v", result)
+	}
+	result = tool.Execute(ctx, map[string]any{"action": "read", "name": "bad"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "invalid serial port config") {
+		t.Errorf("expected config error, got %+v", result)
+	}
+	result = tool.Execute(ctx, map[string]any{"action": "write", "name": "mcu", "data": "x"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "confirm") {
+		t.Errorf("expected confirm error, got %+v", result)
+	}
+	result = tool.Execute(ctx, map[string]any{"action": "open", "name": "mcu", "parity": "mark"})
+	if !result.IsError {
+		t.Error("expected error for invalid parity")
+	}
+}
+
+func TestSerialTool_WriteRead(t *testing.T) {
+	tool, master := newTestSerialTool(t)
+	ctx := context.Background()
+
+	result := tool.Execute(ctx, map[string]any{"action": "open", "name": "mcu", "baud": float64(57600), "stop_bits": float64(2)})
+	if result.IsError || !strings.Contains(result.ForLLM, "57600 8N2") {
+		t.Fatalf("open = %+v", result)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "write", "name": "mcu", "data": "AT\r\n", "confirm": true})
+	if result.IsError || !strings.Contains(result.ForLLM, `"bytes_written": 4`) {
+		t.Fatalf("write = %+v", result)
+	}
+	buf := make([]byte, 16)
+	master.SetReadDeadline(time.Now().Add(time.Second))
+	n, _ := master.Read(buf)
+	if string(buf[:n]) != "AT\r\n" {
+		t.Errorf("device received %q", buf[:n])
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "write", "name": "mcu", "data": "01 03 ff", "encoding": "hex", "confirm": true})
+	if result.IsError {
+		t.Fatalf("hex write = %+v", result)
+	}
+	master.SetReadDeadline(time.Now().Add(time.Second))
+	n, _ = master.Read(buf)
+	if string(buf[:n]) != "\x01\x03\xff" {
+		t.Errorf("device received %x", buf[:n])
+	}
+
+	// Reading until a pattern keeps what follows for the next read.
+	master.Write([]byte("+CSQ: 20\r\nOK\r\nRING\r\n"))
+	result = tool.Execute(ctx, map[string]any{"action": "read", "name": "mcu", "until": `OK\r\n`, "timeout_ms": float64(1000)})
+	if result.IsError || !strings.Contains(result.ForLLM, `"matched": true`) ||
+		!strings.Contains(result.ForLLM, `"data": "+CSQ: 20\r\nOK\r\n"`) {
+		t.Fatalf("read until = %+v", result)
+	}
+	result = tool.Execute(ctx, map[string]any{"action": "read", "name": "mcu", "timeout_ms": float64(500)})
+	if result.IsError || !strings.Contains(result.ForLLM, `"data": "RING\r\n"`) {
+		t.Errorf("read remainder = %+v", result)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "read", "name": "mcu", "until": "OK", "timeout_ms": float64(50)})
+	if result.IsError || !strings.Contains(result.ForLLM, `"timed_out": true`) || !strings.Contains(result.ForLLM, `"matched": false`) {
+		t.Errorf("read timeout = %+v", result)
+	}
+
+	master.Write([]byte{0x00, 0xfe})
+	result = tool.Execute(ctx, map[string]any{"action": "read", "name": "mcu", "timeout_ms": float64(500)})
+	if result.IsError || !strings.Contains(result.ForLLM, `"hex": "00fe"`) {
+		t.Errorf("binary read = %+v", result)
+	}
+
+	result = tool.Execute(ctx, map[string]any{"action": "close", "name": "mcu"})
+	if result.IsError {
+		t.Errorf("close = %+v", result)
+	}
+	result = tool.Execute(ctx, map[string]any{"action": "list"})
+	if !strings.Contains(result.ForLLM, `"open": false`) {
+		t.Errorf("list = %+v", result)
+	}
+}
@@ -255,6 +255,15 @@ func (t *ExecTool) Execute(ctx context.Context, args map[string]any) *ToolResult
 	}
 }
 
+// CheckCommand applies the safety guard to a command that would run in the
+// tool's working directory, for callers that run commands themselves.
+func (t *ExecTool) CheckCommand(command string) error {
+	if guardError := t.guardCommand(command, t.workingDir); guardError != "" {
+		return errors.New(guardError)
+	}
+	return nil
+}
+
 func (t *ExecTool) guardCommand(command, cwd string) string {
 	cmd := strings.TrimSpace(command)
 	lower := strings.ToLower(cmd)
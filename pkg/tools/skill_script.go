@@ -0,0 +1,224 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+const (
+	defaultSkillScriptTimeout = 30 * time.Second
+	maxSkillScriptTimeout     = 10 * time.Minute
+	maxSkillScriptOutput      = 10000
+)
+
+// baseScriptEnv is passed to every skill script so interpreters work;
+// everything else must be allowlisted.
+var baseScriptEnv = []string{"PATH", "HOME", "LANG", "LC_ALL", "TZ", "TMPDIR", "SYSTEMROOT", "TEMP", "TMP"}
+
+// interpreters runs entrypoints by extension, since archive installs often
+// lose the executable bit.
+var interpreters = map[string]string{
+	".sh": "sh",
+	".py": "python3",
+	".js": "node",
+}
+
+// SkillScriptOptions are host-side limits applied to every skill tool.
+type SkillScriptOptions struct {
+	Workspace      string
+	DefaultTimeout time.Duration // used when the skill declares none
+	AllowedEnv     []string      // host environment variables skills may request
+}
+
+// SkillScriptTool exposes a tool declared in a skill's frontmatter. It runs
+// the skill's entrypoint script directly (no shell) in the skill directory,
+// with the validated arguments as a JSON object on stdin and stdout as the
+// result.
+type SkillScriptTool struct {
+	spec      skills.ToolSpec
+	schema    *jsonschema.Resolved
+	timeout   time.Duration
+	env       []string
+	workspace string
+}
+
+func NewSkillScriptTool(spec skills.ToolSpec, opts SkillScriptOptions) (*SkillScriptTool, error) {
+	schema, err := resolveToolSchema(spec.Parameters)
+	if err != nil {
+		return nil, fmt.Errorf("skill %s tool %s: invalid parameters schema: %w", spec.Skill, spec.Name, err)
+	}
+
+	timeout := opts.DefaultTimeout
+	if timeout <= 0 {
+		timeout = defaultSkillScriptTimeout
+	}
+	if spec.Timeout > 0 {
+		timeout = time.Duration(spec.Timeout) * time.Second
+	}
+	timeout = min(timeout, maxSkillScriptTimeout)
+
+	env := make([]string, 0, len(baseScriptEnv)+len(spec.Env))
+	for _, name := range baseScriptEnv {
+		if v, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+v)
+		}
+	}
+	for _, name := range spec.Env {
+		if !slices.Contains(opts.AllowedEnv, name) {
+			logger.WarnCF("tool", "Skill tool requests an environment variable that is not allowed",
+				map[string]any{
+					"skill":    spec.Skill,
+					"tool":     spec.Name,
+					"variable": name,
+				})
+			continue
+		}
+		if v, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+v)
+		}
+	}
+	env = append(env,
+		"PICOCLAW_SKILL_DIR="+spec.Dir,
+		"PICOCLAW_WORKSPACE="+opts.Workspace,
+	)
+
+	return &SkillScriptTool{
+		spec:      spec,
+		schema:    schema,
+		timeout:   timeout,
+		env:       env,
+		workspace: opts.Workspace,
+	}, nil
+}
+
+func resolveToolSchema(params map[string]any) (*jsonschema.Resolved, error) {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	var s jsonschema.Schema
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, err
+	}
+	return s.Resolve(nil)
+}
+
+func (t *SkillScriptTool) Name() string {
+	return t.spec.Name
+}
+
+func (t *SkillScriptTool) Description() string {
+	return t.spec.Description + " (from skill " + t.spec.Skill + ")"
+}
+
+func (t *SkillScriptTool) Parameters() map[string]any {
+	return t.spec.Parameters
+}
+
+func (t *SkillScriptTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	if args == nil {
+		args = map[string]any{}
+	}
+	input, err := json.Marshal(args)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("failed to encode arguments: %v", err))
+	}
+	// Validate the JSON form, which is what the script will see.
+	var instance any
+	_ = json.Unmarshal(input, &instance)
+	if err := t.schema.Validate(instance); err != nil {
+		return ErrorResult(fmt.Sprintf("invalid arguments: %v", err))
+	}
+
+	cmdCtx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	name, cmdArgs := t.command()
+	cmd := exec.CommandContext(cmdCtx, name, cmdArgs...)
+	cmd.Dir = t.spec.Dir
+	cmd.Env = t.env
+	cmd.Stdin = bytes.NewReader(input)
+
+	prepareCommandForTermination(cmd)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		return ErrorResult(fmt.Sprintf("failed to start %s: %v", t.spec.Entrypoint, err))
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err = <-done:
+	case <-cmdCtx.Done():
+		_ = terminateProcessTree(cmd)
+		select {
+		case err = <-done:
+		case <-time.After(2 * time.Second):
+			if cmd.Process != nil {
+				_ = cmd.Process.Kill()
+			}
+			err = <-done
+		}
+	}
+
+	if errors.Is(cmdCtx.Err(), context.DeadlineExceeded) {
+		return ErrorResult(fmt.Sprintf("%s timed out after %v", t.spec.Name, t.timeout))
+	}
+
+	output := stdout.String()
+	if err != nil {
+		if stderr.Len() > 0 {
+			output += "\nSTDERR:\n" + stderr.String()
+		}
+		output += fmt.Sprintf("\nExit code: %v", err)
+	}
+	if output == "" {
+		output = "(no output)"
+	}
+	if len(output) > maxSkillScriptOutput {
+		output = output[:maxSkillScriptOutput] +
+			fmt.Sprintf("\n... (truncated, %d more chars)", len(output)-maxSkillScriptOutput)
+	}
+
+	if err != nil {
+		return ErrorResult(output).WithError(err)
+	}
+	return NewToolResult(output)
+}
+
+// command returns the program and arguments that run the entrypoint.
+func (t *SkillScriptTool) command() (string, []string) {
+	entry := t.spec.EntrypointPath()
+	if t.spec.Interpreter != "" {
+		return t.spec.Interpreter, []string{entry}
+	}
+	if interp, ok := interpreters[strings.ToLower(filepath.Ext(entry))]; ok {
+		if runtime.GOOS == "windows" && interp == "python3" {
+			interp = "python"
+		}
+		return interp, []string{entry}
+	}
+	return entry, nil
+}
@@ -0,0 +1,105 @@
+//go:build !windows
+
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+func newTestSkillTool(t *testing.T, script string, spec skills.ToolSpec, opts SkillScriptOptions) *SkillScriptTool {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "run.sh"), []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	spec.Skill = "test-skill"
+	spec.Dir = dir
+	spec.Entrypoint = "run.sh"
+	if spec.Name == "" {
+		spec.Name = "test_tool"
+	}
+	if spec.Parameters == nil {
+		spec.Parameters = map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"city": map[string]any{"type": "string"}},
+			"required":   []any{"city"},
+		}
+	}
+	tool, err := NewSkillScriptTool(spec, opts)
+	if err != nil {
+		t.Fatalf("NewSkillScriptTool() error: %v", err)
+	}
+	return tool
+}
+
+func TestSkillScriptTool_PassesArgsOnStdin(t *testing.T) {
+	tool := newTestSkillTool(t, "cat; echo; pwd", skills.ToolSpec{}, SkillScriptOptions{})
+
+	result := tool.Execute(context.Background(), map[string]any{"city": "Paris"})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", result.ForLLM)
+	}
+	lines := strings.Split(strings.TrimSpace(result.ForLLM), "\n")
+	if lines[0] != `{"city":"Paris"}` {
+		t.Errorf("stdin = %q", lines[0])
+	}
+	wantDir, _ := filepath.EvalSymlinks(tool.spec.Dir)
+	if gotDir, _ := filepath.EvalSymlinks(lines[1]); gotDir != wantDir {
+		t.Errorf("working dir = %q, want %q", lines[1], tool.spec.Dir)
+	}
+}
+
+func TestSkillScriptTool_ValidatesArgs(t *testing.T) {
+	tool := newTestSkillTool(t, "echo ran", skills.ToolSpec{}, SkillScriptOptions{})
+
+	result := tool.Execute(context.Background(), map[string]any{"city": 42})
+	if !result.IsError || !strings.Contains(result.ForLLM, "invalid arguments") {
+		t.Errorf("expected validation error, got %+v", result)
+	}
+}
+
+func TestSkillScriptTool_EnvAllowlist(t *testing.T) {
+	t.Setenv("ALLOWED_KEY", "yes")
+	t.Setenv("DENIED_KEY", "secret")
+	t.Setenv("UNDECLARED_KEY", "secret")
+
+	tool := newTestSkillTool(t,
+		`echo "allowed=$ALLOWED_KEY denied=$DENIED_KEY undeclared=$UNDECLARED_KEY ws=$PICOCLAW_WORKSPACE"`,
+		skills.ToolSpec{Env: []string{"ALLOWED_KEY", "DENIED_KEY"}},
+		SkillScriptOptions{Workspace: "/ws", AllowedEnv: []string{"ALLOWED_KEY", "UNDECLARED_KEY"}},
+	)
+
+	result := tool.Execute(context.Background(), map[string]any{"city": "x"})
+	if got := strings.TrimSpace(result.ForLLM); got != "allowed=yes denied= undeclared= ws=/ws" {
+		t.Errorf("env = %q", got)
+	}
+}
+
+func TestSkillScriptTool_Timeout(t *testing.T) {
+	tool := newTestSkillTool(t, "sleep 5", skills.ToolSpec{}, SkillScriptOptions{DefaultTimeout: 200 * time.Millisecond})
+
+	start := time.Now()
+	result := tool.Execute(context.Background(), map[string]any{"city": "x"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "timed out") {
+		t.Errorf("expected timeout error, got %+v", result)
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Errorf("timeout took too long: %v", time.Since(start))
+	}
+}
+
+func TestSkillScriptTool_NonZeroExit(t *testing.T) {
+	tool := newTestSkillTool(t, "echo partial; echo boom >&2; exit 3", skills.ToolSpec{}, SkillScriptOptions{})
+
+	result := tool.Execute(context.Background(), map[string]any{"city": "x"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "boom") || !strings.Contains(result.ForLLM, "exit status 3") {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
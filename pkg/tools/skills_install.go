@@ -158,11 +158,29 @@ func (t *InstallSkillTool) Execute(ctx context.Context, args map[string]any) *To
 		_ = err
 	}
 
+	// Pin the installed version in the workspace lockfile.
+	if err := skills.RecordInstall(t.workspace, slug, skills.LockEntry{
+		Registry:    registry.Name(),
+		Slug:        slug,
+		Version:     result.Version,
+		ResolvedURL: result.ResolvedURL,
+	}); err != nil {
+		logger.ErrorCF("tool", "Failed to update skills lockfile",
+			map[string]any{
+				"tool":  "install_skill",
+				"error": err.Error(),
+				"slug":  slug,
+			})
+	}
+
 	// Build result with moderation warning if suspicious.
 	var output string
 	if result.IsSuspicious {
 		output = fmt.Sprintf("⚠️ Warning: skill %q is flagged as suspicious (may contain risky patterns).\n\n", slug)
 	}
+	if result.Trust.Status != "" && result.Trust.Status != skills.TrustVerified {
+		output += fmt.Sprintf("⚠️ Warning: skill %q signature: %s.\n\n", slug, result.Trust)
+	}
 	output += fmt.Sprintf("Successfully installed skill %q v%s from %s registry.\nLocation: %s\n",
 		slug, result.Version, registry.Name(), targetDir)
 
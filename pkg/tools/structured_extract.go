@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// StructuredExtractTool extracts data from text into JSON matching a
+// caller-supplied JSON schema. Skills use it to get machine-readable output
+// without parsing free-form model replies themselves.
+type StructuredExtractTool struct {
+	provider  providers.LLMProvider
+	model     string
+	maxTokens int
+}
+
+func NewStructuredExtractTool(provider providers.LLMProvider, model string) *StructuredExtractTool {
+	return &StructuredExtractTool{
+		provider:  provider,
+		model:     model,
+		maxTokens: 4096,
+	}
+}
+
+func (t *StructuredExtractTool) Name() string {
+	return "structured_extract"
+}
+
+func (t *StructuredExtractTool) Description() string {
+	return "Extract information from text as JSON that conforms to a given JSON schema. " +
+		"The result is validated against the schema before it is returned."
+}
+
+func (t *StructuredExtractTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"text": map[string]any{
+				"type":        "string",
+				"description": "The text to extract information from",
+			},
+			"schema": map[string]any{
+				"type":        "object",
+				"description": "JSON schema the result must conform to",
+			},
+			"instructions": map[string]any{
+				"type":        "string",
+				"description": "Optional extra guidance on what to extract",
+			},
+		},
+		"required": []string{"text", "schema"},
+	}
+}
+
+func (t *StructuredExtractTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	text, ok := args["text"].(string)
+	if !ok || strings.TrimSpace(text) == "" {
+		return ErrorResult("text is required and must be a non-empty string")
+	}
+
+	var schema map[string]any
+	switch s := args["schema"].(type) {
+	case map[string]any:
+		schema = s
+	case string:
+		// Some models send nested objects as JSON strings.
+		if err := json.Unmarshal([]byte(s), &schema); err != nil {
+			return ErrorResult(fmt.Sprintf("schema is not valid JSON: %v", err))
+		}
+	}
+	if len(schema) == 0 {
+		return ErrorResult("schema is required and must be a JSON schema object")
+	}
+
+	prompt := "Extract the requested information from the text below."
+	if instructions, _ := args["instructions"].(string); instructions != "" {
+		prompt += "\n" + instructions
+	}
+	prompt += "\n\nText:\n" + text
+
+	result, err := providers.ChatStructured(ctx, t.provider,
+		[]providers.Message{{Role: "user", Content: prompt}},
+		t.model,
+		map[string]any{"max_tokens": t.maxTokens, "temperature": 0.0},
+		providers.ResponseFormat{Name: "extraction", Schema: schema},
+		providers.DefaultStructuredAttempts,
+	)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("extraction failed: %v", err)).WithError(err)
+	}
+
+	return NewToolResult(string(result))
+}
@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+type extractProvider struct {
+	replies []string
+	calls   int
+}
+
+func (p *extractProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	options map[string]any,
+) (*providers.LLMResponse, error) {
+	reply := p.replies[min(p.calls, len(p.replies)-1)]
+	p.calls++
+	return &providers.LLMResponse{Content: reply}, nil
+}
+
+func (p *extractProvider) GetDefaultModel() string {
+	return "test-model"
+}
+
+func TestStructuredExtractTool_Execute(t *testing.T) {
+	provider := &extractProvider{replies: []string{`{"city": 1}`, `{"city": "Paris"}`}}
+	tool := NewStructuredExtractTool(provider, "test-model")
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"text":   "I live in Paris.",
+		"schema": `{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}`,
+	})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", result.ForLLM)
+	}
+	if result.ForLLM != `{"city": "Paris"}` {
+		t.Errorf("ForLLM = %q", result.ForLLM)
+	}
+	if provider.calls != 2 {
+		t.Errorf("expected a retry after the invalid reply, got %d calls", provider.calls)
+	}
+}
+
+func TestStructuredExtractTool_InvalidArgs(t *testing.T) {
+	tool := NewStructuredExtractTool(&extractProvider{replies: []string{"{}"}}, "test-model")
+
+	result := tool.Execute(context.Background(), map[string]any{"text": "x"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "schema") {
+		t.Errorf("expected schema error, got %+v", result)
+	}
+	result = tool.Execute(context.Background(), map[string]any{"schema": map[string]any{"type": "object"}})
+	if !result.IsError || !strings.Contains(result.ForLLM, "text") {
+		t.Errorf("expected text error, got %+v", result)
+	}
+}
@@ -54,7 +54,7 @@ func RunToolLoop(
 		// 1. Build tool definitions
 		var providerToolDefs []providers.ToolDefinition
 		if config.Tools != nil {
-			providerToolDefs = config.Tools.ToProviderDefs()
+			providerToolDefs = FilterDefs(ctx, config.Tools.ToProviderDefs())
 		}
 
 		// 2. Set default LLM options
@@ -12,6 +12,7 @@ import (
 	"path"
 	"strings"
 
+	"github.com/sipeed/picoclaw/pkg/access"
 	"github.com/sipeed/picoclaw/pkg/agent"
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/config"
@@ -198,7 +199,8 @@ func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 		req.SessionKey = "web:default"
 	}
 
-	ctx := context.Background()
+	// The web UI is the device owner's console, not subject to access roles.
+	ctx := access.Trusted(context.Background())
 	response, err := s.agentLoop.ProcessDirectWithChannel(ctx, req.Content, req.SessionKey, "web", "default")
 	if err != nil {
 		logWebError("chat_process_failed", err)